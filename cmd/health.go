@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/spf13/cobra"
+)
+
+// healthCmd represents the health command
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check that the Searxng instance answers searches",
+	Long: `Run a trivial search against the configured Searxng instance and exit
+with a nonzero status unless it answers with JSON results within --timeout.
+
+This command is meant for container health checks and scripts; the cache
+and rate limit don't apply to it. In HTTP mode, serve also offers the
+/healthz (liveness) and /readyz (readiness) probe endpoints.
+
+Examples:
+  # Check the configured instance
+  searxng-mcp health
+
+  # Fail when the instance takes longer than 5 seconds
+  searxng-mcp health --instance-url https://searxng.example.com --timeout 5s`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.NoArgs(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := searxng.NewClient(newSearxngConfig())
+		if err != nil {
+			return fmt.Errorf("failed to create searxng client: %w", err)
+		}
+
+		// The instance being down is not a usage error
+		cmd.SilenceUsage = true
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		start := time.Now()
+		if err := client.HealthCheck(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("ok: %s answered in %s\n", client.BaseURL(), time.Since(start).Round(time.Millisecond))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(healthCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/paths"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/spf13/viper"
+)
+
+// queryHistoryFile holds the searches run by the search command, for --last
+// and !! references
+const queryHistoryFile = "queries.json"
+
+// maxQueryHistory bounds the number of remembered searches
+const maxQueryHistory = 100
+
+// queryHistoryEntry is a search run by the search command
+type queryHistoryEntry struct {
+	Query      string             `json:"query"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TimeRange  string             `json:"time_range,omitempty"`
+	Category   string             `json:"category,omitempty"`
+	SafeSearch searxng.SafeSearch `json:"safe_search,omitempty"`
+	Dedupe     bool               `json:"dedupe,omitempty"`
+	Time       time.Time          `json:"time"`
+}
+
+// queryHistoryDir returns --state-dir when set, and otherwise the OS state
+// directory (see paths.StateDir)
+func queryHistoryDir() (string, error) {
+	if dir := viper.GetString("state-dir"); dir != "" {
+		return dir, nil
+	}
+	dir, err := paths.StateDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the query history: %w", err)
+	}
+	return dir, nil
+}
+
+// loadQueryHistory returns the remembered searches, oldest first
+func loadQueryHistory() ([]queryHistoryEntry, error) {
+	dir, err := queryHistoryDir()
+	if err != nil {
+		return nil, err
+	}
+	var entries []queryHistoryEntry
+	if err := readStateFile(dir, queryHistoryFile, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// appendQueryHistory remembers entry, dropping the oldest searches beyond
+// maxQueryHistory
+func appendQueryHistory(entries []queryHistoryEntry, entry queryHistoryEntry) error {
+	dir, err := queryHistoryDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	entries = append(entries, entry)
+	if len(entries) > maxQueryHistory {
+		entries = entries[len(entries)-maxQueryHistory:]
+	}
+	return writeStateFile(dir, queryHistoryFile, entries)
+}
+
+// historyReference matches !! (the previous query) and !-N (the Nth
+// previous query)
+var historyReference = regexp.MustCompile(`^(?:!!|!-([0-9]+))$`)
+
+// expandHistory returns the query of the search arguments. A history
+// reference as the first argument is replaced with the remembered query,
+// followed by the other arguments, e.g. "!! tutorial" refines the previous
+// query. References are only recognized as whole arguments, so queries such
+// as "!!g golang" (a SearXNG bang) are searched as they are.
+func expandHistory(args []string, entries []queryHistoryEntry) (string, error) {
+	match := historyReference.FindStringSubmatch(args[0])
+	if match == nil {
+		return strings.Join(args, " "), nil
+	}
+	n := 1
+	if match[1] != "" {
+		n, _ = strconv.Atoi(match[1])
+	}
+	if n < 1 || n > len(entries) {
+		return "", fmt.Errorf("%s: no such query in history (%d remembered)", args[0], len(entries))
+	}
+	return strings.Join(append([]string{entries[len(entries)-n].Query}, args[1:]...), " "), nil
+}
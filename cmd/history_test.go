@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandHistory(t *testing.T) {
+	entries := []queryHistoryEntry{{Query: "golang"}, {Query: "rust"}}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"!!"}, "rust"},
+		{[]string{"!!", "generics"}, "rust generics"},
+		{[]string{"!-2", "generics", "tutorial"}, "golang generics tutorial"},
+		{[]string{"!!g golang"}, "!!g golang"},
+		{[]string{"!! generics"}, "!! generics"},
+		{[]string{"what is !!"}, "what is !!"},
+	}
+	for _, tt := range tests {
+		got, err := expandHistory(tt.args, entries)
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, tt.want, got, tt.args)
+	}
+
+	_, err := expandHistory([]string{"!-3"}, entries)
+	assert.ErrorContains(t, err, "!-3: no such query in history (2 remembered)")
+	_, err = expandHistory([]string{"!!"}, nil)
+	assert.Error(t, err)
+}
+
+func TestSearchArgs(t *testing.T) {
+	assert.NoError(t, searchArgs(searchCmd, []string{"golang tutorial"}))
+	assert.NoError(t, searchArgs(searchCmd, []string{"!!", "generics"}))
+	assert.Error(t, searchArgs(searchCmd, []string{"golang", "tutorial"}))
+}
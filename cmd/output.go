@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the search command
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var flagOutput string
+
+// errTooFewResults is returned when --fail-empty or --min-results fail
+var errTooFewResults = errors.New("too few results")
+
+// usageError is an invalid invocation: bad flags, arguments or flag values
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// usageErrorf formats a usageError
+func usageErrorf(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// configError is an invalid setting from flags, the environment or the
+// config file
+type configError struct {
+	err error
+}
+
+func (e configError) Error() string { return e.err.Error() }
+func (e configError) Unwrap() error { return e.err }
+
+// jsonOutput reports whether cmd prints its results, and so its errors, as
+// JSON
+func jsonOutput(cmd *cobra.Command) bool {
+	switch cmd {
+	case searchCmd:
+		return flagOutput == outputJSON
+	case suggestCmd:
+		return flagSuggestOutput == outputJSON
+	}
+	return false
+}
+
+// silenceForJSON keeps cobra from printing errors and usage in text when
+// they are written as JSON by Execute
+func silenceForJSON(cmd *cobra.Command) {
+	if jsonOutput(cmd) {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+}
+
+// cliError is the JSON form of an error written to stderr with --output json
+type cliError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"` // the same command may succeed later
+}
+
+// classifyError maps err to a stable code scripts can branch on
+func classifyError(err error) cliError {
+	e := cliError{Code: "error", Message: err.Error()}
+	var usage usageError
+	var config configError
+	var httpErr *searxng.HTTPError
+	switch {
+	case errors.As(err, &usage):
+		e.Code = "usage"
+	case errors.As(err, &config):
+		e.Code = "invalid_config"
+	case errors.Is(err, errTooFewResults):
+		e.Code = "too_few_results"
+	case errors.Is(err, searxng.ErrInstanceLimited):
+		e.Code, e.Retryable = "instance_limited", true
+	case errors.Is(err, searxng.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		e.Code, e.Retryable = "timeout", true
+	case errors.Is(err, searxng.ErrPinMismatch):
+		e.Code = "pin_mismatch"
+	case errors.Is(err, searxng.ErrJSONFormatDisabled):
+		e.Code = "json_format_disabled"
+	case errors.Is(err, searxng.ErrInvalidURL), errors.Is(err, searxng.ErrInvalidPin):
+		e.Code = "invalid_config"
+	case errors.Is(err, searxng.ErrInvalidResponse):
+		e.Code, e.Retryable = "invalid_response", true
+	case errors.As(err, &httpErr) && httpErr.StatusCode < 500:
+		e.Code = "request_failed"
+	case errors.Is(err, searxng.ErrRequestFailed):
+		e.Code, e.Retryable = "request_failed", true
+	}
+	return e
+}
+
+// writeJSONError writes err to w as a single-line cliError object
+func writeJSONError(w io.Writer, err error) {
+	_ = json.NewEncoder(w).Encode(map[string]cliError{"error": classifyError(err)})
+}
+
+// jsonSearchResult is a search result printed with --output json
+type jsonSearchResult struct {
+	Title         string     `json:"title"`
+	URL           string     `json:"url"`
+	Content       string     `json:"content,omitempty"`
+	PublishedDate *time.Time `json:"published_date,omitempty"`
+	Engines       []string   `json:"engines,omitempty"`
+	Score         float64    `json:"score,omitempty"`
+}
+
+// writeJSONResults writes resp to w as an indented JSON object
+func writeJSONResults(w io.Writer, resp *searxng.SearchResponse) error {
+	results := make([]jsonSearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		engines := r.Engines
+		if len(engines) == 0 && r.Engine != "" {
+			engines = []string{r.Engine}
+		}
+		results = append(results, jsonSearchResult{
+			Title:         r.Title,
+			URL:           r.URL,
+			Content:       r.Content,
+			PublishedDate: r.PublishedDate,
+			Engines:       engines,
+			Score:         r.Score,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Query           string             `json:"query"`
+		NumberOfResults int                `json:"number_of_results"`
+		Results         []jsonSearchResult `json:"results"`
+		Answers         []string           `json:"answers,omitempty"`
+		Suggestions     []string           `json:"suggestions,omitempty"`
+		Corrections     []string           `json:"corrections,omitempty"`
+	}{resp.Query, resp.NumberOfResults, results, resp.Answers, resp.Suggestions, resp.Corrections})
+}
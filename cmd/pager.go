@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Terminal size assumed when it can't be queried and $COLUMNS and $LINES
+// aren't set
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
+// markdownHeading matches ATX headings, the only kind the reader writes
+var markdownHeading = regexp.MustCompile(`^#{1,6}(\s|$)`)
+
+// pagerCommand is an action of the built-in pager
+type pagerCommand int
+
+const (
+	pagerNone pagerCommand = iota
+	pagerQuit
+	pagerLineDown
+	pagerLineUp
+	pagerPageDown
+	pagerPageUp
+	pagerTop
+	pagerBottom
+	pagerNextHeading
+	pagerPrevHeading
+)
+
+// pagerHeading is a Markdown heading of the text of a pager
+type pagerHeading struct {
+	line  int // index in pager.lines
+	title string
+}
+
+// pager shows text one screen at a time, with keys to scroll and jump
+// between Markdown headings
+type pager struct {
+	title    string
+	lines    []string // wrapped to the screen width
+	headings []pagerHeading
+	top      int // first line shown
+	width    int
+	height   int // lines of text per screen, the status line excluded
+}
+
+// newPager splits content into lines of at most width runes, finding the
+// headings outside fenced code blocks
+func newPager(title, content string, width, height int) *pager {
+	p := &pager{title: title, width: max(width, 1), height: max(height, 1)}
+	inFence := false
+	for line := range strings.Lines(strings.TrimRight(content, "\n")) {
+		line = strings.ReplaceAll(strings.TrimRight(line, "\r\n"), "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		} else if !inFence && markdownHeading.MatchString(line) {
+			p.headings = append(p.headings, pagerHeading{line: len(p.lines), title: strings.TrimSpace(strings.TrimLeft(line, "#"))})
+		}
+		p.lines = append(p.lines, wrapLine(line, p.width)...)
+	}
+	return p
+}
+
+// wrapLine cuts line into pieces of at most width runes
+func wrapLine(line string, width int) []string {
+	var pieces []string
+	for utf8.RuneCountInString(line) > width {
+		cut := 0
+		for range width {
+			_, size := utf8.DecodeRuneInString(line[cut:])
+			cut += size
+		}
+		pieces = append(pieces, line[:cut])
+		line = line[cut:]
+	}
+	return append(pieces, line)
+}
+
+// fits reports whether the text fits on one screen
+func (p *pager) fits() bool {
+	return len(p.lines) <= p.height
+}
+
+// scrollTo shows line first, as far as the end of the text allows
+func (p *pager) scrollTo(line int) {
+	p.top = max(0, min(line, len(p.lines)-p.height))
+}
+
+// apply runs cmd, reporting false once the pager should quit
+func (p *pager) apply(cmd pagerCommand) bool {
+	switch cmd {
+	case pagerQuit:
+		return false
+	case pagerLineDown:
+		p.scrollTo(p.top + 1)
+	case pagerLineUp:
+		p.scrollTo(p.top - 1)
+	case pagerPageDown:
+		p.scrollTo(p.top + p.height)
+	case pagerPageUp:
+		p.scrollTo(p.top - p.height)
+	case pagerTop:
+		p.scrollTo(0)
+	case pagerBottom:
+		p.scrollTo(len(p.lines))
+	case pagerNextHeading:
+		for _, h := range p.headings {
+			if h.line > p.top {
+				p.scrollTo(h.line)
+				break
+			}
+		}
+	case pagerPrevHeading:
+		for i := len(p.headings) - 1; i >= 0; i-- {
+			if p.headings[i].line < p.top {
+				p.scrollTo(p.headings[i].line)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// section returns the heading of the section shown at the top of the screen
+func (p *pager) section() string {
+	section := ""
+	for _, h := range p.headings {
+		if h.line > p.top {
+			break
+		}
+		section = h.title
+	}
+	return section
+}
+
+// render draws the current screen and the status line below it
+func (p *pager) render(w io.Writer) {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	end := min(p.top+p.height, len(p.lines))
+	for _, line := range p.lines[p.top:end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for range p.height - (end - p.top) {
+		b.WriteString("~\n")
+	}
+
+	status := fmt.Sprintf("%s  lines %d-%d of %d (%d%%)", p.title, p.top+1, end, len(p.lines), 100*end/max(len(p.lines), 1))
+	if section := p.section(); section != "" {
+		status += "  § " + section
+	}
+	status += "  [space/b] page [n/p] heading [g/G] top/bottom [q] quit"
+	b.WriteString("\x1b[7m")
+	b.WriteString(wrapLine(status, p.width)[0])
+	b.WriteString("\x1b[0m")
+	_, _ = io.WriteString(w, b.String())
+}
+
+// run shows the text until the user quits or in ends. In raw mode each key
+// is a command; otherwise commands are typed as lines, an empty one
+// showing the next page.
+func (p *pager) run(in io.Reader, out io.Writer, raw bool) error {
+	// Use the alternate screen, so the terminal is restored on exit
+	_, _ = io.WriteString(out, "\x1b[?1049h")
+	defer func() { _, _ = io.WriteString(out, "\x1b[?1049l") }()
+
+	r := bufio.NewReader(in)
+	for {
+		p.render(out)
+		cmd, err := readPagerCommand(r, raw)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !p.apply(cmd) {
+			return nil
+		}
+	}
+}
+
+// readPagerCommand reads the next command: a key in raw mode, a line
+// otherwise
+func readPagerCommand(r *bufio.Reader, raw bool) (pagerCommand, error) {
+	if !raw {
+		line, err := r.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err != nil {
+				return pagerNone, err
+			}
+			return pagerPageDown, nil
+		}
+		return keyCommand(line[0]), nil
+	}
+
+	key, err := r.ReadByte()
+	if err != nil {
+		return pagerNone, err
+	}
+	// Arrow, Page Up/Down, Home and End keys send escape sequences
+	if key != 0x1b || r.Buffered() == 0 {
+		return keyCommand(key), nil
+	}
+	seq := make([]byte, 0, 3)
+	for r.Buffered() > 0 && len(seq) < cap(seq) {
+		b, _ := r.ReadByte()
+		seq = append(seq, b)
+		if b != '[' && b != 'O' && (b < '0' || b > '9') {
+			break
+		}
+	}
+	switch strings.TrimLeft(string(seq), "[O") {
+	case "A":
+		return pagerLineUp, nil
+	case "B":
+		return pagerLineDown, nil
+	case "5~":
+		return pagerPageUp, nil
+	case "6~":
+		return pagerPageDown, nil
+	case "H", "1~":
+		return pagerTop, nil
+	case "F", "4~":
+		return pagerBottom, nil
+	}
+	return pagerNone, nil
+}
+
+// keyCommand maps a key to its command, following less
+func keyCommand(key byte) pagerCommand {
+	switch key {
+	case 'q', 'Q', 0x03, 0x04: // Ctrl-C and Ctrl-D quit too, signals are off in raw mode
+		return pagerQuit
+	case 'j', 'e', '\r', '\n':
+		return pagerLineDown
+	case 'k', 'y':
+		return pagerLineUp
+	case ' ', 'f', 'd':
+		return pagerPageDown
+	case 'b', 'u':
+		return pagerPageUp
+	case 'g', '<':
+		return pagerTop
+	case 'G', '>':
+		return pagerBottom
+	case 'n', ']':
+		return pagerNextHeading
+	case 'p', 'N', '[':
+		return pagerPrevHeading
+	}
+	return pagerNone
+}
+
+// stripControl drops the C0 and C1 control characters of s but tabs and
+// newlines, so text fetched from the web can't send escape sequences to the
+// terminal (moving the cursor, rewriting the title or the clipboard, ...)
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\t' && r != '\n' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// envTerminalSize returns the terminal size from $COLUMNS and $LINES,
+// falling back to 80x24
+func envTerminalSize() (width, height int) {
+	width, height = defaultTerminalWidth, defaultTerminalHeight
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		width = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		height = n
+	}
+	return width, height
+}
+
+// showPaged writes content to stdout through $PAGER, or the built-in pager
+// when it is unset. Content is written as is when stdout isn't a terminal,
+// and without control characters when it fits on one screen.
+func showPaged(title, content string) error {
+	if !isTerminal(os.Stdout) {
+		_, err := fmt.Fprintln(os.Stdout, content)
+		return err
+	}
+	content = stripControl(content)
+
+	if args := strings.Fields(os.Getenv("PAGER")); len(args) > 0 {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(content + "\n")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run pager %s: %w", args[0], err)
+		}
+		return nil
+	}
+
+	width, height := terminalSize(os.Stdout)
+	p := newPager(title, content, width, height-1)
+	if p.fits() {
+		_, err := fmt.Fprintln(os.Stdout, content)
+		return err
+	}
+	restore, err := makeRaw(os.Stdin)
+	raw := err == nil
+	if raw {
+		defer restore()
+	}
+	return p.run(os.Stdin, os.Stdout, raw)
+}
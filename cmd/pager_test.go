@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapLine(t *testing.T) {
+	assert.Equal(t, []string{""}, wrapLine("", 4))
+	assert.Equal(t, []string{"abcd"}, wrapLine("abcd", 4))
+	assert.Equal(t, []string{"abcd", "ef"}, wrapLine("abcdef", 4))
+	assert.Equal(t, []string{"äöü", "ß"}, wrapLine("äöüß", 3), "runes are not split")
+}
+
+func TestNewPager_Headings(t *testing.T) {
+	content := strings.Join([]string{
+		"# Title",
+		"intro",
+		"```",
+		"# not a heading",
+		"```",
+		"#hashtag",
+		"## Section\twith tab",
+		"a very long line that wraps",
+		"### Sub",
+	}, "\n")
+	p := newPager("page", content, 20, 3)
+
+	assert.Equal(t, []pagerHeading{
+		{line: 0, title: "Title"},
+		{line: 6, title: "Section    with tab"},
+		{line: 10, title: "Sub"},
+	}, p.headings)
+	assert.Len(t, p.lines, 11, "long lines are wrapped")
+	assert.False(t, p.fits())
+	assert.True(t, newPager("page", "one\ntwo\n", 10, 3).fits())
+}
+
+func TestPager_Apply(t *testing.T) {
+	var lines []string
+	for i := range 20 {
+		if i%5 == 0 {
+			lines = append(lines, "# Heading")
+			continue
+		}
+		lines = append(lines, "text")
+	}
+	p := newPager("page", strings.Join(lines, "\n"), 80, 4)
+
+	steps := []struct {
+		cmd     pagerCommand
+		wantTop int
+	}{
+		{pagerLineUp, 0},
+		{pagerLineDown, 1},
+		{pagerPageDown, 5},
+		{pagerNextHeading, 10},
+		{pagerPrevHeading, 5},
+		{pagerBottom, 16},
+		{pagerPageDown, 16},
+		{pagerNextHeading, 16},
+		{pagerPageUp, 12},
+		{pagerTop, 0},
+		{pagerNone, 0},
+	}
+	for _, step := range steps {
+		assert.True(t, p.apply(step.cmd))
+		assert.Equal(t, step.wantTop, p.top, "after command %d", step.cmd)
+	}
+	assert.False(t, p.apply(pagerQuit))
+}
+
+func TestReadPagerCommand(t *testing.T) {
+	read := func(input string, raw bool) []pagerCommand {
+		r := bufio.NewReader(strings.NewReader(input))
+		var cmds []pagerCommand
+		for {
+			cmd, err := readPagerCommand(r, raw)
+			if err == io.EOF {
+				return cmds
+			}
+			require.NoError(t, err)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	assert.Equal(t, []pagerCommand{pagerPageDown, pagerNextHeading, pagerQuit, pagerNone},
+		read("\n  n\nquit\nx", false))
+	assert.Equal(t, []pagerCommand{pagerLineDown, pagerPageUp, pagerLineUp, pagerPageDown, pagerTop, pagerBottom, pagerQuit},
+		read("j\x1b[5~\x1b[A\x1b[6~\x1bOH\x1b[4~\x03", true))
+}
+
+func TestStripControl(t *testing.T) {
+	assert.Equal(t, "title\tcell\nnext line", stripControl("title\tcell\nnext line"))
+	assert.Equal(t, "[2J31mred]0;pwned", stripControl("\x1b[2J\u009b31mred\a\x1b]0;pwned\x07\r\x7f"))
+	assert.Equal(t, "a\uFFFDb", stripControl("a\x9bb"), "a raw 8-bit CSI isn't passed through")
+	assert.Equal(t, "Grüße", stripControl("Grüße"))
+}
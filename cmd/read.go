@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/denysvitali/searxng-mcp/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	flagReadMode        string
+	flagReadBoilerplate string
+	flagReadRawHTML     bool
+	flagReadOutput      string
+	flagReadRobots      bool
+	flagReadPager       bool
+)
+
+// readCmd represents the read command
+var readCmd = &cobra.Command{
+	Use:   "read <url>",
+	Short: "Read a web page as Markdown",
+	Long: `Fetch a web page and convert it to Markdown with the same pipeline as
+the searxng_read tool, printing it to stdout.
+
+This command is useful for testing the reader and reading pages without an
+MCP client. It doesn't need a Searxng instance.
+
+Examples:
+  # Print a page as Markdown
+  searxng-mcp read https://go.dev/doc/effective_go
+
+  # Extract only the main article
+  searxng-mcp read https://go.dev/blog/go1.22 --mode article
+
+  # Save the page to a file
+  searxng-mcp read https://go.dev/doc/effective_go --output effective_go.md
+
+  # Print the sanitized HTML instead of Markdown
+  searxng-mcp read https://go.dev/doc/effective_go --raw-html
+
+  # Read the page in $PAGER, or the built-in pager when it is unset
+  searxng-mcp read https://go.dev/doc/effective_go --pager`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagReadPager && flagReadOutput != "" {
+			return usageErrorf("--pager can't be combined with --output")
+		}
+		mode, err := server.ParseReadMode(flagReadMode)
+		if err != nil {
+			return usageError{err}
+		}
+		boilerplate, err := server.ParseBoilerplateLevel(flagReadBoilerplate)
+		if err != nil {
+			return usageError{err}
+		}
+
+		srv := server.NewWithOptions(nil, server.Options{
+			Boilerplate:   boilerplate,
+			RespectRobots: flagReadRobots,
+			ProxyURL:      viper.GetString("proxy"),
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		page, err := srv.Read(ctx, server.ReadRequest{URL: args[0], Mode: mode, IncludeHTML: flagReadRawHTML})
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+
+		content := page.Markdown
+		if flagReadRawHTML {
+			if page.HTML == "" {
+				return fmt.Errorf("%s has no HTML to print; read it without --raw-html", args[0])
+			}
+			content = page.HTML
+		}
+		if flagReadPager {
+			return showPaged(args[0], content)
+		}
+		if flagReadOutput == "" {
+			if isTerminal(os.Stdout) {
+				content = stripControl(content)
+			}
+			_, err = fmt.Fprintln(os.Stdout, content)
+			return err
+		}
+		if err := os.WriteFile(flagReadOutput, []byte(content+"\n"), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", flagReadOutput, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(readCmd)
+
+	readCmd.Flags().StringVar(&flagReadMode, "mode", "full", "full converts the whole page; article extracts only the main article")
+	readCmd.Flags().StringVar(&flagReadBoilerplate, "boilerplate", "normal", "Trailing boilerplate removal: off, normal or aggressive")
+	readCmd.Flags().BoolVar(&flagReadRawHTML, "raw-html", false, "Print the sanitized HTML of the page instead of Markdown")
+	readCmd.Flags().StringVarP(&flagReadOutput, "output", "o", "", "File to write the page to (default: stdout)")
+	readCmd.Flags().BoolVar(&flagReadPager, "pager", false, "Show the page in $PAGER, or a built-in pager with heading navigation when it is unset")
+	readCmd.Flags().BoolVar(&flagReadRobots, "respect-robots", false, "Refuse URLs disallowed by the site's robots.txt")
+}
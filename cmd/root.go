@@ -3,22 +3,54 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/internal/paths"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	// Flags
+	flagConfig      string
 	flagInstanceURL string
 	flagLogLevel    string
+	flagLogFormat   string
+	flagLogFile     string
 	flagTimeout     time.Duration
 
+	flagMaxIdleConns        int
+	flagMaxIdleConnsPerHost int
+	flagMaxConnsPerHost     int
+	flagIdleConnTimeout     time.Duration
+	flagDisableHTTP2        bool
+	flagPinSPKI             []string
+	flagRateLimit           int
+	flagRateBurst           int
+	flagEngines             []string
+	flagCacheTTL            time.Duration
+	flagCacheSize           int
+	flagStateDir            string
+	flagImageProxySecret    string
+	flagProxy               string
+	flagRankBy              string
+	flagTrustedDomains      []string
+	flagDistrustedDomains   []string
+	flagCookies             []string
+	flagLogMaxSize          int
+	flagLogMaxBackups       int
+	flagLogComponentLevels  []string
+
 	// Config values that will be used by subcommands
 	instanceURL string
 	timeout     time.Duration
+
+	// configErr is set by initConfig when an explicitly requested config
+	// file cannot be read; it is reported from PersistentPreRunE
+	configErr error
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -28,54 +60,193 @@ var rootCmd = &cobra.Command{
 	Long: `Searxng MCP Server - A Model Context Protocol server that enables
 AI agents to search and navigate the web using Searxng instances.
 
-This server provides two main tools:
+This server provides the following tools:
   - searxng_search: Search the web and return limited results
-  - searxng_read: Fetch and read content from URLs, converting HTML to Markdown`,
+  - searxng_read: Fetch and read content from URLs, converting HTML to Markdown
+  - searxng_refine_search: Rewrite a previous search from feedback and run it
+  - searxng_image_search: Search images with their thumbnails and source pages
+  - searxng_search_and_read: Search and read the top results in one call
+  - searxng_suggestions: Get autocomplete suggestions to refine a query
+  - searxng_compare_pages: Compare the sections and text of two pages
+  - searxng_extract_links: List the links of a page`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// Initialize logger
-		log.Init(viper.GetString("log-level"))
+		silenceForJSON(cmd)
+		if configErr != nil {
+			return configError{configErr}
+		}
+
+		if err := initLogging(); err != nil {
+			return configError{err}
+		}
 
 		// Set config values from viper (merges flags, env, config file)
 		instanceURL = viper.GetString("instance-url")
 		timeout = viper.GetDuration("timeout")
 
-		if instanceURL == "" {
-			return fmt.Errorf("instance URL cannot be empty")
+		// read fetches pages itself, without the instance
+		if instanceURL == "" && cmd != readCmd {
+			return configError{fmt.Errorf("instance URL cannot be empty")}
 		}
 
 		if timeout == 0 {
 			timeout = 30 * time.Second
 		}
 
+		if proxy := viper.GetString("proxy"); proxy != "" {
+			if _, err := searxng.ParseProxyURL(proxy); err != nil {
+				return configError{err}
+			}
+		}
+
+		if _, err := searxng.ParseRankStrategy(viper.GetString("rank-by")); err != nil {
+			return configError{err}
+		}
+
+		if _, err := searxng.ParseCookies(viper.GetStringSlice("cookie")); err != nil {
+			return configError{err}
+		}
+
+		if f := viper.ConfigFileUsed(); f != "" {
+			log.WithField("config_file", f).Debug("loaded config file")
+		}
 		log.WithField("instance_url", instanceURL).Debug("using searxng instance")
 		return nil
 	},
 }
 
+// initLogging configures the logger from the merged log settings
+func initLogging() error {
+	componentLevels, err := log.ParseComponentLevels(viper.GetStringSlice("log-component-levels"))
+	if err != nil {
+		return err
+	}
+	return log.Configure(log.Config{
+		Level:           viper.GetString("log-level"),
+		Format:          viper.GetString("log-format"),
+		File:            viper.GetString("log-file"),
+		MaxSize:         int64(viper.GetInt("log-max-size")) << 20,
+		MaxBackups:      viper.GetInt("log-max-backups"),
+		ComponentLevels: componentLevels,
+	})
+}
+
+// newSearxngConfig builds the Searxng client config from the merged
+// flag/env/config-file values.
+func newSearxngConfig() *searxng.Config {
+	rankBy, _ := searxng.ParseRankStrategy(viper.GetString("rank-by")) // validated in PersistentPreRunE
+	cookies, _ := searxng.ParseCookies(viper.GetStringSlice("cookie")) // validated in PersistentPreRunE
+	return &searxng.Config{
+		BaseURL:             instanceURL,
+		Timeout:             timeout,
+		MaxIdleConns:        viper.GetInt("max-idle-conns"),
+		MaxIdleConnsPerHost: viper.GetInt("max-idle-conns-per-host"),
+		MaxConnsPerHost:     viper.GetInt("max-conns-per-host"),
+		IdleConnTimeout:     viper.GetDuration("idle-conn-timeout"),
+		DisableHTTP2:        viper.GetBool("disable-http2"),
+		PinnedSPKI:          viper.GetStringSlice("pin-spki"),
+		RateLimit:           viper.GetInt("rate-limit"),
+		RateBurst:           viper.GetInt("rate-burst"),
+		Engines:             viper.GetStringSlice("engines"),
+		CacheTTL:            viper.GetDuration("cache-ttl"),
+		CacheSize:           viper.GetInt("cache-size"),
+		ImageProxySecret:    viper.GetString("image-proxy-secret"),
+		ProxyURL:            viper.GetString("proxy"),
+		RankBy:              rankBy,
+		TrustedDomains:      viper.GetStringSlice("trusted-domains"),
+		DistrustedDomains:   viper.GetStringSlice("distrusted-domains"),
+		Cookies:             cookies,
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return
+	}
+	if jsonOutput(cmd) {
+		writeJSONError(os.Stderr, err)
+	} else {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
 	}
+	os.Exit(1)
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		silenceForJSON(cmd)
+		return usageError{err}
+	})
 
+	rootCmd.PersistentFlags().StringVar(&flagConfig, "config", "", "Config file (YAML or TOML; default: config.{yaml,toml} in the OS config directory, e.g. ~/.config/searxng-mcp)")
 	rootCmd.PersistentFlags().StringVar(&flagInstanceURL, "instance-url", "", "Searxng instance URL")
-	rootCmd.PersistentFlags().StringVar(&flagLogLevel, "log-level", "info", "Log level: debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&flagLogLevel, "log-level", "info", "Log level: trace, debug, info, warn, error (SIGUSR1 cycles through the more verbose levels at runtime)")
+	rootCmd.PersistentFlags().StringVar(&flagLogFormat, "log-format", "text", "Log format: text or json (logs go to stderr, or --log-file; never stdout)")
+	rootCmd.PersistentFlags().StringVar(&flagLogFile, "log-file", "", "Append logs to this file instead of stderr, rotating it at --log-max-size")
+	rootCmd.PersistentFlags().IntVar(&flagLogMaxSize, "log-max-size", log.DefaultMaxSize>>20, "Size in MiB at which --log-file is rotated")
+	rootCmd.PersistentFlags().IntVar(&flagLogMaxBackups, "log-max-backups", 3, "Rotated log files kept next to --log-file (0: none)")
+	rootCmd.PersistentFlags().StringSliceVar(&flagLogComponentLevels, "log-component-levels", nil, "Log levels of components overriding --log-level, e.g. searxng=debug,server=warn (components: cmd, server, searxng)")
 	rootCmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", 30*time.Second, "Request timeout")
+	rootCmd.PersistentFlags().IntVar(&flagMaxIdleConns, "max-idle-conns", 0, "Maximum idle keep-alive connections to the instance (0: Go default)")
+	rootCmd.PersistentFlags().IntVar(&flagMaxIdleConnsPerHost, "max-idle-conns-per-host", 0, "Maximum idle keep-alive connections per host (0: Go default)")
+	rootCmd.PersistentFlags().IntVar(&flagMaxConnsPerHost, "max-conns-per-host", 0, "Maximum connections per host, including active ones (0: unlimited)")
+	rootCmd.PersistentFlags().DurationVar(&flagIdleConnTimeout, "idle-conn-timeout", 0, "How long idle connections are kept open (0: Go default)")
+	rootCmd.PersistentFlags().BoolVar(&flagDisableHTTP2, "disable-http2", false, "Use HTTP/1.1 only when talking to the instance")
+	rootCmd.PersistentFlags().StringSliceVar(&flagPinSPKI, "pin-spki", nil, "Base64 SHA-256 SPKI pins of the instance certificate or its CAs (repeatable); connections fail unless one matches a certificate of the verified chain, so self-signed and private CA certificates need their CA trusted by the system")
+	rootCmd.PersistentFlags().IntVar(&flagRateLimit, "rate-limit", 10, "Maximum searches per second sent to the instance")
+	rootCmd.PersistentFlags().IntVar(&flagRateBurst, "rate-burst", 0, "Searches that may be sent at once before --rate-limit applies (0: --rate-limit)")
+	rootCmd.PersistentFlags().StringSliceVar(&flagEngines, "engines", nil, "Default Searxng engines for searches that don't specify any (comma-separated)")
+	rootCmd.PersistentFlags().DurationVar(&flagCacheTTL, "cache-ttl", 0, "Serve identical searches from an in-memory cache for this long (0: disabled)")
+	rootCmd.PersistentFlags().IntVar(&flagCacheSize, "cache-size", searxng.DefaultCacheSize, "Maximum number of cached search responses")
+	rootCmd.PersistentFlags().StringVar(&flagImageProxySecret, "image-proxy-secret", "", "The instance's server.secret_key; rewrites image URLs in results to its image_proxy (requires image_proxy enabled on the instance)")
+	rootCmd.PersistentFlags().StringVar(&flagProxy, "proxy", "", "HTTP(S) or SOCKS5 proxy for searches and page reads, e.g. socks5://127.0.0.1:9050 for Tor")
+	rootCmd.PersistentFlags().StringVar(&flagRankBy, "rank-by", "", "Reorder results: score, consensus (engines agreeing), recency or weighted (all signals plus --trusted-domains) (empty: the instance's order)")
+	rootCmd.PersistentFlags().StringSliceVar(&flagTrustedDomains, "trusted-domains", nil, "Domains ranked higher by --rank-by weighted, subdomains included (comma-separated)")
+	rootCmd.PersistentFlags().StringSliceVar(&flagDistrustedDomains, "distrusted-domains", nil, "Domains ranked lower by --rank-by weighted, subdomains included (comma-separated)")
+	rootCmd.PersistentFlags().StringArrayVar(&flagCookies, "cookie", nil, "Cookie sent to the instance as name=value (repeatable), e.g. a preference like safesearch=2 or disabled_engines=... for instances that need the settings of their /preferences page")
+	rootCmd.PersistentFlags().StringVar(&flagStateDir, "state-dir", "", "Directory where serve persists the search cache, result history and rate limiter state across restarts, and search its query history (empty: not persisted by serve; the OS state directory for search and the state commands)")
 
 	// Bind flags to viper
 	_ = viper.BindPFlag("instance-url", rootCmd.PersistentFlags().Lookup("instance-url"))
 	_ = viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
+	_ = viper.BindPFlag("log-format", rootCmd.PersistentFlags().Lookup("log-format"))
+	_ = viper.BindPFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
+	_ = viper.BindPFlag("log-max-size", rootCmd.PersistentFlags().Lookup("log-max-size"))
+	_ = viper.BindPFlag("log-max-backups", rootCmd.PersistentFlags().Lookup("log-max-backups"))
+	_ = viper.BindPFlag("log-component-levels", rootCmd.PersistentFlags().Lookup("log-component-levels"))
 	_ = viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	_ = viper.BindPFlag("max-idle-conns", rootCmd.PersistentFlags().Lookup("max-idle-conns"))
+	_ = viper.BindPFlag("max-idle-conns-per-host", rootCmd.PersistentFlags().Lookup("max-idle-conns-per-host"))
+	_ = viper.BindPFlag("max-conns-per-host", rootCmd.PersistentFlags().Lookup("max-conns-per-host"))
+	_ = viper.BindPFlag("idle-conn-timeout", rootCmd.PersistentFlags().Lookup("idle-conn-timeout"))
+	_ = viper.BindPFlag("disable-http2", rootCmd.PersistentFlags().Lookup("disable-http2"))
+	_ = viper.BindPFlag("pin-spki", rootCmd.PersistentFlags().Lookup("pin-spki"))
+	_ = viper.BindPFlag("rate-limit", rootCmd.PersistentFlags().Lookup("rate-limit"))
+	_ = viper.BindPFlag("rate-burst", rootCmd.PersistentFlags().Lookup("rate-burst"))
+	_ = viper.BindPFlag("engines", rootCmd.PersistentFlags().Lookup("engines"))
+	_ = viper.BindPFlag("cache-ttl", rootCmd.PersistentFlags().Lookup("cache-ttl"))
+	_ = viper.BindPFlag("cache-size", rootCmd.PersistentFlags().Lookup("cache-size"))
+	_ = viper.BindPFlag("state-dir", rootCmd.PersistentFlags().Lookup("state-dir"))
+	_ = viper.BindPFlag("image-proxy-secret", rootCmd.PersistentFlags().Lookup("image-proxy-secret"))
+	_ = viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	_ = viper.BindPFlag("rank-by", rootCmd.PersistentFlags().Lookup("rank-by"))
+	_ = viper.BindPFlag("trusted-domains", rootCmd.PersistentFlags().Lookup("trusted-domains"))
+	_ = viper.BindPFlag("distrusted-domains", rootCmd.PersistentFlags().Lookup("distrusted-domains"))
+	_ = viper.BindPFlag("cookie", rootCmd.PersistentFlags().Lookup("cookie"))
+
+	// Every key can be overridden with a SEARXNG_MCP_ prefixed env var,
+	// e.g. SEARXNG_MCP_INSTANCE_URL or SEARXNG_MCP_RATE_LIMIT. These take
+	// precedence over the legacy names bound below.
+	viper.SetEnvPrefix("SEARXNG_MCP")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
 
 	// Bind environment variables (legacy support)
 	_ = viper.BindEnv("instance-url", "SEARXNG_URL")
 	_ = viper.BindEnv("timeout", "SEARXNG_TIMEOUT")
 	_ = viper.BindEnv("log-level", "LOG_LEVEL")
+	_ = viper.BindEnv("pin-spki", "SEARXNG_PIN_SPKI")
 
 	// Tracing env vars — these are read directly by the tracing package,
 	// but we also bind them so they can be set in the config file.
@@ -86,13 +257,27 @@ func init() {
 }
 
 func initConfig() {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("$HOME/.config/searxng-mcp")
+	if flagConfig != "" {
+		// Explicit config file: the format is taken from its extension and
+		// failing to read it is an error
+		viper.SetConfigFile(flagConfig)
+		if err := viper.ReadInConfig(); err != nil {
+			configErr = fmt.Errorf("failed to read config file %s: %w", flagConfig, err)
+		}
+	} else {
+		// config.yaml, config.yml or config.toml in the OS config directory,
+		// then in ~/.config/searxng-mcp where earlier releases looked on
+		// every OS
+		viper.SetConfigName("config")
+		if dir, err := paths.ConfigDir(); err == nil {
+			viper.AddConfigPath(dir)
+		}
+		viper.AddConfigPath("$HOME/.config/searxng-mcp")
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			fmt.Fprintf(os.Stderr, "warning: error reading config file: %v\n", err)
+		if err := viper.ReadInConfig(); err != nil {
+			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+				fmt.Fprintf(os.Stderr, "warning: error reading config file: %v\n", err)
+			}
 		}
 	}
 
@@ -6,16 +6,24 @@ import (
 	"os"
 	"strconv"
 	"text/tabwriter"
+	"time"
 
+	"github.com/denysvitali/searxng-mcp/internal/log"
 	"github.com/denysvitali/searxng-mcp/pkg/searxng"
 	"github.com/spf13/cobra"
 )
 
 var (
-	flagLimit     int
-	flagTimeRange string
-	flagCategory  string
-	flagPage      int
+	flagLimit      int
+	flagTimeRange  string
+	flagCategory   string
+	flagSafe       string
+	flagPage       int
+	flagFailEmpty  bool
+	flagMinResults int
+	flagDedupe     bool
+	flagLast       bool
+	flagNoHistory  bool
 )
 
 // searchCmd represents the search command
@@ -38,30 +46,67 @@ Examples:
   searxng-mcp search "golang news" --time-range day
 
   # Search images
-  searxng-mcp search "cats" --category images --limit 10`,
-	Args: cobra.ExactArgs(1),
+  searxng-mcp search "cats" --category images --limit 10
+
+  # Exit with a nonzero status when nothing is found (for scripts)
+  searxng-mcp search "some rare phrase" --fail-empty
+
+  # Require at least 3 results
+  searxng-mcp search "golang tutorial" --min-results 3
+
+  # Re-run the previous search, or continue its pagination
+  searxng-mcp search --last
+  searxng-mcp search --last --page 2
+
+  # Refine the previous query (!! is the previous query, !-2 the one before)
+  searxng-mcp search '!!' generics`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
+		if flagOutput != outputText && flagOutput != outputJSON {
+			return usageErrorf("invalid --output %q (must be text or json)", flagOutput)
+		}
 
-		// Create Searxng client config
-		config := &searxng.Config{
-			BaseURL: instanceURL,
-			Timeout: timeout,
+		safeSearch, err := searxng.ParseSafeSearch(flagSafe)
+		if err != nil {
+			return usageError{err}
 		}
 
-		// Create Searxng client
-		client, err := searxng.NewClient(config)
+		history, err := loadQueryHistory()
 		if err != nil {
-			return fmt.Errorf("failed to create searxng client: %w", err)
+			log.WithField("error", err).Warn("failed to load the query history")
 		}
 
 		// Build search request
 		req := searxng.SearchRequest{
-			Query:     query,
-			Limit:     flagLimit,
-			Page:      flagPage,
-			TimeRange: flagTimeRange,
-			Category:  flagCategory,
+			Limit:      flagLimit,
+			Page:       flagPage,
+			TimeRange:  flagTimeRange,
+			Category:   flagCategory,
+			SafeSearch: safeSearch,
+			Dedupe:     flagDedupe,
+		}
+		switch {
+		case flagLast && len(args) > 0:
+			return usageErrorf("--last doesn't take a query; use !! to refine the previous one")
+		case flagLast:
+			if len(history) == 0 {
+				return fmt.Errorf("--last: no previous search in history")
+			}
+			req = lastSearchRequest(cmd, history[len(history)-1], safeSearch)
+		case len(args) == 0:
+			return usageErrorf("a query is required (or --last to repeat the previous search)")
+		default:
+			if req.Query, err = expandHistory(args, history); err != nil {
+				return err
+			}
+		}
+
+		// Create Searxng client config
+		config := newSearxngConfig()
+
+		// Create Searxng client
+		client, err := searxng.NewClient(config)
+		if err != nil {
+			return fmt.Errorf("failed to create searxng client: %w", err)
 		}
 
 		// Perform search
@@ -71,14 +116,85 @@ Examples:
 			return fmt.Errorf("search failed: %w", err)
 		}
 
+		if !flagNoHistory {
+			if err := appendQueryHistory(history, queryHistoryEntry{
+				Query:      req.Query,
+				Page:       req.Page,
+				Limit:      req.Limit,
+				TimeRange:  req.TimeRange,
+				Category:   req.Category,
+				SafeSearch: req.SafeSearch,
+				Dedupe:     req.Dedupe,
+				Time:       time.Now(),
+			}); err != nil {
+				log.WithField("error", err).Warn("failed to save the query history")
+			}
+		}
+
 		// Display results
-		displayResults(resp)
+		if flagOutput == outputJSON {
+			if err := writeJSONResults(os.Stdout, resp); err != nil {
+				return err
+			}
+		} else {
+			displayResults(resp, req)
+		}
 
-		return nil
+		// Failing the result count check is not a usage error
+		cmd.SilenceUsage = true
+		return checkResultCount(len(resp.Results))
 	},
 }
 
-func displayResults(resp *searxng.SearchResponse) {
+// searchArgs validates the arguments of the search command: the query, or
+// a history reference followed by the words refining it
+func searchArgs(cmd *cobra.Command, args []string) error {
+	silenceForJSON(cmd)
+	if len(args) > 0 && historyReference.MatchString(args[0]) {
+		return nil
+	}
+	if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+		return usageError{err}
+	}
+	return nil
+}
+
+// lastSearchRequest rebuilds the search of entry. Flags given on the command
+// line override the remembered settings, so --last --page 2 fetches the next
+// page of the previous search. safeSearch is the parsed --safe-search.
+func lastSearchRequest(cmd *cobra.Command, entry queryHistoryEntry, safeSearch searxng.SafeSearch) searxng.SearchRequest {
+	req := searxng.SearchRequest{
+		Query:      entry.Query,
+		Limit:      entry.Limit,
+		Page:       entry.Page,
+		TimeRange:  entry.TimeRange,
+		Category:   entry.Category,
+		SafeSearch: entry.SafeSearch,
+		Dedupe:     entry.Dedupe,
+	}
+	flags := cmd.Flags()
+	if flags.Changed("limit") {
+		req.Limit = flagLimit
+	}
+	if flags.Changed("page") {
+		req.Page = flagPage
+	}
+	if flags.Changed("time-range") {
+		req.TimeRange = flagTimeRange
+	}
+	if flags.Changed("category") {
+		req.Category = flagCategory
+	}
+	if flags.Changed("safe-search") {
+		req.SafeSearch = safeSearch
+	}
+	if flags.Changed("dedupe") {
+		req.Dedupe = flagDedupe
+	}
+	return req
+}
+
+func displayResults(resp *searxng.SearchResponse, req searxng.SearchRequest) {
 	fmt.Printf("\nQuery: %s\n", resp.Query)
 	fmt.Printf("Total results: %d\n\n", resp.NumberOfResults)
 
@@ -123,11 +239,11 @@ func displayResults(resp *searxng.SearchResponse) {
 	}
 
 	// Show pagination info
-	resultsPerPage := flagLimit
+	resultsPerPage := req.Limit
 	if resultsPerPage == 0 {
 		resultsPerPage = 5
 	}
-	currentPage := flagPage
+	currentPage := req.Page
 	if currentPage == 0 {
 		currentPage = 1
 	}
@@ -135,15 +251,39 @@ func displayResults(resp *searxng.SearchResponse) {
 	if resp.NumberOfResults > resultsPerPage*currentPage {
 		nextPage := currentPage + 1
 		fmt.Printf("\n-- More results available (page %d) --\n", nextPage)
-		fmt.Printf("Run: searxng-mcp search %s --page %d\n", strconv.Quote(resp.Query), nextPage)
+		if flagNoHistory {
+			fmt.Printf("Run: searxng-mcp search %s --page %d\n", strconv.Quote(resp.Query), nextPage)
+		} else {
+			fmt.Printf("Run: searxng-mcp search --last --page %d\n", nextPage)
+		}
+	}
+}
+
+// checkResultCount enforces --fail-empty and --min-results
+func checkResultCount(count int) error {
+	minResults := flagMinResults
+	if flagFailEmpty {
+		minResults = max(minResults, 1)
+	}
+	if count < minResults {
+		return fmt.Errorf("%w: got %d, expected at least %d", errTooFewResults, count, minResults)
 	}
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(searchCmd)
+	searchCmd.Args = searchArgs // assigned here, searchArgs refers to searchCmd
 
 	searchCmd.Flags().IntVarP(&flagLimit, "limit", "l", 5, "Number of results to return (1-20)")
-	searchCmd.Flags().StringVar(&flagTimeRange, "time-range", "", "Time range filter: day, month, year")
+	searchCmd.Flags().StringVar(&flagTimeRange, "time-range", "", "Time range filter: day, week, month, year")
 	searchCmd.Flags().StringVar(&flagCategory, "category", "", "Search category: general, images, videos, etc.")
+	searchCmd.Flags().StringVar(&flagSafe, "safe-search", "", "Safe search level: off, moderate or strict (default: the instance's setting)")
 	searchCmd.Flags().IntVarP(&flagPage, "page", "p", 1, "Page number for pagination")
+	searchCmd.Flags().BoolVar(&flagDedupe, "dedupe", false, "Merge results pointing at the same page (ignoring http/https, trailing slashes and utm_* parameters)")
+	searchCmd.Flags().BoolVar(&flagLast, "last", false, "Repeat the previous search; other flags override its settings, e.g. --last --page 2")
+	searchCmd.Flags().BoolVar(&flagNoHistory, "no-history", false, "Don't remember this search for --last and !! references")
+	searchCmd.Flags().BoolVar(&flagFailEmpty, "fail-empty", false, "Exit with a nonzero status when no results are found")
+	searchCmd.Flags().IntVar(&flagMinResults, "min-results", 0, "Exit with a nonzero status when fewer results are found (0 disables)")
+	searchCmd.Flags().StringVarP(&flagOutput, "output", "o", outputText, "Output format: text, or json for results on stdout and errors on stderr as {\"error\": {\"code\", \"message\", \"retryable\"}}")
 }
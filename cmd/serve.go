@@ -2,22 +2,84 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
 
 	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/internal/paths"
 	"github.com/denysvitali/searxng-mcp/internal/tracing"
 	"github.com/denysvitali/searxng-mcp/pkg/searxng"
 	"github.com/denysvitali/searxng-mcp/pkg/server"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	flagTransport string
-	flagPort      int
+	flagTransport   string
+	flagPort        int
+	flagPreflight   string
+	flagBoilerplate string
+	flagToolLocale  string
+	flagKeepWarm    time.Duration
+	flagStatusPath  string
+	flagMetricsPath string
+	flagImageProxy  string
+	flagRobots      bool
+	flagProxyRetry  bool
+	flagReadRate    int
+	flagReadBurst   int
+	flagReadCache   time.Duration
+	flagReadCacheN  int
+	flagDiskCache   bool
+	flagDiskCacheN  int
+	flagReadMaxB    int64
+	flagReadMaxLen  int
+	flagMaxTimeout  time.Duration
+	flagMirrors     []string
+	flagIncludeDoms []string
+	flagExcludeDoms []string
+	flagAnnotations string
+	flagDetectLang  bool
+	flagShutdown    time.Duration
+	flagHTTP3       bool
+	flagIdleTimeout time.Duration
+	flagLogResults  string
+
+	flagDefaultLimit      int
+	flagDefaultCategory   string
+	flagSafeSearch        string
+	flagInstanceAllowlist []string
+	flagProxyAllowlist    []string
+	flagAuthTokens        []string
+	flagAdminTokens       []string
+	flagCORSOrigins       []string
+	flagDisabledTools     []string
+	flagLogRequests       bool
+	flagGRPCAddr          string
+
+	// toolOverrides holds the "tools" section of the config file; there is
+	// no flag for it
+	toolOverrides map[string]server.ToolOverride
+
+	// domainAnnotations is the content of --domain-annotations
+	domainAnnotations map[string]string
 )
 
+// mcpEndpoints are the paths served by the http and sse transports,
+// including their probe endpoints
+var mcpEndpoints = []string{"/mcp", "/sse", "/message", server.HealthPath, server.ReadyPath}
+
 // serveCmd represents the serve command
 var serveCmd = &cobra.Command{
 	Use:   "serve",
@@ -35,17 +97,138 @@ Examples:
   searxng-mcp serve
 
   # Start in HTTP mode
-  searxng-mcp serve --transport http --port 8080`,
+  searxng-mcp serve --transport http --port 8080
+
+  # Start with the legacy SSE transport for older clients
+  searxng-mcp serve --transport sse --port 8080
+
+  # Expose the HTTP endpoint beyond localhost, requiring a token
+  SEARXNG_MCP_AUTH_TOKEN=secret searxng-mcp serve --transport http --log-requests
+
+  # Also serve the Search and Read RPCs over gRPC for backend services
+  searxng-mcp serve --transport http --grpc-addr :9090
+
+  # Verify the instance on startup and exit if it is misconfigured
+  searxng-mcp serve --preflight strict`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		flagTransport = viper.GetString("transport")
 		flagPort = viper.GetInt("port")
+		flagPreflight = viper.GetString("preflight")
+		flagBoilerplate = viper.GetString("boilerplate")
+		flagToolLocale = viper.GetString("tool-locale")
+		flagKeepWarm = viper.GetDuration("keep-warm")
+		flagDefaultLimit = viper.GetInt("default-limit")
+		flagDefaultCategory = viper.GetString("default-category")
+		flagSafeSearch = viper.GetString("safe-search")
+		flagInstanceAllowlist = viper.GetStringSlice("instance-allowlist")
+		flagAuthTokens = viper.GetStringSlice("auth-token")
+		flagAdminTokens = viper.GetStringSlice("admin-token")
+		flagCORSOrigins = viper.GetStringSlice("cors-origin")
+		flagDisabledTools = viper.GetStringSlice("disable-tool")
+		flagLogRequests = viper.GetBool("log-requests")
+		flagGRPCAddr = viper.GetString("grpc-addr")
+		flagStatusPath = viper.GetString("status-path")
+		flagMetricsPath = viper.GetString("metrics-path")
+		flagImageProxy = viper.GetString("image-proxy-url")
+		flagRobots = viper.GetBool("respect-robots")
+		flagProxyRetry = viper.GetBool("proxy-fallback")
+		flagProxyAllowlist = viper.GetStringSlice("proxy-allowlist")
+		flagReadRate = viper.GetInt("read-rate-limit")
+		flagReadBurst = viper.GetInt("read-rate-burst")
+		flagReadCache = viper.GetDuration("read-cache-ttl")
+		flagReadCacheN = viper.GetInt("read-cache-size")
+		flagDiskCache = viper.GetBool("read-disk-cache")
+		flagDiskCacheN = viper.GetInt("read-disk-cache-size")
+		flagReadMaxB = viper.GetInt64("read-max-bytes")
+		flagReadMaxLen = viper.GetInt("read-max-length")
+		flagMaxTimeout = viper.GetDuration("max-request-timeout")
+		flagMirrors = viper.GetStringSlice("read-mirror")
+		flagIncludeDoms = viper.GetStringSlice("include-domains")
+		flagExcludeDoms = viper.GetStringSlice("exclude-domains")
+		flagAnnotations = viper.GetString("domain-annotations")
+		flagDetectLang = viper.GetBool("detect-language")
+		flagShutdown = viper.GetDuration("shutdown-timeout")
+		flagHTTP3 = viper.GetBool("http3")
+		flagIdleTimeout = viper.GetDuration("idle-timeout")
+		flagLogResults = viper.GetString("log-tool-results")
 
-		if flagTransport != "stdio" && flagTransport != "http" {
-			return fmt.Errorf("invalid transport: %s (must be 'stdio' or 'http')", flagTransport)
+		if flagTransport != "stdio" && flagTransport != "http" && flagTransport != "sse" {
+			return fmt.Errorf("invalid transport: %s (must be 'stdio', 'http' or 'sse')", flagTransport)
 		}
-		if flagTransport == "http" && (flagPort < 1 || flagPort > 65535) {
+		if flagTransport != "stdio" && (flagPort < 1 || flagPort > 65535) {
 			return fmt.Errorf("invalid port: %d", flagPort)
 		}
+		if flagPreflight != "off" && flagPreflight != "warn" && flagPreflight != "strict" {
+			return fmt.Errorf("invalid preflight mode: %s (must be 'off', 'warn' or 'strict')", flagPreflight)
+		}
+		if _, err := server.ParseBoilerplateLevel(flagBoilerplate); err != nil {
+			return err
+		}
+		if _, err := searxng.ParseSafeSearch(flagSafeSearch); err != nil {
+			return err
+		}
+		if _, err := server.ParseToolLocale(flagToolLocale); err != nil {
+			return err
+		}
+		if _, err := server.ParseToolLogging(flagLogResults); err != nil {
+			return err
+		}
+		if flagStatusPath != "" && (!strings.HasPrefix(flagStatusPath, "/") || slices.Contains(mcpEndpoints, flagStatusPath)) {
+			return fmt.Errorf("invalid status path: %s (must start with '/' and not be an MCP endpoint)", flagStatusPath)
+		}
+		if flagMetricsPath != "" && (!strings.HasPrefix(flagMetricsPath, "/") || slices.Contains(mcpEndpoints, flagMetricsPath) || flagMetricsPath == flagStatusPath) {
+			return fmt.Errorf("invalid metrics path: %s (must start with '/' and not be an MCP endpoint or the status path)", flagMetricsPath)
+		}
+		if flagImageProxy != "" {
+			if flagTransport == "stdio" {
+				return fmt.Errorf("--image-proxy-url requires the http or sse transport")
+			}
+			u, err := url.Parse(flagImageProxy)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path == "" ||
+				slices.Contains(mcpEndpoints, u.Path) || u.Path == flagStatusPath || u.Path == flagMetricsPath {
+				return fmt.Errorf("invalid image proxy URL: %s (must be an absolute http(s) URL whose path isn't used by another endpoint)", flagImageProxy)
+			}
+		}
+		// QUIC connections would bypass the proxy and the pins
+		if flagHTTP3 && viper.GetString("proxy") != "" {
+			return fmt.Errorf("--http3 can't be combined with --proxy")
+		}
+		if flagHTTP3 && len(viper.GetStringSlice("pin-spki")) > 0 {
+			return fmt.Errorf("--http3 can't be combined with --pin-spki")
+		}
+		for _, proxy := range flagProxyAllowlist {
+			if _, err := searxng.ParseProxyURL(proxy); err != nil {
+				return fmt.Errorf("--proxy-allowlist: %w", err)
+			}
+		}
+		if flagIdleTimeout > 0 && flagKeepWarm > 0 {
+			return fmt.Errorf("--idle-timeout and --keep-warm can't be used together")
+		}
+		if err := viper.UnmarshalKey("tools", &toolOverrides); err != nil {
+			return fmt.Errorf("invalid tools config: %w", err)
+		}
+		if err := server.ValidateToolOverrides(toolOverrides); err != nil {
+			return err
+		}
+		if err := server.ValidateDisabledTools(flagDisabledTools); err != nil {
+			return err
+		}
+		if err := server.ValidateReadMirrors(flagMirrors); err != nil {
+			return err
+		}
+		if err := server.ValidateDomains(flagIncludeDoms); err != nil {
+			return fmt.Errorf("--include-domains: %w", err)
+		}
+		if err := server.ValidateDomains(flagExcludeDoms); err != nil {
+			return fmt.Errorf("--exclude-domains: %w", err)
+		}
+		if flagAnnotations != "" {
+			annotations, err := server.LoadDomainAnnotations(flagAnnotations)
+			if err != nil {
+				return err
+			}
+			domainAnnotations = annotations
+		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -56,15 +239,23 @@ Examples:
 		}
 		defer tracing.Shutdown(ctx) //nolint:errcheck
 
+		cycleLogLevelOnSignal(ctx)
+
 		if tracing.Enabled() {
 			log.Info("tracing enabled")
 		}
 
-		// Create Searxng client config
-		config := &searxng.Config{
-			BaseURL: instanceURL,
-			Timeout: timeout,
-		}
+		// Create Searxng client config. The client and page fetches share
+		// one tuned connection pool, sized by the pool flags.
+		config := newSearxngConfig()
+		transport := searxng.NewPooledTransport(searxng.TransportOptions{
+			MaxIdleConns:        config.MaxIdleConns,
+			MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     config.MaxConnsPerHost,
+			IdleConnTimeout:     config.IdleConnTimeout,
+		})
+		config.Transport = transport
+		config.HTTP3 = flagHTTP3
 
 		// Create Searxng client
 		client, err := searxng.NewClient(config)
@@ -72,33 +263,348 @@ Examples:
 			return fmt.Errorf("failed to create searxng client: %w", err)
 		}
 
+		if err := runPreflight(ctx, client, flagPreflight); err != nil {
+			return err
+		}
+
+		if flagKeepWarm > 0 {
+			warmCtx, stopWarm := context.WithCancel(ctx)
+			defer stopWarm()
+			log.WithField("interval", flagKeepWarm).Info("keeping searxng instance warm")
+			go client.KeepWarm(warmCtx, flagKeepWarm)
+		}
+
 		log.WithField("transport", flagTransport).Info("starting MCP server")
 
 		// Build MCP server options (tracing middleware, hooks, etc.)
 		var mcpOpts []mcpserver.ServerOption
 		mcpOpts = append(mcpOpts, tracing.MCPServerOptions(flagTransport)...)
 
+		// Validated in PreRunE
+		boilerplate, _ := server.ParseBoilerplateLevel(flagBoilerplate)
+		toolLocale, _ := server.ParseToolLocale(flagToolLocale)
+		toolLogging, _ := server.ParseToolLogging(flagLogResults)
+		safeSearch, _ := searxng.ParseSafeSearch(flagSafeSearch)
+
+		var diskCacheDir string
+		if flagDiskCache {
+			if diskCacheDir, err = readDiskCacheDir(); err != nil {
+				return err
+			}
+		}
+
 		// Create and start server
-		srv := server.New(client, mcpOpts...)
+		srv := server.NewWithOptions(client, server.Options{
+			Boilerplate:       boilerplate,
+			ToolLocale:        toolLocale,
+			DefaultLimit:      flagDefaultLimit,
+			DefaultCategory:   flagDefaultCategory,
+			SafeSearch:        safeSearch,
+			InstanceAllowlist: flagInstanceAllowlist,
+			ToolOverrides:     toolOverrides,
+			DisabledTools:     flagDisabledTools,
+			StatusPath:        flagStatusPath,
+			MetricsPath:       flagMetricsPath,
+			ImageProxyURL:     flagImageProxy,
+			RespectRobots:     flagRobots,
+			ProxyURL:          viper.GetString("proxy"),
+			ProxyFallback:     flagProxyRetry,
+			ProxyAllowlist:    flagProxyAllowlist,
+			ReadRateLimit:     flagReadRate,
+			ReadRateBurst:     flagReadBurst,
+			ReadCacheTTL:      flagReadCache,
+			ReadCacheSize:     flagReadCacheN,
+			ReadDiskCacheDir:  diskCacheDir,
+			ReadDiskCacheSize: flagDiskCacheN,
+			ReadMaxBytes:      flagReadMaxB,
+			ReadMaxLength:     flagReadMaxLen,
+			MaxRequestTimeout: flagMaxTimeout,
+			ReadMirrors:       flagMirrors,
+			IncludeDomains:    flagIncludeDoms,
+			ExcludeDomains:    flagExcludeDoms,
+			DomainAnnotations: domainAnnotations,
+			DetectLanguage:    flagDetectLang,
+			LogToolResults:    toolLogging,
+			AuthTokens:        flagAuthTokens,
+			AdminTokens:       flagAdminTokens,
+			CORSOrigins:       flagCORSOrigins,
+			LogRequests:       flagLogRequests,
+			Transport:         transport,
+		}, mcpOpts...)
+
+		if err := srv.AdaptToInstance(ctx); err != nil {
+			log.WithField("error", err).Warn("not listing the instance categories in the tool schemas")
+		}
+
+		stateDir := viper.GetString("state-dir")
+		history := persistHistory(flagTransport)
+		if stateDir != "" {
+			if err := loadState(stateDir, client, srv, history); err != nil {
+				log.WithField("error", err).Warn("failed to restore state")
+			}
+			defer func() {
+				if err := saveState(stateDir, client, srv, history); err != nil {
+					log.WithField("error", err).Error("failed to save state")
+				}
+			}()
+		}
+
+		if flagIdleTimeout > 0 {
+			// Without a state directory the search cache is dropped for good
+			var hooks server.IdleHooks
+			if stateDir != "" {
+				hooks.Flush = func() error { return saveState(stateDir, client, srv, history) }
+				hooks.Restore = func() error { return loadCache(stateDir, client) }
+			}
+			idleCtx, stopIdle := context.WithCancel(ctx)
+			defer stopIdle()
+			log.WithField("timeout", flagIdleTimeout).Info("releasing resources when idle")
+			go srv.ReleaseWhenIdle(idleCtx, flagIdleTimeout, hooks)
+		}
+
+		if flagGRPCAddr != "" {
+			go func() {
+				if err := srv.ServeGRPC(flagGRPCAddr); err != nil {
+					log.WithField("error", err).Error("gRPC server failed")
+				}
+			}()
+		}
 
 		switch flagTransport {
-		case "http":
+		case "http", "sse":
 			addr := fmt.Sprintf(":%d", flagPort)
 			log.WithField("address", addr).Info("listening")
-			return srv.ServeHTTP(addr)
+
+			// Return on SIGINT/SIGTERM so deferred cleanup (state, tracing) runs
+			sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			errCh := make(chan error, 1)
+			go func() {
+				if flagTransport == "sse" {
+					errCh <- srv.ServeSSE(addr)
+				} else {
+					errCh <- srv.ServeHTTP(addr)
+				}
+			}()
+			select {
+			case err := <-errCh:
+				return err
+			case <-sigCtx.Done():
+			}
+
+			// Drain in-flight tool calls, up to --shutdown-timeout
+			stop()
+			shutdownCtx, cancel := context.WithTimeout(ctx, flagShutdown)
+			defer cancel()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				log.WithField("error", err).Warn("shutdown timed out")
+			} else {
+				log.Info("shut down")
+			}
+			return <-errCh
 
 		default: // stdio
-			return srv.ServeStdio()
+			err := srv.ServeStdio()
+			if flagGRPCAddr != "" {
+				shutdownCtx, cancel := context.WithTimeout(ctx, flagShutdown)
+				defer cancel()
+				if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+					log.WithField("error", shutdownErr).Warn("shutdown timed out")
+				}
+			}
+			return err
 		}
 	},
 }
 
+// runPreflight runs the local startup checks and then the client's checks
+// against the instance. In "strict" mode the first failing check aborts
+// startup; in "warn" mode failures are only logged.
+func runPreflight(ctx context.Context, client *searxng.Client, mode string) error {
+	if mode == "off" {
+		return nil
+	}
+
+	checks := append(localPreflight(), client.Preflight(ctx)...)
+	for _, check := range checks {
+		if check.OK() {
+			log.WithFields(logrus.Fields{"check": check.Name, "duration": check.Duration}).Debug("preflight check passed")
+			continue
+		}
+		if mode == "strict" {
+			return fmt.Errorf("preflight check %q failed: %w", check.Name, check.Err)
+		}
+		log.WithFields(logrus.Fields{"check": check.Name, "error": check.Err}).Warn("preflight check failed")
+	}
+	return nil
+}
+
+// localPreflight checks the parts of the configuration the instance has no
+// say in: the auth tokens and, when they are used, that the state directory
+// and the read disk cache directory are writable
+func localPreflight() []searxng.PreflightCheck {
+	checks := []searxng.PreflightCheck{runLocalCheck("auth_config", checkAuthConfig)}
+	if stateDir := viper.GetString("state-dir"); stateDir != "" {
+		checks = append(checks, runLocalCheck("state_dir_writable", func() error {
+			return checkWritable(stateDir)
+		}))
+	}
+	if flagDiskCache {
+		checks = append(checks, runLocalCheck("read_cache_dir_writable", func() error {
+			dir, err := readDiskCacheDir()
+			if err != nil {
+				return err
+			}
+			return checkWritable(dir)
+		}))
+	}
+	return checks
+}
+
+func runLocalCheck(name string, check func() error) searxng.PreflightCheck {
+	start := time.Now()
+	err := check()
+	return searxng.PreflightCheck{Name: name, Err: err, Duration: time.Since(start)}
+}
+
+// checkAuthConfig rejects tokens no client can present and tokens that no
+// listener would ask for
+func checkAuthConfig() error {
+	tokens := append(slices.Clone(flagAuthTokens), flagAdminTokens...)
+	for _, token := range tokens {
+		if strings.TrimSpace(token) == "" {
+			return errors.New("empty auth or admin token")
+		}
+		if strings.ContainsFunc(token, unicode.IsSpace) {
+			return errors.New("auth and admin tokens must not contain whitespace")
+		}
+	}
+	if len(tokens) > 0 && flagTransport == "stdio" && flagGRPCAddr == "" {
+		return errors.New("auth and admin tokens are ignored by the stdio transport (use --transport http or sse, or --grpc-addr)")
+	}
+	return nil
+}
+
+// checkWritable creates dir when missing and writes and removes a file in
+// it, so a read-only or foreign-owned directory is reported at startup
+// instead of when the state is first saved
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".preflight-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	err = f.Close()
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	return nil
+}
+
+// readDiskCacheDir returns --read-disk-cache-dir when set, and otherwise
+// the pages directory of the OS cache directory (see paths.CacheDir)
+func readDiskCacheDir() (string, error) {
+	if dir := viper.GetString("read-disk-cache-dir"); dir != "" {
+		return dir, nil
+	}
+	dir, err := paths.CacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the read cache: %w", err)
+	}
+	return filepath.Join(dir, "pages"), nil
+}
+
 func init() {
 	rootCmd.AddCommand(serveCmd)
 
-	serveCmd.Flags().StringVarP(&flagTransport, "transport", "t", "stdio", "Transport type: stdio or http")
-	serveCmd.Flags().IntVarP(&flagPort, "port", "p", 8080, "Port for HTTP transport")
+	serveCmd.Flags().StringVarP(&flagTransport, "transport", "t", "stdio", "Transport type: stdio, http (StreamableHTTP at /mcp) or sse (legacy SSE at /sse and /message)")
+	serveCmd.Flags().IntVarP(&flagPort, "port", "p", 8080, "Port for the http and sse transports")
+	serveCmd.Flags().StringVar(&flagBoilerplate, "boilerplate", "off", "Default trailing boilerplate removal for searxng_read: off, normal or aggressive")
+	serveCmd.Flags().StringVar(&flagToolLocale, "tool-locale", server.DefaultToolLocale, fmt.Sprintf("Language of tool descriptions: %s", strings.Join(server.SupportedToolLocales(), ", ")))
+	serveCmd.Flags().IntVar(&flagDefaultLimit, "default-limit", 0, "Default number of searxng_search results when the client doesn't pass a limit (0: 5)")
+	serveCmd.Flags().StringVar(&flagDefaultCategory, "default-category", "", "Default searxng_search category when the client doesn't pass one")
+	serveCmd.Flags().StringVar(&flagSafeSearch, "safe-search", "", "Safe search level of searches, and the least strict one clients may request: off, moderate or strict (default: the instance's setting)")
+	serveCmd.Flags().StringSliceVar(&flagInstanceAllowlist, "instance-allowlist", nil, "Additional Searxng instance URLs clients may select per search via instance_url or as their session default")
+	serveCmd.Flags().StringSliceVar(&flagAuthTokens, "auth-token", nil, "Bearer tokens or API keys (X-API-Key) required by the http and sse transports and the gRPC service (prefer SEARXNG_MCP_AUTH_TOKEN)")
+	serveCmd.Flags().StringSliceVar(&flagAdminTokens, "admin-token", nil, "Tokens accepted like --auth-token that may also call the searxng_set_log_level tool in http and sse modes (prefer SEARXNG_MCP_ADMIN_TOKEN)")
+	serveCmd.Flags().StringSliceVar(&flagCORSOrigins, "cors-origin", nil, "Browser origins allowed to call the http and sse transports (*: any)")
+	serveCmd.Flags().StringSliceVar(&flagDisabledTools, "disable-tool", nil, "Built-in tools not to register (repeatable), e.g. searxng_read for a search-only server")
+	serveCmd.Flags().BoolVar(&flagLogRequests, "log-requests", false, "Log every HTTP request of the http and sse transports")
+	serveCmd.Flags().StringVar(&flagGRPCAddr, "grpc-addr", "", "Also serve the searxng.v1.Searxng gRPC service (Search and Read RPCs) on this address, e.g. :9090 (empty: disabled)")
+	serveCmd.Flags().DurationVar(&flagKeepWarm, "keep-warm", 0, "Search the instance after this much idle time to keep its connections and those to its engines warm (0 disables)")
+	serveCmd.Flags().StringVar(&flagStatusPath, "status-path", "", "Serve an HTML status page at this path in HTTP mode, e.g. /status (empty: disabled)")
+	serveCmd.Flags().StringVar(&flagMetricsPath, "metrics-path", "/metrics", "Serve Prometheus metrics at this path in HTTP mode (empty: disabled)")
+	serveCmd.Flags().StringVar(&flagImageProxy, "image-proxy-url", "", "Public URL of an image proxy served by this server in HTTP mode, e.g. https://mcp.example.com/image_proxy; image URLs in results are rewritten to it")
+	serveCmd.Flags().BoolVar(&flagRobots, "respect-robots", false, "Refuse searxng_read URLs disallowed by the site's robots.txt for the searxng-mcp user agent")
+	serveCmd.Flags().BoolVar(&flagProxyRetry, "proxy-fallback", false, "Read pages directly and only use --proxy to retry pages answering 403")
+	serveCmd.Flags().StringSliceVar(&flagProxyAllowlist, "proxy-allowlist", nil, "Proxy URLs clients may select per read via the proxy argument of searxng_read and searxng_search_and_read (comma-separated; empty: the argument isn't offered)")
+	serveCmd.Flags().IntVar(&flagReadRate, "read-rate-limit", 10, "Maximum pages fetched per second by searxng_read and searxng_search_and_read, independent of --rate-limit (0: unlimited)")
+	serveCmd.Flags().IntVar(&flagReadBurst, "read-rate-burst", 0, "Pages that may be fetched at once before --read-rate-limit applies (0: --read-rate-limit)")
+	serveCmd.Flags().DurationVar(&flagReadCache, "read-cache-ttl", 10*time.Minute, "Reuse read pages for identical reads and expand_snippets for this long (0: disabled)")
+	serveCmd.Flags().IntVar(&flagReadCacheN, "read-cache-size", server.DefaultReadCacheSize, "Maximum number of cached pages")
+	serveCmd.Flags().BoolVar(&flagDiskCache, "read-disk-cache", false, "Keep read pages on disk across restarts, revalidating them with ETag/Last-Modified")
+	serveCmd.Flags().String("read-disk-cache-dir", "", "Directory of the disk cache (default: pages in the OS cache directory)")
+	serveCmd.Flags().IntVar(&flagDiskCacheN, "read-disk-cache-size", server.DefaultReadDiskCacheSize, "Maximum number of pages kept on disk")
+	serveCmd.Flags().Int64Var(&flagReadMaxB, "read-max-bytes", server.DefaultReadMaxBytes, "Maximum size in bytes of a downloaded page; larger pages are cut and reported as truncated")
+	serveCmd.Flags().DurationVar(&flagMaxTimeout, "max-request-timeout", server.DefaultMaxRequestTimeout, "Maximum timeout_seconds clients may pass to searxng_search and searxng_read")
+	serveCmd.Flags().IntVar(&flagReadMaxLen, "read-max-length", server.DefaultReadMaxLength, "Maximum number of characters returned by a searxng_read call, whatever its max_length; the rest is read with an offset")
+	serveCmd.Flags().StringSliceVar(&flagMirrors, "read-mirror", nil, "Mirror or reader URL template, e.g. https://r.jina.ai/{url}, that pages failing to fetch are read through, tried in order (repeatable; {url_escaped} inserts the URL query-escaped)")
+	serveCmd.Flags().StringSliceVar(&flagIncludeDoms, "include-domains", nil, "Only return search results from and read pages on these domains, subdomains included (comma-separated; empty: any domain)")
+	serveCmd.Flags().StringSliceVar(&flagExcludeDoms, "exclude-domains", nil, "Drop search results from and refuse to read pages on these domains, subdomains included (comma-separated)")
+	serveCmd.Flags().StringVar(&flagAnnotations, "domain-annotations", "", "File of \"domain: label\" lines, e.g. \"wikipedia.org: authoritative\"; search results on these domains and their subdomains carry the label as domain_label")
+	serveCmd.Flags().BoolVar(&flagDetectLang, "detect-language", false, "Detect the language of searxng_search queries without a language argument and search in it")
+	serveCmd.Flags().DurationVar(&flagShutdown, "shutdown-timeout", 30*time.Second, "How long to wait for in-flight tool calls on SIGINT/SIGTERM in http and sse modes")
+	serveCmd.Flags().BoolVar(&flagHTTP3, "http3", false, "Search over HTTP/3 (QUIC) first, falling back to HTTP/2 and HTTP/1.1 when it fails")
+	serveCmd.Flags().DurationVar(&flagIdleTimeout, "idle-timeout", 0, "Release connections and caches after this long without tool calls, flushing the cache to --state-dir; meant for stdio servers on laptops (0 disables)")
+	serveCmd.Flags().StringVar(&flagLogResults, "log-tool-results", "none", "Log the arguments and results of tool calls, which may contain sensitive queries and large pages: none, truncated or full")
+	serveCmd.Flags().StringVar(&flagPreflight, "preflight", "off", "Startup checks against the instance: off, warn (log failures) or strict (exit on failure)")
 
 	_ = viper.BindPFlag("transport", serveCmd.Flags().Lookup("transport"))
 	_ = viper.BindPFlag("port", serveCmd.Flags().Lookup("port"))
+	_ = viper.BindPFlag("preflight", serveCmd.Flags().Lookup("preflight"))
+	_ = viper.BindEnv("preflight", "SEARXNG_PREFLIGHT")
+	_ = viper.BindPFlag("boilerplate", serveCmd.Flags().Lookup("boilerplate"))
+	_ = viper.BindPFlag("tool-locale", serveCmd.Flags().Lookup("tool-locale"))
+	_ = viper.BindPFlag("keep-warm", serveCmd.Flags().Lookup("keep-warm"))
+	_ = viper.BindPFlag("default-limit", serveCmd.Flags().Lookup("default-limit"))
+	_ = viper.BindPFlag("default-category", serveCmd.Flags().Lookup("default-category"))
+	_ = viper.BindPFlag("safe-search", serveCmd.Flags().Lookup("safe-search"))
+	_ = viper.BindPFlag("instance-allowlist", serveCmd.Flags().Lookup("instance-allowlist"))
+	_ = viper.BindPFlag("auth-token", serveCmd.Flags().Lookup("auth-token"))
+	_ = viper.BindPFlag("admin-token", serveCmd.Flags().Lookup("admin-token"))
+	_ = viper.BindPFlag("cors-origin", serveCmd.Flags().Lookup("cors-origin"))
+	_ = viper.BindPFlag("disable-tool", serveCmd.Flags().Lookup("disable-tool"))
+	_ = viper.BindPFlag("log-requests", serveCmd.Flags().Lookup("log-requests"))
+	_ = viper.BindPFlag("grpc-addr", serveCmd.Flags().Lookup("grpc-addr"))
+	_ = viper.BindPFlag("status-path", serveCmd.Flags().Lookup("status-path"))
+	_ = viper.BindPFlag("metrics-path", serveCmd.Flags().Lookup("metrics-path"))
+	_ = viper.BindPFlag("image-proxy-url", serveCmd.Flags().Lookup("image-proxy-url"))
+	_ = viper.BindPFlag("respect-robots", serveCmd.Flags().Lookup("respect-robots"))
+	_ = viper.BindPFlag("proxy-fallback", serveCmd.Flags().Lookup("proxy-fallback"))
+	_ = viper.BindPFlag("proxy-allowlist", serveCmd.Flags().Lookup("proxy-allowlist"))
+	_ = viper.BindPFlag("read-rate-limit", serveCmd.Flags().Lookup("read-rate-limit"))
+	_ = viper.BindPFlag("read-rate-burst", serveCmd.Flags().Lookup("read-rate-burst"))
+	_ = viper.BindPFlag("read-cache-ttl", serveCmd.Flags().Lookup("read-cache-ttl"))
+	_ = viper.BindPFlag("read-cache-size", serveCmd.Flags().Lookup("read-cache-size"))
+	_ = viper.BindPFlag("read-disk-cache", serveCmd.Flags().Lookup("read-disk-cache"))
+	_ = viper.BindPFlag("read-disk-cache-dir", serveCmd.Flags().Lookup("read-disk-cache-dir"))
+	_ = viper.BindPFlag("read-disk-cache-size", serveCmd.Flags().Lookup("read-disk-cache-size"))
+	_ = viper.BindPFlag("read-max-bytes", serveCmd.Flags().Lookup("read-max-bytes"))
+	_ = viper.BindPFlag("read-max-length", serveCmd.Flags().Lookup("read-max-length"))
+	_ = viper.BindPFlag("max-request-timeout", serveCmd.Flags().Lookup("max-request-timeout"))
+	_ = viper.BindPFlag("read-mirror", serveCmd.Flags().Lookup("read-mirror"))
+	_ = viper.BindPFlag("include-domains", serveCmd.Flags().Lookup("include-domains"))
+	_ = viper.BindPFlag("exclude-domains", serveCmd.Flags().Lookup("exclude-domains"))
+	_ = viper.BindPFlag("domain-annotations", serveCmd.Flags().Lookup("domain-annotations"))
+	_ = viper.BindPFlag("detect-language", serveCmd.Flags().Lookup("detect-language"))
+	_ = viper.BindPFlag("shutdown-timeout", serveCmd.Flags().Lookup("shutdown-timeout"))
+	_ = viper.BindPFlag("http3", serveCmd.Flags().Lookup("http3"))
+	_ = viper.BindPFlag("idle-timeout", serveCmd.Flags().Lookup("idle-timeout"))
+	_ = viper.BindPFlag("log-tool-results", serveCmd.Flags().Lookup("log-tool-results"))
 }
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+)
+
+// cycleLogLevelOnSignal switches to the next more verbose log level on each
+// SIGUSR1 (debug, trace, then back to --log-level) until ctx ends
+func cycleLogLevelOnSignal(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				// Logged as a warning, so it shows at every level but error
+				log.WithField("new_level", log.CycleLevel()).Warn("log level changed")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package cmd
+
+import "context"
+
+// cycleLogLevelOnSignal does nothing: Windows has no SIGUSR1
+func cycleLogLevelOnSignal(ctx context.Context) {}
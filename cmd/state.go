@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/internal/paths"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/denysvitali/searxng-mcp/pkg/server"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Files of a state directory and of exported bundles
+const (
+	stateCacheFile   = "cache.json"
+	stateHistoryFile = "history.json"
+	stateLimitsFile  = "limits.json"
+)
+
+// maxStateFileSize bounds the state files imported from a bundle, so a
+// crafted bundle can't exhaust memory or disk
+const maxStateFileSize = 64 << 20
+
+// stateFiles are the files of exported bundles. The rate limits belong to
+// the deployment rather than the research, so they aren't bundled.
+var stateFiles = []string{stateCacheFile, stateHistoryFile}
+
+// limitsState is the persisted state of the rate limiters: when each one
+// has its whole burst available again, so a restart doesn't refill drained
+// buckets
+type limitsState struct {
+	Searches time.Time `json:"searches,omitzero"`
+	Reads    time.Time `json:"reads,omitzero"`
+}
+
+var flagStateOutput string
+
+// stateCmd groups the state bundle commands
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Export or import the search cache and session history",
+	Long: `Export or import the search cache and result history that "serve --state-dir"
+persists, so research state can be moved between machines or shared to
+reproduce an agent run.
+
+Examples:
+  # Bundle the state directory into a tarball
+  searxng-mcp state export --state-dir ~/.local/state/searxng-mcp -o research.tar.gz
+
+  # Unpack a bundle into another machine's state directory
+  searxng-mcp state import --state-dir ~/.local/state/searxng-mcp research.tar.gz
+
+Without --state-dir, the OS state directory is used: ~/.local/state/searxng-mcp
+($XDG_STATE_HOME) on Linux, ~/Library/Application Support/searxng-mcp on
+macOS and %LocalAppData%\searxng-mcp on Windows.`,
+	// The state commands don't talk to the instance, so unlike the root
+	// command they don't require an instance URL
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configErr != nil {
+			return configErr
+		}
+		if err := initLogging(); err != nil {
+			return configError{err}
+		}
+		if viper.GetString("state-dir") == "" {
+			dir, err := paths.StateDir()
+			if err != nil {
+				return fmt.Errorf("--state-dir is required: %w", err)
+			}
+			viper.Set("state-dir", dir)
+		}
+		return nil
+	},
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the state directory to a .tar.gz bundle",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return exportStateBundle(viper.GetString("state-dir"), flagStateOutput)
+	},
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <bundle.tar.gz>",
+	Short: "Unpack a .tar.gz bundle into the state directory",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return importStateBundle(args[0], viper.GetString("state-dir"))
+	},
+}
+
+// exportStateBundle writes the state files of dir to a gzipped tarball
+func exportStateBundle(dir, output string) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	written := 0
+	for _, name := range stateFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		written++
+	}
+	if written == 0 {
+		return fmt.Errorf("no state found in %s", dir)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return f.Close()
+}
+
+// importStateBundle extracts the state files of a bundle into dir,
+// replacing existing ones. Other archive members are ignored.
+func importStateBundle(bundle, dir string) error {
+	f, err := os.Open(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tr := tar.NewReader(gz)
+	imported := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !slices.Contains(stateFiles, header.Name) {
+			log.WithField("name", header.Name).Warn("skipping unknown bundle member")
+			continue
+		}
+
+		if header.Size > maxStateFileSize {
+			return fmt.Errorf("invalid bundle: %s is larger than %d bytes", header.Name, maxStateFileSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxStateFileSize+1))
+		if err != nil {
+			return fmt.Errorf("invalid bundle: %w", err)
+		}
+		if len(data) > maxStateFileSize {
+			return fmt.Errorf("invalid bundle: %s is larger than %d bytes", header.Name, maxStateFileSize)
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("invalid bundle: %s is not valid JSON", header.Name)
+		}
+		if err := os.WriteFile(filepath.Join(dir, header.Name), data, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", header.Name, err)
+		}
+		imported++
+	}
+	if imported == 0 {
+		return fmt.Errorf("bundle %s contains no state", bundle)
+	}
+	return nil
+}
+
+// loadState restores the cache, history and rate limits persisted in dir.
+// The result history is only restored with history set (see
+// persistHistory). Missing files are not an error.
+func loadState(dir string, client *searxng.Client, srv *server.Server, history bool) error {
+	var entries []searxng.CacheEntry
+	if err := readStateFile(dir, stateCacheFile, &entries); err != nil {
+		return err
+	}
+	var urls []string
+	if history {
+		if err := readStateFile(dir, stateHistoryFile, &urls); err != nil {
+			return err
+		}
+	}
+	var limits limitsState
+	if err := readStateFile(dir, stateLimitsFile, &limits); err != nil {
+		return err
+	}
+
+	imported := client.ImportCache(entries)
+	srv.ImportHistory(urls)
+	client.RestoreRateLimit(limits.Searches)
+	srv.RestoreReadRateLimit(limits.Reads)
+	log.WithFields(logrus.Fields{"cache_entries": imported, "history_urls": len(urls)}).Info("restored state")
+	return nil
+}
+
+// persistHistory reports whether serve keeps the result history of the
+// transport across restarts. Restored history applies to every session, so
+// it is only kept for stdio, where the sessions are those of one client;
+// over http and sse the URLs seen by one client would be hidden from the
+// others.
+func persistHistory(transport string) bool {
+	return transport == "stdio"
+}
+
+// loadCache restores the search cache persisted to dir, e.g. when waking up
+// from idle mode
+func loadCache(dir string, client *searxng.Client) error {
+	var entries []searxng.CacheEntry
+	if err := readStateFile(dir, stateCacheFile, &entries); err != nil {
+		return err
+	}
+	log.WithField("cache_entries", client.ImportCache(entries)).Debug("restored search cache")
+	return nil
+}
+
+// saveState persists the cache, rate limits and, with history set, the
+// result history to dir
+func saveState(dir string, client *searxng.Client, srv *server.Server, history bool) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := writeStateFile(dir, stateCacheFile, client.ExportCache()); err != nil {
+		return err
+	}
+	if history {
+		if err := writeStateFile(dir, stateHistoryFile, srv.ExportHistory()); err != nil {
+			return err
+		}
+	}
+	return writeStateFile(dir, stateLimitsFile, limitsState{
+		Searches: client.RateLimitFullAt(),
+		Reads:    srv.ReadRateLimitFullAt(),
+	})
+}
+
+func readStateFile(dir, name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeStateFile(dir, name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	// Write atomically so a crash never leaves a truncated file behind
+	tmp := filepath.Join(dir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return os.Rename(tmp, filepath.Join(dir, name))
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+
+	stateExportCmd.Flags().StringVarP(&flagStateOutput, "output", "o", "searxng-mcp-state.tar.gz", "Bundle file to write")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBundle writes a bundle with one member declaring size and holding
+// content, which may be shorter
+func writeBundle(t *testing.T, path, name string, size int64, content string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: size, Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte(content))
+	require.NoError(t, err)
+	if size == int64(len(content)) {
+		require.NoError(t, tw.Close())
+	}
+	require.NoError(t, gz.Close())
+}
+
+func TestImportStateBundle(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "bundle.tar.gz")
+	state := filepath.Join(dir, "state")
+
+	history := `["https://example.com"]`
+	writeBundle(t, bundle, stateHistoryFile, int64(len(history)), history)
+	require.NoError(t, importStateBundle(bundle, state))
+	data, err := os.ReadFile(filepath.Join(state, stateHistoryFile))
+	require.NoError(t, err)
+	assert.JSONEq(t, history, string(data))
+
+	writeBundle(t, bundle, stateCacheFile, maxStateFileSize+1, "[]")
+	assert.ErrorContains(t, importStateBundle(bundle, state), "cache.json is larger than")
+	assert.NoFileExists(t, filepath.Join(state, stateCacheFile))
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSuggestOutput   string
+	flagSuggestLanguage string
+	flagSuggestLimit    int
+)
+
+// suggestCmd represents the suggest command
+var suggestCmd = &cobra.Command{
+	Use:   "suggest <partial query>",
+	Short: "Print the instance's autocomplete suggestions for a query",
+	Long: `Print the autocomplete suggestions of the Searxng instance for a
+partial query, one per line, like the searxng_suggestions tool.
+
+The instance must have an autocomplete backend configured (search.autocomplete
+in its settings.yml); otherwise nothing is printed.
+
+Examples:
+  # Print suggestions
+  searxng-mcp suggest "golang gen"
+
+  # Pick a suggestion with fzf and search it
+  searxng-mcp search "$(searxng-mcp suggest "golang gen" | fzf)"
+
+  # German suggestions as JSON
+  searxng-mcp suggest "wetter" --language de --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagSuggestOutput != outputText && flagSuggestOutput != outputJSON {
+			return usageErrorf("invalid --output %q (must be text or json)", flagSuggestOutput)
+		}
+		if flagSuggestLimit < 0 {
+			return usageErrorf("invalid --limit %d (must be at least 0)", flagSuggestLimit)
+		}
+		query := args[0]
+		if strings.TrimSpace(query) == "" {
+			return usageErrorf("a query is required")
+		}
+
+		client, err := searxng.NewClient(newSearxngConfig())
+		if err != nil {
+			return fmt.Errorf("failed to create searxng client: %w", err)
+		}
+
+		cmd.SilenceUsage = true
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		suggestions, err := client.Suggestions(ctx, query, flagSuggestLanguage)
+		if err != nil {
+			return fmt.Errorf("autocomplete failed: %w", err)
+		}
+		if flagSuggestLimit > 0 && len(suggestions) > flagSuggestLimit {
+			suggestions = suggestions[:flagSuggestLimit]
+		}
+
+		if flagSuggestOutput == outputJSON {
+			return writeJSONSuggestions(os.Stdout, query, suggestions)
+		}
+		for _, suggestion := range suggestions {
+			fmt.Println(suggestion)
+		}
+		return nil
+	},
+}
+
+// suggestArgs validates the arguments of the suggest command
+func suggestArgs(cmd *cobra.Command, args []string) error {
+	silenceForJSON(cmd)
+	if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+		return usageError{err}
+	}
+	return nil
+}
+
+// writeJSONSuggestions writes the suggestions for query to w as an
+// indented JSON object
+func writeJSONSuggestions(w io.Writer, query string, suggestions []string) error {
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Query       string   `json:"query"`
+		Suggestions []string `json:"suggestions"`
+	}{query, suggestions})
+}
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+	suggestCmd.Args = suggestArgs // assigned here, suggestArgs refers to suggestCmd
+
+	suggestCmd.Flags().StringVarP(&flagSuggestOutput, "output", "o", outputText, "Output format: text (one suggestion per line), or json for {\"query\", \"suggestions\"} on stdout and errors on stderr as {\"error\": {\"code\", \"message\", \"retryable\"}}")
+	suggestCmd.Flags().StringVar(&flagSuggestLanguage, "language", "", "Language of the suggestions as a locale code, e.g. de (default: the instance's setting)")
+	suggestCmd.Flags().IntVarP(&flagSuggestLimit, "limit", "l", 0, "Maximum number of suggestions to print (0: all)")
+}
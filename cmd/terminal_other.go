@@ -0,0 +1,19 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package cmd
+
+import (
+	"errors"
+	"os"
+)
+
+// makeRaw fails: raw mode is only supported on Linux and the BSDs, so the
+// built-in pager reads commands as lines elsewhere
+func makeRaw(f *os.File) (restore func(), err error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+// terminalSize returns the size from $COLUMNS and $LINES
+func terminalSize(f *os.File) (width, height int) {
+	return envTerminalSize()
+}
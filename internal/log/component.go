@@ -0,0 +1,109 @@
+package log
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Components are the names of the parts of the program whose logs can get
+// their own level with Config.ComponentLevels: the name of the package
+// logging
+var Components = []string{"cmd", "server", "searxng"}
+
+// componentKey is the field naming the component of a log entry. It's only
+// set when component levels are configured.
+const componentKey = "component"
+
+// componentLevels are the levels of Config.ComponentLevels, set by
+// Configure
+var componentLevels map[string]logrus.Level
+
+// parseComponentLevels validates the names and levels of
+// Config.ComponentLevels
+func parseComponentLevels(levels map[string]string) (map[string]logrus.Level, error) {
+	parsed := make(map[string]logrus.Level, len(levels))
+	for component, level := range levels {
+		if !slices.Contains(Components, component) {
+			return nil, fmt.Errorf("unknown log component %q (must be one of: %s)", component, strings.Join(Components, ", "))
+		}
+		l, err := logrus.ParseLevel(level)
+		if err != nil || !slices.Contains(Levels, level) {
+			return nil, fmt.Errorf("invalid log level %q of %s (must be one of: %s)", level, component, strings.Join(Levels, ", "))
+		}
+		parsed[component] = l
+	}
+	return parsed, nil
+}
+
+// ParseComponentLevels parses "component=level" pairs, e.g.
+// "searxng=debug", into Config.ComponentLevels
+func ParseComponentLevels(pairs []string) (map[string]string, error) {
+	levels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		component, level, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid log component level %q (must be component=level)", pair)
+		}
+		levels[strings.TrimSpace(component)] = strings.TrimSpace(level)
+	}
+	if _, err := parseComponentLevels(levels); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// entry returns a new entry of the global logger, naming the component of
+// the caller of the exported function calling it when component levels
+// are configured
+func entry() *logrus.Entry {
+	e := logrus.NewEntry(Get())
+	if len(componentLevels) == 0 {
+		return e
+	}
+	return e.WithField(componentKey, callerComponent(3))
+}
+
+// callerComponent returns the last element of the package path of the
+// function skip frames up the stack, e.g. "server" for
+// github.com/denysvitali/searxng-mcp/pkg/server
+func callerComponent(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	dir, name := path.Split(fn.Name())
+	pkg, _, _ := strings.Cut(name, ".")
+	if pkg == "" {
+		return path.Base(dir)
+	}
+	return pkg
+}
+
+// componentFilter drops the entries above the level of their component,
+// as the logger lets through the most verbose of the configured levels.
+// logrus writes nothing for the empty result.
+type componentFilter struct {
+	logrus.Formatter
+}
+
+func (f componentFilter) Format(e *logrus.Entry) ([]byte, error) {
+	level := logrus.Level(baseLevel.Load())
+	if component, ok := e.Data[componentKey].(string); ok {
+		if l, ok := componentLevels[component]; ok {
+			level = l
+		}
+	}
+	if e.Level > level {
+		return nil, nil
+	}
+	return f.Formatter.Format(e)
+}
@@ -1,36 +1,166 @@
 package log
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
 
 var logger *logrus.Logger
 
-// Init initializes the global logger with the specified level
+// logFile is the file of Config.File, closed when the logger is replaced
+var logFile io.Closer
+
+// baseLevel is the level of logs outside Config.ComponentLevels, changed
+// at runtime by SetLevel and CycleLevel
+var baseLevel atomic.Uint32
+
+// initialLevel is the level set by Init, which CycleLevel returns to
+var initialLevel logrus.Level
+
+// Levels are the level names accepted by Init and SetLevel, most verbose
+// first
+var Levels = []string{"trace", "debug", "info", "warn", "error"}
+
+// Formats are the log formats accepted by Config.Format
+var Formats = []string{"text", "json"}
+
+// Config configures the global logger
+type Config struct {
+	// Level is one of Levels; info when empty or invalid
+	Level string
+	// Format is one of Formats; text when empty
+	Format string
+	// File is the path logs are appended to instead of stderr, rotated
+	// when it reaches MaxSize
+	File string
+	// MaxSize is the size of File in bytes at which it's rotated
+	// (default: DefaultMaxSize)
+	MaxSize int64
+	// MaxBackups is the number of rotated files kept next to File, as
+	// File.1 (the newest) to File.<MaxBackups>
+	MaxBackups int
+	// ComponentLevels overrides Level for the logs of components (see
+	// Components), e.g. {"searxng": "debug"}
+	ComponentLevels map[string]string
+}
+
+// Init initializes the global logger with the specified level, logging in
+// text to stderr
 func Init(level string) {
-	logger = logrus.New()
+	_ = Configure(Config{Level: level})
+}
+
+// Configure initializes the global logger. On error, the logger is left
+// unchanged.
+func Configure(config Config) error {
+	var formatter logrus.Formatter
+	switch config.Format {
+	case "", "text":
+		formatter = &logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+		}
+	case "json":
+		formatter = &logrus.JSONFormatter{}
+	default:
+		return fmt.Errorf("invalid log format %q (must be one of: %s)", config.Format, strings.Join(Formats, ", "))
+	}
+	components, err := parseComponentLevels(config.ComponentLevels)
+	if err != nil {
+		return err
+	}
 	// In MCP stdio mode, stdout is reserved for protocol messages.
 	// Keep logs on stderr to avoid corrupting the stream.
-	logger.SetOutput(os.Stderr)
-	logger.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02 15:04:05",
-	})
-
-	switch level {
-	case "debug":
-		logger.SetLevel(logrus.DebugLevel)
-	case "info":
-		logger.SetLevel(logrus.InfoLevel)
-	case "warn":
-		logger.SetLevel(logrus.WarnLevel)
-	case "error":
-		logger.SetLevel(logrus.ErrorLevel)
+	var out io.Writer = os.Stderr
+	var file io.Closer
+	if config.File != "" {
+		rotating, err := openRotatingFile(config.File, config.MaxSize, config.MaxBackups)
+		if err != nil {
+			return err
+		}
+		out, file = rotating, rotating
+	}
+
+	level, err := logrus.ParseLevel(config.Level)
+	if err != nil || !slices.Contains(Levels, config.Level) {
+		level = logrus.InfoLevel
+	}
+	if len(components) > 0 {
+		formatter = componentFilter{formatter}
+	}
+
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	logger = logrus.New()
+	logger.SetOutput(out)
+	logger.SetFormatter(formatter)
+	logFile = file
+	componentLevels = components
+	setBaseLevel(level)
+	initialLevel = level
+	return nil
+}
+
+// setBaseLevel sets the level of logs outside the component levels. The
+// logger lets through the most verbose of all levels; componentFilter
+// drops the rest.
+func setBaseLevel(level logrus.Level) {
+	baseLevel.Store(uint32(level))
+	for _, l := range componentLevels {
+		level = max(level, l)
+	}
+	logger.SetLevel(level)
+}
+
+// Level returns the name of the current level
+func Level() string {
+	Get()
+	return levelName(logrus.Level(baseLevel.Load()))
+}
+
+// SetLevel changes the level at runtime, e.g. to debug a running server.
+// level is one of Levels.
+func SetLevel(level string) error {
+	if !slices.Contains(Levels, level) {
+		return fmt.Errorf("invalid log level %q (must be one of: %s)", level, strings.Join(Levels, ", "))
+	}
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	Get()
+	setBaseLevel(parsed)
+	return nil
+}
+
+// CycleLevel switches to the next more verbose level, up to trace, then
+// back to the level set by Init, and returns the name of the new level
+func CycleLevel() string {
+	Get()
+	switch level := logrus.Level(baseLevel.Load()); {
+	case level >= logrus.TraceLevel:
+		setBaseLevel(initialLevel)
+	case level < logrus.DebugLevel:
+		setBaseLevel(logrus.DebugLevel)
 	default:
-		logger.SetLevel(logrus.InfoLevel)
+		setBaseLevel(level + 1)
+	}
+	return Level()
+}
+
+// levelName returns the name of level as in Levels
+func levelName(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "warn"
 	}
+	return level.String()
 }
 
 // Get returns the global logger instance
@@ -43,35 +173,35 @@ func Get() *logrus.Logger {
 
 // WithField returns a logger entry with a single field
 func WithField(key string, value interface{}) *logrus.Entry {
-	return Get().WithField(key, value)
+	return entry().WithField(key, value)
 }
 
 // WithFields returns a logger entry with multiple fields
 func WithFields(fields logrus.Fields) *logrus.Entry {
-	return Get().WithFields(fields)
+	return entry().WithFields(fields)
 }
 
 // Debug logs a message at DebugLevel
 func Debug(args ...interface{}) {
-	Get().Debug(args...)
+	entry().Debug(args...)
 }
 
 // Info logs a message at InfoLevel
 func Info(args ...interface{}) {
-	Get().Info(args...)
+	entry().Info(args...)
 }
 
 // Warn logs a message at WarnLevel
 func Warn(args ...interface{}) {
-	Get().Warn(args...)
+	entry().Warn(args...)
 }
 
 // Error logs a message at ErrorLevel
 func Error(args ...interface{}) {
-	Get().Error(args...)
+	entry().Error(args...)
 }
 
 // Fatal logs a message at FatalLevel and exits
 func Fatal(args ...interface{}) {
-	Get().Fatal(args...)
+	entry().Fatal(args...)
 }
@@ -1,7 +1,11 @@
 package log
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -16,3 +20,155 @@ func TestInit_LogsToStderr(t *testing.T) {
 		t.Fatal("logger output must not be os.Stdout")
 	}
 }
+
+func TestCycleLevel(t *testing.T) {
+	Init("info")
+
+	var got []string
+	for range 4 {
+		got = append(got, CycleLevel())
+	}
+	want := []string{"debug", "trace", "info", "debug"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected levels %v, got %v", want, got)
+	}
+
+	Init("warn")
+	if level := CycleLevel(); level != "debug" {
+		t.Fatalf("expected debug after warn, got %s", level)
+	}
+	CycleLevel()
+	if level := CycleLevel(); level != "warn" {
+		t.Fatalf("expected the initial level after trace, got %s", level)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	Init("info")
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	if Level() != "debug" {
+		t.Fatalf("expected debug, got %s", Level())
+	}
+	if err := SetLevel("panic"); err == nil {
+		t.Fatal("expected an error for a level outside Levels")
+	}
+	if Level() != "debug" {
+		t.Fatalf("invalid levels must not change the level, got %s", Level())
+	}
+}
+
+// readLogLines returns the JSON log lines of path
+func readLogLines(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestConfigure_JSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "searxng-mcp.log")
+	if err := Configure(Config{Level: "info", Format: "json", File: path}); err != nil {
+		t.Fatal(err)
+	}
+	defer Init("info")
+
+	WithField("query", "golang").Info("searching")
+	Debug("not logged")
+
+	lines := readLogLines(t, path)
+	if len(lines) != 1 || lines[0]["msg"] != "searching" || lines[0]["query"] != "golang" {
+		t.Fatalf("unexpected log lines %v", lines)
+	}
+	if _, ok := lines[0][componentKey]; ok {
+		t.Fatal("the component is only logged with component levels")
+	}
+
+	if err := Configure(Config{Format: "xml"}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestConfigure_ComponentLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "searxng-mcp.log")
+	levels, err := ParseComponentLevels([]string{"server=warn", " searxng = debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Configure(Config{Level: "info", Format: "json", File: path, ComponentLevels: levels}); err != nil {
+		t.Fatal(err)
+	}
+	defer Init("info")
+
+	// The tests of this package are the "log" component, at the base level
+	Info("base info")
+	Debug("base debug")
+	entry := Get().WithField(componentKey, "searxng")
+	entry.Debug("searxng debug")
+	entry = Get().WithField(componentKey, "server")
+	entry.Info("server info")
+	entry.Warn("server warn")
+
+	var got []string
+	for _, line := range readLogLines(t, path) {
+		got = append(got, line["msg"].(string))
+	}
+	want := []string{"base info", "searxng debug", "server warn"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if err := SetLevel("debug"); err != nil || Level() != "debug" {
+		t.Fatalf("SetLevel changes the base level, got %s (%v)", Level(), err)
+	}
+
+	for _, pairs := range [][]string{{"reader=debug"}, {"server=verbose"}, {"server"}} {
+		if _, err := ParseComponentLevels(pairs); err == nil {
+			t.Fatalf("expected an error for %v", pairs)
+		}
+	}
+}
+
+func TestCallerComponent(t *testing.T) {
+	if got := callerComponent(1); got != "log" {
+		t.Fatalf("expected the log package, got %q", got)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, want := range map[string]string{"app.log": "fourth\n", "app.log.1": "third\n", "app.log.2": "second\n"} {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		if err != nil || string(data) != want {
+			t.Fatalf("expected %s to hold %q, got %q (%v)", name, want, data, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatal("only MaxBackups rotated files are kept")
+	}
+}
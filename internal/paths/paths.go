@@ -0,0 +1,69 @@
+// Package paths resolves the default directories of the config file, the
+// persisted state (search cache, result and query history) and disposable
+// caches. Defaults follow the conventions of each OS: the XDG base
+// directories on Linux and other Unixes, ~/Library on macOS and
+// %AppData%/%LocalAppData% on Windows. Packagers can embed other defaults
+// at build time:
+//
+//	go build -ldflags "-X github.com/denysvitali/searxng-mcp/internal/paths.configDir=/etc/searxng-mcp"
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appName is the directory name under the OS base directories
+const appName = "searxng-mcp"
+
+// Build-time defaults, set with -ldflags "-X ...". Empty values are
+// resolved at runtime.
+var (
+	configDir string
+	stateDir  string
+	cacheDir  string
+)
+
+// ConfigDir returns the directory of the default config file
+func ConfigDir() (string, error) {
+	if configDir != "" {
+		return configDir, nil
+	}
+	return osConfigDir()
+}
+
+// StateDir returns the default directory of the persisted state
+func StateDir() (string, error) {
+	if stateDir != "" {
+		return stateDir, nil
+	}
+	return osStateDir()
+}
+
+// CacheDir returns the default directory of caches that can be deleted at
+// any time, such as fetched pages
+func CacheDir() (string, error) {
+	if cacheDir != "" {
+		return cacheDir, nil
+	}
+	return osCacheDir()
+}
+
+// xdgDir returns $env/searxng-mcp when env is set to an absolute path, as
+// the XDG spec requires, and ~/fallback/searxng-mcp otherwise
+func xdgDir(env string, fallback ...string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+	return homeDir(fallback...)
+}
+
+// homeDir returns ~/elem.../searxng-mcp
+func homeDir(elem ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the home directory: %w", err)
+	}
+	return filepath.Join(append(append([]string{home}, elem...), appName)...), nil
+}
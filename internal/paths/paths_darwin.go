@@ -0,0 +1,32 @@
+//go:build darwin
+
+package paths
+
+import "os"
+
+// osConfigDir returns ~/Library/Application Support/searxng-mcp, or
+// $XDG_CONFIG_HOME/searxng-mcp for users who set it
+func osConfigDir() (string, error) {
+	if os.Getenv("XDG_CONFIG_HOME") != "" {
+		return xdgDir("XDG_CONFIG_HOME", "Library", "Application Support")
+	}
+	return homeDir("Library", "Application Support")
+}
+
+// osStateDir returns ~/Library/Application Support/searxng-mcp, or
+// $XDG_STATE_HOME/searxng-mcp for users who set it
+func osStateDir() (string, error) {
+	if os.Getenv("XDG_STATE_HOME") != "" {
+		return xdgDir("XDG_STATE_HOME", "Library", "Application Support")
+	}
+	return homeDir("Library", "Application Support")
+}
+
+// osCacheDir returns ~/Library/Caches/searxng-mcp, or
+// $XDG_CACHE_HOME/searxng-mcp for users who set it
+func osCacheDir() (string, error) {
+	if os.Getenv("XDG_CACHE_HOME") != "" {
+		return xdgDir("XDG_CACHE_HOME", "Library", "Caches")
+	}
+	return homeDir("Library", "Caches")
+}
@@ -0,0 +1,57 @@
+package paths
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestBuildTimeDefaults(t *testing.T) {
+	defer func(config, state, cache string) { configDir, stateDir, cacheDir = config, state, cache }(configDir, stateDir, cacheDir)
+	configDir, stateDir, cacheDir = "/etc/searxng-mcp", "/var/lib/searxng-mcp", "/var/cache/searxng-mcp"
+
+	if dir, err := ConfigDir(); err != nil || dir != "/etc/searxng-mcp" {
+		t.Fatalf("ConfigDir() = %q, %v", dir, err)
+	}
+	if dir, err := StateDir(); err != nil || dir != "/var/lib/searxng-mcp" {
+		t.Fatalf("StateDir() = %q, %v", dir, err)
+	}
+	if dir, err := CacheDir(); err != nil || dir != "/var/cache/searxng-mcp" {
+		t.Fatalf("CacheDir() = %q, %v", dir, err)
+	}
+}
+
+func TestXDGDirs(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG defaults apply to Linux and other Unixes")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "relative/ignored")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	tests := []struct {
+		name string
+		dir  func() (string, error)
+		want string
+	}{
+		{"config", ConfigDir, filepath.Join(home, ".config", "searxng-mcp")},
+		{"state", StateDir, filepath.Join(home, ".local", "state", "searxng-mcp")},
+		{"cache", CacheDir, filepath.Join(home, ".cache", "searxng-mcp")},
+	}
+	for _, tt := range tests {
+		if dir, err := tt.dir(); err != nil || dir != tt.want {
+			t.Errorf("%s dir = %q, %v; want %q", tt.name, dir, err, tt.want)
+		}
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	if dir, _ := ConfigDir(); dir != filepath.Join("/xdg/config", "searxng-mcp") {
+		t.Errorf("ConfigDir() = %q", dir)
+	}
+	if dir, _ := StateDir(); dir != filepath.Join("/xdg/state", "searxng-mcp") {
+		t.Errorf("StateDir() = %q", dir)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !darwin && !windows
+
+package paths
+
+// osConfigDir returns $XDG_CONFIG_HOME/searxng-mcp, by default
+// ~/.config/searxng-mcp
+func osConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// osStateDir returns $XDG_STATE_HOME/searxng-mcp, by default
+// ~/.local/state/searxng-mcp
+func osStateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// osCacheDir returns $XDG_CACHE_HOME/searxng-mcp, by default
+// ~/.cache/searxng-mcp
+func osCacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// osConfigDir returns %AppData%\searxng-mcp, which roams with the user
+// profile
+func osConfigDir() (string, error) {
+	return appDataDir("AppData")
+}
+
+// osStateDir returns %LocalAppData%\searxng-mcp, which stays on the
+// machine
+func osStateDir() (string, error) {
+	return appDataDir("LocalAppData")
+}
+
+// osCacheDir returns %LocalAppData%\searxng-mcp\cache
+func osCacheDir() (string, error) {
+	dir, err := appDataDir("LocalAppData")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache"), nil
+}
+
+func appDataDir(env string) (string, error) {
+	dir := os.Getenv(env)
+	if dir == "" {
+		return "", fmt.Errorf("%%%s%% is not set", env)
+	}
+	return filepath.Join(dir, appName), nil
+}
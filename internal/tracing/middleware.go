@@ -155,7 +155,7 @@ func networkTransport(transport string) string {
 	switch transport {
 	case "stdio":
 		return "pipe"
-	case "http":
+	case "http", "sse":
 		return "tcp"
 	default:
 		return "pipe"
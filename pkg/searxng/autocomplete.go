@@ -0,0 +1,103 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Suggestions returns the autocomplete suggestions of the instance's
+// /autocompleter endpoint for query, optionally in language (e.g. "de").
+// Instances without an autocomplete backend configured return none.
+func (c *Client) Suggestions(ctx context.Context, query, language string) ([]string, error) {
+	baseURL, err := url.Parse(c.config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	autocompleterPath, _ := url.Parse("/autocompleter")
+	u := baseURL.ResolveReference(autocompleterPath)
+	params := url.Values{"q": {query}}
+	if language != "" {
+		params.Set("language", language)
+	}
+	u.RawQuery = params.Encode()
+
+	ctx, span := tracer().Start(ctx, "searxng.suggestions")
+	span.SetAttributes(attribute.String("searxng.instance", hostOf(c.config.BaseURL)))
+	suggestions, err := c.fetchSuggestions(ctx, u.String())
+	if err == nil {
+		span.SetAttributes(attribute.Int("searxng.suggestions", len(suggestions)))
+	}
+	endSpan(span, err)
+	return suggestions, err
+}
+
+func (c *Client) fetchSuggestions(ctx context.Context, suggestURL string) ([]string, error) {
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, suggestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	c.touch()
+	httpResp, err := c.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRequestFailed, err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d", httpResp.StatusCode)
+	}
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return parseSuggestions(body)
+}
+
+// parseSuggestions decodes an /autocompleter response: the OpenSearch
+// suggestions format ["query", ["suggestion", ...]] sent to browsers, or
+// the plain list sent to the instance's own search form
+func parseSuggestions(body []byte) ([]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+
+	var suggestions []string
+	if len(raw) == 2 {
+		var prefix string
+		if json.Unmarshal(raw[0], &prefix) == nil && json.Unmarshal(raw[1], &suggestions) == nil {
+			return nonEmpty(suggestions), nil
+		}
+	}
+	if err := json.Unmarshal(body, &suggestions); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return nonEmpty(suggestions), nil
+}
+
+// nonEmpty returns the non-blank suggestions, never nil
+func nonEmpty(suggestions []string) []string {
+	kept := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		if s = strings.TrimSpace(s); s != "" {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
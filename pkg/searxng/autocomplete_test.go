@@ -0,0 +1,67 @@
+package searxng
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/autocompleter", r.URL.Path)
+		assert.Equal(t, "golang", r.URL.Query().Get("q"))
+		assert.Equal(t, "de", r.URL.Query().Get("language"))
+		w.Header().Set("Content-Type", "application/x-suggestions+json")
+		_, _ = w.Write([]byte(`["golang", ["golang tutorial", " ", "golang generics"]]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	require.NoError(t, err)
+	suggestions, err := client.Suggestions(context.Background(), "golang", "de")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang tutorial", "golang generics"}, suggestions)
+}
+
+func TestParseSuggestions(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{"opensearch", `["go", ["go tour", "go modules"]]`, []string{"go tour", "go modules"}, false},
+		{"plain list", `["go tour", "go modules", "go vet"]`, []string{"go tour", "go modules", "go vet"}, false},
+		{"two suggestions", `["go tour", "go modules"]`, []string{"go tour", "go modules"}, false},
+		{"none", `[]`, []string{}, false},
+		{"no backend", `["go", []]`, []string{}, false},
+		{"invalid", `{"error": "x"}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSuggestions([]byte(tt.body))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidResponse)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSuggestions_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	require.NoError(t, err)
+	_, err = client.Suggestions(context.Background(), "golang", "")
+	assert.EqualError(t, err, "HTTP 404")
+}
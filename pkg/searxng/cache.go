@@ -0,0 +1,182 @@
+package searxng
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheSize is the default maximum number of cached search responses
+const DefaultCacheSize = 128
+
+// searchCache is an LRU cache of search responses whose entries expire
+// after a fixed TTL. It is safe for concurrent use.
+type searchCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List // front: most recently used
+	entries map[string]*list.Element
+	clock   clock
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type cacheEntry struct {
+	key     string
+	resp    *SearchResponse
+	expires time.Time
+}
+
+// CacheEntry is a cached search response as exported by Client.ExportCache
+type CacheEntry struct {
+	Key      string          `json:"key"`
+	Response *SearchResponse `json:"response"`
+	Expires  time.Time       `json:"expires"`
+}
+
+// newSearchCache creates a cache, or returns nil when ttl disables caching
+func newSearchCache(clk clock, ttl time.Duration, size int) *searchCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &searchCache{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		clock:   clk,
+	}
+}
+
+// get returns a copy of the cached response for key, if present and fresh
+func (c *searchCache) get(key string) (*SearchResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if c.clock.Now().After(entry.expires) {
+		c.remove(elem)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	c.hits.Add(1)
+	resp := *entry.resp
+	return &resp, true
+}
+
+// put stores resp under key, evicting the least recently used entry when
+// the cache is full
+func (c *searchCache) put(key string, resp *SearchResponse) {
+	c.putUntil(key, resp, c.clock.Now().Add(c.ttl))
+}
+
+func (c *searchCache) putUntil(key string, resp *SearchResponse, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *resp
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.resp, entry.expires = &stored, expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, resp: &stored, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// len returns the number of cached entries, including expired ones that
+// have not been evicted yet
+func (c *searchCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// export returns the fresh entries, least recently used first
+func (c *searchCache) export() []CacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	entries := make([]CacheEntry, 0, c.order.Len())
+	for elem := c.order.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*cacheEntry)
+		if now.After(entry.expires) {
+			continue
+		}
+		entries = append(entries, CacheEntry{Key: entry.key, Response: entry.resp, Expires: entry.expires})
+	}
+	return entries
+}
+
+// clear drops every entry, returning how many there were
+func (c *searchCache) clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.order.Len()
+	c.order.Init()
+	clear(c.entries)
+	return n
+}
+
+func (c *searchCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+// ExportCache returns the fresh cache entries, least recently used first,
+// for persisting them with ImportCache later. It returns nil when caching
+// is disabled.
+func (c *Client) ExportCache() []CacheEntry {
+	if c.cache == nil {
+		return nil
+	}
+	return c.cache.export()
+}
+
+// ImportCache adds previously exported entries to the cache, keeping their
+// original expiry. Expired entries are skipped. It returns the number of
+// imported entries; nothing is imported when caching is disabled.
+func (c *Client) ImportCache(entries []CacheEntry) int {
+	if c.cache == nil {
+		return 0
+	}
+	now := c.clock.Now()
+	imported := 0
+	for _, entry := range entries {
+		if entry.Response == nil || now.After(entry.Expires) {
+			continue
+		}
+		c.cache.putUntil(entry.Key, entry.Response, entry.Expires)
+		imported++
+	}
+	return imported
+}
+
+// ClearCache drops every cached response, e.g. to release memory once
+// persisted with ExportCache. It returns the number of dropped entries.
+func (c *Client) ClearCache() int {
+	if c.cache == nil {
+		return 0
+	}
+	return c.cache.clear()
+}
@@ -0,0 +1,166 @@
+package searxng
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSearchCache_Disabled(t *testing.T) {
+	assert.Nil(t, newSearchCache(realClock{}, 0, 10))
+
+	cache := newSearchCache(realClock{}, time.Minute, 0)
+	require.NotNil(t, cache)
+	assert.Equal(t, DefaultCacheSize, cache.size)
+}
+
+func TestSearchCache_LRU(t *testing.T) {
+	cache := newSearchCache(realClock{}, time.Minute, 2)
+
+	cache.put("a", &SearchResponse{Query: "a"})
+	cache.put("b", &SearchResponse{Query: "b"})
+	_, ok := cache.get("a") // a becomes most recently used
+	require.True(t, ok)
+	cache.put("c", &SearchResponse{Query: "c"})
+
+	_, ok = cache.get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+	resp, ok := cache.get("a")
+	require.True(t, ok)
+	assert.Equal(t, "a", resp.Query)
+
+	assert.Equal(t, 2, cache.len())
+	assert.Equal(t, uint64(1), cache.evictions.Load())
+	assert.Equal(t, uint64(2), cache.hits.Load())
+	assert.Equal(t, uint64(1), cache.misses.Load())
+}
+
+func TestSearchCache_TTL(t *testing.T) {
+	clk := newFakeClock()
+	cache := newSearchCache(clk, time.Minute, 10)
+
+	cache.put("q", &SearchResponse{Query: "q"})
+	_, ok := cache.get("q")
+	assert.True(t, ok)
+
+	clk.Advance(time.Minute - time.Second)
+	_, ok = cache.get("q")
+	assert.True(t, ok)
+
+	clk.Advance(2 * time.Second)
+	_, ok = cache.get("q")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.len())
+}
+
+func TestClient_Search_Cache(t *testing.T) {
+	defer gock.OffAll()
+
+	// Two backend responses: the second search is served from the cache,
+	// the NoCache one goes to the instance
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "cached").
+		Times(2).
+		Reply(200).
+		JSON(APIResponse{Query: "cached", Results: []APIResult{{URL: "https://example.com", Title: "Example"}}})
+
+	config := DefaultConfig()
+	config.CacheTTL = time.Minute
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	first, err := client.Search(ctx, SearchRequest{Query: "cached"})
+	require.NoError(t, err)
+	second, err := client.Search(ctx, SearchRequest{Query: "cached"})
+	require.NoError(t, err)
+	assert.Equal(t, first.Results, second.Results)
+
+	_, err = client.Search(ctx, SearchRequest{Query: "cached", NoCache: true})
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone())
+
+	stats := client.Stats()
+	assert.Equal(t, uint64(3), stats.Searches)
+	assert.Equal(t, uint64(2), stats.Requests)
+	assert.Equal(t, uint64(1), stats.CacheHits)
+	assert.Equal(t, uint64(1), stats.CacheMisses)
+	assert.Equal(t, 1, stats.CacheEntries)
+}
+
+func TestClient_Search_CacheKeyIncludesParams(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "golang").
+		Times(2).
+		Reply(200).
+		JSON(APIResponse{Query: "golang"})
+
+	config := DefaultConfig()
+	config.CacheTTL = time.Minute
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "golang"})
+	require.NoError(t, err)
+	_, err = client.Search(context.Background(), SearchRequest{Query: "golang", Page: 2})
+	require.NoError(t, err)
+
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, uint64(0), client.Stats().CacheHits)
+}
+
+func TestClient_ExportImportCache(t *testing.T) {
+	config := DefaultConfig()
+	config.CacheTTL = time.Minute
+	source, err := NewClient(config)
+	require.NoError(t, err)
+
+	source.cache.put("GET a", &SearchResponse{Query: "a"})
+	source.cache.put("GET b", &SearchResponse{Query: "b"})
+	source.cache.putUntil("GET stale", &SearchResponse{Query: "stale"}, time.Now().Add(-time.Second))
+
+	entries := source.ExportCache()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "GET a", entries[0].Key, "least recently used first")
+
+	target, err := NewClient(config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, target.ImportCache(entries))
+	resp, ok := target.cache.get("GET b")
+	require.True(t, ok)
+	assert.Equal(t, "b", resp.Query)
+
+	disabled, err := NewClient(DefaultConfig())
+	require.NoError(t, err)
+	assert.Nil(t, disabled.ExportCache())
+	assert.Equal(t, 0, disabled.ImportCache(entries))
+}
+
+func TestClient_ClearCache(t *testing.T) {
+	config := DefaultConfig()
+	config.CacheTTL = time.Minute
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	client.cache.put("GET a", &SearchResponse{Query: "a"})
+	client.cache.put("GET b", &SearchResponse{Query: "b"})
+	assert.Equal(t, 2, client.ClearCache())
+	assert.Empty(t, client.ExportCache())
+	_, ok := client.cache.get("GET a")
+	assert.False(t, ok)
+
+	client.cache.put("GET c", &SearchResponse{Query: "c"})
+	assert.Len(t, client.ExportCache(), 1, "the cache is usable after clearing")
+
+	disabled, err := NewClient(DefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 0, disabled.ClearCache())
+}
@@ -0,0 +1,108 @@
+package searxng
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned by InstanceInfo.Supports for requests the
+// instance can't serve
+var ErrUnsupported = errors.New("not supported by this searxng instance")
+
+// timeRanges lists the time ranges in increasing length, with the first
+// instance version accepting each. Searx releases before the SearXNG fork
+// (1.0.0) only know day, month and year.
+var timeRanges = []struct {
+	name       string
+	minVersion string
+}{
+	{"day", ""},
+	{"week", "1.0.0"},
+	{"month", ""},
+	{"year", ""},
+}
+
+// parseVersion parses the leading numeric components of an instance
+// version: "1.1.0-92-gd2c2ab5", "2024.5.24+c2b0ab2" and "2023.10.1" are all
+// accepted. It returns false when version has no leading number.
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	for i, part := range strings.SplitN(version, ".", 3) {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, i > 0
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+// versionAtLeast reports whether the instance version is at least min. An
+// unknown version is assumed to be recent.
+func (i *InstanceInfo) versionAtLeast(min string) bool {
+	if min == "" {
+		return true
+	}
+	version, ok := parseVersion(i.Version)
+	if !ok {
+		return true
+	}
+	minVersion, _ := parseVersion(min)
+	return slices.Compare(version[:], minVersion[:]) >= 0
+}
+
+// TimeRanges returns the time ranges the instance accepts
+func (i *InstanceInfo) TimeRanges() []string {
+	var names []string
+	for _, tr := range timeRanges {
+		if i.versionAtLeast(tr.minVersion) {
+			names = append(names, tr.name)
+		}
+	}
+	return names
+}
+
+// SupportsLanguage reports whether the instance knows the locale lang,
+// either exactly or through its language ("de" for "de-CH"). "all" and
+// "auto" are always supported, as is any locale when the instance doesn't
+// publish its locales.
+func (i *InstanceInfo) SupportsLanguage(lang string) bool {
+	if lang == "" || lang == "all" || lang == "auto" || len(i.Locales) == 0 {
+		return true
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	for locale := range i.Locales {
+		if strings.EqualFold(locale, lang) || strings.EqualFold(locale, base) {
+			return true
+		}
+	}
+	return false
+}
+
+// Supports checks the time range, category and language of req against the
+// instance's version and published categories and locales. Settings the
+// instance doesn't describe are assumed to be supported.
+func (i *InstanceInfo) Supports(req SearchRequest) error {
+	if req.TimeRange != "" {
+		supported := i.TimeRanges()
+		if !slices.Contains(supported, req.TimeRange) {
+			return fmt.Errorf("time_range %q is %w (version %s; supported: %s)",
+				req.TimeRange, ErrUnsupported, i.Version, strings.Join(supported, ", "))
+		}
+	}
+	if req.Category != "" && len(i.Categories) > 0 && !slices.Contains(i.Categories, req.Category) {
+		return fmt.Errorf("category %q is %w (available categories: %s)",
+			req.Category, ErrUnsupported, strings.Join(i.Categories, ", "))
+	}
+	if !i.SupportsLanguage(req.Language) {
+		return fmt.Errorf("language %q is %w (available languages: %s)",
+			req.Language, ErrUnsupported, strings.Join(slices.Sorted(maps.Keys(i.Locales)), ", "))
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    [3]int
+		ok      bool
+	}{
+		{"2024.5.24+c2b0ab2", [3]int{2024, 5, 24}, true},
+		{"2023.10.1", [3]int{2023, 10, 1}, true},
+		{"1.1.0-92-gd2c2ab5", [3]int{1, 1, 0}, true},
+		{"0.18", [3]int{0, 18, 0}, true},
+		{"1.x", [3]int{1, 0, 0}, true},
+		{"", [3]int{}, false},
+		{"unknown", [3]int{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseVersion(tt.version)
+		assert.Equal(t, tt.ok, ok, tt.version)
+		assert.Equal(t, tt.want, got, tt.version)
+	}
+}
+
+func TestInstanceInfo_Supports(t *testing.T) {
+	searx := &InstanceInfo{Version: "0.18.0", Categories: []string{"general", "images"}}
+	assert.Equal(t, []string{"day", "month", "year"}, searx.TimeRanges())
+	assert.NoError(t, searx.Supports(SearchRequest{TimeRange: "month", Category: "images"}))
+
+	err := searx.Supports(SearchRequest{TimeRange: "week"})
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	assert.EqualError(t, err, `time_range "week" is not supported by this searxng instance (version 0.18.0; supported: day, month, year)`)
+
+	err = searx.Supports(SearchRequest{Category: "science"})
+	assert.EqualError(t, err, `category "science" is not supported by this searxng instance (available categories: general, images)`)
+
+	searxng := &InstanceInfo{Version: "2024.5.24+c2b0ab2"}
+	assert.Equal(t, []string{"day", "week", "month", "year"}, searxng.TimeRanges())
+	assert.NoError(t, searxng.Supports(SearchRequest{TimeRange: "week", Category: "anything"}), "categories aren't published")
+
+	unknown := &InstanceInfo{}
+	assert.NoError(t, unknown.Supports(SearchRequest{TimeRange: "week"}), "unknown versions are assumed recent")
+	assert.Error(t, unknown.Supports(SearchRequest{TimeRange: "decade"}))
+	assert.NoError(t, unknown.Supports(SearchRequest{Language: "xx"}), "locales aren't published")
+}
+
+func TestInstanceInfo_SupportsLanguage(t *testing.T) {
+	info := &InstanceInfo{Locales: map[string]string{"en": "English", "de": "Deutsch", "pt-BR": "Português (Brasil)"}}
+	for _, lang := range []string{"", "all", "auto", "de", "DE", "de-CH", "pt-br", "en-US"} {
+		assert.True(t, info.SupportsLanguage(lang), lang)
+	}
+	assert.False(t, info.SupportsLanguage("pt"), "only pt-BR is known")
+	assert.False(t, info.SupportsLanguage("fr"))
+
+	err := info.Supports(SearchRequest{Language: "fr"})
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	assert.EqualError(t, err, `language "fr" is not supported by this searxng instance (available languages: de, en, pt-BR)`)
+}
+
+func TestClient_InstanceInfo(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/config").
+		Reply(200).
+		SetHeader("X-SearXNG-Version", "2023.10.1").
+		JSON(map[string]interface{}{
+			"categories": []string{"general", "it"},
+			"engines":    []map[string]interface{}{{"name": "github", "enabled": true}},
+		})
+
+	client, err := NewClient(DefaultConfig())
+	require.NoError(t, err)
+
+	info, err := client.InstanceInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2023.10.1", info.Version, "the header is used when /config has no version")
+	assert.Equal(t, []string{"general", "it"}, info.Categories)
+	require.Len(t, info.Engines, 1)
+
+	engines, err := client.Engines(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, info.Engines, engines, "served from the cached /config")
+	assert.True(t, gock.IsDone())
+}
@@ -10,11 +10,13 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/denysvitali/searxng-mcp/internal/log"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -22,90 +24,108 @@ var (
 	ErrRequestFailed   = errors.New("search request failed")
 	ErrInvalidResponse = errors.New("invalid response from searxng")
 	ErrTimeout         = errors.New("request timeout")
+	ErrInstanceLimited = errors.New("searxng instance is rate limiting this client")
 )
 
-// rateLimiter implements a simple rate limiter using a token bucket
-type rateLimiter struct {
-	mu         sync.Mutex
-	tokens     int
-	maxTokens  int
-	refillRate time.Duration
-	lastRefill time.Time
-}
-
-// newRateLimiter creates a new rate limiter
-// maxTokens: maximum number of tokens
-// refillRate: time to add one token
-func newRateLimiter(maxTokens int, refillRate time.Duration) *rateLimiter {
-	return &rateLimiter{
-		tokens:     maxTokens,
-		maxTokens:  maxTokens,
-		refillRate: refillRate,
-		lastRefill: time.Now(),
-	}
-}
-
-// wait waits until a token is available
-func (rl *rateLimiter) wait(ctx context.Context) error {
-	for {
-		rl.mu.Lock()
-		now := time.Now()
-		elapsed := now.Sub(rl.lastRefill)
-
-		// Refill tokens based on elapsed time
-		tokensToAdd := int(elapsed / rl.refillRate)
-		if tokensToAdd > 0 {
-			rl.tokens = min(rl.maxTokens, rl.tokens+tokensToAdd)
-			rl.lastRefill = now
-		}
-
-		if rl.tokens > 0 {
-			rl.tokens--
-			rl.mu.Unlock()
-			return nil
-		}
-
-		rl.mu.Unlock()
-
-		// Wait for next refill or context cancellation
-		select {
-		case <-time.After(rl.refillRate):
-			continue
-		case <-ctx.Done():
-			return ctx.Err()
-		}
-	}
-}
-
 // Client is a Searxng API client
 type Client struct {
-	config      *Config
-	httpClient  *http.Client
-	rateLimiter *rateLimiter
+	config       *Config
+	httpClient   *http.Client
+	clock        clock
+	rateLimiter  *rateLimiter
+	cache        *searchCache // nil when caching is disabled
+	instanceInfo instanceInfoCache
+	lastActivity atomic.Int64 // unix nanoseconds of the last backend request
+	stats        clientStats
 }
 
 // NewClient creates a new Searxng client
 func NewClient(config *Config) (*Client, error) {
+	return newClient(config, realClock{})
+}
+
+// newClient creates a client whose time-based behavior follows clk
+func newClient(config *Config, clk clock) (*Client, error) {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
 	// Validate base URL
-	if _, err := url.Parse(config.BaseURL); err != nil {
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
 	}
+	if len(config.PinnedSPKI) > 0 && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("%w: SPKI pinning requires an https instance URL", ErrInvalidURL)
+	}
+
+	if err := checkHTTP3Config(config); err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.HTTP3 {
+		transport = newHTTP3Transport(newQUICTransport(transport), transport, config.HTTP3FallbackPeriod, clk)
+	}
+
+	jar, err := newCookieJar(baseURL, config.Cookies)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := config.RateLimit
+	if rateLimit <= 0 {
+		rateLimit = DefaultRateLimit
+	}
 
 	return &Client{
 		config: config,
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: transport,
+			Jar:       jar,
 		},
-		rateLimiter: newRateLimiter(10, 100*time.Millisecond), // 10 req/s limit
+		clock:       clk,
+		rateLimiter: newBurstRateLimiter(clk, rateLimit, config.RateBurst),
+		cache:       newSearchCache(clk, config.CacheTTL, config.CacheSize),
 	}, nil
 }
 
-// Search performs a search query against Searxng
-func (c *Client) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+// WithBaseURL returns a new client for another instance, sharing this
+// client's configuration but not its rate limiter, statistics or the
+// cookies the instance set
+func (c *Client) WithBaseURL(baseURL string) (*Client, error) {
+	config := *c.config
+	config.BaseURL = baseURL
+	return newClient(&config, c.clock)
+}
+
+// BaseURL returns the instance URL the client talks to
+func (c *Client) BaseURL() string {
+	return c.config.BaseURL
+}
+
+// Search performs a search query against Searxng. With a TracerProvider
+// set (see otel.SetTracerProvider), it is traced with its rate limiter and
+// retry waits and HTTP requests.
+func (c *Client) Search(ctx context.Context, req SearchRequest) (resp *SearchResponse, err error) {
+	ctx = withRequestTimeout(ctx, req.Timeout)
+	ctx, span := c.startSearchSpan(ctx, req)
+	defer func() {
+		traceSearchResult(span, resp)
+		endSpan(span, err)
+	}()
+	return c.search(ctx, req)
+}
+
+// search is Search, within its span
+func (c *Client) search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	if len(req.EngineArgs) > 0 {
+		return c.searchWithEngineArgs(ctx, req, c.Search)
+	}
+
 	// Apply defaults
 	if req.Limit <= 0 {
 		req.Limit = 5
@@ -117,46 +137,130 @@ func (c *Client) Search(ctx context.Context, req SearchRequest) (*SearchResponse
 		req.Page = 1
 	}
 
+	c.stats.searches.Add(1)
+
+	// Build API request URL
+	apiURL, err := c.buildSearchURL(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build search URL: %w", err)
+	}
+
+	return c.runSearch(ctx, req, http.MethodGet+" "+apiURL, func(ctx context.Context) (*SearchResponse, error) {
+		return c.doSearchRequest(ctx, apiURL)
+	})
+}
+
+// runSearch performs a search with send, serving it from the cache under
+// cacheKey when possible, after waiting for the rate limiter, and retrying
+// failed requests. When req.Deadline passes first, the results decoded so
+// far are returned with SearchResponse.Partial set; partial responses
+// aren't cached.
+func (c *Client) runSearch(ctx context.Context, req SearchRequest, cacheKey string, send func(ctx context.Context) (*SearchResponse, error)) (*SearchResponse, error) {
+	if resp, ok := c.cached(ctx, req, cacheKey); ok {
+		return c.postProcess(req, resp), nil
+	}
+
+	if req.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withSearchDeadline(ctx, c.clock, req.Deadline)
+		defer cancel()
+	}
+
 	// Rate limiting
-	if err := c.rateLimiter.wait(ctx); err != nil {
+	if err := c.waitRateLimit(ctx); err != nil {
+		if searchDeadlinePassed(ctx) {
+			return c.partialResponse(req), nil
+		}
+		c.stats.failures.Add(1)
 		return nil, fmt.Errorf("%w: %w", ErrTimeout, err)
 	}
 
+	// The query is left out of the logs, it may be sensitive
 	log.WithFields(logrus.Fields{
-		"query": req.Query,
 		"limit": req.Limit,
 		"page":  req.Page,
 	}).Debug("performing search")
 
-	// Build API request URL
-	apiURL, err := c.buildSearchURL(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build search URL: %w", err)
-	}
-
 	// Perform request with retries
 	var lastErr error
 	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
 		if attempt > 0 {
-			log.WithField("attempt", attempt).Debug("retrying search request")
-			time.Sleep(time.Duration(attempt) * time.Second)
+			if err := c.waitRetry(ctx, attempt, lastErr); err != nil {
+				if searchDeadlinePassed(ctx) {
+					return c.partialResponse(req), nil
+				}
+				c.stats.failures.Add(1)
+				return nil, fmt.Errorf("%w: %w (not retried: %w)", ErrRequestFailed, lastErr, err)
+			}
 		}
 
 		var resp *SearchResponse
-		resp, lastErr = c.doSearchRequest(ctx, apiURL)
+		resp, lastErr = send(ctx)
 		if lastErr == nil {
-			return resp, nil
+			if !resp.Partial {
+				c.store(cacheKey, resp)
+			}
+			c.stats.recordEngines(resp)
+			return c.postProcess(req, resp), nil
+		}
+		if searchDeadlinePassed(ctx) {
+			return c.partialResponse(req), nil
 		}
 
-		// Don't retry context errors or 4xx errors
-		if errors.Is(lastErr, context.Canceled) || errors.Is(lastErr, context.DeadlineExceeded) {
+		// Don't retry context errors, pin mismatches or 4xx errors
+		if permanent(lastErr) {
+			c.stats.failures.Add(1)
 			return nil, lastErr
 		}
+		if !retryable(lastErr) {
+			break
+		}
 	}
 
+	c.stats.failures.Add(1)
 	return nil, fmt.Errorf("%w: %w", ErrRequestFailed, lastErr)
 }
 
+// cached returns the cached response for key unless caching is disabled or
+// bypassed by req.NoCache
+func (c *Client) cached(ctx context.Context, req SearchRequest, key string) (*SearchResponse, bool) {
+	if c.cache == nil || req.NoCache {
+		return nil, false
+	}
+	resp, ok := c.cache.get(key)
+	if ok {
+		log.Debug("search served from cache")
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("searxng.cache_hit", true))
+	}
+	return resp, ok
+}
+
+// store caches resp under key. NoCache requests still refresh the cache so
+// later requests see the newest results.
+func (c *Client) store(key string, resp *SearchResponse) {
+	if c.cache != nil {
+		c.cache.put(key, resp)
+	}
+}
+
+// postProcess applies the result transformations requested by req, then
+// the ranking. resp may be shared with the cache, so it is copied rather
+// than modified.
+func (c *Client) postProcess(req SearchRequest, resp *SearchResponse) *SearchResponse {
+	if req.Dedupe {
+		resp = dedupeResults(resp)
+	}
+	return c.ranker(req).rank(resp)
+}
+
+// engines returns the engines of req, falling back to the configured defaults
+func (c *Client) engines(req SearchRequest) []string {
+	if len(req.Engines) > 0 {
+		return req.Engines
+	}
+	return c.config.Engines
+}
+
 // buildSearchURL builds the search API URL
 func (c *Client) buildSearchURL(req SearchRequest) (string, error) {
 	baseURL, err := url.Parse(c.config.BaseURL)
@@ -184,8 +288,11 @@ func (c *Client) buildSearchURL(req SearchRequest) (string, error) {
 	if req.TimeRange != "" {
 		queryParams.Set("time_range", req.TimeRange)
 	}
+	if safeSearch := req.SafeSearch.param(); safeSearch != "" {
+		queryParams.Set("safesearch", safeSearch)
+	}
 
-	for _, engine := range req.Engines {
+	for _, engine := range c.engines(req) {
 		queryParams.Add("engines", engine)
 	}
 
@@ -207,30 +314,40 @@ func (c *Client) doSearchRequest(ctx context.Context, searchURL string) (*Search
 	httpReq.Header.Set("Accept", "application/json")
 
 	// Execute request
-	httpResp, err := c.httpClient.Do(httpReq)
+	c.touch()
+	c.stats.requests.Add(1)
+	httpResp, err := c.do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
-	// Check status code
-	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, fmt.Errorf("HTTP %d: %s", httpResp.StatusCode, string(body))
-	}
-
-	// Parse response
-	var apiResp APIResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	resp, err := parseSearchResponse(httpResp, c.clock.Now())
+	if err != nil {
+		return nil, err
 	}
+	c.proxifyImages(resp)
+	return resp, nil
+}
 
-	resp := toSearchResponse(apiResp)
-	return &resp, nil
+// SearchJSON performs a search using POST with JSON body, traced like
+// Search
+func (c *Client) SearchJSON(ctx context.Context, req SearchRequest) (resp *SearchResponse, err error) {
+	ctx = withRequestTimeout(ctx, req.Timeout)
+	ctx, span := c.startSearchSpan(ctx, req)
+	defer func() {
+		traceSearchResult(span, resp)
+		endSpan(span, err)
+	}()
+	return c.searchJSON(ctx, req)
 }
 
-// SearchJSON performs a search using POST with JSON body
-func (c *Client) SearchJSON(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+// searchJSON is SearchJSON, within its span
+func (c *Client) searchJSON(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	if len(req.EngineArgs) > 0 {
+		return c.searchWithEngineArgs(ctx, req, c.SearchJSON)
+	}
+
 	// Apply defaults
 	if req.Limit <= 0 {
 		req.Limit = 5
@@ -242,16 +359,7 @@ func (c *Client) SearchJSON(ctx context.Context, req SearchRequest) (*SearchResp
 		req.Page = 1
 	}
 
-	// Rate limiting
-	if err := c.rateLimiter.wait(ctx); err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrTimeout, err)
-	}
-
-	log.WithFields(logrus.Fields{
-		"query": req.Query,
-		"limit": req.Limit,
-		"page":  req.Page,
-	}).Debug("performing JSON search")
+	c.stats.searches.Add(1)
 
 	// Build API request URL
 	baseURL, err := url.Parse(c.config.BaseURL)
@@ -264,13 +372,14 @@ func (c *Client) SearchJSON(ctx context.Context, req SearchRequest) (*SearchResp
 
 	// Build JSON request body
 	apiReq := APIRequest{
-		Query:     req.Query,
-		Category:  req.Category,
-		Engines:   req.Engines,
-		Language:  req.Language,
-		Pageno:    req.Page,
-		TimeRange: req.TimeRange,
-		Format:    "json",
+		Query:      req.Query,
+		Category:   req.Category,
+		Engines:    c.engines(req),
+		Language:   req.Language,
+		Pageno:     req.Page,
+		TimeRange:  req.TimeRange,
+		SafeSearch: req.SafeSearch.param(),
+		Format:     "json",
 	}
 
 	body, err := json.Marshal(apiReq)
@@ -278,27 +387,9 @@ func (c *Client) SearchJSON(ctx context.Context, req SearchRequest) (*SearchResp
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Perform request with retries
-	var lastErr error
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			log.WithField("attempt", attempt).Debug("retrying JSON search request")
-			time.Sleep(time.Duration(attempt) * time.Second)
-		}
-
-		var resp *SearchResponse
-		resp, lastErr = c.doSearchJSONRequest(ctx, apiURL, body)
-		if lastErr == nil {
-			return resp, nil
-		}
-
-		// Don't retry context errors or 4xx errors
-		if errors.Is(lastErr, context.Canceled) || errors.Is(lastErr, context.DeadlineExceeded) {
-			return nil, lastErr
-		}
-	}
-
-	return nil, fmt.Errorf("%w: %w", ErrRequestFailed, lastErr)
+	return c.runSearch(ctx, req, http.MethodPost+" "+apiURL+" "+string(body), func(ctx context.Context) (*SearchResponse, error) {
+		return c.doSearchJSONRequest(ctx, apiURL, body)
+	})
 }
 
 // doSearchJSONRequest performs the actual HTTP POST request
@@ -317,24 +408,62 @@ func (c *Client) doSearchJSONRequest(ctx context.Context, apiURL string, body []
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Execute request
-	httpResp, err := c.httpClient.Do(httpReq)
+	c.touch()
+	c.stats.requests.Add(1)
+	httpResp, err := c.do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
+	resp, err := parseSearchResponse(httpResp, c.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	c.proxifyImages(resp)
+	return resp, nil
+}
+
+// parseSearchResponse checks the status of a search response and decodes
+// its JSON body (see normalizeBody and decodeAPIResponse for the tolerated
+// encodings). Bot-limiter pages and HTML served in place of JSON are
+// reported as ErrInstanceLimited and ErrJSONFormatDisabled respectively.
+// now is the time Retry-After dates are relative to.
+func parseSearchResponse(httpResp *http.Response, now time.Time) (*SearchResponse, error) {
+	body, err := io.ReadAll(httpResp.Body)
+	// A search deadline cutting the body short leaves partial results
+	partial := err != nil && httpResp.Request != nil && searchDeadlinePassed(httpResp.Request.Context())
+	if err != nil && !partial {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	body = normalizeBody(body)
+
+	if isLimiterPage(httpResp.StatusCode, body) {
+		return nil, withRetryAfter(fmt.Errorf("%w: got its bot-limiter/CAPTCHA page (HTTP %d) instead of results; use a private instance or allowlist this client in the instance's limiter settings", ErrInstanceLimited, httpResp.StatusCode), httpResp, now)
+	}
+
 	// Check status code
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
-		body, _ := io.ReadAll(httpResp.Body)
-		return nil, fmt.Errorf("HTTP %d: %s", httpResp.StatusCode, string(body))
+		return nil, withRetryAfter(&HTTPError{StatusCode: httpResp.StatusCode, Body: string(body)}, httpResp, now)
 	}
 
-	// Parse response
-	var apiResp APIResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	if looksLikeHTML(body) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, ErrJSONFormatDisabled)
 	}
 
-	resp := toSearchResponse(apiResp)
+	var resp SearchResponse
+	if partial {
+		resp = toSearchResponse(decodePartialAPIResponse(body))
+		resp.Partial = true
+	} else {
+		apiResp, err := decodeAPIResponse(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+		}
+		resp = toSearchResponse(apiResp)
+	}
+	if len(resp.DecodeWarnings) > 0 {
+		log.WithField("warnings", resp.DecodeWarnings).Debug("dropped parts of the response with unexpected types")
+	}
 	return &resp, nil
 }
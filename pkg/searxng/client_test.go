@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -384,18 +385,27 @@ func TestDefaultConfig(t *testing.T) {
 }
 
 func TestRateLimiter(t *testing.T) {
-	rl := newRateLimiter(5, 10*time.Millisecond)
+	clk := newFakeClock()
+	rl := newRateLimiter(clk, 5, 10*time.Millisecond)
 	ctx := context.Background()
 
-	start := time.Now()
-	for i := 0; i < 7; i++ {
-		err := rl.wait(ctx)
-		assert.NoError(t, err)
-	}
-	elapsed := time.Since(start)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 7; i++ {
+			err := rl.wait(ctx)
+			assert.NoError(t, err)
+		}
+	}()
 
-	// Should have waited for at least one refill (10ms)
-	assert.GreaterOrEqual(t, elapsed, 10*time.Millisecond)
+	// The burst of 5 passes, the 6th and 7th calls wait for a refill each
+	for range 2 {
+		clk.waitPending(t, 1)
+		clk.Advance(10 * time.Millisecond)
+	}
+	<-done
+	assert.Equal(t, uint64(2), rl.waits.Load())
+	assert.Equal(t, int64(20*time.Millisecond), rl.waitNanos.Load())
 }
 
 func TestClient_SearchJSON(t *testing.T) {
@@ -624,3 +634,317 @@ func TestClient_Search_UnresponsiveEnginesTupleFormat(t *testing.T) {
 	assert.Equal(t, "google", resp.UnresponsiveEngines[2].Name)
 	assert.Equal(t, "access denied", resp.UnresponsiveEngines[2].Error)
 }
+
+func TestClient_Search_AnswerFormats(t *testing.T) {
+	tests := []struct {
+		name        string
+		answers     string
+		wantTexts   []string
+		wantEngines []string
+	}{
+		{
+			name:        "plain strings",
+			answers:     `["42"]`,
+			wantTexts:   []string{"42"},
+			wantEngines: []string{""},
+		},
+		{
+			name:        "answer objects",
+			answers:     `[{"answer": "Paris", "url": "https://en.wikipedia.org/wiki/Paris", "engine": "wikipedia"}]`,
+			wantTexts:   []string{"Paris"},
+			wantEngines: []string{"wikipedia"},
+		},
+		{
+			name:        "empty",
+			answers:     `[]`,
+			wantTexts:   []string{},
+			wantEngines: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"query": "q", "results": [], "answers": ` + tt.answers + `}`))
+			}))
+			defer ts.Close()
+
+			config := DefaultConfig()
+			config.BaseURL = ts.URL
+			client, err := NewClient(config)
+			require.NoError(t, err)
+
+			resp, err := client.Search(context.Background(), SearchRequest{Query: "q"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTexts, resp.Answers)
+
+			engines := make([]string, len(resp.AnswerDetails))
+			for i, a := range resp.AnswerDetails {
+				engines[i] = a.Engine
+			}
+			assert.Equal(t, tt.wantEngines, engines)
+		})
+	}
+}
+
+func TestClient_Stats(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(1).
+		Reply(500)
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(1).
+		Reply(200).
+		JSON(APIResponse{Query: "test"})
+
+	config := DefaultConfig()
+	config.MaxRetries = 1
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "test"})
+	require.NoError(t, err)
+
+	stats := client.Stats()
+	assert.Equal(t, uint64(1), stats.Searches)
+	assert.Equal(t, uint64(2), stats.Requests)
+	assert.Equal(t, uint64(1), stats.Retries)
+	assert.Zero(t, stats.Failures)
+	assert.Positive(t, stats.RateLimitTokens)
+	assert.LessOrEqual(t, stats.RateLimitTokens, 10)
+}
+
+func TestClient_Stats_EngineResults(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(1).
+		Reply(200).
+		JSON(map[string]interface{}{"query": "test", "results": []map[string]interface{}{
+			{"url": "https://a.example", "engines": []string{"google", "bing"}},
+			{"url": "https://b.example", "engine": "ddg"},
+			{"url": "https://c.example", "engines": []string{"bing"}},
+		}})
+
+	config := DefaultConfig()
+	config.CacheTTL = time.Minute
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	for range 2 {
+		_, err = client.Search(context.Background(), SearchRequest{Query: "test"})
+		require.NoError(t, err)
+	}
+
+	stats := client.Stats()
+	assert.Equal(t, map[string]uint64{"google": 1, "bing": 2, "ddg": 1}, stats.EngineResults, "cache hits aren't counted")
+	assert.Equal(t, map[string]uint64{"bing": 1, "ddg": 1}, stats.EngineUniqueResults)
+}
+
+func TestRateLimiter_WaitStats(t *testing.T) {
+	clk := newFakeClock()
+	rl := newRateLimiter(clk, 1, 10*time.Millisecond)
+	ctx := context.Background()
+
+	require.NoError(t, rl.wait(ctx))
+	assert.Zero(t, rl.waits.Load())
+
+	go func() {
+		clk.waitPending(t, 1)
+		clk.Advance(10 * time.Millisecond)
+	}()
+	require.NoError(t, rl.wait(ctx))
+	assert.Equal(t, uint64(1), rl.waits.Load())
+	assert.Positive(t, rl.waitNanos.Load())
+}
+
+func TestClient_Search_DefaultEngines(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("engines", "duckduckgo").
+		Reply(200).
+		JSON(APIResponse{Query: "test"})
+
+	config := DefaultConfig()
+	config.Engines = []string{"duckduckgo"}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "test"})
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestNewClient_RateLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.RateLimit = 2
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.rateLimiter.maxTokens)
+	assert.Equal(t, 500*time.Millisecond, client.rateLimiter.refillRate)
+
+	client, err = NewClient(DefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRateLimit, client.rateLimiter.maxTokens)
+}
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`"3:05"`, "3:05"},
+		{`" 12:34 "`, "12:34"},
+		{`185`, "3:05"},
+		{`3723.5`, "1:02:03"},
+		{`0`, ""},
+		{`null`, ""},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseLength(json.RawMessage(tt.raw)), tt.raw)
+	}
+}
+
+func TestClient_Search_DeadlinePartial(t *testing.T) {
+	defer gock.OffAll()
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"test","results":[{"url":"https://a.example","title":"A"},{"url":"https://b.ex`))
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	resp, err := client.Search(context.Background(), SearchRequest{Query: "test", Deadline: 100 * time.Millisecond})
+	require.NoError(t, err)
+	assert.True(t, resp.Partial)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "A", resp.Results[0].Title)
+
+	_, _ = client.Search(context.Background(), SearchRequest{Query: "test", Deadline: 50 * time.Millisecond})
+	assert.EqualValues(t, 2, requests.Load(), "partial responses aren't cached")
+}
+
+func TestClient_Search_DeadlineBeforeResponse(t *testing.T) {
+	defer gock.OffAll()
+
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	resp, err := client.Search(context.Background(), SearchRequest{Query: "test", Deadline: 50 * time.Millisecond})
+	require.NoError(t, err)
+	assert.True(t, resp.Partial)
+	assert.Empty(t, resp.Results)
+	assert.Equal(t, "test", resp.Query)
+
+	// Without a deadline of its own, the caller's cancellation is an error
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Search(ctx, SearchRequest{Query: "test", Deadline: time.Minute})
+	assert.Error(t, err)
+}
+
+func TestClient_SearchJSON_Deadline(t *testing.T) {
+	defer gock.OffAll()
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	clk := newFakeClock()
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	client, err := newClient(config, clk)
+	require.NoError(t, err)
+
+	done := make(chan *SearchResponse, 1)
+	go func() {
+		resp, err := client.SearchJSON(context.Background(), SearchRequest{Query: "test", Deadline: time.Minute})
+		assert.NoError(t, err)
+		done <- resp
+	}()
+	clk.waitPending(t, 1)
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, 5*time.Second, time.Millisecond)
+	clk.Advance(time.Minute - time.Second)
+	select {
+	case <-done:
+		t.Fatal("returned before the deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clk.Advance(time.Second)
+	resp := <-done
+	require.NotNil(t, resp)
+	assert.True(t, resp.Partial)
+	assert.Empty(t, resp.Results)
+	assert.Equal(t, "test", resp.Query)
+	assert.Zero(t, clk.pending(), "the deadline timer is released")
+
+	go func() {
+		resp, _ := client.SearchJSON(context.Background(), SearchRequest{Query: "test", Deadline: time.Minute})
+		done <- resp
+	}()
+	require.Eventually(t, func() bool { return requests.Load() == 2 }, 5*time.Second, time.Millisecond,
+		"partial responses aren't cached")
+	clk.Advance(time.Minute)
+	<-done
+}
+
+func TestClient_Search_RequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(100 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"test","results":[{"url":"https://a.example","title":"A"}]}`))
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	config.Timeout = 20 * time.Millisecond
+	config.MaxRetries = 0
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "test"})
+	require.Error(t, err, "the client timeout is shorter than the instance")
+
+	resp, err := client.Search(context.Background(), SearchRequest{Query: "test", Timeout: 5 * time.Second})
+	require.NoError(t, err, "the request timeout replaces the client's")
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, 20*time.Millisecond, client.httpClient.Timeout, "the client is left unchanged")
+}
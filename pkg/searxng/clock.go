@@ -0,0 +1,46 @@
+package searxng
+
+import (
+	"context"
+	"time"
+)
+
+// clock is the source of time of the rate limiters, retries, caches and
+// ranking of a client. Tests replace the real clock with a fake one they
+// advance, so time-based behavior is tested without sleeping.
+type clock interface {
+	Now() time.Time
+	// After returns a channel receiving the time once d has passed, and a
+	// func releasing the timer when the caller stops waiting earlier
+	After(d time.Duration) (<-chan time.Time, func())
+}
+
+// realClock is the clock of the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) (<-chan time.Time, func()) {
+	timer := time.NewTimer(d)
+	return timer.C, func() { timer.Stop() }
+}
+
+// sleep waits for d on clk, returning the context's error when it ends
+// first
+func sleep(ctx context.Context, clk clock, d time.Duration) error {
+	c, stop := clk.After(d)
+	defer stop()
+	select {
+	case <-c:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exceedsDeadline reports whether waiting for d on clk would end after the
+// context's deadline
+func exceedsDeadline(ctx context.Context, clk clock, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && deadline.Sub(clk.Now()) < d
+}
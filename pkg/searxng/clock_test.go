@@ -0,0 +1,115 @@
+package searxng
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a clock tests advance by hand: its timers fire when Advance
+// moves the time past them
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t.c, func() {}
+	}
+	c.timers = append(c.timers, t)
+	return t.c, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.timers = slices.DeleteFunc(c.timers, func(other *fakeTimer) bool { return other == t })
+	}
+}
+
+// Advance moves the time forward by d, firing the timers due by then
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool {
+		if t.at.After(c.now) {
+			return false
+		}
+		t.c <- c.now
+		return true
+	})
+}
+
+// pending returns the number of timers that haven't fired nor been stopped
+func (c *fakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// waitPending waits until n timers are pending, i.e. the goroutines under
+// test are sleeping on the clock
+func (c *fakeClock) waitPending(t testing.TB, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.pending() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d timers pending, want %d", c.pending(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	clk := newFakeClock()
+	done := make(chan error, 1)
+	go func() { done <- sleep(context.Background(), clk, time.Minute) }()
+
+	clk.waitPending(t, 1)
+	clk.Advance(time.Minute - time.Second)
+	assert.Equal(t, 1, clk.pending(), "not due yet")
+	clk.Advance(time.Second)
+	assert.NoError(t, <-done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- sleep(ctx, clk, time.Minute) }()
+	clk.waitPending(t, 1)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Zero(t, clk.pending(), "the timer is released")
+}
+
+func TestExceedsDeadline(t *testing.T) {
+	clk := newFakeClock()
+	assert.False(t, exceedsDeadline(context.Background(), clk, time.Hour), "no deadline")
+
+	ctx, cancel := context.WithDeadline(context.Background(), clk.Now().Add(time.Minute))
+	defer cancel()
+	assert.False(t, exceedsDeadline(ctx, clk, 30*time.Second))
+	assert.True(t, exceedsDeadline(ctx, clk, 2*time.Minute))
+	clk.Advance(45 * time.Second)
+	assert.True(t, exceedsDeadline(ctx, clk, 30*time.Second))
+}
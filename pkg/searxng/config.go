@@ -1,10 +1,20 @@
 package searxng
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
 // DefaultInstanceURL is the default Searxng instance URL
 const DefaultInstanceURL = "https://searxng.example.com"
 
+// DefaultLimiterBackoff is the default per-attempt retry delay after a
+// bot-limiter response
+const DefaultLimiterBackoff = 5 * time.Second
+
+// DefaultRateLimit is the default maximum number of searches per second
+const DefaultRateLimit = 10
+
 // Config holds the configuration for the Searxng client
 type Config struct {
 	// BaseURL is the base URL of the Searxng instance
@@ -16,8 +26,117 @@ type Config struct {
 	// MaxRetries is the maximum number of retries for failed requests
 	MaxRetries int
 
+	// Retry sets the delays between retries (zero: DefaultRetryPolicy)
+	Retry RetryPolicy
+
 	// UserAgent is the HTTP User-Agent header value
 	UserAgent string
+
+	// MaxIdleConns caps idle keep-alive connections across all hosts (0: net/http default)
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost caps idle keep-alive connections per host (0: net/http default)
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost caps total connections per host, including active ones (0: unlimited)
+	MaxConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept open (0: net/http default)
+	IdleConnTimeout time.Duration
+
+	// DisableHTTP2 forces HTTP/1.1 even when the instance supports HTTP/2
+	DisableHTTP2 bool
+
+	// Transport, when set, carries the requests to the instance instead of
+	// a transport of the client's own, so it can share its connection pool
+	// with other clients (see NewPooledTransport). The pool settings above
+	// are then ignored; with DisableHTTP2, a proxy or SPKI pins the client
+	// uses a copy of it.
+	Transport *http.Transport
+
+	// HTTP3 sends requests to https instances over HTTP/3 (QUIC) first,
+	// keeping the connection alive between searches, which lowers latency on
+	// lossy networks for instances behind Caddy or Cloudflare. When HTTP/3
+	// fails, e.g. because UDP is blocked, the request is sent again over
+	// HTTP/2 or HTTP/1.1 and HTTP/3 is skipped for HTTP3FallbackPeriod. It
+	// can't be combined with ProxyURL or PinnedSPKI, which QUIC connections
+	// would bypass.
+	HTTP3 bool
+
+	// HTTP3FallbackPeriod is how long requests skip HTTP/3 after it failed
+	// (0: DefaultHTTP3FallbackPeriod)
+	HTTP3FallbackPeriod time.Duration
+
+	// PinnedSPKI lists base64 SHA-256 digests of SubjectPublicKeyInfo
+	// ("sha256/..." prefix optional). When set, TLS connections to the
+	// instance fail unless a certificate of the verified chain matches one
+	// of them. Pinning adds to the regular verification, so instances with
+	// self-signed or private CA certificates can only be pinned once their
+	// CA is trusted by the system.
+	PinnedSPKI []string
+
+	// ProxyURL routes requests to the instance through an HTTP(S) or SOCKS5
+	// proxy, e.g. socks5://127.0.0.1:9050 for Tor (see ParseProxyURL). The
+	// empty value uses the proxy environment variables.
+	ProxyURL string
+
+	// RateLimit is the maximum number of searches per second sent to the
+	// instance (0: 10 per second)
+	RateLimit int
+
+	// RateBurst is the number of searches that may be sent at once before
+	// RateLimit applies (0: RateLimit)
+	RateBurst int
+
+	// Cookies are sent with every request to the instance by name, e.g.
+	// the preferences its /preferences page saves in cookies (safesearch,
+	// locale, enabled_engines...) for instances that need them. Cookies
+	// the instance sets are kept along with them for the life of the
+	// client.
+	Cookies map[string]string
+
+	// Engines are the default engines for requests that don't set any
+	Engines []string
+
+	// LimiterBackoff is the per-attempt retry delay after the instance
+	// served its bot-limiter page (attempt n waits n*LimiterBackoff;
+	// 0: DefaultLimiterBackoff)
+	LimiterBackoff time.Duration
+
+	// CacheTTL is how long identical searches are served from an in-memory
+	// cache instead of the instance (0: caching disabled). Cached
+	// responses share their slices, so callers must not modify them.
+	CacheTTL time.Duration
+
+	// CacheSize is the maximum number of cached responses; the least
+	// recently used one is evicted first (0: DefaultCacheSize)
+	CacheSize int
+
+	// SkipProbe makes NewClientWithProbe skip its connectivity checks
+	SkipProbe bool
+
+	// RankBy is the default ranking strategy applied to results
+	// (RankInstance: the instance's order)
+	RankBy RankStrategy
+
+	// RankWeights weighs the signals of RankWeighted (zero:
+	// DefaultRankWeights)
+	RankWeights RankWeights
+
+	// RecencyHalfLife is the age at which the recency signal of a result
+	// is halved (0: DefaultRecencyHalfLife)
+	RecencyHalfLife time.Duration
+
+	// TrustedDomains and DistrustedDomains raise and lower the rank of
+	// results on these domains and their subdomains under RankWeighted
+	TrustedDomains    []string
+	DistrustedDomains []string
+
+	// ImageProxySecret is the server.secret_key of an instance with
+	// image_proxy enabled. When set, image and thumbnail URLs of results are
+	// rewritten to go through the instance's /image_proxy endpoint, since
+	// the JSON API returns the original URLs.
+	ImageProxySecret string
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -27,5 +146,7 @@ func DefaultConfig() *Config {
 		Timeout:    30 * time.Second,
 		MaxRetries: 3,
 		UserAgent:  "searxng-mcp/1.0",
+
+		LimiterBackoff: DefaultLimiterBackoff,
 	}
 }
@@ -0,0 +1,61 @@
+package searxng
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidCookie is returned for cookies that can't be sent in a
+// Cookie header
+var ErrInvalidCookie = errors.New("invalid cookie")
+
+// newCookieJar returns the cookie jar of a client of the instance at
+// baseURL, holding the configured cookies. The jar also keeps the cookies
+// the instance sets, e.g. after a redirect through /preferences, for the
+// life of the client.
+func newCookieJar(baseURL *url.URL, cookies map[string]string) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return jar, nil
+	}
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]*http.Cookie, 0, len(cookies))
+	for _, name := range names {
+		cookie := &http.Cookie{Name: name, Value: cookies[name], Path: "/"}
+		if err := cookie.Valid(); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidCookie, name, err)
+		}
+		list = append(list, cookie)
+	}
+	jar.SetCookies(baseURL, list)
+	return jar, nil
+}
+
+// ParseCookies parses "name=value" pairs, e.g. "safesearch=2", into
+// Config.Cookies
+func ParseCookies(pairs []string) (map[string]string, error) {
+	cookies := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w %q (must be name=value)", ErrInvalidCookie, pair)
+		}
+		if err := (&http.Cookie{Name: name, Value: value}).Valid(); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidCookie, name, err)
+		}
+		cookies[name] = value
+	}
+	return cookies, nil
+}
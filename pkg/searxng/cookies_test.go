@@ -0,0 +1,62 @@
+package searxng
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Cookies(t *testing.T) {
+	var received []map[string]string
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies := map[string]string{}
+		for _, cookie := range r.Cookies() {
+			cookies[cookie.Name] = cookie.Value
+		}
+		received = append(received, cookies)
+		http.SetCookie(w, &http.Cookie{Name: "theme", Value: "simple", Path: "/"})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query": "q", "results": []}`))
+	}))
+	defer instance.Close()
+
+	client, err := NewClient(&Config{
+		BaseURL: instance.URL,
+		Cookies: map[string]string{"safesearch": "2", "locale": "de"},
+	})
+	require.NoError(t, err)
+	for _, query := range []string{"a", "b"} {
+		_, err = client.Search(context.Background(), SearchRequest{Query: query})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, received, 2)
+	assert.Equal(t, map[string]string{"safesearch": "2", "locale": "de"}, received[0])
+	assert.Equal(t, map[string]string{"safesearch": "2", "locale": "de", "theme": "simple"}, received[1], "cookies set by the instance are kept")
+
+	other, err := client.WithBaseURL(instance.URL)
+	require.NoError(t, err)
+	_, err = other.Search(context.Background(), SearchRequest{Query: "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"safesearch": "2", "locale": "de"}, received[2], "clients don't share the cookies set by the instance")
+}
+
+func TestNewClient_InvalidCookie(t *testing.T) {
+	_, err := NewClient(&Config{BaseURL: "https://searxng.example.com", Cookies: map[string]string{"bad name": "x"}})
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+}
+
+func TestParseCookies(t *testing.T) {
+	cookies, err := ParseCookies([]string{"safesearch=2", " enabled_engines=duckduckgo__general ", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"safesearch": "2", "enabled_engines": "duckduckgo__general", "empty": ""}, cookies)
+
+	for _, pair := range []string{"safesearch", "=2", "bad name=1", "locale=a;b"} {
+		_, err := ParseCookies([]string{pair})
+		assert.ErrorIs(t, err, ErrInvalidCookie, pair)
+	}
+}
@@ -0,0 +1,74 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// errSearchDeadline is the cause of the context of a search whose
+// SearchRequest.Deadline passed
+var errSearchDeadline = errors.New("search deadline passed")
+
+// partialKey marks the contexts of searches with a deadline, whose body
+// reads cut short by it are decoded as partial results
+type partialKey struct{}
+
+// withSearchDeadline bounds ctx by deadline on clk, marking it as accepting
+// partial results. The deadline is also set on ctx, so the waits of the
+// search fail fast when they would exceed it.
+func withSearchDeadline(ctx context.Context, clk clock, deadline time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancelDeadline := context.WithDeadlineCause(ctx, clk.Now().Add(deadline), errSearchDeadline)
+	ctx, cancel := context.WithCancelCause(ctx)
+	due, stop := clk.After(deadline)
+	go func() {
+		defer stop()
+		select {
+		case <-due:
+			cancel(errSearchDeadline)
+		case <-ctx.Done():
+		}
+	}()
+	return context.WithValue(ctx, partialKey{}, true), func() {
+		cancel(nil)
+		cancelDeadline()
+	}
+}
+
+// searchDeadlinePassed reports whether ctx is the context of a search whose
+// own deadline passed, rather than one canceled by its caller
+func searchDeadlinePassed(ctx context.Context) bool {
+	return ctx.Value(partialKey{}) != nil && errors.Is(context.Cause(ctx), errSearchDeadline)
+}
+
+// requestTimeoutKey holds the SearchRequest.Timeout of a search in its
+// context
+type requestTimeoutKey struct{}
+
+// withRequestTimeout makes the HTTP requests of ctx use timeout instead of
+// Config.Timeout; timeouts <= 0 leave ctx unchanged
+func withRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// httpClientFor returns the HTTP client of requests made with ctx: the
+// client's, or a copy with the timeout of withRequestTimeout
+func (c *Client) httpClientFor(ctx context.Context) *http.Client {
+	timeout, ok := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	if !ok {
+		return c.httpClient
+	}
+	client := *c.httpClient
+	client.Timeout = timeout
+	return &client
+}
+
+// partialResponse returns the empty partial response of a search whose
+// deadline passed before the instance answered
+func (c *Client) partialResponse(req SearchRequest) *SearchResponse {
+	return c.postProcess(req, &SearchResponse{Query: req.Query, Partial: true})
+}
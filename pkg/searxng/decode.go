@@ -0,0 +1,203 @@
+package searxng
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// maxDecompressedSize bounds bodies gunzipped by normalizeBody
+const maxDecompressedSize = 32 << 20
+
+// bodyPrefixLen is the number of body bytes quoted in decoding errors
+const bodyPrefixLen = 64
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	utf8BOM   = []byte{0xef, 0xbb, 0xbf}
+)
+
+// normalizeBody undoes the encodings some proxies in front of instances add
+// to responses: gzip without a Content-Encoding header (which net/http
+// leaves compressed) and a leading UTF-8 byte order mark. Bodies that
+// aren't gzip data despite the magic bytes are returned unchanged.
+func normalizeBody(body []byte) []byte {
+	if bytes.HasPrefix(body, gzipMagic) {
+		if zr, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if plain, err := io.ReadAll(io.LimitReader(zr, maxDecompressedSize)); err == nil {
+				body = plain
+			}
+		}
+	}
+	return bytes.TrimPrefix(body, utf8BOM)
+}
+
+// decodeAPIResponse decodes a search response body. Besides a single JSON
+// object, it accepts NDJSON: a stream of response objects, merged in order,
+// or of bare results. Errors quote the start of the body.
+func decodeAPIResponse(body []byte) (APIResponse, error) {
+	var (
+		merged APIResponse
+		count  int
+	)
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF && count > 0 {
+			return merged, nil
+		}
+		if err != nil {
+			return APIResponse{}, fmt.Errorf("%w (%s)", err, describeBody(body))
+		}
+
+		part, err := decodeAPIPart(raw)
+		if err != nil {
+			return APIResponse{}, fmt.Errorf("%w (%s)", err, describeBody(body))
+		}
+		mergeAPIResponse(&merged, part, count == 0)
+		count++
+	}
+}
+
+// decodeAPIPart decodes one object of a search response: a response or,
+// in NDJSON streams, a bare result
+func decodeAPIPart(raw json.RawMessage) (APIResponse, error) {
+	var part APIResponse
+	if err := json.Unmarshal(raw, &part); err != nil {
+		return APIResponse{}, err
+	}
+	if part.Query == "" && len(part.Results) == 0 {
+		// NDJSON of bare results, one per line
+		var result APIResult
+		if err := json.Unmarshal(raw, &result); err == nil && result.URL != "" {
+			part.Warnings = append(part.Warnings, result.takeWarnings("result.")...)
+			part.Results = []APIResult{result}
+		}
+	}
+	return part, nil
+}
+
+// decodePartialAPIResponse decodes what a search response body cut short
+// holds: the complete objects of an NDJSON stream, then the complete
+// fields and results of the interrupted object. It never fails; a body
+// with nothing complete decodes to an empty response.
+func decodePartialAPIResponse(body []byte) APIResponse {
+	var (
+		merged APIResponse
+		count  int
+		offset int64
+	)
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			break
+		}
+		offset = dec.InputOffset()
+		if part, err := decodeAPIPart(raw); err == nil {
+			mergeAPIResponse(&merged, part, count == 0)
+			count++
+		}
+	}
+	if part, ok := decodeTruncatedObject(body[offset:]); ok {
+		mergeAPIResponse(&merged, part, count == 0)
+	}
+	return merged
+}
+
+// decodeTruncatedObject decodes the fields of a response object that were
+// read whole, and the complete entries of its results array, streaming
+// through data until it ends
+func decodeTruncatedObject(data []byte) (APIResponse, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return APIResponse{}, false
+	}
+
+	fields := map[string]json.RawMessage{}
+	var results []APIResult
+	var warnings []string
+fields:
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, _ := tok.(string)
+		if key != "results" {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				break
+			}
+			fields[key] = raw
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			break
+		}
+		for dec.More() {
+			var result APIResult
+			if err := dec.Decode(&result); err != nil {
+				break fields
+			}
+			warnings = append(warnings, result.takeWarnings(fmt.Sprintf("results[%d].", len(results)))...)
+			results = append(results, result)
+		}
+		if _, err := dec.Token(); err != nil {
+			break
+		}
+	}
+
+	var resp APIResponse
+	if raw, err := json.Marshal(fields); err == nil {
+		_ = json.Unmarshal(raw, &resp)
+	}
+	resp.Results = results
+	resp.Warnings = append(resp.Warnings, warnings...)
+	return resp, true
+}
+
+// mergeAPIResponse appends the results and lists of part to merged. Query,
+// answers and unresponsive engines come from the first object that has them.
+func mergeAPIResponse(merged *APIResponse, part APIResponse, first bool) {
+	if first {
+		*merged = part
+		return
+	}
+	if merged.Query == "" {
+		merged.Query = part.Query
+	}
+	merged.NumberOfResults = max(merged.NumberOfResults, part.NumberOfResults)
+	merged.Results = append(merged.Results, part.Results...)
+	merged.Corrections = append(merged.Corrections, part.Corrections...)
+	merged.Infoboxes = append(merged.Infoboxes, part.Infoboxes...)
+	merged.Suggestions = append(merged.Suggestions, part.Suggestions...)
+	merged.Warnings = append(merged.Warnings, part.Warnings...)
+	if len(merged.Answers) == 0 {
+		merged.Answers = part.Answers
+	}
+	if len(merged.UnresponsiveEngines) == 0 {
+		merged.UnresponsiveEngines = part.UnresponsiveEngines
+	}
+}
+
+// describeBody quotes the first bytes of body for diagnostics, as text when
+// they are valid UTF-8 and in hex otherwise
+func describeBody(body []byte) string {
+	if len(body) == 0 {
+		return "empty body"
+	}
+	prefix := body[:min(len(body), bodyPrefixLen)]
+	suffix := ""
+	if len(body) > bodyPrefixLen {
+		suffix = "..."
+	}
+	if utf8.Valid(prefix) {
+		return fmt.Sprintf("body starts with %q%s", prefix, suffix)
+	}
+	return fmt.Sprintf("body starts with 0x%x%s", prefix, suffix)
+}
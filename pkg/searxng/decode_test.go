@@ -0,0 +1,113 @@
+package searxng
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestNormalizeBody(t *testing.T) {
+	assert.Equal(t, []byte(`{"query":"q"}`), normalizeBody([]byte(`{"query":"q"}`)))
+	assert.Equal(t, []byte(`{"query":"q"}`), normalizeBody([]byte("\xef\xbb\xbf{\"query\":\"q\"}")))
+	assert.Equal(t, []byte(`{"query":"q"}`), normalizeBody(gzipped(t, "\xef\xbb\xbf{\"query\":\"q\"}")))
+
+	// Not actually gzip: left alone
+	assert.Equal(t, []byte{0x1f, 0x8b, 'x'}, normalizeBody([]byte{0x1f, 0x8b, 'x'}))
+}
+
+func TestDecodeAPIResponse(t *testing.T) {
+	resp, err := decodeAPIResponse([]byte(`{"query":"q","results":[{"url":"https://a"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "q", resp.Query)
+	require.Len(t, resp.Results, 1)
+
+	// NDJSON of response objects
+	resp, err = decodeAPIResponse([]byte(`{"query":"q","number_of_results":5,"results":[{"url":"https://a"}],"suggestions":["s1"]}
+{"query":"q","number_of_results":7,"results":[{"url":"https://b"}],"suggestions":["s2"]}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "q", resp.Query)
+	assert.Equal(t, 7, resp.NumberOfResults)
+	assert.Equal(t, []string{"s1", "s2"}, resp.Suggestions)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "https://b", resp.Results[1].URL)
+
+	// NDJSON of bare results
+	resp, err = decodeAPIResponse([]byte("{\"url\":\"https://a\",\"title\":\"A\"}\n{\"url\":\"https://b\",\"title\":\"B\"}\n"))
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "B", resp.Results[1].Title)
+}
+
+func TestDecodeAPIResponse_Errors(t *testing.T) {
+	_, err := decodeAPIResponse(nil)
+	assert.ErrorContains(t, err, "empty body")
+
+	_, err = decodeAPIResponse([]byte("Service temporarily unavailable"))
+	assert.ErrorContains(t, err, `body starts with "Service temporarily unavailable"`)
+
+	_, err = decodeAPIResponse([]byte(strings.Repeat("x", 100)))
+	assert.ErrorContains(t, err, `body starts with "`+strings.Repeat("x", bodyPrefixLen)+`"...`)
+
+	_, err = decodeAPIResponse([]byte{0xff, 0xfe, 0x00})
+	assert.ErrorContains(t, err, "body starts with 0xfffe00")
+}
+
+func TestClient_Search_GzipWithoutHeader(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		Body(bytes.NewReader(gzipped(t, "\xef\xbb\xbf{\"query\":\"test\",\"results\":[{\"url\":\"https://example.com\",\"title\":\"Example\"}]}")))
+
+	client, err := NewClient(DefaultConfig())
+	require.NoError(t, err)
+
+	resp, err := client.Search(context.Background(), SearchRequest{Query: "test"})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "Example", resp.Results[0].Title)
+}
+
+func TestDecodePartialAPIResponse(t *testing.T) {
+	body := `{"query":"test","number_of_results":3,"results":[{"url":"https://a.example","title":"A"},{"url":"https://b.example","title":"B"},{"url":"https://c.exa`
+	resp := decodePartialAPIResponse([]byte(body))
+	assert.Equal(t, "test", resp.Query)
+	assert.Equal(t, 3, resp.NumberOfResults)
+	require.Len(t, resp.Results, 2, "the cut result is dropped")
+	assert.Equal(t, "https://b.example", resp.Results[1].URL)
+
+	// Fields after the results, then a cut field
+	body = `{"results":[{"url":"https://a.example"}],"suggestions":["x"],"answers":["cut`
+	resp = decodePartialAPIResponse([]byte(body))
+	assert.Len(t, resp.Results, 1)
+	assert.Equal(t, []string{"x"}, resp.Suggestions)
+	assert.Empty(t, resp.Answers)
+
+	// NDJSON: complete lines, then a cut one
+	body = "{\"url\":\"https://a.example\"}\n{\"url\":\"https://b.example\"}\n{\"url\":\"https://c."
+	resp = decodePartialAPIResponse([]byte(body))
+	assert.Len(t, resp.Results, 2)
+
+	for _, body := range []string{"", "{", `{"query":"te`, "not json"} {
+		assert.Empty(t, decodePartialAPIResponse([]byte(body)).Results, body)
+	}
+}
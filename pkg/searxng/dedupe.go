@@ -0,0 +1,95 @@
+package searxng
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// canonicalURL normalizes rawURL for duplicate detection: the scheme is
+// dropped (http and https variants match), the host is lowercased, utm_*
+// tracking parameters, the fragment and trailing slashes are removed and
+// the remaining query parameters are sorted
+func canonicalURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	query := parsed.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+
+	key := strings.ToLower(parsed.Host) + strings.TrimRight(parsed.EscapedPath(), "/")
+	if len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+	return key
+}
+
+// dedupeResults returns a copy of resp where results pointing at the same
+// canonical URL are merged into the first of them: engines and positions
+// are combined and scores added up, as Searxng does for results found by
+// several engines. The https variant of a URL is preferred. Results are
+// then reordered by score, keeping the original order for equal scores.
+func dedupeResults(resp *SearchResponse) *SearchResponse {
+	results := make([]SearchResult, 0, len(resp.Results))
+	index := make(map[string]int, len(resp.Results))
+	merged := false
+	for _, result := range resp.Results {
+		key := canonicalURL(result.URL)
+		i, dup := index[key]
+		if !dup {
+			index[key] = len(results)
+			result.Engines = slices.Clone(resultEngines(result))
+			result.Positions = slices.Clone(result.Positions)
+			results = append(results, result)
+			continue
+		}
+
+		merged = true
+		kept := &results[i]
+		for _, engine := range resultEngines(result) {
+			if !slices.Contains(kept.Engines, engine) {
+				kept.Engines = append(kept.Engines, engine)
+			}
+		}
+		kept.Positions = append(kept.Positions, result.Positions...)
+		kept.Score += result.Score
+		if strings.HasPrefix(kept.URL, "http://") && strings.HasPrefix(result.URL, "https://") {
+			kept.URL = result.URL
+		}
+	}
+	if merged {
+		sortByScore(results)
+	}
+
+	deduped := *resp
+	deduped.Results = results
+	return &deduped
+}
+
+// sortByScore orders results by decreasing score, keeping the original
+// order for equal scores
+func sortByScore(results []SearchResult) {
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+}
+
+// resultEngines returns the engines that found r
+func resultEngines(r SearchResult) []string {
+	if len(r.Engines) == 0 && r.Engine != "" {
+		return []string{r.Engine}
+	}
+	return r.Engines
+}
@@ -0,0 +1,82 @@
+package searxng
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"http://example.com/page", "https://example.com/page"},
+		{"https://example.com/page/", "https://example.com/page"},
+		{"https://Example.COM/page", "https://example.com/page"},
+		{"https://example.com/page?utm_source=x&utm_medium=y", "https://example.com/page"},
+		{"https://example.com/page?b=2&a=1", "https://example.com/page?a=1&b=2"},
+		{"https://example.com/page#section", "https://example.com/page"},
+		{"https://example.com/", "https://example.com"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, canonicalURL(tt.b), canonicalURL(tt.a), tt.a)
+	}
+
+	assert.NotEqual(t, canonicalURL("https://example.com/page?id=1"), canonicalURL("https://example.com/page?id=2"))
+	assert.NotEqual(t, canonicalURL("https://example.com/a"), canonicalURL("https://example.org/a"))
+}
+
+func TestDedupeResults(t *testing.T) {
+	resp := &SearchResponse{Query: "q", Results: []SearchResult{
+		{URL: "http://example.com/a", Title: "A", Score: 1, Engines: []string{"bing"}, Positions: []int{1}},
+		{URL: "https://example.com/b", Title: "B", Score: 1.5, Engine: "google"},
+		{URL: "https://example.com/a/?utm_source=news", Title: "A again", Score: 1, Engines: []string{"google", "bing"}, Positions: []int{3}},
+	}}
+
+	deduped := dedupeResults(resp)
+	require.Len(t, deduped.Results, 2)
+	assert.Len(t, resp.Results, 3, "input must not be modified")
+	assert.Equal(t, []string{"bing"}, resp.Results[0].Engines)
+
+	first := deduped.Results[0]
+	assert.Equal(t, "A", first.Title)
+	assert.Equal(t, "https://example.com/a/?utm_source=news", first.URL, "https variant is preferred")
+	assert.Equal(t, 2.0, first.Score)
+	assert.Equal(t, []string{"bing", "google"}, first.Engines)
+	assert.Equal(t, []int{1, 3}, first.Positions)
+
+	second := deduped.Results[1]
+	assert.Equal(t, "B", second.Title)
+	assert.Equal(t, []string{"google"}, second.Engines)
+}
+
+func TestClient_Search_Dedupe(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		JSON(APIResponse{Query: "q", Results: []APIResult{
+			{URL: "https://example.com/a", Title: "A"},
+			{URL: "http://example.com/a/", Title: "A again"},
+		}})
+
+	config := DefaultConfig()
+	config.CacheTTL = time.Minute
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	resp, err := client.Search(ctx, SearchRequest{Query: "q", Dedupe: true})
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 1)
+
+	// The cache keeps the raw response
+	resp, err = client.Search(ctx, SearchRequest{Query: "q"})
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+}
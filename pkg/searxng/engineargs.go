@@ -0,0 +1,149 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// EngineArgs are per-engine search settings. Searxng only takes request-wide
+// parameters, so engines with arguments are queried in a search of their
+// own whose results are merged into the response.
+type EngineArgs struct {
+	// Language selects the language edition of the engine, e.g. "de" for
+	// de.wikipedia.org
+	Language string
+}
+
+// searchWithEngineArgs runs one search per engine of req.EngineArgs plus one
+// for the remaining engines of req, concurrently, with search (Search or
+// SearchJSON), and merges the responses, post-processed like those of
+// search. Without explicit engines the remaining search queries the
+// instance's engines for the category (see implicitEngines). Failed
+// sub-searches are reported as unresponsive engines unless every search
+// failed.
+func (c *Client) searchWithEngineArgs(ctx context.Context, req SearchRequest, search searchFunc) (*SearchResponse, error) {
+	engines := c.engines(req)
+	if len(engines) == 0 {
+		var err error
+		if engines, err = c.implicitEngines(ctx, req); err != nil {
+			return nil, fmt.Errorf("engine arguments need the instance's engines: %w", err)
+		}
+	}
+
+	var reqs []SearchRequest
+	remaining := slices.DeleteFunc(slices.Clone(engines), func(engine string) bool {
+		_, ok := req.EngineArgs[engine]
+		return ok
+	})
+	if len(remaining) > 0 {
+		base := req
+		base.Engines = remaining
+		base.EngineArgs = nil
+		reqs = append(reqs, base)
+	}
+	for _, engine := range slices.Sorted(maps.Keys(req.EngineArgs)) {
+		sub := req
+		sub.Engines = []string{engine}
+		sub.EngineArgs = nil
+		if lang := req.EngineArgs[engine].Language; lang != "" {
+			sub.Language = lang
+		}
+		reqs = append(reqs, sub)
+	}
+
+	merged, err := c.fanOut(ctx, req.Query, reqs, search, mergeResponse)
+	if err != nil {
+		return nil, err
+	}
+	sortByScore(merged.Results)
+	return c.postProcess(req, merged), nil
+}
+
+// implicitEngines returns the engines the instance queries for req when it
+// names none: its enabled engines of req.Category (general by default)
+func (c *Client) implicitEngines(ctx context.Context, req SearchRequest) ([]string, error) {
+	info, err := c.InstanceInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	category := req.Category
+	if category == "" {
+		category = "general"
+	}
+	var engines []string
+	for _, engine := range info.Engines {
+		if engine.Enabled && slices.Contains(engine.Categories, category) {
+			engines = append(engines, engine.Name)
+		}
+	}
+	return engines, nil
+}
+
+// searchFunc is Client.Search or Client.SearchJSON
+type searchFunc func(ctx context.Context, req SearchRequest) (*SearchResponse, error)
+
+// fanOut runs reqs concurrently with search and combines the responses
+// with merge. Failed searches are reported as unresponsive engines unless
+// every search failed.
+func (c *Client) fanOut(ctx context.Context, query string, reqs []SearchRequest, search searchFunc, merge func(merged, resp *SearchResponse)) (*SearchResponse, error) {
+	resps := make([]*SearchResponse, len(reqs))
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, sub := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resps[i], errs[i] = search(ctx, sub)
+		}()
+	}
+	wg.Wait()
+
+	merged := &SearchResponse{Query: query}
+	succeeded := false
+	for i, resp := range resps {
+		if errs[i] != nil {
+			for _, engine := range reqs[i].Engines {
+				merged.UnresponsiveEngines = append(merged.UnresponsiveEngines, UnresponsiveEngine{Name: engine, Error: errs[i].Error()})
+			}
+			continue
+		}
+		succeeded = true
+		merge(merged, resp)
+	}
+	if !succeeded {
+		return nil, errors.Join(errs...)
+	}
+	return merged, nil
+}
+
+// mergeResponse appends the results and metadata of resp to merged,
+// skipping results whose URL is already present
+func mergeResponse(merged, resp *SearchResponse) {
+	merged.NumberOfResults += resp.NumberOfResults
+	for _, result := range resp.Results {
+		if !slices.ContainsFunc(merged.Results, func(r SearchResult) bool { return r.URL == result.URL }) {
+			merged.Results = append(merged.Results, result)
+		}
+	}
+	merged.Answers = appendUnique(merged.Answers, resp.Answers...)
+	merged.AnswerDetails = append(merged.AnswerDetails, resp.AnswerDetails...)
+	merged.Corrections = appendUnique(merged.Corrections, resp.Corrections...)
+	merged.Infoboxes = append(merged.Infoboxes, resp.Infoboxes...)
+	merged.Suggestions = appendUnique(merged.Suggestions, resp.Suggestions...)
+	merged.UnresponsiveEngines = append(merged.UnresponsiveEngines, resp.UnresponsiveEngines...)
+	merged.Partial = merged.Partial || resp.Partial
+	merged.DecodeWarnings = append(merged.DecodeWarnings, resp.DecodeWarnings...)
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,138 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Search_EngineArgs(t *testing.T) {
+	// The logger is initialized lazily; do it before the concurrent searches
+	log.Get()
+
+	var mu sync.Mutex
+	got := map[string]string{} // engines -> language
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/config" {
+			_ = json.NewEncoder(w).Encode(InstanceInfo{Engines: []Engine{
+				{Name: "duckduckgo", Categories: []string{"general"}, Enabled: true},
+				{Name: "wikipedia", Categories: []string{"general"}, Enabled: true},
+				{Name: "bing", Categories: []string{"general"}, Enabled: true},
+				{Name: "google", Categories: []string{"general"}},
+				{Name: "arxiv", Categories: []string{"science"}, Enabled: true},
+			}})
+			return
+		}
+		q := r.URL.Query()
+		mu.Lock()
+		got[q.Get("engines")] = q.Get("language")
+		mu.Unlock()
+
+		resp := APIResponse{Query: q.Get("q")}
+		switch q.Get("engines") {
+		case "wikipedia":
+			resp.Results = []APIResult{{URL: "https://de.wikipedia.org/wiki/Go", Score: 2, Engine: "wikipedia", PublishedDate: "2024-01-02"}}
+			resp.Suggestions = []string{"golang"}
+		case "duckduckgo":
+			resp.Results = []APIResult{
+				{URL: "https://go.dev/", Score: 3, Engine: "duckduckgo"},
+				{URL: "https://example.com/", Score: 1, Engine: "duckduckgo"},
+			}
+			resp.Suggestions = []string{"golang", "go language"}
+		case "bing":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{BaseURL: srv.URL, MaxRetries: 0})
+	require.NoError(t, err)
+
+	resp, err := client.Search(context.Background(), SearchRequest{
+		Query:      "go",
+		Language:   "en",
+		Engines:    []string{"duckduckgo", "wikipedia"},
+		EngineArgs: map[string]EngineArgs{"wikipedia": {Language: "de"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"duckduckgo": "en", "wikipedia": "de"}, got)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, "https://go.dev/", resp.Results[0].URL)
+	assert.Equal(t, "https://de.wikipedia.org/wiki/Go", resp.Results[1].URL)
+	assert.ElementsMatch(t, []string{"golang", "go language"}, resp.Suggestions)
+
+	resp, err = client.Search(context.Background(), SearchRequest{
+		Query:      "go",
+		Engines:    []string{"duckduckgo", "wikipedia"},
+		EngineArgs: map[string]EngineArgs{"wikipedia": {Language: "de"}},
+		RankBy:     RankRecency,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://de.wikipedia.org/wiki/Go", resp.Results[0].URL, "the merged results are ranked")
+
+	// A failing engine is reported without failing the whole search
+	clear(got)
+	resp, err = client.Search(context.Background(), SearchRequest{
+		Query:      "go",
+		EngineArgs: map[string]EngineArgs{"wikipedia": {Language: "de"}, "bing": {Language: "fr"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"duckduckgo": "", "wikipedia": "de", "bing": "fr"}, got,
+		"the base search queries the instance's engines without those with arguments")
+	require.Len(t, resp.Results, 3)
+	require.Len(t, resp.UnresponsiveEngines, 1)
+	assert.Equal(t, "bing", resp.UnresponsiveEngines[0].Name)
+
+	_, err = client.Search(context.Background(), SearchRequest{
+		Query:      "go",
+		Engines:    []string{"bing"},
+		EngineArgs: map[string]EngineArgs{"bing": {Language: "fr"}},
+	})
+	assert.Error(t, err, "fails when every search fails")
+}
+
+func TestClient_SearchJSON_EngineArgs(t *testing.T) {
+	log.Get()
+
+	var mu sync.Mutex
+	got := map[string]string{} // engines -> language
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var apiReq APIRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&apiReq))
+		require.Len(t, apiReq.Engines, 1)
+		mu.Lock()
+		got[apiReq.Engines[0]] = apiReq.Language
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(APIResponse{Query: apiReq.Query, Results: []APIResult{
+			{URL: "https://" + apiReq.Engines[0] + ".example/", Score: 1, Engine: apiReq.Engines[0]},
+			{URL: "https://" + apiReq.Engines[0] + ".example", Score: 1, Engine: apiReq.Engines[0]},
+		}})
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{BaseURL: srv.URL, MaxRetries: 0})
+	require.NoError(t, err)
+
+	resp, err := client.SearchJSON(context.Background(), SearchRequest{
+		Query:      "go",
+		Language:   "en",
+		Engines:    []string{"duckduckgo", "wikipedia"},
+		EngineArgs: map[string]EngineArgs{"wikipedia": {Language: "de"}},
+		Dedupe:     true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"duckduckgo": "en", "wikipedia": "de"}, got)
+	assert.Len(t, resp.Results, 2, "the merged results are deduplicated")
+}
@@ -0,0 +1,123 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+)
+
+// instanceInfoTTL is how long Client.InstanceInfo reuses a fetched /config
+const instanceInfoTTL = 10 * time.Minute
+
+// versionHeader is set by some deployments whose /config omits the version
+const versionHeader = "X-SearXNG-Version"
+
+// Engine describes a search engine configured on the instance
+type Engine struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+	Shortcut   string   `json:"shortcut"`
+	Enabled    bool     `json:"enabled"`
+}
+
+// InstanceInfo is what the instance publishes about itself at /config
+type InstanceInfo struct {
+	// Version is the instance version, e.g. "2024.5.24+c2b0ab2"; empty when
+	// the instance doesn't publish it
+	Version    string   `json:"version"`
+	Categories []string `json:"categories"`
+	Engines    []Engine `json:"engines"`
+	// Locales maps the locale codes the instance knows, e.g. "de" or
+	// "pt-BR", to their names
+	Locales map[string]string `json:"locales"`
+}
+
+// instanceInfoCache caches the /config of the instance
+type instanceInfoCache struct {
+	mu      sync.Mutex
+	info    *InstanceInfo
+	fetched time.Time
+	logged  bool // the detected capabilities were logged
+}
+
+// InstanceInfo returns the version, categories and engines of the instance,
+// as listed by its /config endpoint. The result is cached for a few minutes.
+func (c *Client) InstanceInfo(ctx context.Context) (*InstanceInfo, error) {
+	c.instanceInfo.mu.Lock()
+	defer c.instanceInfo.mu.Unlock()
+	if c.instanceInfo.info != nil && c.clock.Now().Sub(c.instanceInfo.fetched) < instanceInfoTTL {
+		return c.instanceInfo.info, nil
+	}
+
+	info, err := c.fetchInstanceInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.instanceInfo.info, c.instanceInfo.fetched = info, c.clock.Now()
+	if !c.instanceInfo.logged {
+		c.instanceInfo.logged = true
+		log.WithFields(logrus.Fields{
+			"version":     info.Version,
+			"categories":  info.Categories,
+			"time_ranges": info.TimeRanges(),
+			"engines":     len(info.Engines),
+		}).Info("detected searxng instance capabilities")
+	}
+	return info, nil
+}
+
+// Engines returns the engines configured on the instance (see InstanceInfo)
+func (c *Client) Engines(ctx context.Context) ([]Engine, error) {
+	info, err := c.InstanceInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.Engines, nil
+}
+
+func (c *Client) fetchInstanceInfo(ctx context.Context) (*InstanceInfo, error) {
+	baseURL, err := url.Parse(c.config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	configPath, _ := url.Parse("/config")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.ResolveReference(configPath).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	c.touch()
+	httpResp, err := c.do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d", httpResp.StatusCode)
+	}
+
+	var info InstanceInfo
+	if err := json.NewDecoder(httpResp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	if info.Version == "" {
+		info.Version = httpResp.Header.Get(versionHeader)
+	}
+	if info.Engines == nil {
+		info.Engines = []Engine{}
+	}
+	return &info, nil
+}
@@ -0,0 +1,53 @@
+package searxng
+
+import (
+	"context"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Engines(t *testing.T) {
+	defer gock.OffAll()
+
+	// Only one request: the second call is served from the cached list
+	gock.New("https://searxng.example.com").
+		Get("/config").
+		Times(1).
+		Reply(200).
+		JSON(map[string]interface{}{
+			"engines": []map[string]interface{}{
+				{"name": "wikipedia", "categories": []string{"general"}, "shortcut": "wp", "enabled": true},
+				{"name": "bing", "categories": []string{"general"}, "shortcut": "bi", "enabled": false},
+			},
+		})
+
+	client, err := NewClient(DefaultConfig())
+	require.NoError(t, err)
+
+	engines, err := client.Engines(context.Background())
+	require.NoError(t, err)
+	require.Len(t, engines, 2)
+	assert.Equal(t, Engine{Name: "wikipedia", Categories: []string{"general"}, Shortcut: "wp", Enabled: true}, engines[0])
+	assert.False(t, engines[1].Enabled)
+
+	_, err = client.Engines(context.Background())
+	require.NoError(t, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestClient_Engines_Error(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/config").
+		Reply(404)
+
+	client, err := NewClient(DefaultConfig())
+	require.NoError(t, err)
+
+	_, err = client.Engines(context.Background())
+	assert.ErrorContains(t, err, "HTTP 404")
+}
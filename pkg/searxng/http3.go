@@ -0,0 +1,125 @@
+package searxng
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// DefaultHTTP3FallbackPeriod is how long requests skip HTTP/3 after it
+// failed to reach the instance
+const DefaultHTTP3FallbackPeriod = 5 * time.Minute
+
+// QUIC settings of the HTTP/3 transport: the handshake gives up quickly so
+// a network blocking UDP only delays the first request, and keep-alives
+// hold the connection open between searches
+const (
+	http3HandshakeTimeout = 3 * time.Second
+	http3KeepAlive        = 15 * time.Second
+)
+
+// newQUICTransport returns an HTTP/3 transport trusting the same roots as
+// fallback, when it is an *http.Transport with a TLS config
+func newQUICTransport(fallback http.RoundTripper) *http3.Transport {
+	transport := &http3.Transport{
+		QUICConfig: &quic.Config{
+			HandshakeIdleTimeout: http3HandshakeTimeout,
+			KeepAlivePeriod:      http3KeepAlive,
+		},
+	}
+	if t, ok := fallback.(*http.Transport); ok && t.TLSClientConfig != nil {
+		transport.TLSClientConfig = t.TLSClientConfig.Clone()
+	}
+	return transport
+}
+
+// http3Transport sends requests over HTTP/3 first and falls back to the
+// HTTP/2 and HTTP/1.1 transport when HTTP/3 fails, e.g. when UDP is blocked
+// on the network. After a failure, requests go straight to the fallback
+// transport for the fallback period, so they don't all pay for a QUIC
+// handshake timing out.
+type http3Transport struct {
+	h3       http.RoundTripper
+	fallback http.RoundTripper
+	period   time.Duration
+	clock    clock
+	// brokenUntil is the unix nanoseconds until which HTTP/3 is skipped
+	brokenUntil atomic.Int64
+}
+
+// newHTTP3Transport wraps h3 around fallback (nil: http.DefaultTransport).
+// HTTP/3 is skipped for period after it failed (0:
+// DefaultHTTP3FallbackPeriod) on clk.
+func newHTTP3Transport(h3, fallback http.RoundTripper, period time.Duration, clk clock) *http3Transport {
+	if fallback == nil {
+		fallback = http.DefaultTransport
+	}
+	if period <= 0 {
+		period = DefaultHTTP3FallbackPeriod
+	}
+	return &http3Transport{h3: h3, fallback: fallback, period: period, clock: clk}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *http3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" || t.clock.Now().UnixNano() < t.brokenUntil.Load() {
+		return t.fallback.RoundTrip(req)
+	}
+	retry := req
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			// The body can't be sent twice, so don't risk HTTP/3
+			return t.fallback.RoundTrip(req)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry = req.Clone(req.Context())
+		retry.Body = body
+	}
+
+	resp, err := t.h3.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+	if ctxErr := req.Context().Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return nil, err
+	}
+	t.brokenUntil.Store(t.clock.Now().Add(t.period).UnixNano())
+	resp, fallbackErr := t.fallback.RoundTrip(retry)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("%w (after HTTP/3 failed: %w)", fallbackErr, err)
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of both transports
+func (t *http3Transport) CloseIdleConnections() {
+	for _, rt := range []http.RoundTripper{t.h3, t.fallback} {
+		if closer, ok := rt.(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+}
+
+// checkHTTP3Config rejects configurations HTTP/3 would silently bypass:
+// QUIC connections neither go through the proxy nor are checked against
+// the SPKI pins of the fallback transport
+func checkHTTP3Config(config *Config) error {
+	if !config.HTTP3 {
+		return nil
+	}
+	if config.ProxyURL != "" {
+		return errors.New("HTTP/3 can't be used with a proxy")
+	}
+	if len(config.PinnedSPKI) > 0 {
+		return errors.New("HTTP/3 can't be used with SPKI pinning")
+	}
+	return nil
+}
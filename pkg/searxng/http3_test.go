@@ -0,0 +1,153 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc adapts a func to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestNewQUICTransport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	})
+	instance := httptest.NewTLSServer(handler)
+	defer instance.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	h3Server := &http3.Server{Handler: handler, TLSConfig: http3.ConfigureTLSConfig(instance.TLS.Clone())}
+	go h3Server.Serve(conn) //nolint:errcheck
+	defer h3Server.Close()
+
+	transport := newQUICTransport(instance.Client().Transport)
+	defer transport.Close()
+	req, err := http.NewRequest(http.MethodGet, "https://"+conn.LocalAddr().String()+"/", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err, "the roots of the fallback transport are trusted")
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/3.0", string(body))
+}
+
+func TestHTTP3Transport_FallsBack(t *testing.T) {
+	instance := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	defer instance.Close()
+
+	clk := newFakeClock()
+	var h3Calls int
+	h3Up := false
+	h3 := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		h3Calls++
+		if !h3Up {
+			return nil, errors.New("timeout: no recent network activity")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Proto: "HTTP/3.0", Header: http.Header{},
+			Body: http.NoBody, Request: req}, nil
+	})
+	transport := newHTTP3Transport(h3, instance.Client().Transport, 0, clk)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(instance.URL)
+	require.NoError(t, err, "served over HTTP/2 or HTTP/1.1")
+	_ = resp.Body.Close()
+	assert.Equal(t, 1, h3Calls)
+
+	h3Up = true
+	resp, err = client.Get(instance.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, 1, h3Calls, "HTTP/3 is skipped for the fallback period")
+	assert.NotEqual(t, "HTTP/3.0", resp.Proto)
+
+	clk.Advance(DefaultHTTP3FallbackPeriod - time.Second)
+	resp, err = client.Get(instance.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, 1, h3Calls)
+
+	clk.Advance(time.Second)
+	resp, err = client.Get(instance.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, 2, h3Calls)
+	assert.Equal(t, "HTTP/3.0", resp.Proto, "and tried again afterwards")
+}
+
+func TestHTTP3Transport_ResendsBody(t *testing.T) {
+	var fallbackBody string
+	fallback := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		fallbackBody = string(body)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	h3 := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		_, _ = req.Body.Read(make([]byte, 4))
+		return nil, errors.New("QUIC handshake failed")
+	})
+	transport := newHTTP3Transport(h3, fallback, 0, realClock{})
+
+	req, err := http.NewRequest(http.MethodPost, "https://searxng.example.com/search", strings.NewReader("q=golang"))
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "q=golang", fallbackBody)
+}
+
+func TestHTTP3Transport_PlainHTTPAndCanceled(t *testing.T) {
+	var h3Calls, fallbackCalls int
+	h3 := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		h3Calls++
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	fallback := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		fallbackCalls++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	transport := newHTTP3Transport(h3, fallback, 0, realClock{})
+
+	req, err := http.NewRequest(http.MethodGet, "http://searxng.local/search", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, h3Calls, "HTTP/3 needs TLS")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, "https://searxng.example.com/search", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, fallbackCalls, "canceled requests aren't sent again")
+}
+
+func TestNewClient_HTTP3(t *testing.T) {
+	client, err := NewClient(&Config{BaseURL: DefaultInstanceURL, HTTP3: true})
+	require.NoError(t, err)
+	assert.IsType(t, &http3Transport{}, client.httpClient.Transport)
+
+	_, err = NewClient(&Config{BaseURL: DefaultInstanceURL, HTTP3: true, ProxyURL: "socks5://127.0.0.1:9050"})
+	assert.ErrorContains(t, err, "proxy")
+	_, err = NewClient(&Config{BaseURL: DefaultInstanceURL, HTTP3: true, PinnedSPKI: []string{"sha256/AAAA"}})
+	assert.ErrorContains(t, err, "SPKI")
+}
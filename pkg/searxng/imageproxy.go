@@ -0,0 +1,48 @@
+package searxng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// ImageProxyURL returns src routed through the image_proxy endpoint of the
+// instance at baseURL, signed like Searxng does with the instance's
+// server.secret_key. URLs that aren't http(s) are returned unchanged.
+func ImageProxyURL(baseURL, secret, src string) string {
+	if strings.HasPrefix(src, "//") {
+		src = "https:" + src
+	}
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return src
+	}
+	base := strings.TrimSuffix(baseURL, "/")
+	if strings.HasPrefix(src, base+"/image_proxy?") {
+		return src
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(src))
+	query := url.Values{"url": {src}, "h": {hex.EncodeToString(mac.Sum(nil))}}
+	return base + "/image_proxy?" + query.Encode()
+}
+
+// proxifyImages rewrites the image and thumbnail URLs of resp to go through
+// the instance's image proxy when Config.ImageProxySecret is set
+func (c *Client) proxifyImages(resp *SearchResponse) {
+	secret := c.config.ImageProxySecret
+	if secret == "" {
+		return
+	}
+	for i := range resp.Results {
+		r := &resp.Results[i]
+		if r.Thumbnail != "" {
+			r.Thumbnail = ImageProxyURL(c.config.BaseURL, secret, r.Thumbnail)
+		}
+		if r.ImageSrc != "" {
+			r.ImageSrc = ImageProxyURL(c.config.BaseURL, secret, r.ImageSrc)
+		}
+	}
+}
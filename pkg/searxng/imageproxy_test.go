@@ -0,0 +1,52 @@
+package searxng
+
+import (
+	"context"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageProxyURL(t *testing.T) {
+	// Signature computed like Searxng's new_hmac(secret_key, url)
+	assert.Equal(t,
+		"https://searx.example.org/image_proxy?h=76e98405bfdb3258ba41e547d84bd3a5012a984f01cd988332ad6ed9098d774d&url=https%3A%2F%2Fimg.example.org%2Fa.png",
+		ImageProxyURL("https://searx.example.org/", "s3cret", "https://img.example.org/a.png"))
+
+	// Protocol-relative URLs are proxied as https, like Searxng does
+	assert.Equal(t,
+		ImageProxyURL("https://searx.example.org", "s3cret", "https://img.example.org/a.png"),
+		ImageProxyURL("https://searx.example.org", "s3cret", "//img.example.org/a.png"))
+
+	data := "data:image/png;base64,AAAA"
+	assert.Equal(t, data, ImageProxyURL("https://searx.example.org", "s3cret", data))
+
+	proxied := ImageProxyURL("https://searx.example.org", "s3cret", "https://img.example.org/a.png")
+	assert.Equal(t, proxied, ImageProxyURL("https://searx.example.org", "s3cret", proxied), "already proxied URLs are kept")
+}
+
+func TestClient_Search_ImageProxy(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		JSON(APIResponse{Query: "cats", Results: []APIResult{
+			{URL: "https://example.com/cat", Title: "Cat", ImgSrc: "https://img.example.org/cat.jpg", ThumbnailSrc: "https://img.example.org/cat_t.jpg"},
+			{URL: "https://example.com/page", Title: "Page"},
+		}})
+
+	config := DefaultConfig()
+	config.ImageProxySecret = "s3cret"
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	resp, err := client.Search(context.Background(), SearchRequest{Query: "cats", Category: "images"})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, ImageProxyURL(config.BaseURL, "s3cret", "https://img.example.org/cat.jpg"), resp.Results[0].ImageSrc)
+	assert.Equal(t, ImageProxyURL(config.BaseURL, "s3cret", "https://img.example.org/cat_t.jpg"), resp.Results[0].Thumbnail)
+	assert.Empty(t, resp.Results[1].ImageSrc)
+}
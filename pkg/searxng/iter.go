@@ -0,0 +1,61 @@
+package searxng
+
+import (
+	"context"
+	"iter"
+)
+
+// MaxIterPages bounds the pages SearchIter requests, so a query the
+// instance keeps answering doesn't page forever
+const MaxIterPages = 10
+
+// SearchIter returns an iterator over the results of req that walks the
+// result pages from req.Page until req.Limit results were yielded. Unlike
+// Search, Limit isn't capped at 20, since Searxng answers about 10 results
+// per page; a Limit of 0 yields results until the instance runs out of
+// them, the loop breaks or MaxIterPages pages were searched.
+//
+// Each page is a Search, so it is cached, rate limited and retried like
+// one. Results already yielded from an earlier page, by canonical URL, are
+// skipped. Iteration stops after the first page without new results or
+// with Partial set. A failed search yields its error with a zero result,
+// then iteration stops.
+func (c *Client) SearchIter(ctx context.Context, req SearchRequest) iter.Seq2[SearchResult, error] {
+	return func(yield func(SearchResult, error) bool) {
+		limit := req.Limit
+		page := max(req.Page, 1)
+		seen := make(map[string]bool)
+		yielded := 0
+		for range MaxIterPages {
+			pageReq := req
+			pageReq.Page = page
+			pageReq.Limit = 20
+			resp, err := c.Search(ctx, pageReq)
+			if err != nil {
+				yield(SearchResult{}, err)
+				return
+			}
+
+			fresh := 0
+			for _, result := range resp.Results {
+				key := canonicalURL(result.URL)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				fresh++
+				if !yield(result, nil) {
+					return
+				}
+				yielded++
+				if limit > 0 && yielded >= limit {
+					return
+				}
+			}
+			if fresh == 0 || resp.Partial {
+				return
+			}
+			page++
+		}
+	}
+}
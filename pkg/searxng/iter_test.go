@@ -0,0 +1,89 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedInstance answers 10 results per page for pages 1 to 3; page 3
+// repeats page 2, as instances do once engines run out of results
+func pagedInstance(t *testing.T, pages *[]int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("pageno"))
+		page = max(page, 1)
+		*pages = append(*pages, page)
+		resp := APIResponse{Query: r.URL.Query().Get("q")}
+		if page <= 3 {
+			for i := range 10 {
+				resp.Results = append(resp.Results, APIResult{URL: fmt.Sprintf("https://example.com/%d/%d", min(page, 2), i), Engine: "google"})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_SearchIter(t *testing.T) {
+	var pages []int
+	srv := pagedInstance(t, &pages)
+	client, err := NewClient(&Config{BaseURL: srv.URL, MaxRetries: 0})
+	require.NoError(t, err)
+
+	var urls []string
+	for result, err := range client.SearchIter(context.Background(), SearchRequest{Query: "go", Limit: 25}) {
+		require.NoError(t, err)
+		urls = append(urls, result.URL)
+	}
+	assert.Len(t, urls, 20, "page 3 only repeats page 2")
+	assert.Equal(t, "https://example.com/1/0", urls[0])
+	assert.Equal(t, "https://example.com/2/9", urls[19])
+	assert.Equal(t, []int{1, 2, 3}, pages)
+
+	pages = nil
+	urls = nil
+	for result, err := range client.SearchIter(context.Background(), SearchRequest{Query: "go", Limit: 12, Page: 2}) {
+		require.NoError(t, err)
+		urls = append(urls, result.URL)
+	}
+	assert.Len(t, urls, 10)
+	assert.Equal(t, []int{2, 3}, pages)
+
+	pages = nil
+	for range client.SearchIter(context.Background(), SearchRequest{Query: "go", Limit: 15}) {
+	}
+	assert.Equal(t, []int{1, 2}, pages, "paging stops once Limit results were yielded")
+
+	pages = nil
+	for range client.SearchIter(context.Background(), SearchRequest{Query: "go"}) {
+		break
+	}
+	assert.Equal(t, []int{1}, pages, "breaking the loop stops paging")
+}
+
+func TestClient_SearchIter_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	require.NoError(t, err)
+
+	var errs []error
+	for result, err := range client.SearchIter(context.Background(), SearchRequest{Query: "go", Limit: 30}) {
+		assert.Zero(t, result)
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.Error(t, errs[0])
+}
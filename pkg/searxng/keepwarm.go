@@ -0,0 +1,104 @@
+package searxng
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+)
+
+// touch records backend activity so KeepWarm can skip pings while the
+// client is busy
+func (c *Client) touch() {
+	c.lastActivity.Store(c.clock.Now().UnixNano())
+}
+
+// idleFor returns how long ago the client last talked to the instance
+func (c *Client) idleFor() time.Duration {
+	return c.clock.Now().Sub(time.Unix(0, c.lastActivity.Load()))
+}
+
+// CloseIdleConnections closes the pooled connections to the instance that
+// are not in use; later searches open new ones
+func (c *Client) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// warmQuery is the query of the searches of KeepWarm
+const warmQuery = "searxng"
+
+// KeepWarm searches the instance whenever the client has been idle for at
+// least interval, keeping pooled connections, the instance's connections to
+// its engines and instance-side caches warm. It blocks until ctx is
+// cancelled and is meant to be run in a goroutine.
+func (c *Client) KeepWarm(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	for {
+		if err := sleep(ctx, c.clock, interval); err != nil {
+			return
+		}
+		if c.idleFor() < interval {
+			continue
+		}
+		if err := c.warm(ctx); err != nil {
+			log.WithField("error", err).Debug("keep-warm search failed")
+		}
+	}
+}
+
+// warm runs a search for warmQuery after waiting for the rate limiter. It
+// bypasses the cache and isn't counted as a search in the statistics.
+func (c *Client) warm(ctx context.Context) error {
+	if err := c.waitRateLimit(ctx); err != nil {
+		return err
+	}
+	searchURL, err := c.buildSearchURL(SearchRequest{Query: warmQuery, Page: 1})
+	if err != nil {
+		return fmt.Errorf("failed to build search URL: %w", err)
+	}
+	_, err = c.doSearchRequest(ctx, searchURL)
+	return err
+}
+
+// Ping performs a cheap request against the instance's healthz endpoint,
+// relative to the instance URL so instances served under a sub-path are
+// reached
+func (c *Client) Ping(ctx context.Context) error {
+	baseURL, err := url.Parse(c.config.BaseURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL = baseURL.JoinPath("/")
+	}
+	healthPath, _ := url.Parse("healthz")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.ResolveReference(healthPath).String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	c.touch()
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+	_, _ = io.Copy(io.Discard, httpResp.Body)
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", httpResp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package searxng
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Ping(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	require.NoError(t, client.Ping(context.Background()))
+	assert.Less(t, client.idleFor(), time.Second)
+}
+
+func TestClient_Ping_SubPath(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	for _, base := range []string{ts.URL + "/searxng", ts.URL + "/searxng/"} {
+		client, err := NewClient(&Config{BaseURL: base})
+		require.NoError(t, err)
+		require.NoError(t, client.Ping(context.Background()))
+	}
+	assert.Equal(t, []string{"/searxng/healthz", "/searxng/healthz"}, paths)
+}
+
+func TestClient_KeepWarm(t *testing.T) {
+	var searches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" && r.URL.Query().Get("q") == warmQuery {
+			searches.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"searxng","results":[]}`))
+	}))
+	defer ts.Close()
+
+	clk := newFakeClock()
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	config.Engines = []string{"duckduckgo"}
+	client, err := newClient(config, clk)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		client.KeepWarm(ctx, time.Minute)
+		close(done)
+	}()
+
+	clk.waitPending(t, 1)
+	clk.Advance(time.Minute)
+	require.Eventually(t, func() bool { return searches.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+	// Busy clients aren't searched
+	clk.waitPending(t, 1)
+	clk.Advance(30 * time.Second)
+	client.touch()
+	clk.Advance(30 * time.Second)
+	clk.waitPending(t, 1)
+	assert.EqualValues(t, 1, searches.Load())
+
+	clk.Advance(time.Minute)
+	require.Eventually(t, func() bool { return searches.Load() == 2 }, 5*time.Second, time.Millisecond)
+	assert.Zero(t, client.Stats().Searches, "keep-warm searches aren't counted")
+
+	cancel()
+	<-done
+}
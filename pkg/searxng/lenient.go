@@ -0,0 +1,277 @@
+package searxng
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fieldDecoder decodes the fields of a JSON object leniently: SearXNG
+// versions and engines disagree on field types (numbers sent as strings,
+// single strings in place of lists), so a field of an unexpected type is
+// converted when it can be and otherwise left empty with a warning,
+// instead of failing the whole response
+type fieldDecoder struct {
+	fields   map[string]json.RawMessage
+	warnings []string
+}
+
+// newFieldDecoder returns a decoder of the fields of data, which must be a
+// JSON object
+func newFieldDecoder(data []byte) (*fieldDecoder, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+	return &fieldDecoder{fields: fields}, nil
+}
+
+// raw returns the value of the field, or nil when it is missing or null
+func (d *fieldDecoder) raw(name string) json.RawMessage {
+	raw := d.fields[name]
+	if isJSONNull(raw) {
+		return nil
+	}
+	return raw
+}
+
+// warn records that the value of field isn't of the wanted type
+func (d *fieldDecoder) warn(field, want string, raw json.RawMessage) {
+	d.warnings = append(d.warnings, decodeWarning(field, want, raw))
+}
+
+// decodeWarning describes the value raw of field not being of the wanted
+// type
+func decodeWarning(field, want string, raw json.RawMessage) string {
+	return fmt.Sprintf("%s: expected %s, got %s", field, want, jsonKind(raw))
+}
+
+// string decodes a string field; numbers and booleans are kept as written
+func (d *fieldDecoder) string(name string, dst *string) {
+	if raw := d.raw(name); raw != nil {
+		if s, ok := lenientString(raw); ok {
+			*dst = s
+		} else {
+			d.warn(name, "string", raw)
+		}
+	}
+}
+
+// float decodes a number field; numeric strings are parsed
+func (d *fieldDecoder) float(name string, dst *float64) {
+	if raw := d.raw(name); raw != nil {
+		if f, ok := lenientFloat(raw); ok {
+			*dst = f
+		} else {
+			d.warn(name, "number", raw)
+		}
+	}
+}
+
+// int decodes an integer field; fractions are cut and numeric strings,
+// e.g. "1,234", are parsed
+func (d *fieldDecoder) int(name string, dst *int) {
+	var f float64
+	d.float(name, &f)
+	*dst = int(f)
+}
+
+// strings decodes a list of strings; a single string is a list of one.
+// Items that aren't strings are skipped with a warning.
+func (d *fieldDecoder) strings(name string, dst *[]string) {
+	raw := d.raw(name)
+	if raw == nil {
+		return
+	}
+	if s, ok := lenientString(raw); ok {
+		*dst = []string{s}
+		return
+	}
+	d.list(name, raw, "string", func(_ int, item json.RawMessage) bool {
+		s, ok := lenientString(item)
+		if ok {
+			*dst = append(*dst, s)
+		}
+		return ok
+	})
+}
+
+// ints decodes a list of integers; a single number is a list of one
+func (d *fieldDecoder) ints(name string, dst *[]int) {
+	raw := d.raw(name)
+	if raw == nil {
+		return
+	}
+	if f, ok := lenientFloat(raw); ok {
+		*dst = []int{int(f)}
+		return
+	}
+	d.list(name, raw, "number", func(_ int, item json.RawMessage) bool {
+		f, ok := lenientFloat(item)
+		if ok {
+			*dst = append(*dst, int(f))
+		}
+		return ok
+	})
+}
+
+// list calls decode with the items of the array raw, recording a warning
+// for those it rejects and for raw not being an array
+func (d *fieldDecoder) list(name string, raw json.RawMessage, want string, decode func(int, json.RawMessage) bool) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		d.warn(name, "array", raw)
+		return
+	}
+	for i, item := range items {
+		if !isJSONNull(item) && !decode(i, item) {
+			d.warn(fmt.Sprintf("%s[%d]", name, i), want, item)
+		}
+	}
+}
+
+// lenientString returns the JSON string raw, or the text of a number or
+// boolean
+func lenientString(raw json.RawMessage) (string, bool) {
+	switch jsonKind(raw) {
+	case "string":
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err == nil
+	case "number", "boolean":
+		return string(bytes.TrimSpace(raw)), true
+	}
+	return "", false
+}
+
+// lenientFloat returns the JSON number raw, or the number a string holds,
+// ignoring thousands separators
+func lenientFloat(raw json.RawMessage) (float64, bool) {
+	var f float64
+	if json.Unmarshal(raw, &f) == nil {
+		return f, true
+	}
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+	return f, err == nil
+}
+
+// jsonKind names the type of the JSON value raw
+func jsonKind(raw json.RawMessage) string {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return "nothing"
+	}
+	switch trimmed[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// isJSONNull reports whether raw is missing or null
+func isJSONNull(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) == 0 || string(trimmed) == "null"
+}
+
+// UnmarshalJSON decodes a result leniently (see fieldDecoder). It only
+// fails when data isn't a JSON object.
+func (r *APIResult) UnmarshalJSON(data []byte) error {
+	d, err := newFieldDecoder(data)
+	if err != nil {
+		return err
+	}
+	*r = APIResult{}
+	d.string("url", &r.URL)
+	d.string("title", &r.Title)
+	d.string("content", &r.Content)
+	d.string("publishedDate", &r.PublishedDate)
+	d.string("engine", &r.Engine)
+	d.string("category", &r.Category)
+	d.float("score", &r.Score)
+	d.string("thumbnail", &r.Thumbnail)
+	d.string("thumbnail_src", &r.ThumbnailSrc)
+	d.string("img_src", &r.ImgSrc)
+	d.string("resolution", &r.Resolution)
+	d.string("img_format", &r.ImgFormat)
+	r.Length = d.raw("length") // see parseLength
+	d.string("iframe_src", &r.IframeSrc)
+	d.string("author", &r.Author)
+	d.strings("engines", &r.Engines)
+	d.ints("positions", &r.Positions)
+	r.warnings = d.warnings
+	return nil
+}
+
+// takeWarnings returns the decoding warnings of the result, prefixed with
+// its place in the response, and clears them
+func (r *APIResult) takeWarnings(prefix string) []string {
+	warnings := make([]string, len(r.warnings))
+	for i, w := range r.warnings {
+		warnings[i] = prefix + w
+	}
+	r.warnings = nil
+	return warnings
+}
+
+// UnmarshalJSON decodes a response leniently (see fieldDecoder): fields of
+// unexpected types, results that aren't objects and invalid infoboxes are
+// dropped and reported in Warnings. It only fails when data isn't a JSON
+// object.
+func (r *APIResponse) UnmarshalJSON(data []byte) error {
+	d, err := newFieldDecoder(data)
+	if err != nil {
+		return err
+	}
+	*r = APIResponse{}
+	d.string("query", &r.Query)
+	d.int("number_of_results", &r.NumberOfResults)
+	if raw := d.raw("results"); raw != nil {
+		d.list("results", raw, "object", func(i int, item json.RawMessage) bool {
+			var result APIResult
+			if jsonKind(item) != "object" || json.Unmarshal(item, &result) != nil {
+				return false
+			}
+			d.warnings = append(d.warnings, result.takeWarnings(fmt.Sprintf("results[%d].", i))...)
+			r.Results = append(r.Results, result)
+			return true
+		})
+	}
+	r.Answers = d.raw("answers") // see safeParseAnswers
+	d.strings("corrections", &r.Corrections)
+	if raw := d.raw("infoboxes"); raw != nil {
+		d.list("infoboxes", raw, "object", func(i int, item json.RawMessage) bool {
+			var infobox Infobox
+			if jsonKind(item) != "object" {
+				return false
+			}
+			if err := json.Unmarshal(item, &infobox); err != nil {
+				d.warnings = append(d.warnings, fmt.Sprintf("infoboxes[%d]: %v", i, err))
+			} else {
+				r.Infoboxes = append(r.Infoboxes, infobox)
+			}
+			return true
+		})
+	}
+	d.strings("suggestions", &r.Suggestions)
+	r.UnresponsiveEngines = d.raw("unresponsive_engines") // see safeParseUnresponsiveEngines
+	r.Warnings = d.warnings
+	return nil
+}
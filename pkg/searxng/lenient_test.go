@@ -0,0 +1,105 @@
+package searxng
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIResponse_UnmarshalJSON_Lenient(t *testing.T) {
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"query": "go",
+		"number_of_results": "1,234",
+		"results": [
+			{"url": "https://go.dev", "title": 42, "score": "1.5", "engines": "google", "positions": ["2", 3]},
+			"https://not-a-result.example",
+			{"url": "https://pkg.go.dev", "score": {"value": 1}, "engines": ["bing", {"name": "ddg"}]},
+			null
+		],
+		"corrections": "golang",
+		"infoboxes": [{"infobox": "Go", "urls": "none"}, {"infobox": "Gopher"}],
+		"suggestions": {"s": 1}
+	}`), &resp))
+
+	assert.Equal(t, "go", resp.Query)
+	assert.Equal(t, 1234, resp.NumberOfResults)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, APIResult{URL: "https://go.dev", Title: "42", Score: 1.5, Engines: []string{"google"}, Positions: []int{2, 3}}, resp.Results[0])
+	assert.Equal(t, APIResult{URL: "https://pkg.go.dev", Engines: []string{"bing"}}, resp.Results[1])
+	assert.Equal(t, []string{"golang"}, resp.Corrections)
+	require.Len(t, resp.Infoboxes, 1)
+	assert.Empty(t, resp.Suggestions)
+	assert.Equal(t, []string{
+		"results[1]: expected object, got string",
+		"results[2].score: expected number, got object",
+		"results[2].engines[1]: expected string, got object",
+		"infoboxes[0]: json: cannot unmarshal string into Go struct field Infobox.urls of type []searxng.InfoboxURL",
+		"suggestions: expected array, got object",
+	}, resp.Warnings)
+}
+
+func TestAPIResponse_UnmarshalJSON_NotAnObject(t *testing.T) {
+	var resp APIResponse
+	assert.Error(t, json.Unmarshal([]byte(`["go"]`), &resp))
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &resp))
+}
+
+func TestSafeParseUnresponsiveEngines(t *testing.T) {
+	for _, tc := range []struct {
+		raw      string
+		want     []UnresponsiveEngine
+		warnings []string
+	}{
+		{`[["bing", "timeout"], {"name": "ddg", "error": "HTTP error"}, ["qwant", null]]`,
+			[]UnresponsiveEngine{{"bing", "timeout"}, {"ddg", "HTTP error"}, {"qwant", ""}}, nil},
+		{`[["brave", 503], ["solo"], 7]`,
+			[]UnresponsiveEngine{{"brave", "503"}},
+			[]string{"unresponsive_engines[1]: expected [name, error] pair or object, got array", "unresponsive_engines[2]: expected [name, error] pair or object, got number"}},
+		{`{"google": "timeout", "bing": ["x"]}`,
+			[]UnresponsiveEngine{{"google", "timeout"}},
+			[]string{"unresponsive_engines.bing: expected string, got array"}},
+		{`"timeout"`, nil, []string{"unresponsive_engines: expected array, got string"}},
+		{`null`, nil, nil},
+	} {
+		engines, warnings := safeParseUnresponsiveEngines(json.RawMessage(tc.raw))
+		assert.Equal(t, tc.want, engines, tc.raw)
+		assert.Equal(t, tc.warnings, warnings, tc.raw)
+	}
+}
+
+func TestSafeParseAnswers_Warnings(t *testing.T) {
+	answers, warnings := safeParseAnswers(json.RawMessage(`["42", {"answer": "43"}, {"text": "44"}, 45]`))
+	assert.Equal(t, []Answer{{Text: "42"}, {Text: "43"}}, answers)
+	assert.Equal(t, []string{
+		"answers[2]: expected string or answer object, got object",
+		"answers[3]: expected string or answer object, got number",
+	}, warnings)
+
+	_, warnings = safeParseAnswers(json.RawMessage(`"42"`))
+	assert.Equal(t, []string{"answers: expected array, got string"}, warnings)
+}
+
+func TestClient_Search_DecodeWarnings(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		BodyString(`{"query": "go", "results": [{"url": "https://go.dev", "title": "Go", "publishedDate": {"y": 2024}}], "unresponsive_engines": [["bing", "timeout"], 1]}`)
+
+	client, err := NewClient(DefaultConfig())
+	require.NoError(t, err)
+	resp, err := client.Search(t.Context(), SearchRequest{Query: "go"})
+	require.NoError(t, err, "fields of unexpected types don't fail the search")
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "Go", resp.Results[0].Title)
+	assert.Equal(t, []UnresponsiveEngine{{Name: "bing", Error: "timeout"}}, resp.UnresponsiveEngines)
+	assert.Equal(t, []string{
+		"results[0].publishedDate: expected string, got object",
+		"unresponsive_engines[1]: expected [name, error] pair or object, got number",
+	}, resp.DecodeWarnings)
+}
@@ -0,0 +1,43 @@
+package searxng
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// limiterMarkers are lowercase fragments of the pages SearXNG's limiter
+// plugin and common reverse-proxy bot checks serve instead of results
+var limiterMarkers = [][]byte{
+	[]byte("too many requests"),
+	[]byte("rate limit"),
+	[]byte("captcha"),
+	[]byte("limiter"),
+	[]byte("are you a robot"),
+	[]byte("bot detected"),
+	[]byte("bot detection"),
+}
+
+// isLimiterPage reports whether a response is a bot-limiter page rather
+// than search results
+func isLimiterPage(statusCode int, body []byte) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if !looksLikeHTML(body) {
+		return false
+	}
+
+	lower := bytes.ToLower(body)
+	for _, marker := range limiterMarkers {
+		if bytes.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeHTML reports whether body is an HTML document
+func looksLikeHTML(body []byte) bool {
+	trimmed := bytes.ToLower(bytes.TrimSpace(body))
+	return bytes.HasPrefix(trimmed, []byte("<!doctype html")) || bytes.HasPrefix(trimmed, []byte("<html"))
+}
@@ -0,0 +1,99 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const limiterPage = `<!DOCTYPE html>
+<html><head><title>Too Many Requests</title></head>
+<body><p>The limiter blocked this request.</p></body></html>`
+
+func TestIsLimiterPage(t *testing.T) {
+	assert.True(t, isLimiterPage(http.StatusTooManyRequests, nil))
+	assert.True(t, isLimiterPage(http.StatusOK, []byte(limiterPage)))
+	assert.True(t, isLimiterPage(http.StatusForbidden, []byte("<html><body>Please solve the CAPTCHA</body></html>")))
+	assert.False(t, isLimiterPage(http.StatusOK, []byte(`{"query": "rate limit"}`)))
+	assert.False(t, isLimiterPage(http.StatusOK, []byte("<html><body>SearXNG</body></html>")))
+}
+
+func TestClient_Search_LimiterPage(t *testing.T) {
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(limiterPage))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(APIResponse{Query: "test"})
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	config.MaxRetries = 1
+	config.LimiterBackoff = time.Millisecond
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	resp, err := client.Search(context.Background(), SearchRequest{Query: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, "test", resp.Query)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestClient_Search_LimiterPageError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(limiterPage))
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	config.MaxRetries = 0
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "test"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInstanceLimited)
+	assert.NotContains(t, err.Error(), "invalid character")
+}
+
+func TestClient_Search_HTMLInsteadOfJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<!DOCTYPE html><html><body>SearXNG</body></html>"))
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	config.MaxRetries = 0
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "test"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+	assert.ErrorIs(t, err, ErrJSONFormatDisabled)
+}
+
+func TestClient_RetryDelay(t *testing.T) {
+	client, err := NewClient(&Config{BaseURL: "https://searxng.example.com"})
+	require.NoError(t, err)
+
+	delay, ok := client.retryDelay(2, ErrInstanceLimited)
+	assert.True(t, ok)
+	assert.Equal(t, 2*DefaultLimiterBackoff, delay)
+}
@@ -0,0 +1,57 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+)
+
+// SearchMulti runs req once per engine group, concurrently, each search
+// restricted to the engines of its group, e.g. [["google"], ["bing"],
+// ["duckduckgo"]]. A combined query tends to be dominated by a single
+// engine; separate searches give every group its share of the results.
+//
+// Results found by several groups are merged as with Dedupe: engines and
+// positions are combined and scores added up, so pages several groups agree
+// on come first. The results are then ranked like those of Search. Groups
+// whose search fails are reported as unresponsive engines unless every
+// search failed. EngineArgs apply to the groups holding their engine.
+func (c *Client) SearchMulti(ctx context.Context, req SearchRequest, engineGroups [][]string) (*SearchResponse, error) {
+	var reqs []SearchRequest
+	for _, group := range engineGroups {
+		if len(group) == 0 {
+			continue
+		}
+		sub := req
+		sub.Engines = group
+		sub.EngineArgs = nil
+		for _, engine := range group {
+			if args, ok := req.EngineArgs[engine]; ok {
+				if sub.EngineArgs == nil {
+					sub.EngineArgs = make(map[string]EngineArgs)
+				}
+				sub.EngineArgs[engine] = args
+			}
+		}
+		reqs = append(reqs, sub)
+	}
+	if len(reqs) == 0 {
+		return nil, errors.New("no engine groups to search")
+	}
+
+	merged, err := c.fanOut(ctx, req.Query, reqs, c.Search, appendResponse)
+	if err != nil {
+		return nil, err
+	}
+	merged = dedupeResults(merged)
+	sortByScore(merged.Results)
+	return c.ranker(req).rank(merged), nil
+}
+
+// appendResponse is mergeResponse keeping every result, so that results
+// found by several searches can be merged by dedupeResults
+func appendResponse(merged, resp *SearchResponse) {
+	merged.Results = append(merged.Results, resp.Results...)
+	rest := *resp
+	rest.Results = nil
+	mergeResponse(merged, &rest)
+}
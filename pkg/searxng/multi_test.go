@@ -0,0 +1,78 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SearchMulti(t *testing.T) {
+	// The logger is initialized lazily; do it before the concurrent searches
+	log.Get()
+
+	var mu sync.Mutex
+	got := map[string]string{} // engines -> language
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		mu.Lock()
+		got[q.Get("engines")] = q.Get("language")
+		mu.Unlock()
+
+		resp := APIResponse{Query: q.Get("q")}
+		switch q.Get("engines") {
+		case "google":
+			resp.Results = []APIResult{
+				{URL: "https://go.dev/", Score: 2, Engine: "google", Positions: []int{1}},
+				{URL: "https://example.com/google-only", Score: 1.5, Engine: "google"},
+			}
+			resp.Suggestions = []string{"golang"}
+		case "bing":
+			resp.Results = []APIResult{
+				{URL: "http://go.dev", Score: 1, Engine: "bing", Positions: []int{2}},
+				{URL: "https://example.com/bing-only", Score: 1.8, Engine: "bing"},
+			}
+			resp.Suggestions = []string{"golang", "go language"}
+		case "duckduckgo":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{BaseURL: srv.URL, MaxRetries: 0})
+	require.NoError(t, err)
+
+	resp, err := client.SearchMulti(context.Background(), SearchRequest{
+		Query:      "go",
+		Language:   "en",
+		EngineArgs: map[string]EngineArgs{"bing": {Language: "fr"}},
+	}, [][]string{{"google"}, {"bing"}, {"duckduckgo"}, {}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"google": "en", "bing": "fr", "duckduckgo": "en"}, got)
+
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, "https://go.dev/", resp.Results[0].URL, "found by both groups")
+	assert.ElementsMatch(t, []string{"google", "bing"}, resp.Results[0].Engines)
+	assert.ElementsMatch(t, []int{1, 2}, resp.Results[0].Positions)
+	assert.InDelta(t, 3.0, resp.Results[0].Score, 0.001)
+	assert.Equal(t, "https://example.com/bing-only", resp.Results[1].URL)
+	assert.Equal(t, "https://example.com/google-only", resp.Results[2].URL)
+	assert.ElementsMatch(t, []string{"golang", "go language"}, resp.Suggestions)
+	require.Len(t, resp.UnresponsiveEngines, 1, "a failing group doesn't fail the search")
+	assert.Equal(t, "duckduckgo", resp.UnresponsiveEngines[0].Name)
+
+	_, err = client.SearchMulti(context.Background(), SearchRequest{Query: "go"}, [][]string{{"duckduckgo"}})
+	assert.Error(t, err, "fails when every search fails")
+
+	_, err = client.SearchMulti(context.Background(), SearchRequest{Query: "go"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package searxng
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidPin  = errors.New("invalid SPKI pin")
+	ErrPinMismatch = errors.New("searxng instance certificate does not match any configured SPKI pin")
+)
+
+// parseSPKIPins decodes pins given as base64 SHA-256 digests of a
+// certificate's SubjectPublicKeyInfo, optionally prefixed with "sha256/"
+// (the format printed by `openssl ... | openssl dgst -sha256 -binary | base64`).
+func parseSPKIPins(pins []string) (map[[sha256.Size]byte]struct{}, error) {
+	parsed := make(map[[sha256.Size]byte]struct{}, len(pins))
+	for _, pin := range pins {
+		encoded := strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+		digest, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidPin, pin, err)
+		}
+		if len(digest) != sha256.Size {
+			return nil, fmt.Errorf("%w %q: expected %d-byte SHA-256 digest, got %d bytes", ErrInvalidPin, pin, sha256.Size, len(digest))
+		}
+		parsed[[sha256.Size]byte(digest)] = struct{}{}
+	}
+	return parsed, nil
+}
+
+// verifyPinnedConnection returns a tls.Config.VerifyConnection callback that
+// accepts the connection only when a certificate of a verified chain matches
+// one of the pins. It runs after the regular chain verification, so pinning
+// adds to rather than replaces it. The presented certificates aren't
+// matched: a server can send any certificate along with its own chain, so
+// only those the chain verification vouches for count.
+func verifyPinnedConnection(pins map[[sha256.Size]byte]struct{}) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		for _, chain := range cs.VerifiedChains {
+			for _, cert := range chain {
+				if _, ok := pins[sha256.Sum256(cert.RawSubjectPublicKeyInfo)]; ok {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("%w (host %s)", ErrPinMismatch, cs.ServerName)
+	}
+}
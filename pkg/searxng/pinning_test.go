@@ -0,0 +1,129 @@
+package searxng
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// newPinnedTestClient creates a client for a TLS test server, trusting the
+// server's self-signed certificate so only the pin decides the outcome.
+func newPinnedTestClient(t *testing.T, ts *httptest.Server, pins []string) *Client {
+	t.Helper()
+
+	config := DefaultConfig()
+	config.BaseURL = ts.URL
+	config.MaxRetries = 0
+	config.PinnedSPKI = pins
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	client.httpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs = roots
+	return client
+}
+
+// selfSignedCert returns a certificate with a fresh key, unlike the one
+// httptest servers share
+func selfSignedCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func mustParsePins(t *testing.T, pins ...string) map[[sha256.Size]byte]struct{} {
+	t.Helper()
+	parsed, err := parseSPKIPins(pins)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestParseSPKIPins(t *testing.T) {
+	valid := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	pins, err := parseSPKIPins([]string{valid, "sha256/" + valid})
+	require.NoError(t, err)
+	assert.Len(t, pins, 1)
+
+	_, err = parseSPKIPins([]string{"not base64!"})
+	assert.ErrorIs(t, err, ErrInvalidPin)
+
+	_, err = parseSPKIPins([]string{base64.StdEncoding.EncodeToString([]byte("short"))})
+	assert.ErrorIs(t, err, ErrInvalidPin)
+}
+
+func TestNewClient_PinningRequiresHTTPS(t *testing.T) {
+	config := DefaultConfig()
+	config.BaseURL = "http://searxng.example.com"
+	config.PinnedSPKI = []string{base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))}
+
+	_, err := NewClient(config)
+	assert.ErrorIs(t, err, ErrInvalidURL)
+}
+
+func TestClient_Search_PinnedSPKI(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(APIResponse{Query: "test"})
+	}))
+	defer ts.Close()
+
+	t.Run("matching pin", func(t *testing.T) {
+		client := newPinnedTestClient(t, ts, []string{spkiPin(ts.Certificate())})
+		resp, err := client.Search(context.Background(), SearchRequest{Query: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, "test", resp.Query)
+	})
+
+	t.Run("pinned certificate outside the verified chain", func(t *testing.T) {
+		pinned := selfSignedCert(t)
+		verify := verifyPinnedConnection(mustParsePins(t, spkiPin(pinned)))
+
+		// A MITM appending the pinned certificate to its own chain
+		err := verify(tls.ConnectionState{
+			ServerName:       "searxng.example.com",
+			PeerCertificates: []*x509.Certificate{ts.Certificate(), pinned},
+			VerifiedChains:   [][]*x509.Certificate{{ts.Certificate()}},
+		})
+		assert.ErrorIs(t, err, ErrPinMismatch)
+
+		err = verify(tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{pinned},
+			VerifiedChains:   [][]*x509.Certificate{{pinned}},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("mismatching pin", func(t *testing.T) {
+		other := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+		client := newPinnedTestClient(t, ts, []string{other})
+		_, err := client.Search(context.Background(), SearchRequest{Query: "test"})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrPinMismatch)
+	})
+}
@@ -0,0 +1,177 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInstanceUnreachable = errors.New("searxng instance unreachable")
+	ErrJSONFormatDisabled  = errors.New("searxng instance does not serve JSON results (enable \"json\" in search.formats of settings.yml)")
+)
+
+// PreflightCheck is the outcome of a single startup check
+type PreflightCheck struct {
+	Name     string
+	Err      error
+	Duration time.Duration // How long the check took
+}
+
+// OK reports whether the check passed
+func (p PreflightCheck) OK() bool {
+	return p.Err == nil
+}
+
+// Preflight runs a set of cheap startup checks against the configured
+// instance so misconfiguration surfaces before the first tool call.
+// Checks are run in order and later checks are skipped when the instance
+// is unreachable.
+func (c *Client) Preflight(ctx context.Context) []PreflightCheck {
+	reachable := runCheck("reachable", func() error { return c.checkReachable(ctx) })
+	if !reachable.OK() {
+		return []PreflightCheck{reachable}
+	}
+
+	return []PreflightCheck{
+		reachable,
+		runCheck("json_format", func() error { return c.checkJSONFormat(ctx) }),
+	}
+}
+
+// HealthCheck runs a trivial search against the instance, bypassing the
+// cache and the rate limiter, and returns an error unless the instance
+// answers it with JSON results before ctx ends
+func (c *Client) HealthCheck(ctx context.Context) error {
+	if err := c.checkJSONFormat(ctx); err != nil {
+		return fmt.Errorf("health check of %s failed: %w", c.config.BaseURL, err)
+	}
+	return nil
+}
+
+func runCheck(name string, check func() error) PreflightCheck {
+	start := time.Now()
+	err := check()
+	return PreflightCheck{Name: name, Err: err, Duration: time.Since(start)}
+}
+
+// ProbeError is returned by NewClientWithProbe when the instance fails one
+// of its checks. Checks holds every check that ran, passed or not.
+type ProbeError struct {
+	BaseURL string
+	Checks  []PreflightCheck
+}
+
+func (e *ProbeError) Error() string {
+	var failed []string
+	for _, check := range e.Checks {
+		if !check.OK() {
+			failed = append(failed, fmt.Sprintf("%s: %v", check.Name, check.Err))
+		}
+	}
+	return fmt.Sprintf("probe of %s failed: %s", e.BaseURL, strings.Join(failed, "; "))
+}
+
+// Unwrap returns the errors of the failed checks, so errors.Is matches
+// ErrInstanceUnreachable, ErrJSONFormatDisabled and friends
+func (e *ProbeError) Unwrap() []error {
+	var errs []error
+	for _, check := range e.Checks {
+		if !check.OK() {
+			errs = append(errs, check.Err)
+		}
+	}
+	return errs
+}
+
+// NewClientWithProbe creates a client like NewClient and then runs its
+// Preflight checks, so library consumers fail fast on a misconfigured
+// instance. Failed checks are reported as a *ProbeError. Probing is skipped
+// when config.SkipProbe is set.
+func NewClientWithProbe(ctx context.Context, config *Config) (*Client, error) {
+	client, err := NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	if client.config.SkipProbe {
+		return client, nil
+	}
+
+	checks := client.Preflight(ctx)
+	for _, check := range checks {
+		if !check.OK() {
+			return nil, &ProbeError{BaseURL: client.config.BaseURL, Checks: checks}
+		}
+	}
+	return client, nil
+}
+
+// checkReachable verifies that the instance answers HTTP requests at all
+func (c *Client) checkReachable(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.BaseURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInstanceUnreachable, err)
+	}
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInstanceUnreachable, err)
+	}
+	defer httpResp.Body.Close()
+	_, _ = io.Copy(io.Discard, httpResp.Body)
+
+	if httpResp.StatusCode >= 500 {
+		return fmt.Errorf("%w: HTTP %d", ErrInstanceUnreachable, httpResp.StatusCode)
+	}
+	return nil
+}
+
+// checkJSONFormat verifies that the instance returns JSON search results.
+// SearXNG answers 403 when the json format is not enabled in settings.yml.
+func (c *Client) checkJSONFormat(ctx context.Context) error {
+	apiURL, err := c.buildSearchURL(SearchRequest{Query: "searxng"})
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusForbidden {
+		return ErrJSONFormatDisabled
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", httpResp.StatusCode)
+	}
+	if ct := httpResp.Header.Get("Content-Type"); strings.Contains(ct, "text/html") {
+		return ErrJSONFormatDisabled
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if _, err := decodeAPIResponse(normalizeBody(body)); err != nil {
+		return fmt.Errorf("%w: %w", ErrJSONFormatDisabled, err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Preflight(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr map[string]error
+	}{
+		{
+			name: "healthy instance",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/search" {
+					w.Header().Set("Content-Type", "application/json")
+					_ = json.NewEncoder(w).Encode(APIResponse{Query: "searxng"})
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			wantErr: map[string]error{"reachable": nil, "json_format": nil},
+		},
+		{
+			name: "json format disabled",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/search" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			wantErr: map[string]error{"reachable": nil, "json_format": ErrJSONFormatDisabled},
+		},
+		{
+			name: "html instead of json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				_, _ = w.Write([]byte("<html></html>"))
+			},
+			wantErr: map[string]error{"reachable": nil, "json_format": ErrJSONFormatDisabled},
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantErr: map[string]error{"reachable": ErrInstanceUnreachable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			config := DefaultConfig()
+			config.BaseURL = ts.URL
+			client, err := NewClient(config)
+			require.NoError(t, err)
+
+			checks := client.Preflight(context.Background())
+			require.Len(t, checks, len(tt.wantErr))
+			for _, check := range checks {
+				want, ok := tt.wantErr[check.Name]
+				require.True(t, ok, "unexpected check %q", check.Name)
+				if want == nil {
+					assert.True(t, check.OK(), "check %q: %v", check.Name, check.Err)
+				} else {
+					assert.ErrorIs(t, check.Err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Preflight_Unreachable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	baseURL := ts.URL
+	ts.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = baseURL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	checks := client.Preflight(context.Background())
+	require.Len(t, checks, 1)
+	assert.Equal(t, "reachable", checks[0].Name)
+	assert.ErrorIs(t, checks[0].Err, ErrInstanceUnreachable)
+}
+
+func TestNewClientWithProbe(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(APIResponse{Query: "searxng"})
+	}))
+	defer healthy.Close()
+
+	client, err := NewClientWithProbe(context.Background(), &Config{BaseURL: healthy.URL})
+	require.NoError(t, err)
+	assert.Equal(t, healthy.URL, client.BaseURL())
+}
+
+func TestNewClientWithProbe_Failure(t *testing.T) {
+	jsonDisabled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer jsonDisabled.Close()
+
+	_, err := NewClientWithProbe(context.Background(), &Config{BaseURL: jsonDisabled.URL})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrJSONFormatDisabled)
+	assert.Contains(t, err.Error(), "json_format")
+
+	var probeErr *ProbeError
+	require.ErrorAs(t, err, &probeErr)
+	require.Len(t, probeErr.Checks, 2)
+	assert.True(t, probeErr.Checks[0].OK())
+	assert.Positive(t, probeErr.Checks[0].Duration)
+
+	// SkipProbe returns the client without contacting the instance
+	client, err := NewClientWithProbe(context.Background(), &Config{BaseURL: jsonDisabled.URL, SkipProbe: true})
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestClient_HealthCheck(t *testing.T) {
+	healthy := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(APIResponse{Query: "searxng"})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(&Config{BaseURL: ts.URL, Timeout: time.Second, CacheTTL: time.Minute})
+	require.NoError(t, err)
+	require.NoError(t, client.HealthCheck(context.Background()))
+
+	healthy = false
+	err = client.HealthCheck(context.Background())
+	assert.ErrorIs(t, err, ErrJSONFormatDisabled, "the result of the previous check isn't cached")
+	assert.ErrorContains(t, err, "health check of "+ts.URL)
+}
@@ -0,0 +1,198 @@
+package searxng
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RankStrategy selects how results are reordered after a search
+type RankStrategy string
+
+// Ranking strategies
+const (
+	// RankInstance keeps the order of the instance
+	RankInstance RankStrategy = ""
+	// RankScore orders results by the instance's score
+	RankScore RankStrategy = "score"
+	// RankConsensus orders results by the number of engines that found
+	// them, then by score
+	RankConsensus RankStrategy = "consensus"
+	// RankRecency orders results by published date, undated ones last
+	RankRecency RankStrategy = "recency"
+	// RankWeighted orders results by a weighted combination of score,
+	// engine consensus, recency and domain reputation (see RankWeights)
+	RankWeighted RankStrategy = "weighted"
+)
+
+// RankStrategies lists the strategies accepted by ParseRankStrategy
+var RankStrategies = []RankStrategy{RankScore, RankConsensus, RankRecency, RankWeighted}
+
+// DefaultRecencyHalfLife is the age at which a result's recency signal is
+// halved
+const DefaultRecencyHalfLife = 30 * 24 * time.Hour
+
+// RankWeights are the weights of the signals combined by RankWeighted.
+// Each signal is normalized to [0, 1] (domain reputation to [-1, 1])
+// before weighting.
+type RankWeights struct {
+	Score   float64 // instance score, relative to the best result
+	Engines float64 // engines that found the result, relative to the most found one
+	Recency float64 // halves every RecencyHalfLife; undated results get 0
+	Domain  float64 // 1 for trusted domains, -1 for distrusted ones
+}
+
+// DefaultRankWeights are the weights used when Config.RankWeights is zero
+var DefaultRankWeights = RankWeights{Score: 1, Engines: 0.5, Recency: 0.25, Domain: 1}
+
+// ParseRankStrategy validates a strategy name ("" or "instance": the
+// instance's order)
+func ParseRankStrategy(name string) (RankStrategy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "instance" {
+		return RankInstance, nil
+	}
+	if strategy := RankStrategy(name); slices.Contains(RankStrategies, strategy) {
+		return strategy, nil
+	}
+	return "", fmt.Errorf("invalid ranking strategy %q (must be one of: score, consensus, recency, weighted)", name)
+}
+
+// ranker reorders results according to a strategy
+type ranker struct {
+	strategy   RankStrategy
+	weights    RankWeights
+	halfLife   time.Duration
+	trusted    []string
+	distrusted []string
+	now        func() time.Time
+}
+
+// ranker returns the ranker for req, whose RankBy overrides the configured
+// strategy
+func (c *Client) ranker(req SearchRequest) ranker {
+	r := ranker{
+		strategy:   c.config.RankBy,
+		weights:    c.config.RankWeights,
+		halfLife:   c.config.RecencyHalfLife,
+		trusted:    c.config.TrustedDomains,
+		distrusted: c.config.DistrustedDomains,
+		now:        c.clock.Now,
+	}
+	if req.RankBy != RankInstance {
+		r.strategy = req.RankBy
+	}
+	if r.weights == (RankWeights{}) {
+		r.weights = DefaultRankWeights
+	}
+	if r.halfLife <= 0 {
+		r.halfLife = DefaultRecencyHalfLife
+	}
+	return r
+}
+
+// rank returns a copy of resp with its results reordered, keeping the
+// original order for equal ranks
+func (r ranker) rank(resp *SearchResponse) *SearchResponse {
+	if r.strategy == RankInstance || len(resp.Results) < 2 {
+		return resp
+	}
+
+	results := resp.Results
+	var maxScore float64
+	maxEngines := 1
+	for _, result := range results {
+		maxScore = max(maxScore, result.Score)
+		maxEngines = max(maxEngines, len(resultEngines(result)))
+	}
+	now := r.now()
+	keys := make([][2]float64, len(results))
+	for i, result := range results {
+		score := 0.0
+		if maxScore > 0 {
+			score = result.Score / maxScore
+		}
+		consensus := float64(len(resultEngines(result))) / float64(maxEngines)
+		recency := r.recency(result, now)
+
+		switch r.strategy {
+		case RankScore:
+			keys[i] = [2]float64{score, 0}
+		case RankConsensus:
+			keys[i] = [2]float64{consensus, score}
+		case RankRecency:
+			keys[i] = [2]float64{recency, score}
+		default:
+			keys[i] = [2]float64{
+				r.weights.Score*score + r.weights.Engines*consensus +
+					r.weights.Recency*recency + r.weights.Domain*r.reputation(result.URL),
+				0,
+			}
+		}
+	}
+
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		ka, kb := keys[a], keys[b]
+		for i := range ka {
+			switch {
+			case ka[i] > kb[i]:
+				return -1
+			case ka[i] < kb[i]:
+				return 1
+			}
+		}
+		return 0
+	})
+
+	ranked := *resp
+	ranked.Results = make([]SearchResult, len(results))
+	for i, j := range order {
+		ranked.Results[i] = results[j]
+	}
+	return &ranked
+}
+
+// recency returns 1 for a result published now, halving every halfLife,
+// and 0 for undated results
+func (r ranker) recency(result SearchResult, now time.Time) float64 {
+	if result.PublishedDate == nil {
+		return 0
+	}
+	age := max(now.Sub(*result.PublishedDate), 0)
+	return math.Exp2(-float64(age) / float64(r.halfLife))
+}
+
+// reputation returns 1 when rawURL is on a trusted domain, -1 when it is on
+// a distrusted one and 0 otherwise. Subdomains match their parents.
+func (r ranker) reputation(rawURL string) float64 {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	host := strings.ToLower(parsed.Hostname())
+	switch {
+	case matchesDomain(host, r.distrusted):
+		return -1
+	case matchesDomain(host, r.trusted):
+		return 1
+	}
+	return 0
+}
+
+// matchesDomain reports whether host is one of domains or a subdomain of one
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "."))
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package searxng
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func titles(resp *SearchResponse) []string {
+	var out []string
+	for _, result := range resp.Results {
+		out = append(out, result.Title)
+	}
+	return out
+}
+
+func TestRanker_Strategies(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) *time.Time {
+		d := now.AddDate(0, 0, -n)
+		return &d
+	}
+	resp := &SearchResponse{Query: "q", Results: []SearchResult{
+		{URL: "https://spam.example.net/a", Title: "A", Score: 4, Engines: []string{"google"}},
+		{URL: "https://docs.example.org/b", Title: "B", Score: 2, Engines: []string{"google", "bing", "brave"}, PublishedDate: day(300)},
+		{URL: "https://example.com/c", Title: "C", Score: 1, Engine: "bing", PublishedDate: day(1)},
+		{URL: "https://example.com/d", Title: "D", Score: 3, Engines: []string{"google", "bing"}},
+	}}
+
+	tests := []struct {
+		strategy RankStrategy
+		want     []string
+	}{
+		{RankInstance, []string{"A", "B", "C", "D"}},
+		{RankScore, []string{"A", "D", "B", "C"}},
+		{RankConsensus, []string{"B", "D", "A", "C"}},
+		{RankRecency, []string{"C", "B", "A", "D"}},
+		{RankWeighted, []string{"B", "D", "C", "A"}},
+	}
+	for _, tt := range tests {
+		r := ranker{
+			strategy:   tt.strategy,
+			weights:    DefaultRankWeights,
+			halfLife:   DefaultRecencyHalfLife,
+			trusted:    []string{"example.org"},
+			distrusted: []string{"spam.example.net"},
+			now:        func() time.Time { return now },
+		}
+		assert.Equal(t, tt.want, titles(r.rank(resp)), string(tt.strategy))
+	}
+	assert.Equal(t, []string{"A", "B", "C", "D"}, titles(resp), "input must not be modified")
+}
+
+func TestRanker_Reputation(t *testing.T) {
+	r := ranker{trusted: []string{"Go.dev", ".example.org"}, distrusted: []string{"bad.example.org"}}
+	assert.Equal(t, 1.0, r.reputation("https://go.dev/doc"))
+	assert.Equal(t, 1.0, r.reputation("https://pkg.go.dev/net/http"))
+	assert.Equal(t, 1.0, r.reputation("https://example.org"))
+	assert.Equal(t, -1.0, r.reputation("https://www.bad.example.org/x"), "distrust wins over a trusted parent")
+	assert.Equal(t, 0.0, r.reputation("https://notgo.dev"))
+	assert.Equal(t, 0.0, r.reputation("://broken"))
+}
+
+func TestParseRankStrategy(t *testing.T) {
+	for name, want := range map[string]RankStrategy{
+		"":          RankInstance,
+		"instance":  RankInstance,
+		"Recency":   RankRecency,
+		" weighted": RankWeighted,
+	} {
+		got, err := ParseRankStrategy(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := ParseRankStrategy("random")
+	assert.ErrorContains(t, err, "invalid ranking strategy")
+}
+
+func TestClient_Search_RankBy(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(2).
+		Reply(200).
+		JSON(APIResponse{Query: "q", Results: []APIResult{
+			{URL: "https://example.com/a", Title: "A", Score: 1, Engines: []string{"google", "bing"}},
+			{URL: "https://example.com/b", Title: "B", Score: 2, Engines: []string{"google"}},
+		}})
+
+	config := DefaultConfig()
+	config.RankBy = RankScore
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	resp, err := client.Search(ctx, SearchRequest{Query: "q"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B", "A"}, titles(resp), "configured strategy")
+
+	resp, err = client.Search(ctx, SearchRequest{Query: "q", RankBy: RankConsensus})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, titles(resp), "request overrides the configured strategy")
+}
@@ -0,0 +1,193 @@
+package searxng
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// rateLimiter is a token bucket implemented with the generic cell rate
+// algorithm. Its whole state is the theoretical arrival time (TAT) of the
+// next token, updated with compare-and-swap, so concurrent callers never
+// contend on a lock. Callers that have to wait reserve their slot up front
+// and sleep exactly until it instead of polling, which keeps them in
+// arrival order. Times are nanoseconds on the clock since start, monotonic
+// with the real clock.
+type rateLimiter struct {
+	maxTokens  int
+	refillRate time.Duration // time to add one token
+	clock      clock
+	start      time.Time
+	tat        atomic.Int64
+
+	waits     atomic.Uint64 // calls that had to wait for a token
+	waitNanos atomic.Int64  // total time spent waiting
+}
+
+// newRateLimiter creates a new rate limiter
+// clk: the clock it waits on
+// maxTokens: maximum number of tokens
+// refillRate: time to add one token (<= 0: unlimited)
+func newRateLimiter(clk clock, maxTokens int, refillRate time.Duration) *rateLimiter {
+	return &rateLimiter{
+		maxTokens:  maxTokens,
+		refillRate: refillRate,
+		clock:      clk,
+		start:      clk.Now(),
+	}
+}
+
+// unlimited reports whether every request is let through without taking a
+// token
+func (rl *rateLimiter) unlimited() bool {
+	return rl.refillRate <= 0
+}
+
+func (rl *rateLimiter) now() int64 {
+	return int64(rl.clock.Now().Sub(rl.start))
+}
+
+// reserve takes a token and returns how long the caller has to wait before
+// using it, and the TAT it set
+func (rl *rateLimiter) reserve() (time.Duration, int64) {
+	if rl.unlimited() {
+		return 0, 0
+	}
+	interval := int64(rl.refillRate)
+	burst := int64(rl.maxTokens-1) * interval
+	for {
+		now := rl.now()
+		tat := rl.tat.Load()
+		next := max(tat, now) + interval
+		if rl.tat.CompareAndSwap(tat, next) {
+			return time.Duration(max(tat-burst-now, 0)), next
+		}
+	}
+}
+
+// release returns a reserved token, unless later callers have reserved
+// tokens since, in which case it is lost
+func (rl *rateLimiter) release(tat int64) {
+	rl.tat.CompareAndSwap(tat, tat-int64(rl.refillRate))
+}
+
+// wait waits until a token is available. It fails without waiting when the
+// token would only be available after the context deadline.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	delay, tat := rl.reserve()
+	if delay == 0 {
+		return nil
+	}
+	if exceedsDeadline(ctx, rl.clock, delay) {
+		rl.release(tat)
+		return fmt.Errorf("rate limiter wait of %s would exceed the deadline: %w", delay, context.DeadlineExceeded)
+	}
+
+	rl.waits.Add(1)
+	start := rl.clock.Now()
+	defer func() { rl.waitNanos.Add(int64(rl.clock.Now().Sub(start))) }()
+
+	if err := sleep(ctx, rl.clock, delay); err != nil {
+		rl.release(tat)
+		return err
+	}
+	return nil
+}
+
+// available returns the number of tokens currently available
+func (rl *rateLimiter) available() int {
+	if rl.unlimited() {
+		return rl.maxTokens
+	}
+	interval := int64(rl.refillRate)
+	tokens := (rl.now() + int64(rl.maxTokens)*interval - rl.tat.Load()) / interval
+	return int(min(max(tokens, 0), int64(rl.maxTokens)))
+}
+
+// fullAt returns when the bucket will be full again, or the zero time when
+// it is full
+func (rl *rateLimiter) fullAt() time.Time {
+	tat := rl.tat.Load()
+	if tat <= rl.now() {
+		return time.Time{}
+	}
+	return rl.start.Add(time.Duration(tat)).Round(0)
+}
+
+// restore drains the bucket so it is full again at fullAt, e.g. the value
+// of fullAt before a restart. It never refills the bucket, and it drains it
+// at most completely, whatever the clock says.
+func (rl *rateLimiter) restore(fullAt time.Time) {
+	if fullAt.IsZero() || rl.unlimited() {
+		return
+	}
+	now := rl.now()
+	tat := min(int64(fullAt.Sub(rl.start)), now+int64(rl.maxTokens)*int64(rl.refillRate))
+	for {
+		current := rl.tat.Load()
+		if tat <= current || rl.tat.CompareAndSwap(current, tat) {
+			return
+		}
+	}
+}
+
+// RateLimiter limits a class of requests made outside the client, e.g. page
+// reads, independently of the searches' limiter
+type RateLimiter struct {
+	limiter *rateLimiter
+}
+
+// NewRateLimiter allows perSecond requests per second on average, with
+// bursts of up to burst requests (burst <= 0: perSecond). With perSecond <= 0
+// there is no limit: Wait returns right away.
+func NewRateLimiter(perSecond, burst int) *RateLimiter {
+	return &RateLimiter{limiter: newBurstRateLimiter(realClock{}, perSecond, burst)}
+}
+
+// newBurstRateLimiter creates a rate limiter from a rate per second and a
+// burst size (burst <= 0: perSecond). It is unlimited when perSecond <= 0.
+func newBurstRateLimiter(clk clock, perSecond, burst int) *rateLimiter {
+	if perSecond <= 0 {
+		return newRateLimiter(clk, max(burst, 0), 0)
+	}
+	if burst <= 0 {
+		burst = perSecond
+	}
+	return newRateLimiter(clk, burst, time.Second/time.Duration(perSecond))
+}
+
+// Wait waits until a request may be made (see rateLimiter.wait)
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return l.limiter.wait(ctx)
+}
+
+// FullAt returns when the limiter will have its whole burst available
+// again, or the zero time when it has, for persisting with Restore
+func (l *RateLimiter) FullAt() time.Time {
+	return l.limiter.fullAt()
+}
+
+// Restore drains the limiter as it was when FullAt returned fullAt, so a
+// restart doesn't refill it
+func (l *RateLimiter) Restore(fullAt time.Time) {
+	l.limiter.restore(fullAt)
+}
+
+// Stats returns the number of requests that had to wait, the total time
+// spent waiting and the tokens currently available
+func (l *RateLimiter) Stats() (waits uint64, waitTime time.Duration, tokens int) {
+	return l.limiter.waits.Load(), time.Duration(l.limiter.waitNanos.Load()), l.limiter.available()
+}
+
+// RateLimitFullAt returns when the searches' rate limiter will have its
+// whole burst available again (see RateLimiter.FullAt)
+func (c *Client) RateLimitFullAt() time.Time {
+	return c.rateLimiter.fullAt()
+}
+
+// RestoreRateLimit drains the searches' rate limiter as it was when
+// RateLimitFullAt returned fullAt (see RateLimiter.Restore)
+func (c *Client) RestoreRateLimit(fullAt time.Time) {
+	c.rateLimiter.restore(fullAt)
+}
@@ -0,0 +1,206 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Burst(t *testing.T) {
+	rl := newRateLimiter(newFakeClock(), 3, time.Hour)
+	assert.Equal(t, 3, rl.available())
+
+	for i := range 3 {
+		delay, _ := rl.reserve()
+		assert.Zero(t, delay, "token %d is part of the burst", i)
+	}
+	assert.Equal(t, 0, rl.available())
+
+	delay, _ := rl.reserve()
+	assert.InDelta(t, time.Hour, delay, float64(time.Second))
+	delay, _ = rl.reserve()
+	assert.InDelta(t, 2*time.Hour, delay, float64(time.Second), "reservations queue up")
+}
+
+func TestRateLimiter_Refill(t *testing.T) {
+	clk := newFakeClock()
+	rl := newRateLimiter(clk, 2, 10*time.Millisecond)
+	rl.reserve()
+	rl.reserve()
+	clk.Advance(5 * time.Millisecond)
+	assert.Equal(t, 0, rl.available())
+	clk.Advance(20 * time.Millisecond)
+
+	// Partial refills aren't lost: 25ms buy two tokens, capped at the burst
+	assert.Equal(t, 2, rl.available())
+}
+
+func TestRateLimiter_Concurrent(t *testing.T) {
+	const callers = 200
+	clk := newFakeClock()
+	rl := newRateLimiter(clk, 10, time.Millisecond)
+
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rl.wait(context.Background()) != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	// Beyond the burst of 10, each token takes 1ms: the last caller waits
+	// for 190ms
+	clk.waitPending(t, callers-10)
+	clk.Advance((callers - 11) * time.Millisecond)
+	assert.Equal(t, 1, clk.pending())
+	clk.Advance(time.Millisecond)
+	wg.Wait()
+
+	assert.Zero(t, failed.Load())
+	assert.Equal(t, uint64(callers-10), rl.waits.Load())
+}
+
+func TestRateLimiter_DeadlineFailsFast(t *testing.T) {
+	clk := newFakeClock()
+	rl := newRateLimiter(clk, 1, time.Hour)
+	require.NoError(t, rl.wait(context.Background()))
+
+	ctx, cancel := context.WithDeadline(context.Background(), clk.Now().Add(time.Second))
+	defer cancel()
+	err := rl.wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, clk.pending(), "returned without waiting")
+
+	// The failed call gave its reservation back
+	delay, _ := rl.reserve()
+	assert.InDelta(t, time.Hour, delay, float64(time.Second))
+}
+
+func TestRateLimiter_CancelReleases(t *testing.T) {
+	clk := newFakeClock()
+	rl := newRateLimiter(clk, 1, 50*time.Millisecond)
+	require.NoError(t, rl.wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		clk.waitPending(t, 1)
+		clk.Advance(5 * time.Millisecond)
+		cancel()
+	}()
+	assert.ErrorIs(t, rl.wait(ctx), context.Canceled)
+	assert.Equal(t, uint64(1), rl.waits.Load())
+
+	delay, _ := rl.reserve()
+	assert.Equal(t, 45*time.Millisecond, delay, "the reservation is given back")
+}
+
+func BenchmarkRateLimiter_Wait(b *testing.B) {
+	// A limit that never blocks, so the benchmark measures the hot path
+	rl := newRateLimiter(realClock{}, 1<<30, time.Nanosecond)
+	ctx := context.Background()
+
+	b.SetParallelism(128)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = rl.wait(ctx)
+		}
+	})
+}
+
+func BenchmarkClient_SearchConcurrent(b *testing.B) {
+	payload, err := json.Marshal(APIResponse{Query: "q", Results: []APIResult{{URL: "https://example.com", Title: "Example"}}})
+	require.NoError(b, err)
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(payload)
+	}))
+	defer instance.Close()
+
+	client, err := NewClient(&Config{
+		BaseURL:             instance.URL,
+		RateLimit:           1_000_000,
+		MaxIdleConnsPerHost: 128,
+	})
+	require.NoError(b, err)
+
+	var n atomic.Int64
+	b.SetParallelism(128)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		ctx := context.Background()
+		for pb.Next() {
+			query := fmt.Sprintf("q%d", n.Add(1))
+			if _, err := client.Search(ctx, SearchRequest{Query: query}); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "searches/s")
+}
+
+func TestRateLimiter_Restore(t *testing.T) {
+	clk := newFakeClock()
+	rl := newRateLimiter(clk, 3, time.Hour)
+	assert.True(t, rl.fullAt().IsZero(), "a full bucket has nothing to persist")
+	rl.reserve()
+	rl.reserve()
+	fullAt := rl.fullAt()
+	assert.WithinDuration(t, clk.Now().Add(2*time.Hour), fullAt, time.Millisecond)
+
+	// A new process starts with a full bucket until the state is restored
+	restarted := newRateLimiter(clk, 3, time.Hour)
+	restarted.restore(fullAt)
+	assert.Equal(t, 1, restarted.available())
+	assert.WithinDuration(t, fullAt, restarted.fullAt(), time.Millisecond)
+
+	restarted.restore(clk.Now().Add(-time.Hour))
+	assert.Equal(t, 1, restarted.available(), "restoring never refills")
+	restarted.restore(clk.Now().Add(24 * time.Hour))
+	assert.Equal(t, 0, restarted.available())
+	assert.WithinDuration(t, clk.Now().Add(3*time.Hour), restarted.fullAt(), time.Millisecond, "drained at most completely")
+}
+
+func TestNewRateLimiter_Burst(t *testing.T) {
+	rl := NewRateLimiter(1, 3)
+	for i := range 3 {
+		require.NoError(t, rl.Wait(context.Background()), "request %d is part of the burst", i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, rl.Wait(ctx), context.DeadlineExceeded)
+
+	waits, _, tokens := rl.Stats()
+	assert.Zero(t, waits, "the failed wait returned before waiting")
+	assert.Zero(t, tokens)
+
+	assert.Equal(t, 5, NewRateLimiter(5, 0).limiter.maxTokens, "the burst defaults to the rate")
+}
+
+func TestNewRateLimiter_Unlimited(t *testing.T) {
+	for _, perSecond := range []int{0, -1} {
+		rl := NewRateLimiter(perSecond, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		for range 100 {
+			require.NoError(t, rl.Wait(ctx), "rate %d", perSecond)
+		}
+		cancel()
+
+		rl.Restore(time.Now().Add(time.Hour))
+		assert.True(t, rl.FullAt().IsZero())
+		waits, _, _ := rl.Stats()
+		assert.Zero(t, waits)
+	}
+}
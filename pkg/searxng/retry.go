@@ -0,0 +1,183 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RetryPolicy sets the delays between retries of failed searches. Delays
+// grow exponentially from InitialBackoff and are randomly shortened by up
+// to Jitter, so clients failing together don't retry in lockstep. 429 and
+// 503 responses with a Retry-After header are retried after the requested
+// delay instead.
+type RetryPolicy struct {
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries (0: uncapped)
+	MaxBackoff time.Duration
+
+	// Multiplier grows the delay after each retry (below 1: constant
+	// delays)
+	Multiplier float64
+
+	// Jitter is the fraction, between 0 and 1, by which delays are at most
+	// randomly shortened
+	Jitter float64
+
+	// MaxRetryAfter is the longest Retry-After delay waited for; responses
+	// asking for longer fail the search without retrying (0: no limit)
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of configs without one: 1s, 2s,
+// 4s... up to 30s, shortened by up to 20%
+var DefaultRetryPolicy = RetryPolicy{
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	MaxRetryAfter:  time.Minute,
+}
+
+// backoff returns the delay before the given retry attempt (1 for the
+// first retry); random returns numbers in [0, 1)
+func (p RetryPolicy) backoff(attempt int, random func() float64) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(max(p.Multiplier, 1), float64(attempt-1))
+	if p.MaxBackoff > 0 {
+		delay = min(delay, float64(p.MaxBackoff))
+	}
+	delay -= delay * min(max(p.Jitter, 0), 1) * random()
+	return time.Duration(delay)
+}
+
+// HTTPError is a search answered with an unexpected status code
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// retryAfterError is a 429 or 503 response asking to retry after delay
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// withRetryAfter attaches the Retry-After delay of a 429 or 503 response to
+// err, the error it caused. Retry-After dates are relative to now.
+func withRetryAfter(err error, httpResp *http.Response, now time.Time) error {
+	if httpResp.StatusCode != http.StatusTooManyRequests && httpResp.StatusCode != http.StatusServiceUnavailable {
+		return err
+	}
+	delay, ok := parseRetryAfter(httpResp.Header.Get("Retry-After"), now)
+	if !ok {
+		return err
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// parseRetryAfter parses a Retry-After header, either a number of seconds
+// or an HTTP date. Dates in the past mean no delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// permanent reports whether err is a failure no retry can fix: a canceled
+// or expired context or a certificate pin mismatch. The search returns it
+// as is.
+func permanent(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrPinMismatch)
+}
+
+// retryable reports whether a search that failed with err is worth
+// retrying. 4xx responses other than the bot limiter are not: the same
+// request gets the same answer.
+func retryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 {
+		return false
+	}
+	return !permanent(err)
+}
+
+// retryPolicy returns the configured retry policy, DefaultRetryPolicy when
+// the config has none
+func (c *Client) retryPolicy() RetryPolicy {
+	if c.config.Retry == (RetryPolicy{}) {
+		return DefaultRetryPolicy
+	}
+	return c.config.Retry
+}
+
+// retryDelay returns how long to wait before the given retry attempt after
+// lastErr, and false when the instance asked to wait longer than the policy
+// allows. Limiter responses back off for longer, since retrying quickly
+// only extends the block.
+func (c *Client) retryDelay(attempt int, lastErr error) (time.Duration, bool) {
+	policy := c.retryPolicy()
+	var retryAfter *retryAfterError
+	if errors.As(lastErr, &retryAfter) {
+		return retryAfter.delay, policy.MaxRetryAfter <= 0 || retryAfter.delay <= policy.MaxRetryAfter
+	}
+	if errors.Is(lastErr, ErrInstanceLimited) {
+		backoff := c.config.LimiterBackoff
+		if backoff <= 0 {
+			backoff = DefaultLimiterBackoff
+		}
+		return time.Duration(attempt) * backoff, true
+	}
+	return policy.backoff(attempt, rand.Float64), true
+}
+
+// waitRetry waits before the given retry attempt after lastErr. It fails
+// when the search should rather give up: the context ends first or the
+// instance asked to wait too long.
+func (c *Client) waitRetry(ctx context.Context, attempt int, lastErr error) error {
+	delay, ok := c.retryDelay(attempt, lastErr)
+	if !ok {
+		return fmt.Errorf("the instance asked to retry after %s", delay)
+	}
+	if exceedsDeadline(ctx, c.clock, delay) {
+		return fmt.Errorf("retrying after %s would exceed the deadline: %w", delay, context.DeadlineExceeded)
+	}
+
+	c.stats.retries.Add(1)
+	log.WithFields(logrus.Fields{"attempt": attempt, "delay": delay}).Debug("retrying search request")
+	_, span := tracer().Start(ctx, "searxng.retry_wait", trace.WithAttributes(
+		attribute.Int("searxng.attempt", attempt),
+		attribute.String("searxng.retry_delay", delay.String()),
+	))
+	defer span.End()
+	return sleep(ctx, c.clock, delay)
+}
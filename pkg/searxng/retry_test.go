@@ -0,0 +1,145 @@
+package searxng
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	none := func() float64 { return 0 }
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	var delays []time.Duration
+	for attempt := 1; attempt <= 5; attempt++ {
+		delays = append(delays, policy.backoff(attempt, none))
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, delays)
+
+	policy.Jitter = 0.5
+	assert.Equal(t, 3*time.Second, policy.backoff(3, func() float64 { return 0.5 }), "4s shortened by 25%")
+
+	constant := RetryPolicy{InitialBackoff: time.Second}
+	assert.Equal(t, time.Second, constant.backoff(4, none))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Wed, 01 May 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 May 2024 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+}
+
+func TestClient_RetryDelay_Policy(t *testing.T) {
+	client, err := NewClient(&Config{BaseURL: "https://searxng.example.com"})
+	require.NoError(t, err)
+	delay, ok := client.retryDelay(3, assert.AnError)
+	assert.True(t, ok)
+	assert.InDelta(t, 4*time.Second, delay, float64(time.Second), "the default policy doubles from 1s, shortened by up to 20%")
+
+	delay, ok = client.retryDelay(1, &retryAfterError{err: assert.AnError, delay: 10 * time.Second})
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, delay)
+	_, ok = client.retryDelay(1, &retryAfterError{err: assert.AnError, delay: time.Hour})
+	assert.False(t, ok, "longer than MaxRetryAfter")
+}
+
+// newRetryInstance answers searches with the given statuses in turn, then
+// with results, and counts the requests
+func newRetryInstance(requests *atomic.Int32, retryAfter string, statuses ...int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[n-1])
+			_, _ = w.Write([]byte("failed"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query": "q", "results": []}`))
+	}))
+}
+
+func TestClient_Search_RetryPolicy(t *testing.T) {
+	fast := RetryPolicy{InitialBackoff: time.Millisecond, Multiplier: 2, MaxRetryAfter: time.Second}
+	search := func(instance *httptest.Server) error {
+		client, err := NewClient(&Config{BaseURL: instance.URL, MaxRetries: 2, Retry: fast})
+		require.NoError(t, err)
+		_, err = client.Search(context.Background(), SearchRequest{Query: "q"})
+		return err
+	}
+
+	t.Run("5xx are retried", func(t *testing.T) {
+		var requests atomic.Int32
+		instance := newRetryInstance(&requests, "", http.StatusBadGateway, http.StatusInternalServerError)
+		defer instance.Close()
+		assert.NoError(t, search(instance))
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("4xx are not retried", func(t *testing.T) {
+		var requests atomic.Int32
+		instance := newRetryInstance(&requests, "", http.StatusBadRequest)
+		defer instance.Close()
+		err := search(instance)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrRequestFailed)
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("Retry-After is honored", func(t *testing.T) {
+		var requests atomic.Int32
+		instance := newRetryInstance(&requests, "1", http.StatusServiceUnavailable)
+		defer instance.Close()
+		clk := newFakeClock()
+		client, err := newClient(&Config{BaseURL: instance.URL, MaxRetries: 2, Retry: fast}, clk)
+		require.NoError(t, err)
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.Search(context.Background(), SearchRequest{Query: "q"})
+			done <- err
+		}()
+
+		clk.waitPending(t, 1)
+		clk.Advance(time.Second - time.Millisecond)
+		assert.Equal(t, int32(1), requests.Load(), "waiting for the Retry-After")
+		clk.Advance(time.Millisecond)
+		assert.NoError(t, <-done)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("long Retry-After gives up", func(t *testing.T) {
+		var requests atomic.Int32
+		instance := newRetryInstance(&requests, "3600", http.StatusTooManyRequests)
+		defer instance.Close()
+		err := search(instance)
+		assert.ErrorIs(t, err, ErrInstanceLimited)
+		assert.ErrorContains(t, err, "not retried: the instance asked to retry after 1h0m0s")
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
@@ -0,0 +1,61 @@
+package searxng
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SafeSearch is the safe search level of a search, sent to the instance as
+// its safesearch parameter
+type SafeSearch int
+
+// Safe search levels, from the least to the most strict
+const (
+	// SafeSearchInstance leaves the level to the instance's default
+	// (search.safe_search in its settings)
+	SafeSearchInstance SafeSearch = iota
+	// SafeSearchOff disables filtering (safesearch=0)
+	SafeSearchOff
+	// SafeSearchModerate filters explicit results (safesearch=1)
+	SafeSearchModerate
+	// SafeSearchStrict filters explicit and suggestive results
+	// (safesearch=2)
+	SafeSearchStrict
+)
+
+// SafeSearchLevels lists the level names accepted by ParseSafeSearch
+var SafeSearchLevels = []string{"off", "moderate", "strict"}
+
+// ParseSafeSearch validates a level name, also accepting the instance's
+// values 0, 1 and 2 ("" or "instance": the instance's default)
+func ParseSafeSearch(name string) (SafeSearch, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "instance":
+		return SafeSearchInstance, nil
+	case "off", "0":
+		return SafeSearchOff, nil
+	case "moderate", "1":
+		return SafeSearchModerate, nil
+	case "strict", "2":
+		return SafeSearchStrict, nil
+	}
+	return SafeSearchInstance, fmt.Errorf("invalid safe search level %q (must be one of: %s)", name, strings.Join(SafeSearchLevels, ", "))
+}
+
+// String returns the name of the level
+func (s SafeSearch) String() string {
+	if s > SafeSearchInstance && int(s) <= len(SafeSearchLevels) {
+		return SafeSearchLevels[s-1]
+	}
+	return "instance"
+}
+
+// param returns the safesearch parameter of the level, "" for
+// SafeSearchInstance
+func (s SafeSearch) param() string {
+	if s <= SafeSearchInstance || s > SafeSearchStrict {
+		return ""
+	}
+	return strconv.Itoa(int(s) - 1)
+}
@@ -0,0 +1,55 @@
+package searxng
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSafeSearch(t *testing.T) {
+	for name, want := range map[string]SafeSearch{
+		"":         SafeSearchInstance,
+		"instance": SafeSearchInstance,
+		"off":      SafeSearchOff,
+		"0":        SafeSearchOff,
+		"Moderate": SafeSearchModerate,
+		" strict":  SafeSearchStrict,
+		"2":        SafeSearchStrict,
+	} {
+		got, err := ParseSafeSearch(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := ParseSafeSearch("3")
+	assert.ErrorContains(t, err, "invalid safe search level")
+
+	assert.Equal(t, "strict", SafeSearchStrict.String())
+	assert.Equal(t, "instance", SafeSearchInstance.String())
+}
+
+func TestClient_Search_SafeSearch(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, set := r.URL.Query()["safesearch"]
+		if set {
+			got = append(got, r.URL.Query().Get("safesearch"))
+		} else {
+			got = append(got, "unset")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query": "go", "results": []}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	require.NoError(t, err)
+	for _, level := range []SafeSearch{SafeSearchInstance, SafeSearchOff, SafeSearchModerate, SafeSearchStrict} {
+		_, err := client.Search(context.Background(), SearchRequest{Query: "go", SafeSearch: level})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"unset", "0", "1", "2"}, got)
+}
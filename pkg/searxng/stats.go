@@ -0,0 +1,101 @@
+package searxng
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ClientStats is a point-in-time snapshot of client counters. Counters are
+// monotonic for the lifetime of the client, so they can be exported as
+// Prometheus-style counters by embedders.
+type ClientStats struct {
+	// Searches is the number of Search/SearchJSON calls
+	Searches uint64
+	// Requests is the number of HTTP requests sent to the instance, including retries
+	Requests uint64
+	// Retries is the number of retried search requests
+	Retries uint64
+	// Failures is the number of searches that returned an error
+	Failures uint64
+	// RateLimitWaits is the number of searches that had to wait for a rate limiter token
+	RateLimitWaits uint64
+	// RateLimitWaitTime is the total time spent waiting for rate limiter tokens
+	RateLimitWaitTime time.Duration
+	// RateLimitTokens is the number of rate limiter tokens currently available
+	RateLimitTokens int
+	// CacheHits is the number of searches served from the response cache
+	CacheHits uint64
+	// CacheMisses is the number of cacheable searches not found in the cache
+	CacheMisses uint64
+	// CacheEvictions is the number of responses evicted to make room
+	CacheEvictions uint64
+	// CacheEntries is the number of responses currently cached
+	CacheEntries int
+	// EngineResults is the number of results each engine returned, counted
+	// on responses fetched from the instance (not on cache hits)
+	EngineResults map[string]uint64
+	// EngineUniqueResults is the number of results only that engine returned
+	EngineUniqueResults map[string]uint64
+}
+
+// clientStats holds the live counters behind ClientStats
+type clientStats struct {
+	searches atomic.Uint64
+	requests atomic.Uint64
+	retries  atomic.Uint64
+	failures atomic.Uint64
+
+	enginesMu     sync.Mutex
+	engineResults map[string]uint64
+	engineUnique  map[string]uint64
+}
+
+// recordEngines counts the results of resp each engine contributed
+func (s *clientStats) recordEngines(resp *SearchResponse) {
+	s.enginesMu.Lock()
+	defer s.enginesMu.Unlock()
+	if s.engineResults == nil {
+		s.engineResults = map[string]uint64{}
+		s.engineUnique = map[string]uint64{}
+	}
+	for _, result := range resp.Results {
+		engines := resultEngines(result)
+		for _, engine := range engines {
+			s.engineResults[engine]++
+		}
+		if len(engines) == 1 {
+			s.engineUnique[engines[0]]++
+		}
+	}
+}
+
+// engines returns copies of the per-engine counters
+func (s *clientStats) engines() (results, unique map[string]uint64) {
+	s.enginesMu.Lock()
+	defer s.enginesMu.Unlock()
+	return maps.Clone(s.engineResults), maps.Clone(s.engineUnique)
+}
+
+// Stats returns a snapshot of the client's counters. It is safe for
+// concurrent use.
+func (c *Client) Stats() ClientStats {
+	stats := ClientStats{
+		Searches:          c.stats.searches.Load(),
+		Requests:          c.stats.requests.Load(),
+		Retries:           c.stats.retries.Load(),
+		Failures:          c.stats.failures.Load(),
+		RateLimitWaits:    c.rateLimiter.waits.Load(),
+		RateLimitWaitTime: time.Duration(c.rateLimiter.waitNanos.Load()),
+		RateLimitTokens:   c.rateLimiter.available(),
+	}
+	stats.EngineResults, stats.EngineUniqueResults = c.stats.engines()
+	if c.cache != nil {
+		stats.CacheHits = c.cache.hits.Load()
+		stats.CacheMisses = c.cache.misses.Load()
+		stats.CacheEvictions = c.cache.evictions.Load()
+		stats.CacheEntries = c.cache.len()
+	}
+	return stats
+}
@@ -0,0 +1,111 @@
+package searxng
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans of the client
+const tracerName = "github.com/denysvitali/searxng-mcp/pkg/searxng"
+
+// tracer returns the tracer of the global TracerProvider (see
+// otel.SetTracerProvider); without one, spans are no-ops
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startSearchSpan starts the span of a search. The query is left out, it
+// may be sensitive.
+func (c *Client) startSearchSpan(ctx context.Context, req SearchRequest) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "searxng.search", trace.WithAttributes(
+		attribute.String("searxng.instance", hostOf(c.config.BaseURL)),
+		attribute.String("searxng.category", req.Category),
+		attribute.Int("searxng.page", req.Page),
+		attribute.Int("searxng.limit", req.Limit),
+		attribute.String("searxng.time_range", req.TimeRange),
+		attribute.String("searxng.language", req.Language),
+		attribute.StringSlice("searxng.engines", req.Engines),
+	))
+}
+
+// do sends an HTTP request to the instance within a client span,
+// propagating the trace to the instance in the request headers. Only the
+// host of the URL is recorded, as the URL holds the query.
+func (c *Client) do(httpReq *http.Request) (*http.Response, error) {
+	ctx, span := tracer().Start(httpReq.Context(), httpReq.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", httpReq.Method),
+			attribute.String("server.address", httpReq.URL.Hostname()),
+		),
+	)
+	if port := httpReq.URL.Port(); port != "" {
+		if n, err := strconv.Atoi(port); err == nil {
+			span.SetAttributes(attribute.Int("server.port", n))
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+	defer span.End()
+
+	// The span covers the response headers; reading the body is left to
+	// the search span
+	httpResp, err := c.httpClientFor(ctx).Do(httpReq.WithContext(ctx))
+	if err != nil {
+		err = fmt.Errorf("HTTP request failed: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", httpResp.StatusCode))
+	if httpResp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, httpResp.Status)
+	}
+	return httpResp, nil
+}
+
+// waitRateLimit waits for the rate limiter within a span, so slow searches
+// show the time spent queued
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	ctx, span := tracer().Start(ctx, "searxng.rate_limit_wait")
+	err := c.rateLimiter.wait(ctx)
+	endSpan(span, err)
+	return err
+}
+
+// endSpan records err, if any, on span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceSearchResult records the outcome of a search on its span
+func traceSearchResult(span trace.Span, resp *SearchResponse) {
+	if resp == nil {
+		return
+	}
+	span.SetAttributes(
+		attribute.Int("searxng.results", len(resp.Results)),
+		attribute.Bool("searxng.partial", resp.Partial),
+	)
+}
+
+// hostOf returns the host of rawURL, for span attributes
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
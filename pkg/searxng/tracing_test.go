@@ -0,0 +1,98 @@
+package searxng
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans installs a global TracerProvider recording the spans ended
+// during the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestSearch_Tracing(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var requests int
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		traceparent = r.Header.Get("traceparent")
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query": "go", "results": [{"url": "https://go.dev/", "title": "Go"}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{
+		BaseURL:    srv.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+		Retry:      RetryPolicy{InitialBackoff: time.Millisecond},
+		CacheTTL:   time.Minute,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "go", Category: "general"})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	assert.Equal(t, []string{"searxng.rate_limit_wait", "GET", "searxng.retry_wait", "GET", "searxng.search"}, names)
+
+	search := spans[len(spans)-1]
+	for _, span := range spans[:len(spans)-1] {
+		assert.Equal(t, search.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+	}
+	assert.Equal(t, "general", spanAttribute(search, "searxng.category").AsString())
+	assert.EqualValues(t, 1, spanAttribute(search, "searxng.results").AsInt64())
+	assert.EqualValues(t, 503, spanAttribute(spans[1], "http.response.status_code").AsInt64())
+	assert.EqualValues(t, 200, spanAttribute(spans[3], "http.response.status_code").AsInt64())
+	assert.Contains(t, traceparent, search.SpanContext().TraceID().String(), "the trace is propagated to the instance")
+	for _, span := range spans {
+		for _, kv := range span.Attributes() {
+			assert.NotContains(t, kv.Value.Emit(), "q=go", "the query is left out")
+		}
+	}
+
+	_, err = client.Search(context.Background(), SearchRequest{Query: "go", Category: "general"})
+	require.NoError(t, err)
+	spans = recorder.Ended()
+	assert.True(t, spanAttribute(spans[len(spans)-1], "searxng.cache_hit").AsBool())
+	assert.Equal(t, 2, requests, "served from the cache")
+}
@@ -0,0 +1,157 @@
+package searxng
+
+import (
+	"cmp"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// proxySchemes are the proxy URL schemes supported by net/http
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ParseProxyURL parses an HTTP(S) or SOCKS5 proxy URL, e.g.
+// socks5://127.0.0.1:9050 for a local Tor daemon
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if !slices.Contains(proxySchemes, proxyURL.Scheme) || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL: %s (must be http://, https://, socks5:// or socks5h:// followed by a host)", rawURL)
+	}
+	return proxyURL, nil
+}
+
+// TransportOptions tunes the transport built by NewPooledTransport. Zero
+// values select the defaults in parentheses.
+type TransportOptions struct {
+	// MaxIdleConns caps idle keep-alive connections across all hosts (100)
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost caps idle keep-alive connections per host (16)
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost caps total connections per host, including active
+	// ones (unlimited)
+	MaxConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept open (90s)
+	IdleConnTimeout time.Duration
+
+	// DialTimeout bounds establishing a TCP connection (10s)
+	DialTimeout time.Duration
+
+	// KeepAlive is the TCP keep-alive probe interval (30s)
+	KeepAlive time.Duration
+}
+
+// NewPooledTransport returns a transport tuned for many concurrent requests
+// to few hosts: more idle connections per host than net/http keeps, dial
+// and TLS handshake timeouts, TCP keep-alives and HTTP/2 with health-check
+// pings, so dead HTTP/2 connections are dropped instead of stalling the
+// requests multiplexed on them. It is meant to be shared, through
+// Config.Transport and the page fetches of the MCP server, so all requests
+// draw from one connection pool.
+func NewPooledTransport(opts TransportOptions) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   cmp.Or(opts.DialTimeout, 10*time.Second),
+		KeepAlive: cmp.Or(opts.KeepAlive, 30*time.Second),
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cmp.Or(opts.MaxIdleConns, 100),
+		MaxIdleConnsPerHost:   cmp.Or(opts.MaxIdleConnsPerHost, 16),
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       cmp.Or(opts.IdleConnTimeout, 90*time.Second),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		HTTP2: &http.HTTP2Config{
+			SendPingTimeout: 30 * time.Second,
+			PingTimeout:     15 * time.Second,
+		},
+	}
+}
+
+// newTransport builds the transport shared by all requests of a client.
+// Config.Transport is used as is unless HTTP/2 is disabled or a proxy or
+// SPKI pins are configured, which need a copy of their own. Without
+// Config.Transport, when neither pool settings, a proxy nor SPKI pins are
+// configured it returns nil so the client falls back to
+// http.DefaultTransport.
+func newTransport(config *Config) (http.RoundTripper, error) {
+	ownTransport := config.DisableHTTP2 || len(config.PinnedSPKI) > 0 || config.ProxyURL != ""
+	if config.Transport != nil && !ownTransport {
+		return config.Transport, nil
+	}
+	if config.Transport == nil && !ownTransport && config.MaxIdleConns == 0 && config.MaxIdleConnsPerHost == 0 &&
+		config.MaxConnsPerHost == 0 && config.IdleConnTimeout == 0 {
+		return nil, nil
+	}
+
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if config.Transport != nil {
+		base, ok = config.Transport, true
+	}
+	if !ok {
+		if len(config.PinnedSPKI) == 0 && config.ProxyURL == "" {
+			// http.DefaultTransport was replaced (e.g. by an HTTP mocking library)
+			return http.DefaultTransport, nil
+		}
+		// Pinning and proxying must never be bypassed, so start from a
+		// fresh transport
+		base = &http.Transport{Proxy: http.ProxyFromEnvironment, ForceAttemptHTTP2: true}
+	}
+
+	transport := base.Clone()
+	if config.Transport == nil {
+		applyPoolSettings(transport, config)
+	}
+	if config.DisableHTTP2 {
+		// A non-nil, empty TLSNextProto map disables HTTP/2 negotiation
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if config.ProxyURL != "" {
+		proxyURL, err := ParseProxyURL(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if len(config.PinnedSPKI) > 0 {
+		pins, err := parseSPKIPins(config.PinnedSPKI)
+		if err != nil {
+			return nil, err
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.VerifyConnection = verifyPinnedConnection(pins)
+	}
+
+	return transport, nil
+}
+
+// applyPoolSettings applies the connection pool settings of config to
+// transport. They are those of Config.Transport when one is set.
+func applyPoolSettings(transport *http.Transport, config *Config) {
+	if config.MaxIdleConns > 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = config.MaxConnsPerHost
+	}
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+}
@@ -2,6 +2,10 @@ package searxng
 
 import (
 	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -14,17 +18,41 @@ type SearchRequest struct {
 	Category  string   // "general", "images", "videos", etc.
 	Language  string   // Language code (e.g., "en", "fr")
 	Engines   []string // Specific engines to use
+	NoCache   bool     // Skip the response cache and query the instance
+	Dedupe    bool     // Merge results pointing at the same canonical URL
+
+	// RankBy reorders the results, overriding Config.RankBy (RankInstance:
+	// the configured strategy)
+	RankBy RankStrategy
+
+	// SafeSearch filters explicit results (SafeSearchInstance: the
+	// instance's default)
+	SafeSearch SafeSearch
+
+	// EngineArgs holds per-engine settings, keyed by engine name (see
+	// EngineArgs)
+	EngineArgs map[string]EngineArgs
+
+	// Deadline bounds the search (0: no bound besides the client timeout).
+	// When it passes, the results decoded so far are returned with
+	// SearchResponse.Partial set instead of an error.
+	Deadline time.Duration
+
+	// Timeout replaces Config.Timeout for the HTTP requests of this search,
+	// e.g. to give a slow instance longer (0: Config.Timeout)
+	Timeout time.Duration
 }
 
 // APIRequest is the API request format (exported for testing)
 type APIRequest struct {
-	Query     string   `json:"q"`
-	Category  string   `json:"category,omitempty"`
-	Engines   []string `json:"engines,omitempty"`
-	Language  string   `json:"language,omitempty"`
-	Pageno    int      `json:"pageno,omitempty"`
-	TimeRange string   `json:"time_range,omitempty"`
-	Format    string   `json:"format"`
+	Query      string   `json:"q"`
+	Category   string   `json:"category,omitempty"`
+	Engines    []string `json:"engines,omitempty"`
+	Language   string   `json:"language,omitempty"`
+	Pageno     int      `json:"pageno,omitempty"`
+	TimeRange  string   `json:"time_range,omitempty"`
+	SafeSearch string   `json:"safesearch,omitempty"`
+	Format     string   `json:"format"`
 }
 
 // SearchResult represents a single search result from Searxng
@@ -38,23 +66,36 @@ type SearchResult struct {
 	Score         float64
 	Thumbnail     string
 	ImageSrc      string
+	Resolution    string // Image results, e.g. "1920 x 1080"
+	ImageFormat   string // Image results, e.g. "jpeg"
+	Duration      string // Video results, e.g. "3:05" or "1:02:03"
+	EmbedURL      string // Video results, URL of the embeddable player
+	Author        string // Video results, e.g. the channel
 	Engines       []string
 	Positions     []int
 }
 
 // APIResult is the API result format (exported for testing)
 type APIResult struct {
-	URL           string   `json:"url"`
-	Title         string   `json:"title"`
-	Content       string   `json:"content"`
-	PublishedDate string   `json:"publishedDate,omitempty"`
-	Engine        string   `json:"engine,omitempty"`
-	Category      string   `json:"category,omitempty"`
-	Score         float64  `json:"score,omitempty"`
-	Thumbnail     string   `json:"thumbnail,omitempty"`
-	ImgSrc        string   `json:"img_src,omitempty"`
-	Engines       []string `json:"engines,omitempty"`
-	Positions     []int    `json:"positions,omitempty"`
+	URL           string          `json:"url"`
+	Title         string          `json:"title"`
+	Content       string          `json:"content"`
+	PublishedDate string          `json:"publishedDate,omitempty"`
+	Engine        string          `json:"engine,omitempty"`
+	Category      string          `json:"category,omitempty"`
+	Score         float64         `json:"score,omitempty"`
+	Thumbnail     string          `json:"thumbnail,omitempty"`
+	ThumbnailSrc  string          `json:"thumbnail_src,omitempty"` // Image results
+	ImgSrc        string          `json:"img_src,omitempty"`
+	Resolution    string          `json:"resolution,omitempty"`
+	ImgFormat     string          `json:"img_format,omitempty"`
+	Length        json.RawMessage `json:"length,omitempty"` // Video results: "3:05" or seconds, depending on the engine
+	IframeSrc     string          `json:"iframe_src,omitempty"`
+	Author        string          `json:"author,omitempty"`
+	Engines       []string        `json:"engines,omitempty"`
+	Positions     []int           `json:"positions,omitempty"`
+
+	warnings []string // see UnmarshalJSON
 }
 
 // Infobox represents an infobox result from Searxng
@@ -90,6 +131,13 @@ type InfoboxURL struct {
 	ImgSrc   string `json:"img_src,omitempty"`
 }
 
+// Answer represents a direct answer returned by Searxng answerers or engines
+type Answer struct {
+	Text   string `json:"answer"`
+	URL    string `json:"url,omitempty"`
+	Engine string `json:"engine,omitempty"`
+}
+
 // UnresponsiveEngine represents an engine that failed to respond
 type UnresponsiveEngine struct {
 	Name  string `json:"name"`
@@ -102,10 +150,18 @@ type SearchResponse struct {
 	NumberOfResults     int
 	Results             []SearchResult
 	Answers             []string
+	AnswerDetails       []Answer // Answers including their source, when the instance reports it
 	Corrections         []string
 	Infoboxes           []Infobox
 	Suggestions         []string
 	UnresponsiveEngines []UnresponsiveEngine
+	// Partial is set when SearchRequest.Deadline passed before the instance
+	// answered in full; the response holds what was read by then
+	Partial bool
+	// DecodeWarnings describe the parts of the instance's answer that had
+	// unexpected types and were dropped, e.g. "results[3].score: expected
+	// number, got object"
+	DecodeWarnings []string
 }
 
 // APIResponse is the API response format (exported for testing)
@@ -113,11 +169,13 @@ type APIResponse struct {
 	Query               string          `json:"query"`
 	NumberOfResults     int             `json:"number_of_results"`
 	Results             []APIResult     `json:"results"`
-	Answers             []string        `json:"answers"`
+	Answers             json.RawMessage `json:"answers"` // Plain strings on older instances, objects on newer ones
 	Corrections         []string        `json:"corrections"`
 	Infoboxes           []Infobox       `json:"infoboxes"`
 	Suggestions         []string        `json:"suggestions"`
 	UnresponsiveEngines json.RawMessage `json:"unresponsive_engines"` // Changed from []UnresponsiveEngine for flexible parsing
+	// Warnings describe the fields dropped while decoding (see UnmarshalJSON)
+	Warnings []string `json:"-"`
 }
 
 // parsePublishedDate parses a published date string
@@ -143,8 +201,30 @@ func parsePublishedDate(dateStr string) *time.Time {
 	return nil
 }
 
+// parseLength formats the length of a video result as [h:]mm:ss. Strings
+// are kept as the engine wrote them; numbers are seconds.
+func parseLength(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return strings.TrimSpace(text)
+	}
+	var seconds float64
+	if json.Unmarshal(raw, &seconds) != nil || seconds <= 0 {
+		return ""
+	}
+	total := int(seconds)
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+	}
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
 // toSearchResult converts an API result to a SearchResult
 func toSearchResult(r APIResult) SearchResult {
+	thumbnail := r.Thumbnail
+	if thumbnail == "" {
+		thumbnail = r.ThumbnailSrc
+	}
 	return SearchResult{
 		URL:           r.URL,
 		Title:         r.Title,
@@ -153,8 +233,13 @@ func toSearchResult(r APIResult) SearchResult {
 		Engine:        r.Engine,
 		Category:      r.Category,
 		Score:         r.Score,
-		Thumbnail:     r.Thumbnail,
+		Thumbnail:     thumbnail,
 		ImageSrc:      r.ImgSrc,
+		Resolution:    r.Resolution,
+		ImageFormat:   r.ImgFormat,
+		Duration:      parseLength(r.Length),
+		EmbedURL:      r.IframeSrc,
+		Author:        r.Author,
 		Engines:       r.Engines,
 		Positions:     r.Positions,
 	}
@@ -167,51 +252,131 @@ func toSearchResponse(r APIResponse) SearchResponse {
 		results[i] = toSearchResult(result)
 	}
 
+	answerDetails, answerWarnings := safeParseAnswers(r.Answers)
+	engines, engineWarnings := safeParseUnresponsiveEngines(r.UnresponsiveEngines)
+	var answers []string
+	if answerDetails != nil {
+		answers = make([]string, len(answerDetails))
+		for i, a := range answerDetails {
+			answers[i] = a.Text
+		}
+	}
+
 	return SearchResponse{
 		Query:               r.Query,
 		NumberOfResults:     r.NumberOfResults,
 		Results:             results,
-		Answers:             r.Answers,
+		Answers:             answers,
+		AnswerDetails:       answerDetails,
 		Corrections:         r.Corrections,
 		Infoboxes:           r.Infoboxes,
 		Suggestions:         r.Suggestions,
-		UnresponsiveEngines: safeParseUnresponsiveEngines(r.UnresponsiveEngines),
+		UnresponsiveEngines: engines,
+		DecodeWarnings:      slices.Concat(r.Warnings, answerWarnings, engineWarnings),
 	}
 }
 
-// safeParseUnresponsiveEngines parses the unresponsive_engines field safely
-// SearXNG returns this as an array of 2-element arrays: [["engine_name", "error_message"], ...]
-func safeParseUnresponsiveEngines(raw json.RawMessage) []UnresponsiveEngine {
-	if len(raw) == 0 || string(raw) == "null" {
-		return nil
+// safeParseAnswers parses the answers field safely. Older SearXNG versions
+// return plain strings, newer ones objects with answer, url and engine.
+// Other items are skipped with a warning.
+func safeParseAnswers(raw json.RawMessage) ([]Answer, []string) {
+	if isJSONNull(raw) {
+		return nil, nil
 	}
 
-	// SearXNG format: array of [name, error] tuples
-	var tuples [][]string
-	if err := json.Unmarshal(raw, &tuples); err == nil {
-		if tuples == nil {
-			return nil
-		}
-		engines := make([]UnresponsiveEngine, 0, len(tuples))
-		for _, t := range tuples {
-			if len(t) >= 2 {
-				engines = append(engines, UnresponsiveEngine{Name: t[0], Error: t[1]})
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, []string{decodeWarning("answers", "array", raw)}
+	}
+
+	answers := make([]Answer, 0, len(items))
+	var warnings []string
+	for i, item := range items {
+		var text string
+		if err := json.Unmarshal(item, &text); err == nil {
+			if text != "" {
+				answers = append(answers, Answer{Text: text})
 			}
+			continue
 		}
-		return engines
-	}
 
-	// Fallback: try as array of objects
-	var engines []UnresponsiveEngine
-	if err := json.Unmarshal(raw, &engines); err == nil {
-		return engines
+		var answer Answer
+		if err := json.Unmarshal(item, &answer); err == nil && answer.Text != "" {
+			answers = append(answers, answer)
+		} else if !isJSONNull(item) {
+			warnings = append(warnings, decodeWarning(fmt.Sprintf("answers[%d]", i), "string or answer object", item))
+		}
 	}
+	return answers, warnings
+}
 
-	// Fallback: try single object
-	var single UnresponsiveEngine
-	if err := json.Unmarshal(raw, &single); err == nil {
-		return []UnresponsiveEngine{single}
+// safeParseUnresponsiveEngines parses the unresponsive_engines field safely.
+// SearXNG returns it as an array of [name, error] pairs; some versions and
+// forks send objects with name and error, a single such object or an
+// object mapping names to errors. Other items are skipped with a warning.
+func safeParseUnresponsiveEngines(raw json.RawMessage) ([]UnresponsiveEngine, []string) {
+	switch jsonKind(raw) {
+	case "nothing", "null":
+		return nil, nil
+	case "object":
+		if engine, ok := parseUnresponsiveEngine(raw); ok {
+			return []UnresponsiveEngine{engine}, nil
+		}
+		var errs map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &errs); err != nil {
+			return nil, []string{decodeWarning("unresponsive_engines", "array", raw)}
+		}
+		var engines []UnresponsiveEngine
+		var warnings []string
+		for _, name := range slices.Sorted(maps.Keys(errs)) {
+			if text, ok := lenientString(errs[name]); ok || isJSONNull(errs[name]) {
+				engines = append(engines, UnresponsiveEngine{Name: name, Error: text})
+			} else {
+				warnings = append(warnings, decodeWarning("unresponsive_engines."+name, "string", errs[name]))
+			}
+		}
+		return engines, warnings
+	case "array":
+		var items []json.RawMessage
+		_ = json.Unmarshal(raw, &items)
+		engines := make([]UnresponsiveEngine, 0, len(items))
+		var warnings []string
+		for i, item := range items {
+			if engine, ok := parseUnresponsiveEngine(item); ok {
+				engines = append(engines, engine)
+			} else {
+				warnings = append(warnings, decodeWarning(fmt.Sprintf("unresponsive_engines[%d]", i), "[name, error] pair or object", item))
+			}
+		}
+		return engines, warnings
 	}
+	return nil, []string{decodeWarning("unresponsive_engines", "array", raw)}
+}
 
-	return nil
+// parseUnresponsiveEngine parses an item of unresponsive_engines: a
+// [name, error] pair or an object with name and error
+func parseUnresponsiveEngine(raw json.RawMessage) (UnresponsiveEngine, bool) {
+	var engine UnresponsiveEngine
+	switch jsonKind(raw) {
+	case "array":
+		var pair []json.RawMessage
+		if json.Unmarshal(raw, &pair) != nil || len(pair) < 2 {
+			return engine, false
+		}
+		var ok bool
+		if engine.Name, ok = lenientString(pair[0]); !ok {
+			return engine, false
+		}
+		if engine.Error, ok = lenientString(pair[1]); !ok && !isJSONNull(pair[1]) {
+			return engine, false
+		}
+	case "object":
+		d, err := newFieldDecoder(raw)
+		if err != nil {
+			return engine, false
+		}
+		d.string("name", &engine.Name)
+		d.string("error", &engine.Error)
+	}
+	return engine, engine.Name != ""
 }
@@ -0,0 +1,112 @@
+package searxngtest
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// resultsPerPage is the number of canned results on each page of a search
+const resultsPerPage = 10
+
+// fixture generates the canned result number n (counted from 1 across pages)
+// of a category for a query
+type fixture func(query string, n int) searxng.APIResult
+
+// fixtures are the canned results of the categories the fake instance
+// publishes. Results are derived from the query, so tests can tell searches
+// apart without configuring anything.
+var fixtures = map[string]fixture{
+	"general": func(q string, n int) searxng.APIResult {
+		return searxng.APIResult{
+			URL:     fmt.Sprintf("https://example.com/%s/%d", slug(q), n),
+			Title:   fmt.Sprintf("%s - result %d", q, n),
+			Content: fmt.Sprintf("General result %d about %s.", n, q),
+			Engine:  "duckduckgo",
+			Engines: []string{"duckduckgo", "brave"},
+			Score:   1 / float64(n),
+		}
+	},
+	"news": func(q string, n int) searxng.APIResult {
+		return searxng.APIResult{
+			URL:           fmt.Sprintf("https://news.example.com/%s/%d", slug(q), n),
+			Title:         fmt.Sprintf("News %d: %s", n, q),
+			Content:       fmt.Sprintf("Latest news about %s.", q),
+			PublishedDate: fmt.Sprintf("2024-01-%02dT12:00:00Z", (n-1)%28+1),
+			Engine:        "bing news",
+			Engines:       []string{"bing news"},
+		}
+	},
+	"images": func(q string, n int) searxng.APIResult {
+		return searxng.APIResult{
+			URL:          fmt.Sprintf("https://images.example.com/%s/%d.html", slug(q), n),
+			Title:        fmt.Sprintf("%s image %d", q, n),
+			ImgSrc:       fmt.Sprintf("https://images.example.com/%s/%d.jpg", slug(q), n),
+			ThumbnailSrc: fmt.Sprintf("https://images.example.com/%s/%d.thumb.jpg", slug(q), n),
+			Resolution:   "1920 x 1080",
+			ImgFormat:    "jpeg",
+			Engine:       "bing images",
+			Engines:      []string{"bing images"},
+		}
+	},
+	"videos": func(q string, n int) searxng.APIResult {
+		return searxng.APIResult{
+			URL:       fmt.Sprintf("https://videos.example.com/watch?v=%s-%d", slug(q), n),
+			Title:     fmt.Sprintf("%s video %d", q, n),
+			Content:   fmt.Sprintf("A video about %s.", q),
+			Thumbnail: fmt.Sprintf("https://videos.example.com/%s/%d.jpg", slug(q), n),
+			Engine:    "youtube",
+			Engines:   []string{"youtube"},
+		}
+	},
+	"it": func(q string, n int) searxng.APIResult {
+		return searxng.APIResult{
+			URL:     fmt.Sprintf("https://code.example.com/%s/%d", slug(q), n),
+			Title:   fmt.Sprintf("%s - repository %d", q, n),
+			Content: fmt.Sprintf("Source code and documentation for %s.", q),
+			Engine:  "github",
+			Engines: []string{"github", "stackoverflow"},
+		}
+	},
+	"science": func(q string, n int) searxng.APIResult {
+		return searxng.APIResult{
+			URL:           fmt.Sprintf("https://papers.example.com/%s/%d.pdf", slug(q), n),
+			Title:         fmt.Sprintf("On %s (paper %d)", q, n),
+			Content:       fmt.Sprintf("We study %s.", q),
+			PublishedDate: "2023-06-01",
+			Engine:        "arxiv",
+			Engines:       []string{"arxiv"},
+		}
+	},
+}
+
+// Categories returns the categories the fake instance has fixtures for
+func Categories() []string {
+	categories := make([]string, 0, len(fixtures))
+	for category := range fixtures {
+		categories = append(categories, category)
+	}
+	slices.Sort(categories)
+	return categories
+}
+
+// engines are the engines listed by the fake instance's /config
+var engines = []searxng.Engine{
+	{Name: "duckduckgo", Categories: []string{"general"}, Shortcut: "ddg", Enabled: true},
+	{Name: "brave", Categories: []string{"general"}, Shortcut: "br", Enabled: true},
+	{Name: "bing news", Categories: []string{"news"}, Shortcut: "bin", Enabled: true},
+	{Name: "bing images", Categories: []string{"images"}, Shortcut: "bii", Enabled: true},
+	{Name: "youtube", Categories: []string{"videos"}, Shortcut: "yt", Enabled: true},
+	{Name: "github", Categories: []string{"it"}, Shortcut: "gh", Enabled: true},
+	{Name: "stackoverflow", Categories: []string{"it"}, Shortcut: "st", Enabled: true},
+	{Name: "arxiv", Categories: []string{"science"}, Shortcut: "arx", Enabled: true},
+	{Name: "google", Categories: []string{"general"}, Shortcut: "go", Enabled: false},
+}
+
+// slug makes a query usable in a URL path
+func slug(query string) string {
+	return url.PathEscape(strings.ToLower(strings.Join(strings.Fields(query), "-")))
+}
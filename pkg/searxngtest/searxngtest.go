@@ -0,0 +1,234 @@
+// Package searxngtest provides a fake SearXNG instance for integration tests
+// of code embedding pkg/searxng or pkg/server, without mocking the HTTP
+// client.
+//
+// The fake serves the JSON search API (GET and POST /search), /config,
+// /autocompleter and the front page. Searches return canned results for each category in
+// Categories, derived from the query, unless a test sets its own with
+// SetResults. Failures and latency are scripted per server:
+//
+//	fake := searxngtest.NewServer()
+//	defer fake.Close()
+//	fake.FailNext(1, http.StatusBadGateway)
+//	client, _ := searxng.NewClient(&searxng.Config{BaseURL: fake.URL, MaxRetries: 1})
+package searxngtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// Version is the version the fake instance reports by default
+const Version = "2024.5.24+fake"
+
+// Server is a fake SearXNG instance listening on a local port
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	results      map[string][]searxng.APIResult // set by SetResults
+	failures     []int                          // statuses of the next searches
+	latency      time.Duration
+	jsonDisabled bool
+	version      string
+	searches     []searxng.APIRequest
+}
+
+// NewServer starts a fake instance. Close it when done.
+func NewServer() *Server {
+	s := &Server{results: map[string][]searxng.APIResult{}, version: Version}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/config", s.handleConfig)
+	mux.HandleFunc("/autocompleter", handleAutocompleter)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprint(w, "<!DOCTYPE html><html><head><title>SearXNG</title></head><body></body></html>")
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetResults makes searches in category return results instead of the
+// canned ones. Nil restores the canned results.
+func (s *Server) SetResults(category string, results []searxng.APIResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if results == nil {
+		delete(s.results, category)
+		return
+	}
+	s.results[category] = results
+}
+
+// FailNext makes the next n searches answer with status, e.g.
+// http.StatusBadGateway for a broken upstream or http.StatusTooManyRequests
+// for SearXNG's bot limiter
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// SetLatency delays every search response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// DisableJSON makes the instance refuse the json format with 403, as
+// SearXNG does when "json" is missing from search.formats in settings.yml
+func (s *Server) DisableJSON() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jsonDisabled = true
+}
+
+// SetVersion sets the version published at /config ("": none)
+func (s *Server) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// Searches returns the searches received so far, failed ones included
+func (s *Server) Searches() []searxng.APIRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]searxng.APIRequest(nil), s.searches...)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var req searxng.APIRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req = searxng.APIRequest{
+			Query:     q.Get("q"),
+			Category:  q.Get("category"),
+			Engines:   q["engines"],
+			Language:  q.Get("language"),
+			TimeRange: q.Get("time_range"),
+			Format:    q.Get("format"),
+		}
+		req.Pageno, _ = strconv.Atoi(q.Get("pageno"))
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	s.searches = append(s.searches, req)
+	latency, jsonDisabled := s.latency, s.jsonDisabled
+	status := 0
+	if len(s.failures) > 0 {
+		status, s.failures = s.failures[0], s.failures[1:]
+	}
+	results, custom := s.results[categoryOf(req)]
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	switch {
+	case status != 0:
+		http.Error(w, http.StatusText(status), status)
+		return
+	case req.Format != "json" || jsonDisabled:
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	case req.Query == "":
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+
+	if !custom {
+		results = cannedResults(req)
+	}
+	resp := searxng.APIResponse{
+		Query:           req.Query,
+		NumberOfResults: len(results),
+		Results:         results,
+		Answers:         json.RawMessage("[]"),
+		Suggestions:     suggestionsFor(req.Query),
+		Corrections:     []string{},
+		Infoboxes:       []searxng.Infobox{},
+	}
+	if results == nil {
+		resp.Results = []searxng.APIResult{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	version := s.version
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(searxng.InstanceInfo{
+		Version:    version,
+		Categories: Categories(),
+		Engines:    engines,
+	})
+}
+
+// handleAutocompleter answers in the OpenSearch suggestions format, like
+// an instance with an autocomplete backend
+func handleAutocompleter(w http.ResponseWriter, r *http.Request) {
+	query := r.FormValue("q")
+	suggestions := []string{}
+	if query != "" {
+		suggestions = suggestionsFor(query)
+	}
+	w.Header().Set("Content-Type", "application/x-suggestions+json")
+	_ = json.NewEncoder(w).Encode([]interface{}{query, suggestions})
+}
+
+// suggestionsFor returns the canned suggestions for query
+func suggestionsFor(query string) []string {
+	return []string{query + " tutorial", query + " examples"}
+}
+
+// categoryOf returns the category of a search, "general" by default
+func categoryOf(req searxng.APIRequest) string {
+	if req.Category == "" {
+		return "general"
+	}
+	return req.Category
+}
+
+// cannedResults returns a page of the fixtures of the search's category,
+// none for unknown categories
+func cannedResults(req searxng.APIRequest) []searxng.APIResult {
+	fixture, ok := fixtures[categoryOf(req)]
+	if !ok {
+		return nil
+	}
+	first := max(req.Pageno-1, 0)*resultsPerPage + 1
+	results := make([]searxng.APIResult, resultsPerPage)
+	for i := range results {
+		results[i] = fixture(req.Query, first+i)
+		results[i].Category = categoryOf(req)
+	}
+	return results
+}
@@ -0,0 +1,145 @@
+package searxngtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/denysvitali/searxng-mcp/pkg/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, fake *Server, configure func(*searxng.Config)) *searxng.Client {
+	t.Helper()
+	config := searxng.DefaultConfig()
+	config.BaseURL = fake.URL
+	config.MaxRetries = 0
+	if configure != nil {
+		configure(config)
+	}
+	client, err := searxng.NewClient(config)
+	require.NoError(t, err)
+	return client
+}
+
+func TestServer_CannedResults(t *testing.T) {
+	fake := NewServer()
+	defer fake.Close()
+	client := newClient(t, fake, nil)
+
+	resp, err := client.Search(context.Background(), searxng.SearchRequest{Query: "Go generics"})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, resultsPerPage)
+	assert.Equal(t, "https://example.com/go-generics/1", resp.Results[0].URL)
+	assert.Equal(t, "Go generics - result 1", resp.Results[0].Title)
+	assert.Contains(t, resp.Suggestions, "Go generics tutorial")
+
+	suggestions, err := client.Suggestions(context.Background(), "Go generics", "")
+	require.NoError(t, err)
+	assert.Equal(t, resp.Suggestions, suggestions)
+
+	resp, err = client.Search(context.Background(), searxng.SearchRequest{Query: "cats", Category: "images", Page: 2})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Results)
+	assert.Equal(t, "https://images.example.com/cats/11.jpg", resp.Results[0].ImageSrc)
+	assert.Equal(t, "images", resp.Results[0].Category)
+
+	resp, err = client.Search(context.Background(), searxng.SearchRequest{Query: "cats", Category: "news"})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Results[0].PublishedDate)
+
+	searches := fake.Searches()
+	require.Len(t, searches, 3)
+	assert.Equal(t, "images", searches[1].Category)
+	assert.Equal(t, 2, searches[1].Pageno)
+}
+
+func TestServer_SetResults(t *testing.T) {
+	fake := NewServer()
+	defer fake.Close()
+	client := newClient(t, fake, nil)
+
+	fake.SetResults("general", []searxng.APIResult{{URL: "https://go.dev", Title: "Go", Content: "The Go language"}})
+	resp, err := client.Search(context.Background(), searxng.SearchRequest{Query: "go", NoCache: true})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "https://go.dev", resp.Results[0].URL)
+
+	fake.SetResults("general", nil)
+	resp, err = client.Search(context.Background(), searxng.SearchRequest{Query: "go", NoCache: true})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/go/1", resp.Results[0].URL)
+}
+
+func TestServer_Failures(t *testing.T) {
+	fake := NewServer()
+	defer fake.Close()
+
+	fake.FailNext(1, http.StatusBadGateway)
+	client := newClient(t, fake, func(c *searxng.Config) { c.MaxRetries = 1 })
+	resp, err := client.Search(context.Background(), searxng.SearchRequest{Query: "retry"})
+	require.NoError(t, err, "the retry succeeds")
+	assert.NotEmpty(t, resp.Results)
+	assert.Len(t, fake.Searches(), 2)
+
+	fake.FailNext(1, http.StatusTooManyRequests)
+	_, err = newClient(t, fake, nil).Search(context.Background(), searxng.SearchRequest{Query: "limited"})
+	assert.ErrorIs(t, err, searxng.ErrInstanceLimited)
+}
+
+func TestServer_Latency(t *testing.T) {
+	fake := NewServer()
+	defer fake.Close()
+	fake.SetLatency(time.Second)
+
+	client := newClient(t, fake, func(c *searxng.Config) { c.Timeout = 50 * time.Millisecond })
+	_, err := client.Search(context.Background(), searxng.SearchRequest{Query: "slow"})
+	assert.Error(t, err)
+}
+
+func TestServer_PreflightAndConfig(t *testing.T) {
+	fake := NewServer()
+	defer fake.Close()
+	client := newClient(t, fake, nil)
+
+	for _, check := range client.Preflight(context.Background()) {
+		assert.NoError(t, check.Err, check.Name)
+	}
+	info, err := client.InstanceInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Version, info.Version)
+	assert.Equal(t, Categories(), info.Categories)
+	assert.NotEmpty(t, info.Engines)
+
+	fake.DisableJSON()
+	checks := client.Preflight(context.Background())
+	require.Len(t, checks, 2)
+	assert.ErrorIs(t, checks[1].Err, searxng.ErrJSONFormatDisabled)
+}
+
+func TestServer_WithMCPServer(t *testing.T) {
+	fake := NewServer()
+	defer fake.Close()
+	srv := server.New(newClient(t, fake, nil))
+
+	msg, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "searxng_search",
+			"arguments": map[string]interface{}{"query": "mcp", "category": "it"},
+		},
+	})
+	require.NoError(t, err)
+	resp, ok := srv.MCPServer().HandleMessage(context.Background(), msg).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result := resp.Result.(*mcp.CallToolResult)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "https://code.example.com/mcp/1")
+}
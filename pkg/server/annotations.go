@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+)
+
+// domainAnnotations labels search results by the host of their URL, e.g.
+// "authoritative" or "low quality", so agents can prefer some sources
+// without the results of others being dropped. Domains match their
+// subdomains; the most specific domain wins.
+type domainAnnotations map[string]string
+
+// newDomainAnnotations returns the annotations of
+// Options.DomainAnnotations, skipping invalid domains (see
+// ParseDomainAnnotations)
+func newDomainAnnotations(annotations map[string]string) domainAnnotations {
+	valid := make(domainAnnotations, len(annotations))
+	for domain, label := range annotations {
+		d, err := normalizeDomain(domain)
+		if err != nil {
+			log.WithField("error", err).Error("skipping invalid annotated domain")
+			continue
+		}
+		if label = strings.TrimSpace(label); label != "" {
+			valid[d] = label
+		}
+	}
+	return valid
+}
+
+// label returns the label of the most specific annotated domain rawURL is
+// on, or the empty string
+func (a domainAnnotations) label(rawURL string) string {
+	if len(a) == 0 {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	for host != "" {
+		if label, ok := a[host]; ok {
+			return label
+		}
+		_, host, _ = strings.Cut(host, ".")
+	}
+	return ""
+}
+
+// annotate sets the domain_label of the results of output, as built by
+// formatSearchResults
+func (a domainAnnotations) annotate(output map[string]interface{}) {
+	results, _ := output["results"].([]map[string]interface{})
+	for _, result := range results {
+		if label := a.label(result["url"].(string)); label != "" {
+			result["domain_label"] = label
+		}
+	}
+}
+
+// ParseDomainAnnotations reads the annotations of Options.DomainAnnotations
+// from lines of "domain: label", e.g. "wikipedia.org: authoritative".
+// Blank lines and lines starting with "#" are skipped.
+func ParseDomainAnnotations(r io.Reader) (map[string]string, error) {
+	annotations := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domain, label, ok := strings.Cut(line, ":")
+		label = strings.Trim(strings.TrimSpace(label), `"'`)
+		if !ok || label == "" {
+			return nil, fmt.Errorf("line %d: expected \"domain: label\"", n)
+		}
+		d, err := normalizeDomain(domain)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if _, ok := annotations[d]; ok {
+			return nil, fmt.Errorf("line %d: %s is annotated twice", n, d)
+		}
+		annotations[d] = label
+	}
+	return annotations, scanner.Err()
+}
+
+// LoadDomainAnnotations reads the domain annotation file at path (see
+// ParseDomainAnnotations)
+func LoadDomainAnnotations(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domain annotations: %w", err)
+	}
+	defer f.Close()
+	annotations, err := ParseDomainAnnotations(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain annotations in %s: %w", path, err)
+	}
+	return annotations, nil
+}
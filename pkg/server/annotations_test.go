@@ -0,0 +1,95 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDomainAnnotations(t *testing.T) {
+	annotations, err := ParseDomainAnnotations(strings.NewReader(`
+# Sources agents should prefer
+wikipedia.org: authoritative
+*.Go.dev: "official docs"
+
+contentfarm.example: low quality
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"wikipedia.org":       "authoritative",
+		"go.dev":              "official docs",
+		"contentfarm.example": "low quality",
+	}, annotations)
+
+	for input, want := range map[string]string{
+		"wikipedia.org":                          `line 1: expected "domain: label"`,
+		"wikipedia.org:":                         `line 1: expected "domain: label"`,
+		"\na b: label":                           `line 2: invalid domain: "a b"`,
+		"go.dev: docs\npkg.go.dev: x\nGo.dev: y": "line 3: go.dev is annotated twice",
+	} {
+		_, err := ParseDomainAnnotations(strings.NewReader(input))
+		assert.EqualError(t, err, want, input)
+	}
+}
+
+func TestLoadDomainAnnotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotations.txt")
+	require.NoError(t, os.WriteFile(path, []byte("go.dev: official docs\n"), 0o600))
+	annotations, err := LoadDomainAnnotations(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"go.dev": "official docs"}, annotations)
+
+	_, err = LoadDomainAnnotations(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.ErrorContains(t, err, "failed to read domain annotations")
+}
+
+func TestDomainAnnotations_Label(t *testing.T) {
+	a := newDomainAnnotations(map[string]string{"github.com": "code host", "gist.github.com": "snippets", "a b": "invalid", "go.dev": " "})
+	assert.Equal(t, "code host", a.label("https://github.com/golang/go"))
+	assert.Equal(t, "code host", a.label("https://API.GitHub.com./repos"), "subdomains match")
+	assert.Equal(t, "snippets", a.label("https://gist.github.com/x"), "the most specific domain wins")
+	assert.Empty(t, a.label("https://notgithub.com/"))
+	assert.Empty(t, a.label("https://go.dev/"), "empty labels are skipped")
+	assert.Empty(t, a.label("::"))
+	assert.Len(t, a, 2)
+}
+
+func TestHandleWebSearch_DomainAnnotations(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(2).
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "generics", Results: []searxng.APIResult{
+			{URL: "https://en.wikipedia.org/wiki/Generic_programming", Title: "Generic programming"},
+			{URL: "https://contentfarm.example/generics", Title: "Top 10 generics"},
+			{URL: "https://go.dev/doc/tutorial/generics", Title: "Tutorial"},
+		}})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{DomainAnnotations: map[string]string{
+		"wikipedia.org":       "authoritative",
+		"contentfarm.example": "low quality",
+	}})
+
+	result := callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "generics"})
+	require.False(t, result.IsError)
+	results := result.StructuredContent.(map[string]interface{})["results"].([]map[string]interface{})
+	require.Len(t, results, 3, "annotations don't filter")
+	assert.Equal(t, "authoritative", results[0]["domain_label"])
+	assert.Equal(t, "low quality", results[1]["domain_label"])
+	assert.NotContains(t, results[2], "domain_label")
+
+	result = callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "generics", "mode": "compact"})
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "<https://contentfarm.example/generics> [low quality]")
+}
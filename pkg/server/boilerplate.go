@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BoilerplateLevel controls how aggressively trailing page boilerplate
+// (comment sections, related articles, newsletter signups, cookie notices)
+// is removed from converted Markdown.
+type BoilerplateLevel string
+
+const (
+	BoilerplateOff        BoilerplateLevel = "off"
+	BoilerplateNormal     BoilerplateLevel = "normal"
+	BoilerplateAggressive BoilerplateLevel = "aggressive"
+)
+
+// ParseBoilerplateLevel parses a boilerplate level name. An empty string
+// maps to BoilerplateOff.
+func ParseBoilerplateLevel(s string) (BoilerplateLevel, error) {
+	switch BoilerplateLevel(strings.ToLower(strings.TrimSpace(s))) {
+	case "", BoilerplateOff:
+		return BoilerplateOff, nil
+	case BoilerplateNormal:
+		return BoilerplateNormal, nil
+	case BoilerplateAggressive:
+		return BoilerplateAggressive, nil
+	default:
+		return "", fmt.Errorf("invalid boilerplate level: %s (must be 'off', 'normal' or 'aggressive')", s)
+	}
+}
+
+// trailingSectionMarkers are headings that usually start the non-article
+// tail of a page. Everything from the first match onwards is dropped.
+var trailingSectionMarkers = []string{
+	"comments",
+	"leave a comment",
+	"leave a reply",
+	"join the discussion",
+	"join the conversation",
+	"related articles",
+	"related posts",
+	"related stories",
+	"related content",
+	"read more",
+	"read next",
+	"more from",
+	"more stories",
+	"more on this",
+	"you may also like",
+	"you might also like",
+	"recommended for you",
+	"recommended reading",
+	"popular posts",
+	"most popular",
+	"most read",
+	"trending",
+	"share this",
+	"sign up for",
+	"subscribe to",
+	"newsletter",
+}
+
+// maxMarkerLength bounds the length of a line considered as a section
+// marker, so prose that merely starts with "Comments" is kept.
+const maxMarkerLength = 60
+
+// stripTrailingBoilerplate removes trailing boilerplate from Markdown
+// produced by the generic HTML reader.
+//
+// Normal mode truncates at a matching heading in the second half of the
+// document and drops short cookie/newsletter lines. Aggressive mode also
+// accepts bold or short plain-text markers from the last three quarters of
+// the document and strips trailing runs of link-only lines.
+func stripTrailingBoilerplate(markdown string, level BoilerplateLevel) string {
+	if level != BoilerplateNormal && level != BoilerplateAggressive {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+
+	start := len(lines) / 2
+	if level == BoilerplateAggressive {
+		start = len(lines) / 4
+	}
+	for i := max(start, 1); i < len(lines); i++ {
+		if isTrailingSectionMarker(lines[i], level) {
+			lines = lines[:i]
+			break
+		}
+	}
+
+	kept := lines[:0]
+	for _, line := range lines {
+		if isBoilerplateLine(line) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	if level == BoilerplateAggressive {
+		for len(kept) > 0 {
+			last := strings.TrimSpace(kept[len(kept)-1])
+			if last != "" && !isLinkOnlyLine(last) {
+				break
+			}
+			kept = kept[:len(kept)-1]
+		}
+	}
+
+	return cleanMarkdown(strings.Join(kept, "\n"))
+}
+
+// isTrailingSectionMarker reports whether line starts a trailing section
+func isTrailingSectionMarker(line string, level BoilerplateLevel) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || len(trimmed) > maxMarkerLength {
+		return false
+	}
+
+	isHeading := strings.HasPrefix(trimmed, "#")
+	isBold := strings.HasPrefix(trimmed, "**") && strings.HasSuffix(trimmed, "**")
+	switch level {
+	case BoilerplateNormal:
+		if !isHeading {
+			return false
+		}
+	case BoilerplateAggressive:
+		if !isHeading && !isBold && len(trimmed) > maxMarkerLength/2 {
+			return false
+		}
+	}
+
+	text := strings.ToLower(strings.Trim(trimmed, "#*_: \t"))
+	// Headings like "12 Comments" or "Comments (3)"
+	text = strings.TrimLeft(text, "0123456789 ")
+	for _, marker := range trailingSectionMarkers {
+		if strings.HasPrefix(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBoilerplateLine reports whether a single line is a cookie-consent or
+// newsletter-signup remnant
+func isBoilerplateLine(line string) bool {
+	lower := strings.ToLower(strings.TrimSpace(line))
+	if lower == "" || len(lower) > 200 {
+		return false
+	}
+
+	if strings.Contains(lower, "cookie") {
+		for _, phrase := range []string{"accept", "consent", "we use", "this site uses", "this website uses", "cookie policy", "cookie settings"} {
+			if strings.Contains(lower, phrase) {
+				return true
+			}
+		}
+	}
+	if strings.Contains(lower, "newsletter") && (strings.Contains(lower, "subscribe") || strings.Contains(lower, "sign up")) {
+		return true
+	}
+	return strings.Contains(lower, "enter your email")
+}
+
+// isLinkOnlyLine reports whether a line consists only of a Markdown link,
+// optionally as a list item
+func isLinkOnlyLine(line string) bool {
+	trimmed := strings.TrimLeft(line, "-*+ ")
+	return strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, ")") && strings.Contains(trimmed, "](")
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const articleWithTail = `# Article title
+
+First paragraph of the article.
+
+Second paragraph of the article.
+
+Third paragraph of the article.
+
+We use cookies to improve your experience. Accept all cookies.
+
+## 42 Comments
+
+great post!
+
+## Related articles
+
+- [Other article](https://example.com/other)`
+
+func TestParseBoilerplateLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    BoilerplateLevel
+		wantErr bool
+	}{
+		{in: "", want: BoilerplateOff},
+		{in: "off", want: BoilerplateOff},
+		{in: "Normal", want: BoilerplateNormal},
+		{in: " aggressive ", want: BoilerplateAggressive},
+		{in: "extreme", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseBoilerplateLevel(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStripTrailingBoilerplate_Off(t *testing.T) {
+	assert.Equal(t, articleWithTail, stripTrailingBoilerplate(articleWithTail, BoilerplateOff))
+}
+
+func TestStripTrailingBoilerplate_Normal(t *testing.T) {
+	got := stripTrailingBoilerplate(articleWithTail, BoilerplateNormal)
+
+	assert.Contains(t, got, "Third paragraph of the article.")
+	assert.NotContains(t, got, "cookies")
+	assert.NotContains(t, got, "Comments")
+	assert.NotContains(t, got, "great post")
+	assert.NotContains(t, got, "Related articles")
+}
+
+func TestStripTrailingBoilerplate_KeepsEarlyHeadings(t *testing.T) {
+	markdown := "# Comments in Go\n\nGo supports line comments.\n\nAnd block comments."
+	assert.Equal(t, markdown, stripTrailingBoilerplate(markdown, BoilerplateNormal))
+}
+
+func TestStripTrailingBoilerplate_Aggressive(t *testing.T) {
+	markdown := strings.Join([]string{
+		"# Title",
+		"",
+		"Body paragraph one.",
+		"",
+		"Body paragraph two.",
+		"",
+		"Body paragraph three.",
+		"",
+		"**You may also like**",
+		"",
+		"Unrelated teaser.",
+	}, "\n")
+
+	normal := stripTrailingBoilerplate(markdown, BoilerplateNormal)
+	assert.Contains(t, normal, "You may also like")
+
+	aggressive := stripTrailingBoilerplate(markdown, BoilerplateAggressive)
+	assert.NotContains(t, aggressive, "You may also like")
+	assert.NotContains(t, aggressive, "Unrelated teaser")
+	assert.Contains(t, aggressive, "Body paragraph three.")
+}
+
+func TestStripTrailingBoilerplate_AggressiveTrailingLinks(t *testing.T) {
+	markdown := "# Title\n\nBody.\n\n- [Home](https://example.com)\n- [About](https://example.com/about)"
+	assert.Equal(t, "# Title\n\nBody.", stripTrailingBoilerplate(markdown, BoilerplateAggressive))
+}
+
+func TestFetchURLContent_Boilerplate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>
+			<h1>Article</h1>
+			<p>One.</p><p>Two.</p><p>Three.</p><p>Four.</p>
+			<h2>Related posts</h2>
+			<p>Something else</p>
+		</body></html>`))
+	}))
+	defer ts.Close()
+
+	markdown, err := fetchURLContent(context.Background(), ts.URL, readOptions{Boilerplate: BoilerplateNormal})
+	require.NoError(t, err)
+	assert.Contains(t, markdown, "Four.")
+	assert.NotContains(t, markdown, "Related posts")
+
+	markdown, err = fetchURLContent(context.Background(), ts.URL, readOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, markdown, "Related posts")
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"unicode/utf8"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/transform"
+)
+
+// toUTF8 transcodes a page body to UTF-8 from its charset (Shift_JIS, GBK,
+// ISO-8859-x...), taken from a byte order mark, the charset parameter of
+// contentType or a <meta> tag in the first 1024 bytes, as browsers do (see
+// charset.DetermineEncoding). A body without a declared charset is kept
+// when it is valid UTF-8, and otherwise read as windows-1252.
+func toUTF8(body []byte, contentType string) []byte {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || !certain && name == "windows-1252" && utf8.Valid(body) {
+		return body
+	}
+	decoded, _, err := transform.Bytes(enc.NewDecoder(), body)
+	if err != nil {
+		log.WithFields(logrus.Fields{"charset": name, "error": err}).Debug("failed to transcode page, reading it as UTF-8")
+		return body
+	}
+	return decoded
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func encode(t *testing.T, enc encoding.Encoding, s string) []byte {
+	t.Helper()
+	b, err := enc.NewEncoder().Bytes([]byte(s))
+	require.NoError(t, err)
+	return b
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+	}{
+		{"content type", encode(t, charmap.ISO8859_1, "Café"), "text/html; charset=ISO-8859-1", "Café"},
+		{"gbk", encode(t, simplifiedchinese.GBK, "<p>中文网页</p>"), "text/html; charset=gbk", "<p>中文网页</p>"},
+		{
+			"meta tag",
+			encode(t, japanese.ShiftJIS, `<meta charset="Shift_JIS"><p>日本語のページ</p>`),
+			"text/html",
+			`<meta charset="Shift_JIS"><p>日本語のページ</p>`,
+		},
+		{
+			"http-equiv meta tag",
+			encode(t, charmap.ISO8859_15, `<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-15"><p>€uro</p>`),
+			"text/html",
+			`<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-15"><p>€uro</p>`,
+		},
+		{"content type wins", encode(t, charmap.ISO8859_1, `<meta charset="utf-8">Café`), "text/html; charset=latin1", `<meta charset="utf-8">Café`},
+		{"undeclared utf-8", []byte("<p>日本語</p>"), "text/html", "<p>日本語</p>"},
+		{"undeclared legacy", encode(t, charmap.Windows1252, "naïve"), "text/html", "naïve"},
+		{"utf-8", []byte("Café"), "text/plain; charset=utf-8", "Café"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(toUTF8(tt.body, tt.contentType)))
+		})
+	}
+}
+
+func TestHandleWebRead_Charset(t *testing.T) {
+	gock.Off()
+
+	pages := map[string][]byte{
+		"/sjis":   encode(t, japanese.ShiftJIS, `<html><head><meta charset="Shift_JIS"></head><body><h1>東京の天気</h1><p>晴れのち曇り</p></body></html>`),
+		"/latin1": encode(t, charmap.ISO8859_1, "Crème brûlée"),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latin1" {
+			w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+		} else {
+			w.Header().Set("Content-Type", "text/html")
+		}
+		_, _ = w.Write(pages[r.URL.Path])
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": ts.URL + "/sjis", "mode": "full"})
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "# 東京の天気")
+	assert.Contains(t, text, "晴れのち曇り")
+
+	result = callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": ts.URL + "/latin1"})
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Crème brûlée")
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCompactSnippet bounds the snippet length, in characters, of each
+// result in compact mode
+const maxCompactSnippet = 240
+
+// Search output modes of searxng_search
+const (
+	searchModeJSON    = "json"
+	searchModeCompact = "compact"
+)
+
+// formatCompactResults renders the output of formatSearchResults as short
+// Markdown paragraphs, one per result, for models with little context:
+// direct answers and infoboxes first, then "**title** snippet <url>" lines and a footer
+// with the suggestions and filter counters.
+func formatCompactResults(output map[string]interface{}) string {
+	var b strings.Builder
+	if answers, ok := output["answers"].([]interface{}); ok {
+		for _, answer := range answers {
+			fmt.Fprintf(&b, "Answer: %v\n\n", answer)
+		}
+	}
+	if infoboxes, ok := output["infoboxes"].([]map[string]interface{}); ok {
+		for _, infobox := range infoboxes {
+			b.WriteString(formatCompactInfobox(infobox) + "\n\n")
+		}
+	}
+
+	results, _ := output["results"].([]map[string]interface{})
+	if len(results) == 0 {
+		fmt.Fprintf(&b, "No results for %q.\n", output["query"])
+	}
+	for i, result := range results {
+		fmt.Fprintf(&b, "%d. **%s**", i+1, compactText(result["title"].(string), 0))
+		if snippet := compactText(result["snippet"].(string), maxCompactSnippet); snippet != "" {
+			b.WriteString(" " + snippet)
+		}
+		if date, ok := result["published_date"].(string); ok {
+			b.WriteString(" (" + date + ")")
+		}
+		fmt.Fprintf(&b, " <%s>", result["url"])
+		if label, ok := result["domain_label"].(string); ok {
+			b.WriteString(" [" + label + "]")
+		}
+		if reason, ok := result["link_unverified"].(string); ok {
+			b.WriteString(" [unverified: " + reason + "]")
+		}
+		b.WriteString("\n\n")
+	}
+
+	if suggestions, ok := output["suggestions"].([]interface{}); ok {
+		items := make([]string, len(suggestions))
+		for i, s := range suggestions {
+			items[i] = fmt.Sprint(s)
+		}
+		b.WriteString("Related: " + strings.Join(items, "; ") + "\n")
+	}
+	var filtered []string
+	for _, counter := range []struct{ key, label string }{
+		{"filetype_filtered", "wrong file type"},
+		{"dead_links_removed", "dead links"},
+		{"seen_results_filtered", "already seen"},
+	} {
+		if n, ok := output[counter.key].(int); ok && n > 0 {
+			filtered = append(filtered, fmt.Sprintf("%d %s", n, counter.label))
+		}
+	}
+	if len(filtered) > 0 {
+		b.WriteString("Filtered out: " + strings.Join(filtered, ", ") + "\n")
+	}
+	if partial, _ := output["partial"].(bool); partial {
+		b.WriteString("Partial results: the deadline passed before the instance answered in full.\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// compactText collapses whitespace and cuts s to maxLength characters (0:
+// no limit) at a word boundary
+func compactText(s string, maxLength int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return s
+	}
+	cut := string(runes[:maxLength])
+	if i := strings.LastIndexByte(cut, ' '); i > maxLength/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCompactResults(t *testing.T) {
+	output := map[string]interface{}{
+		"query":   "golang",
+		"answers": []interface{}{"Go is a programming language"},
+		"results": []map[string]interface{}{
+			{"title": "The Go  Programming\nLanguage", "url": "https://go.dev/", "snippet": "Build simple,\n secure systems.", "published_date": "2024-02-01"},
+			{"title": "Go on Wikipedia", "url": "https://en.wikipedia.org/wiki/Go", "snippet": "", "link_unverified": "HTTP 403"},
+		},
+		"suggestions":        []interface{}{"golang tutorial", "go generics"},
+		"dead_links_removed": 2,
+		"filetype_filtered":  0,
+	}
+	assert.Equal(t, `Answer: Go is a programming language
+
+1. **The Go Programming Language** Build simple, secure systems. (2024-02-01) <https://go.dev/>
+
+2. **Go on Wikipedia** <https://en.wikipedia.org/wiki/Go> [unverified: HTTP 403]
+
+Related: golang tutorial; go generics
+Filtered out: 2 dead links`, formatCompactResults(output))
+
+	assert.Equal(t, `No results for "nothing".`, formatCompactResults(map[string]interface{}{
+		"query": "nothing", "results": []map[string]interface{}{},
+	}))
+}
+
+func TestCompactText(t *testing.T) {
+	assert.Equal(t, "a b c", compactText(" a\n b\t c ", 0))
+	assert.Equal(t, "short", compactText("short", 10))
+	assert.Equal(t, "hello wonderful…", compactText("hello wonderful world", 18))
+	assert.Equal(t, "abcdefghij…", compactText("abcdefghijklmnop", 10))
+}
+
+func TestHandleWebSearch_CompactMode(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		JSON(searxng.APIResponse{
+			Query:   "golang",
+			Results: []searxng.APIResult{{URL: "https://go.dev/", Title: "Go", Content: "The Go language"}},
+		})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	search := func(mode string) *mcp.CallToolResult {
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{"query": "golang", "mode": mode}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := search("compact")
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Equal(t, "1. **Go** The Go language <https://go.dev/>", text)
+	assert.False(t, strings.HasPrefix(text, "{"))
+	assert.NotNil(t, result.StructuredContent, "structured content still matches the output schema")
+
+	result = search("verbose")
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid mode")
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// Bounds of searxng_compare_pages
+const (
+	// maxCompareLines caps the lines of a section that are diffed, as the
+	// diff takes time and memory quadratic in them
+	maxCompareLines = 500
+	// maxCompareDiffLines caps the diff lines returned per section
+	maxCompareDiffLines = 40
+	// maxCompareLineLength cuts long diff lines (whole paragraphs)
+	maxCompareLineLength = 300
+)
+
+// introHeading names the text before the first heading of a page
+const introHeading = "(introduction)"
+
+// comparePagesTool returns the definition of searxng_compare_pages
+func comparePagesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "searxng_compare_pages",
+		Description: "Read two pages and compare their structure and text: the sections unique to each page, the sections they share with how similar they are, and a diff of the lines that differ. Useful to compare product, pricing or specification pages without reading both in full.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"url_a", "url_b"},
+			Properties: map[string]interface{}{
+				"url_a": map[string]interface{}{
+					"type":        "string",
+					"description": "The URL of the first page",
+				},
+				"url_b": map[string]interface{}{
+					"type":        "string",
+					"description": "The URL of the second page",
+				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"description": "'full' compares whole pages; 'article' only the main article body of each page (default: 'article')",
+					"enum":        []string{"full", "article"},
+				},
+			},
+		},
+	}
+}
+
+// handleComparePages handles the searxng_compare_pages tool call
+func (s *Server) handleComparePages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("handling searxng_compare_pages")
+	ctx, span := startSpan(ctx, "searxng_compare_pages")
+	defer span.End()
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+	urlA, _ := args["url_a"].(string)
+	urlB, _ := args["url_b"].(string)
+	if urlA == "" || urlB == "" {
+		return mcp.NewToolResultError("url_a and url_b are required"), nil
+	}
+	opts := s.defaultReadOptions()
+	opts.Mode = ReadModeArticle
+	if mode, ok := args["mode"].(string); ok {
+		readMode, err := ParseReadMode(mode)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		opts.Mode = readMode
+	}
+
+	urls := [2]string{urlA, urlB}
+	var pages [2]string
+	var errs [2]error
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = s.checkRead(ctx, url); errs[i] != nil {
+				return
+			}
+			page, err := s.fetchPage(ctx, url, opts)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to fetch URL: %w", err)
+				return
+			}
+			s.metrics.readBytes.observe(float64(len(page.Markdown)))
+			pages[i] = page.Markdown
+		}()
+	}
+	wg.Wait()
+	for i, name := range []string{"url_a", "url_b"} {
+		if errs[i] != nil {
+			log.WithFields(logrus.Fields{"url": urls[i], "error": errs[i]}).Debug("reading page to compare failed")
+			spanError(ctx, errs[i])
+			return mcp.NewToolResultError(fmt.Sprintf("%s: %v", name, errs[i])), nil
+		}
+	}
+
+	output := comparePages(splitSections(pages[0]), splitSections(pages[1]))
+	output["url_a"], output["url_b"] = urlA, urlB
+	resultJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to format comparison: %v", err)), nil
+	}
+	return mcp.NewToolResultStructured(output, string(resultJSON)), nil
+}
+
+// pageSection is the text of a page under one heading
+type pageSection struct {
+	heading string // as written; introHeading before the first heading
+	key     string // the normalized heading, numbered when repeated
+	lines   []string
+}
+
+// splitSections splits Markdown at its headings into sections of their
+// non-blank lines. Headings inside fenced code blocks are text.
+func splitSections(markdown string) []pageSection {
+	sections := []pageSection{{heading: introHeading}}
+	var fenced bool
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+		if heading, ok := markdownHeading(trimmed); ok && !fenced {
+			sections = append(sections, pageSection{heading: heading})
+			continue
+		}
+		if trimmed != "" {
+			last := &sections[len(sections)-1]
+			last.lines = append(last.lines, trimmed)
+		}
+	}
+	if len(sections[0].lines) == 0 {
+		sections = sections[1:]
+	}
+
+	seen := make(map[string]int, len(sections))
+	for i := range sections {
+		key := headingKey(sections[i].heading)
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key = fmt.Sprintf("%s#%d", key, n)
+		}
+		sections[i].key = key
+	}
+	return sections
+}
+
+// markdownHeading returns the text of an ATX heading line ("## Pricing")
+func markdownHeading(line string) (string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return "", false
+	}
+	heading := strings.TrimSpace(strings.TrimRight(line[level:], "#"))
+	return heading, heading != ""
+}
+
+// headingKey normalizes a heading for matching the sections of two pages:
+// case, punctuation and spacing are ignored, so "Pricing:" matches
+// "pricing"
+func headingKey(heading string) string {
+	words := strings.FieldsFunc(strings.ToLower(heading), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// comparePages aligns the sections of two pages by heading and diffs the
+// common ones. The similarity of the pages is the share of lines their
+// common sections have in common.
+func comparePages(a, b []pageSection) map[string]interface{} {
+	byKey := make(map[string]pageSection, len(b))
+	for _, section := range b {
+		byKey[section.key] = section
+	}
+
+	common := []map[string]interface{}{}
+	onlyA, onlyB := []string{}, []string{}
+	matched := make(map[string]bool, len(a))
+	var sharedLines, totalLines int
+	for _, section := range a {
+		totalLines += len(section.lines)
+		other, ok := byKey[section.key]
+		if !ok {
+			onlyA = append(onlyA, section.heading)
+			continue
+		}
+		matched[section.key] = true
+		diff, shared := diffLines(section.lines, other.lines)
+		sharedLines += shared
+		entry := map[string]interface{}{
+			"heading":    section.heading,
+			"similarity": similarity(shared, len(section.lines), len(other.lines)),
+		}
+		if len(diff) > 0 {
+			if len(diff) > maxCompareDiffLines {
+				diff = diff[:maxCompareDiffLines]
+				entry["diff_truncated"] = true
+			}
+			entry["diff"] = diff
+		}
+		common = append(common, entry)
+	}
+	for _, section := range b {
+		totalLines += len(section.lines)
+		if !matched[section.key] {
+			onlyB = append(onlyB, section.heading)
+		}
+	}
+
+	return map[string]interface{}{
+		"similarity":      similarity(sharedLines, totalLines, 0),
+		"common_sections": common,
+		"only_in_a":       onlyA,
+		"only_in_b":       onlyB,
+	}
+}
+
+// similarity returns the Dice coefficient of two line lists sharing shared
+// lines, rounded to two decimals; empty lists are identical
+func similarity(shared, lenA, lenB int) float64 {
+	if lenA+lenB == 0 {
+		return 1
+	}
+	return math.Round(200*float64(shared)/float64(lenA+lenB)) / 100
+}
+
+// diffLines diffs two line lists with their longest common subsequence,
+// returning the removed lines prefixed with "- ", the added ones with
+// "+ " and the number of common lines. Lines beyond maxCompareLines are
+// left out.
+func diffLines(a, b []string) ([]string, int) {
+	a, b = a[:min(len(a), maxCompareLines)], b[:min(len(b), maxCompareLines)]
+
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, "- "+cutLine(a[i]))
+			i++
+		default:
+			diff = append(diff, "+ "+cutLine(b[j]))
+			j++
+		}
+	}
+	return diff, lcs[0][0]
+}
+
+// cutLine cuts a diff line to maxCompareLineLength runes
+func cutLine(line string) string {
+	runes := []rune(line)
+	if len(runes) <= maxCompareLineLength {
+		return line
+	}
+	return string(runes[:maxCompareLineLength]) + "…"
+}
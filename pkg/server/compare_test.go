@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSections(t *testing.T) {
+	sections := splitSections("Intro text\n\n# Product\n\n## Pricing:\n$10\n\n```sh\n# not a heading\n```\n## Pricing\n$20\n#hashtag")
+	require.Len(t, sections, 4)
+	assert.Equal(t, introHeading, sections[0].heading)
+	assert.Equal(t, []string{"Intro text"}, sections[0].lines)
+	assert.Equal(t, "Product", sections[1].heading)
+	assert.Empty(t, sections[1].lines)
+	assert.Equal(t, "pricing", sections[2].key)
+	assert.Equal(t, []string{"$10", "```sh", "# not a heading", "```"}, sections[2].lines)
+	assert.Equal(t, "pricing#2", sections[3].key, "repeated headings are numbered")
+	assert.Equal(t, []string{"$20", "#hashtag"}, sections[3].lines)
+
+	assert.Empty(t, splitSections(""))
+}
+
+func TestDiffLines(t *testing.T) {
+	diff, shared := diffLines([]string{"a", "b", "c", "d"}, []string{"a", "c", "e", "d"})
+	assert.Equal(t, 3, shared)
+	assert.Equal(t, []string{"- b", "+ e"}, diff)
+
+	diff, shared = diffLines(nil, []string{"x"})
+	assert.Zero(t, shared)
+	assert.Equal(t, []string{"+ x"}, diff)
+
+	long := strings.Repeat("é", maxCompareLineLength+10)
+	diff, _ = diffLines([]string{long}, nil)
+	assert.Equal(t, "- "+strings.Repeat("é", maxCompareLineLength)+"…", diff[0])
+}
+
+func TestComparePages(t *testing.T) {
+	a := splitSections("# Plans\nFree\nPro: $10\n# Support\nEmail\n# FAQ\nQ1")
+	b := splitSections("# plans\nFree\nPro: $12\n# Support\nEmail\n# Changelog\nv2")
+	output := comparePages(a, b)
+
+	assert.Equal(t, []string{"FAQ"}, output["only_in_a"])
+	assert.Equal(t, []string{"Changelog"}, output["only_in_b"])
+	common := output["common_sections"].([]map[string]interface{})
+	require.Len(t, common, 2)
+	assert.Equal(t, "Plans", common[0]["heading"])
+	assert.Equal(t, 0.5, common[0]["similarity"])
+	assert.Equal(t, []string{"- Pro: $10", "+ Pro: $12"}, common[0]["diff"])
+	assert.Equal(t, 1.0, common[1]["similarity"])
+	assert.NotContains(t, common[1], "diff")
+	assert.Equal(t, 0.5, output["similarity"], "2 of 4 lines on each page are shared")
+}
+
+func TestHandleComparePages(t *testing.T) {
+	gock.Off()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		price := "10"
+		if r.URL.Path == "/b" {
+			price = "12"
+		}
+		_, _ = w.Write([]byte(`<html><body><h1>Widget</h1><h2>Pricing</h2><p>Costs $` + price + ` a month.</p><h2>Support</h2><p>By email.</p></body></html>`))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_compare_pages", map[string]interface{}{
+		"url_a": ts.URL + "/a", "url_b": ts.URL + "/b", "mode": "full",
+	})
+	require.False(t, result.IsError)
+	output := result.StructuredContent.(map[string]interface{})
+	assert.Equal(t, ts.URL+"/a", output["url_a"])
+	assert.Empty(t, output["only_in_a"])
+	var pricing map[string]interface{}
+	for _, section := range output["common_sections"].([]map[string]interface{}) {
+		if section["heading"] == "Pricing" {
+			pricing = section
+		}
+	}
+	require.NotNil(t, pricing)
+	assert.Equal(t, []string{"- Costs $10 a month.", "+ Costs $12 a month."}, pricing["diff"])
+
+	result = callToolResult(t, srv, "searxng_compare_pages", map[string]interface{}{"url_a": ts.URL + "/a"})
+	assert.True(t, result.IsError)
+	result = callToolResult(t, srv, "searxng_compare_pages", map[string]interface{}{"url_a": ts.URL + "/a", "url_b": "ftp://example.com/"})
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// Confidence levels attached to answers and infoboxes. They are a hint for
+// agents on whether to verify a fact with searxng_read before asserting it.
+const (
+	confidenceHigh   = "high"   // two or more engines agree
+	confidenceMedium = "medium" // one source, corroborated by a result snippet
+	confidenceLow    = "low"    // one or unknown source, uncorroborated
+)
+
+// corroborationThreshold is the share of an answer's significant words that
+// must appear in a single result for the result to corroborate it
+const corroborationThreshold = 0.6
+
+// assessAnswers returns one confidence entry per answer, in the order of
+// resp.AnswerDetails (or resp.Answers when the instance reports no sources)
+func assessAnswers(resp *searxng.SearchResponse) []map[string]interface{} {
+	answers := resp.AnswerDetails
+	if len(answers) == 0 {
+		for _, text := range resp.Answers {
+			answers = append(answers, searxng.Answer{Text: text})
+		}
+	}
+
+	assessments := make([]map[string]interface{}, 0, len(answers))
+	for _, answer := range answers {
+		key := normalizeText(answer.Text)
+
+		// Engines agreeing on the same answer, directly or via an infobox
+		engines := []string{}
+		for _, other := range answers {
+			if other.Engine != "" && normalizeText(other.Text) == key {
+				engines = appendUnique(engines, other.Engine)
+			}
+		}
+		for _, infobox := range resp.Infoboxes {
+			if infobox.Engine != "" && key != "" && strings.Contains(normalizeText(infobox.Content), key) {
+				engines = appendUnique(engines, infobox.Engine)
+			}
+		}
+
+		corroborated := false
+		for _, result := range resp.Results {
+			if wordOverlap(answer.Text, result.Title+" "+result.Content) >= corroborationThreshold {
+				corroborated = true
+				break
+			}
+		}
+
+		entry := map[string]interface{}{
+			"answer":       answer.Text,
+			"engines":      engines,
+			"corroborated": corroborated,
+			"confidence":   confidenceLevel(len(engines), corroborated),
+		}
+		if answer.URL != "" {
+			entry["source_url"] = answer.URL
+		}
+		assessments = append(assessments, entry)
+	}
+	return assessments
+}
+
+// assessInfoboxes returns one confidence entry per infobox
+func assessInfoboxes(resp *searxng.SearchResponse) []map[string]interface{} {
+	assessments := make([]map[string]interface{}, 0, len(resp.Infoboxes))
+	for _, infobox := range resp.Infoboxes {
+		label := normalizeText(infobox.Label)
+
+		engines := []string{}
+		for _, other := range resp.Infoboxes {
+			if other.Engine != "" && normalizeText(other.Label) == label {
+				engines = appendUnique(engines, other.Engine)
+			}
+		}
+
+		hosts := make(map[string]bool)
+		for _, u := range infobox.Urls {
+			if parsed, err := url.Parse(u.URL); err == nil && parsed.Hostname() != "" {
+				hosts[strings.TrimPrefix(parsed.Hostname(), "www.")] = true
+			}
+		}
+		corroborated := false
+		for _, result := range resp.Results {
+			parsed, err := url.Parse(result.URL)
+			if err == nil && hosts[strings.TrimPrefix(parsed.Hostname(), "www.")] {
+				corroborated = true
+				break
+			}
+			if label != "" && strings.Contains(normalizeText(result.Title), label) {
+				corroborated = true
+				break
+			}
+		}
+
+		assessments = append(assessments, map[string]interface{}{
+			"label":        infobox.Label,
+			"engines":      engines,
+			"corroborated": corroborated,
+			"confidence":   confidenceLevel(len(engines), corroborated),
+		})
+	}
+	return assessments
+}
+
+// confidenceLevel maps agreement and corroboration to a confidence level
+func confidenceLevel(agreeingEngines int, corroborated bool) string {
+	switch {
+	case agreeingEngines >= 2:
+		return confidenceHigh
+	case corroborated:
+		return confidenceMedium
+	default:
+		return confidenceLow
+	}
+}
+
+// normalizeText lowercases s and collapses punctuation and whitespace
+func normalizeText(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// wordOverlap returns the share of significant words of text (longer than
+// three characters) that also appear in other
+func wordOverlap(text, other string) float64 {
+	otherWords := make(map[string]bool)
+	for _, w := range strings.Fields(normalizeText(other)) {
+		otherWords[w] = true
+	}
+
+	total, found := 0, 0
+	for _, w := range strings.Fields(normalizeText(text)) {
+		if len([]rune(w)) <= 3 {
+			continue
+		}
+		total++
+		if otherWords[w] {
+			found++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(found) / float64(total)
+}
+
+func appendUnique(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssessAnswers(t *testing.T) {
+	resp := &searxng.SearchResponse{
+		AnswerDetails: []searxng.Answer{
+			{Text: "Paris is the capital of France.", Engine: "wikipedia"},
+			{Text: "paris is the capital of france", Engine: "duckduckgo"},
+			{Text: "Berlin has 3.7 million inhabitants", Engine: "wikidata", URL: "https://www.wikidata.org/wiki/Q64"},
+			{Text: "Something nobody else says", Engine: "bing"},
+		},
+		Results: []searxng.SearchResult{
+			{Title: "Berlin", Content: "Berlin has about 3.7 million inhabitants."},
+		},
+	}
+
+	assessments := assessAnswers(resp)
+	require.Len(t, assessments, 4)
+
+	assert.Equal(t, confidenceHigh, assessments[0]["confidence"])
+	assert.Equal(t, []string{"wikipedia", "duckduckgo"}, assessments[0]["engines"])
+
+	assert.Equal(t, confidenceMedium, assessments[2]["confidence"])
+	assert.Equal(t, true, assessments[2]["corroborated"])
+	assert.Equal(t, "https://www.wikidata.org/wiki/Q64", assessments[2]["source_url"])
+
+	assert.Equal(t, confidenceLow, assessments[3]["confidence"])
+}
+
+func TestAssessAnswers_PlainStrings(t *testing.T) {
+	resp := &searxng.SearchResponse{
+		Answers: []string{"42"},
+	}
+
+	assessments := assessAnswers(resp)
+	require.Len(t, assessments, 1)
+	assert.Equal(t, "42", assessments[0]["answer"])
+	assert.Equal(t, []string{}, assessments[0]["engines"])
+	assert.Equal(t, confidenceLow, assessments[0]["confidence"])
+}
+
+func TestAssessAnswers_InfoboxAgreement(t *testing.T) {
+	resp := &searxng.SearchResponse{
+		AnswerDetails: []searxng.Answer{{Text: "Go 1.22", Engine: "duckduckgo"}},
+		Infoboxes:     []searxng.Infobox{{Engine: "wikipedia", Label: "Go", Content: "The latest release is Go 1.22."}},
+	}
+
+	assessments := assessAnswers(resp)
+	require.Len(t, assessments, 1)
+	assert.Equal(t, confidenceHigh, assessments[0]["confidence"])
+}
+
+func TestAssessInfoboxes(t *testing.T) {
+	resp := &searxng.SearchResponse{
+		Infoboxes: []searxng.Infobox{
+			{Label: "Go (programming language)", Engine: "wikipedia", Urls: []searxng.InfoboxURL{{URL: "https://go.dev"}}},
+			{Label: "Go (programming language)", Engine: "wikidata"},
+			{Label: "Gopher", Engine: "wikipedia"},
+		},
+		Results: []searxng.SearchResult{
+			{URL: "https://go.dev/doc/", Title: "Documentation"},
+		},
+	}
+
+	assessments := assessInfoboxes(resp)
+	require.Len(t, assessments, 3)
+	assert.Equal(t, confidenceHigh, assessments[0]["confidence"])
+	assert.Equal(t, true, assessments[0]["corroborated"])
+	assert.Equal(t, confidenceLow, assessments[2]["confidence"])
+}
+
+func TestFormatSearchResults_AnswerConfidence(t *testing.T) {
+	resp := &searxng.SearchResponse{
+		Query:   "q",
+		Answers: []string{"answer 1"},
+	}
+
+	result := formatSearchResults(resp)
+	assert.Len(t, result["answer_confidence"], 1)
+	assert.NotContains(t, result, "infobox_confidence")
+}
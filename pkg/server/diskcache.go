@@ -0,0 +1,266 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+)
+
+// DefaultReadDiskCacheSize is the default maximum number of pages kept by
+// the disk cache
+const DefaultReadDiskCacheSize = 1000
+
+// maxDiskCacheBody bounds the size of cached page bodies; larger pages are
+// not cached
+const maxDiskCacheBody = 8 << 20
+
+// diskCacheHeaders are the response headers kept with cached pages: those
+// the readers and the freshness computation use
+var diskCacheHeaders = []string{"Content-Type", "ETag", "Last-Modified", "Cache-Control", "Expires", "Date"}
+
+// diskCache keeps fetched pages on disk across restarts, keyed by URL,
+// with their validators (ETag, Last-Modified). Pages still fresh per their
+// caching headers are served without a request; others are revalidated
+// with a conditional request, and a 304 answer serves the cached body.
+// Only pages with a validator or a freshness lifetime are cached. Each page
+// is a .json metadata file and a .body file; the least recently stored
+// pages are removed beyond size. It is safe for concurrent use.
+type diskCache struct {
+	mu   sync.Mutex
+	dir  string
+	size int
+	now  func() time.Time
+}
+
+// diskCacheEntry is the metadata of a cached page
+type diskCacheEntry struct {
+	URL       string      `json:"url"`
+	Header    http.Header `json:"header"`
+	FetchedAt time.Time   `json:"fetched_at"`
+
+	body []byte
+}
+
+// newDiskCache creates a cache in dir, creating the directory, or returns
+// nil when dir is empty
+func newDiskCache(dir string, size int) (*diskCache, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the read cache directory: %w", err)
+	}
+	if size <= 0 {
+		size = DefaultReadDiskCacheSize
+	}
+	return &diskCache{dir: dir, size: size, now: time.Now}, nil
+}
+
+// path returns the path of the file of url with the given extension
+func (c *diskCache) path(url, ext string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+ext)
+}
+
+// load returns the cached page of url, nil when there is none or c is nil
+func (c *diskCache) load(url string) *diskCacheEntry {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.path(url, ".json"))
+	if err != nil {
+		return nil
+	}
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil
+	}
+	if entry.body, err = os.ReadFile(c.path(url, ".body")); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// fresh reports whether the entry can be served without revalidation
+func (e *diskCacheEntry) fresh(now time.Time) bool {
+	f := parseFreshness(e.Header, e.FetchedAt)
+	if f == nil || f.NoStore || f.MaxAge <= 0 {
+		return false
+	}
+	return now.Before(e.FetchedAt.Add(time.Duration(f.MaxAge) * time.Second))
+}
+
+// addValidators makes req conditional on the entry being outdated
+func (e *diskCacheEntry) addValidators(req *http.Request) {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// response returns the entry as a 200 response to req, with an Age header
+// so its freshness is computed from the original fetch
+func (e *diskCacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(now.Sub(e.FetchedAt).Seconds())))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// revalidated records that the origin confirmed the entry with a 304
+// response carrying header, and returns the refreshed entry
+func (c *diskCache) revalidated(entry *diskCacheEntry, header http.Header) *diskCacheEntry {
+	refreshed := *entry
+	refreshed.Header = entry.Header.Clone()
+	for _, name := range diskCacheHeaders {
+		if value := header.Get(name); value != "" && name != "Content-Type" {
+			refreshed.Header.Set(name, value)
+		}
+	}
+	refreshed.FetchedAt = c.now()
+	if err := c.write(&refreshed); err != nil {
+		log.WithField("error", err).Debug("failed to update the read cache")
+	}
+	return &refreshed
+}
+
+// store caches the page of resp, a response to a request for url, when it
+// is a cacheable 200 response, and returns a response with an unread body.
+// Uncacheable responses are returned as is.
+func (c *diskCache) store(url string, resp *http.Response) *http.Response {
+	if c == nil || resp.StatusCode != http.StatusOK {
+		return resp
+	}
+	f := parseFreshness(resp.Header, c.now())
+	hasValidator := resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+	if (f != nil && f.NoStore) || (!hasValidator && (f == nil || f.MaxAge <= 0)) {
+		return resp
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDiskCacheBody+1))
+	if err != nil || len(body) > maxDiskCacheBody {
+		// Hand the body back whole, without caching it
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &diskCacheEntry{URL: url, Header: http.Header{}, FetchedAt: c.now(), body: body}
+	for _, name := range diskCacheHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			entry.Header.Set(name, value)
+		}
+	}
+	if err := c.write(entry); err != nil {
+		log.WithField("error", err).Debug("failed to write the read cache")
+	}
+	return resp
+}
+
+// readCloser reads from a reader and closes a closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// write saves entry, atomically per file, then evicts the least recently
+// stored entries beyond c.size
+func (c *diskCache) write(entry *diskCacheEntry) error {
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The body goes first, so a metadata file always has its body
+	if err := writeFileAtomic(c.path(entry.URL, ".body"), entry.body); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.path(entry.URL, ".json"), meta); err != nil {
+		return err
+	}
+	return c.evict()
+}
+
+// evict removes the entries with the oldest metadata beyond c.size. c.mu
+// must be held.
+func (c *diskCache) evict() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	type stored struct {
+		key     string
+		modTime time.Time
+	}
+	var entries []stored
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if info, err := file.Info(); err == nil {
+			entries = append(entries, stored{key, info.ModTime()})
+		}
+	}
+	if len(entries) <= c.size {
+		return nil
+	}
+	slices.SortFunc(entries, func(a, b stored) int { return a.modTime.Compare(b.modTime) })
+	for _, entry := range entries[:len(entries)-c.size] {
+		for _, ext := range []string{".json", ".body"} {
+			if err := os.Remove(filepath.Join(c.dir, entry.key+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file renamed to path, so
+// readers never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache_Revalidation(t *testing.T) {
+	var requests, notModified atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("<html><body><p>Cached page</p></body></html>"))
+	}))
+	defer ts.Close()
+
+	cache, err := newDiskCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	opts := readOptions{DiskCache: cache}
+
+	for range 2 {
+		markdown, err := fetchURLContent(context.Background(), ts.URL, opts)
+		require.NoError(t, err)
+		assert.Contains(t, markdown, "Cached page")
+	}
+	assert.EqualValues(t, 2, requests.Load())
+	assert.EqualValues(t, 1, notModified.Load(), "the second read is revalidated")
+
+	// The cache survives restarts
+	cache, err = newDiskCache(cache.dir, 0)
+	require.NoError(t, err)
+	markdown, err := fetchURLContent(context.Background(), ts.URL, readOptions{DiskCache: cache})
+	require.NoError(t, err)
+	assert.Contains(t, markdown, "Cached page")
+	assert.EqualValues(t, 2, notModified.Load())
+}
+
+func TestDiskCache_Fresh(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte("<html><body><p>Fresh page</p></body></html>"))
+	}))
+	defer ts.Close()
+
+	cache, err := newDiskCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	opts := readOptions{DiskCache: cache}
+
+	for range 2 {
+		markdown, err := fetchURLContent(context.Background(), ts.URL, opts)
+		require.NoError(t, err)
+		assert.Contains(t, markdown, "Fresh page")
+	}
+	assert.EqualValues(t, 1, requests.Load(), "fresh pages are served without a request")
+
+	entry := cache.load(ts.URL)
+	require.NotNil(t, entry)
+	assert.False(t, entry.fresh(now.Add(time.Minute+time.Second)))
+}
+
+func TestDiskCache_Uncacheable(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte("<html><body><p>Private page</p></body></html>"))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	cache, err := newDiskCache(dir, 0)
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := fetchURLContent(context.Background(), ts.URL, readOptions{DiskCache: cache})
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, requests.Load())
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files, "no-store pages are not written")
+}
+
+func TestDiskCache_Eviction(t *testing.T) {
+	cache, err := newDiskCache(t.TempDir(), 2)
+	require.NoError(t, err)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i, url := range []string{"https://a.example", "https://b.example", "https://c.example"} {
+		require.NoError(t, cache.write(&diskCacheEntry{URL: url, Header: http.Header{}, FetchedAt: now, body: []byte(url)}))
+		// Order the entries by modification time
+		mtime := now.Add(-time.Hour + time.Duration(i)*time.Second)
+		require.NoError(t, os.Chtimes(cache.path(url, ".json"), mtime, mtime))
+	}
+	// The eviction runs on the next write
+	require.NoError(t, cache.write(&diskCacheEntry{URL: "https://c.example", Header: http.Header{}, FetchedAt: now, body: []byte("c")}))
+
+	assert.Nil(t, cache.load("https://a.example"))
+	assert.NotNil(t, cache.load("https://b.example"))
+	entry := cache.load("https://c.example")
+	require.NotNil(t, entry)
+	assert.Equal(t, "c", string(entry.body))
+}
+
+func TestNewDiskCache_Disabled(t *testing.T) {
+	cache, err := newDiskCache("", 0)
+	require.NoError(t, err)
+	assert.Nil(t, cache)
+	assert.Nil(t, cache.load("https://example.com"), "nil caches are usable")
+}
@@ -0,0 +1,25 @@
+// Package server exposes a Searxng client as MCP tools (searxng_search,
+// searxng_read, ...), with their caches, limits and transports.
+//
+// NewWithOptions creates a standalone MCP server serving the tools over
+// stdio, StreamableHTTP or SSE. Programs with an MCP server of their own
+// register the tools onto it with RegisterTools instead:
+//
+//	mcpServer := mcpserver.NewMCPServer("my-server", "1.0.0", mcpserver.WithHooks(hooks))
+//	mcpServer.AddTool(myTool, myHandler)
+//	srv := server.RegisterTools(mcpServer, client, server.Options{ReadCacheTTL: 10 * time.Minute})
+//	srv.AddHooks(hooks)
+//
+// # Stability
+//
+// The embedding API follows semantic versioning: New, NewWithOptions,
+// RegisterTools, the exported methods of Server, Options and the other
+// exported types won't be removed or changed incompatibly within a major
+// version. Options may gain fields, whose zero value keeps the previous
+// behavior. The names, required arguments and result fields of the tools
+// are stable too; tools may gain optional arguments and result fields, and
+// their descriptions may change at any time.
+//
+// MCPServer gives access to the underlying mcp-go server, whose API is not
+// covered: it follows the mcp-go releases this module depends on.
+package server
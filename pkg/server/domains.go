@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// domainFilter keeps or drops results by the host of their URL. Domains
+// match their subdomains.
+type domainFilter struct {
+	include []string // when set, only these domains are kept
+	exclude []string // dropped, even when included
+}
+
+// active reports whether the filter drops anything
+func (f domainFilter) active() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0
+}
+
+// allows reports whether the filter keeps rawURL. URLs without a host are
+// only kept without an include list.
+func (f domainFilter) allows(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return len(f.include) == 0
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if onDomain(host, f.exclude) {
+		return false
+	}
+	return len(f.include) == 0 || onDomain(host, f.include)
+}
+
+// apply returns a copy of resp without the results the filter drops, and
+// the number of dropped results
+func (f domainFilter) apply(resp *searxng.SearchResponse) (*searxng.SearchResponse, int) {
+	if !f.active() {
+		return resp, 0
+	}
+	results := make([]searxng.SearchResult, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		if f.allows(result.URL) {
+			results = append(results, result)
+		}
+	}
+
+	filtered := *resp
+	filtered.Results = results
+	return &filtered, len(resp.Results) - len(results)
+}
+
+// onDomain reports whether host is one of domains or a subdomain of one
+func onDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomain turns "Go.dev", ".go.dev", "*.go.dev" or
+// "https://go.dev/doc" into "go.dev"
+func normalizeDomain(domain string) (string, error) {
+	d := strings.ToLower(strings.TrimSpace(domain))
+	if strings.Contains(d, "://") {
+		parsed, err := url.Parse(d)
+		if err != nil {
+			return "", fmt.Errorf("invalid domain: %q", domain)
+		}
+		d = parsed.Hostname()
+	}
+	d = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(d, "*"), "."), ".")
+	if d == "" || strings.ContainsAny(d, " /:?#@*") {
+		return "", fmt.Errorf("invalid domain: %q", domain)
+	}
+	return d, nil
+}
+
+// ValidateDomains checks the domains of Options.IncludeDomains or
+// Options.ExcludeDomains: host names such as "go.dev", optionally with a
+// leading "." or "*." or as a URL
+func ValidateDomains(domains []string) error {
+	for _, domain := range domains {
+		if _, err := normalizeDomain(domain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDomainFilter reads the include_domains and exclude_domains
+// arguments, each a list of domains or a comma-separated string
+func parseDomainFilter(args map[string]interface{}) (domainFilter, error) {
+	var f domainFilter
+	for _, arg := range []struct {
+		name string
+		list *[]string
+	}{{"include_domains", &f.include}, {"exclude_domains", &f.exclude}} {
+		name, list := arg.name, arg.list
+		var raw []string
+		switch v := args[name].(type) {
+		case nil:
+			continue
+		case string:
+			raw = strings.Split(v, ",")
+		case []interface{}:
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return f, fmt.Errorf("%s must be a list of domains", name)
+				}
+				raw = append(raw, s)
+			}
+		default:
+			return f, fmt.Errorf("%s must be a list of domains", name)
+		}
+		for _, domain := range raw {
+			if strings.TrimSpace(domain) == "" {
+				continue
+			}
+			d, err := normalizeDomain(domain)
+			if err != nil {
+				return f, fmt.Errorf("%s: %w", name, err)
+			}
+			*list = append(*list, d)
+		}
+	}
+	return f, nil
+}
+
+// withSiteOperator adds a site: operator to query when the filter includes
+// a single domain, so engines return results from it rather than the
+// filter dropping everything else. Several domains are only filtered, as
+// engines disagree on OR syntax.
+func withSiteOperator(query string, f domainFilter) string {
+	if len(f.include) != 1 || strings.Contains(strings.ToLower(query), "site:") {
+		return query
+	}
+	return query + " site:" + f.include[0]
+}
+
+// newOperatorDomains returns the filter of Options.IncludeDomains and
+// Options.ExcludeDomains, skipping invalid domains (see ValidateDomains)
+func newOperatorDomains(options Options) domainFilter {
+	return domainFilter{
+		include: validDomains(options.IncludeDomains),
+		exclude: validDomains(options.ExcludeDomains),
+	}
+}
+
+// validDomains normalizes domains, logging and skipping invalid ones
+func validDomains(domains []string) []string {
+	var valid []string
+	for _, domain := range domains {
+		d, err := normalizeDomain(domain)
+		if err != nil {
+			log.WithField("error", err).Error("skipping invalid domain")
+			continue
+		}
+		valid = append(valid, d)
+	}
+	return valid
+}
+
+// checkRead returns an error when the operator's domain lists or
+// robots.txt forbid reading rawURL
+func (s *Server) checkRead(ctx context.Context, rawURL string) error {
+	if !s.domains.allows(rawURL) {
+		return fmt.Errorf("%s is not on a domain this server may read", rawURL)
+	}
+	return s.checkRobots(ctx, rawURL)
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	for input, want := range map[string]string{
+		"go.dev":             "go.dev",
+		" Go.Dev ":           "go.dev",
+		".go.dev":            "go.dev",
+		"*.go.dev":           "go.dev",
+		"https://go.dev/doc": "go.dev",
+		"localhost":          "localhost",
+	} {
+		got, err := normalizeDomain(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	for _, input := range []string{"", "go.dev/doc", "go dev", "*"} {
+		_, err := normalizeDomain(input)
+		assert.Error(t, err, input)
+	}
+	assert.NoError(t, ValidateDomains([]string{"go.dev", "*.example.com"}))
+	assert.EqualError(t, ValidateDomains([]string{"go.dev", "a b"}), `invalid domain: "a b"`)
+}
+
+func TestDomainFilter(t *testing.T) {
+	f := domainFilter{include: []string{"go.dev", "github.com"}, exclude: []string{"gist.github.com"}}
+	assert.True(t, f.allows("https://go.dev/doc"))
+	assert.True(t, f.allows("https://pkg.go.dev/net/http"), "subdomains match")
+	assert.True(t, f.allows("https://GitHub.com:443/golang/go"))
+	assert.False(t, f.allows("https://gist.github.com/x"), "exclusion wins")
+	assert.False(t, f.allows("https://notgo.dev/"), "only whole labels match")
+	assert.False(t, f.allows("/relative"))
+	assert.True(t, domainFilter{exclude: []string{"go.dev"}}.allows("/relative"))
+
+	resp := &searxng.SearchResponse{Results: []searxng.SearchResult{
+		{URL: "https://go.dev/"}, {URL: "https://example.com/"}, {URL: "https://pkg.go.dev/"},
+	}}
+	filtered, dropped := f.apply(resp)
+	assert.Equal(t, 1, dropped)
+	assert.Len(t, filtered.Results, 2)
+	assert.Len(t, resp.Results, 3, "the response is copied")
+}
+
+func TestParseDomainFilter(t *testing.T) {
+	f, err := parseDomainFilter(map[string]interface{}{
+		"include_domains": []interface{}{"Go.dev", " "},
+		"exclude_domains": "pinterest.com, *.quora.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go.dev"}, f.include)
+	assert.Equal(t, []string{"pinterest.com", "quora.com"}, f.exclude)
+	assert.Equal(t, "http2 site:go.dev", withSiteOperator("http2", f))
+	assert.Equal(t, "http2 site:go.dev", withSiteOperator("http2 site:go.dev", f), "explicit site: operators are kept")
+	assert.Equal(t, "http2", withSiteOperator("http2", domainFilter{include: []string{"a.com", "b.com"}}))
+
+	_, err = parseDomainFilter(map[string]interface{}{"include_domains": []interface{}{1.0}})
+	assert.EqualError(t, err, "include_domains must be a list of domains")
+	_, err = parseDomainFilter(map[string]interface{}{"exclude_domains": "a/b"})
+	assert.EqualError(t, err, `exclude_domains: invalid domain: "a/b"`)
+}
+
+func TestHandleWebSearch_Domains(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "^generics site:go.dev$").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "generics site:go.dev", Results: []searxng.APIResult{
+			{URL: "https://go.dev/doc/tutorial/generics", Title: "Tutorial"},
+			{URL: "https://tip.golang.org/doc/go1.18", Title: "Go 1.18"},
+			{URL: "https://pinterest.com/go", Title: "Pins"},
+		}})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{ExcludeDomains: []string{"pinterest.com"}})
+
+	result := callToolResult(t, srv, "searxng_search", map[string]interface{}{
+		"query":           "generics",
+		"include_domains": []interface{}{"go.dev"},
+	})
+	require.False(t, result.IsError)
+	output := result.StructuredContent.(map[string]interface{})
+	results := output["results"].([]map[string]interface{})
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://go.dev/doc/tutorial/generics", results[0]["url"])
+	assert.Equal(t, 1, output["domain_filtered"], "the operator's exclusion isn't counted")
+}
+
+func TestHandleWebRead_OperatorDomains(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{IncludeDomains: []string{"go.dev"}})
+
+	result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": "https://example.com/"}},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not on a domain this server may read")
+
+	_, err = srv.Read(context.Background(), ReadRequest{URL: "https://example.com/"})
+	assert.ErrorContains(t, err, "not on a domain this server may read")
+}
+
+func TestHandleWebRead_RedirectChecks(t *testing.T) {
+	var fetched atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.Path {
+		case "allowed.example/to-excluded":
+			http.Redirect(w, r, "http://excluded.example/page", http.StatusFound)
+		case "allowed.example/to-disallowed":
+			http.Redirect(w, r, "http://robots.example/private", http.StatusFound)
+		case "robots.example/robots.txt":
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		case "allowed.example/robots.txt", "excluded.example/robots.txt":
+			http.NotFound(w, r)
+		default:
+			fetched.Add(1)
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("page"))
+		}
+	}))
+	defer site.Close()
+	// Every host is served by site
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, site.Listener.Addr().String())
+	}}
+
+	srv := NewWithOptions(nil, Options{ExcludeDomains: []string{"excluded.example"}, RespectRobots: true, Transport: transport})
+	for url, reason := range map[string]string{
+		"http://allowed.example/to-excluded":   "not on a domain this server may read",
+		"http://allowed.example/to-disallowed": "disallowed by the site's robots.txt",
+	} {
+		result := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": url})
+		require.True(t, result.IsError, url)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "redirect blocked", url)
+		assert.Contains(t, text, reason, url)
+	}
+	assert.Zero(t, fetched.Load(), "the redirect targets aren't fetched")
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// parseEngines reads the engines argument, given either as an array or as a
+// comma-separated string, normalized to lowercase
+func parseEngines(value interface{}) ([]string, error) {
+	var raw []string
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		raw = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("engines must be a list of engine names")
+			}
+			raw = append(raw, name)
+		}
+	default:
+		return nil, fmt.Errorf("engines must be a list of engine names")
+	}
+
+	var engines []string
+	for _, name := range raw {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(engines, name) {
+			engines = append(engines, name)
+		}
+	}
+	return engines, nil
+}
+
+// validateEngines checks names against the engines enabled on the
+// instance. When the instance doesn't publish its engine list, names are
+// passed through unchecked.
+func validateEngines(ctx context.Context, client *searxng.Client, names []string) error {
+	engines, err := client.Engines(ctx)
+	if err != nil {
+		log.WithField("error", err).Debug("engine list unavailable, not validating engines")
+		return nil
+	}
+
+	var enabled, unknown, disabled []string
+	for _, engine := range engines {
+		if engine.Enabled {
+			enabled = append(enabled, engine.Name)
+		}
+	}
+	for _, name := range names {
+		i := slices.IndexFunc(engines, func(e searxng.Engine) bool { return strings.EqualFold(e.Name, name) })
+		switch {
+		case i < 0:
+			unknown = append(unknown, name)
+		case !engines[i].Enabled:
+			disabled = append(disabled, name)
+		}
+	}
+	if len(unknown) == 0 && len(disabled) == 0 {
+		return nil
+	}
+
+	slices.Sort(enabled)
+	var problems []string
+	if len(unknown) > 0 {
+		problems = append(problems, "unknown engines: "+strings.Join(unknown, ", "))
+	}
+	if len(disabled) > 0 {
+		problems = append(problems, "engines disabled on this instance: "+strings.Join(disabled, ", "))
+	}
+	return fmt.Errorf("%s (enabled engines: %s)", strings.Join(problems, "; "), strings.Join(enabled, ", "))
+}
+
+// engineArgKeys are the settings accepted per engine by engine_args
+var engineArgKeys = []string{"language"}
+
+// parseEngineArgs reads the engine_args argument, an object mapping engine
+// names to their settings, e.g. {"wikipedia": {"language": "de"}}
+func parseEngineArgs(value interface{}) (map[string]searxng.EngineArgs, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("engine_args must be an object mapping engine names to their settings")
+	}
+
+	engineArgs := make(map[string]searxng.EngineArgs, len(raw))
+	for name, value := range raw {
+		settings, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("engine_args[%q] must be an object", name)
+		}
+		var args searxng.EngineArgs
+		for key, setting := range settings {
+			switch key {
+			case "language":
+				if args.Language, ok = setting.(string); !ok {
+					return nil, fmt.Errorf("engine_args[%q].language must be a string", name)
+				}
+			default:
+				return nil, fmt.Errorf("engine_args[%q]: unsupported setting %q (supported: %s)", name, key, strings.Join(engineArgKeys, ", "))
+			}
+		}
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			engineArgs[name] = args
+		}
+	}
+	return engineArgs, nil
+}
+
+// validateInstanceSupport checks the time range, category and language of
+// req against the instance's version, categories and locales (see
+// searxng.InstanceInfo.Supports). When the instance doesn't publish its
+// /config, req is passed through unchecked.
+func validateInstanceSupport(ctx context.Context, client *searxng.Client, req searxng.SearchRequest) error {
+	if req.TimeRange == "" && req.Category == "" && req.Language == "" {
+		return nil
+	}
+	info, err := client.InstanceInfo(ctx)
+	if err != nil {
+		log.WithField("error", err).Debug("instance info unavailable, not checking capabilities")
+		return nil
+	}
+	return info.Supports(req)
+}
+
+// AdaptToInstance lists the categories the instance publishes at /config as
+// the enum of the category parameter of the tools, so clients only offer
+// categories that exist there. Without a /config the parameter stays a free
+// string.
+func (s *Server) AdaptToInstance(ctx context.Context) error {
+	if s.searxngClient == nil {
+		return nil
+	}
+	info, err := s.searxngClient.InstanceInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the instance config: %w", err)
+	}
+	if len(info.Categories) == 0 {
+		return nil
+	}
+	if s.options.DefaultCategory != "" && !slices.Contains(info.Categories, s.options.DefaultCategory) {
+		log.WithField("category", s.options.DefaultCategory).Warn("the default category is not one of the instance")
+	}
+
+	var adapted []mcpserver.ServerTool
+	for _, builtin := range builtinTools {
+		// Only the searxng tools, which may share the MCP server with others
+		tool := s.mcpServer.GetTool(s.toolName(builtin))
+		if tool == nil {
+			continue
+		}
+		category, ok := tool.Tool.InputSchema.Properties["category"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// The registered schema may be listed concurrently, so it is copied
+		category = maps.Clone(category)
+		category["enum"] = slices.Clone(info.Categories)
+		tool.Tool.InputSchema.Properties = maps.Clone(tool.Tool.InputSchema.Properties)
+		tool.Tool.InputSchema.Properties["category"] = category
+		adapted = append(adapted, *tool)
+	}
+	s.mcpServer.AddTools(adapted...)
+	return nil
+}
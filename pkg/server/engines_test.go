@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEngines(t *testing.T) {
+	engines, err := parseEngines([]interface{}{"Wikipedia", " github ", "wikipedia"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wikipedia", "github"}, engines)
+
+	engines, err = parseEngines("wikipedia, stackoverflow,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wikipedia", "stackoverflow"}, engines)
+
+	engines, err = parseEngines(nil)
+	require.NoError(t, err)
+	assert.Nil(t, engines)
+
+	_, err = parseEngines([]interface{}{float64(1)})
+	assert.Error(t, err)
+	_, err = parseEngines(float64(1))
+	assert.Error(t, err)
+}
+
+// newEnginesInstance serves a /config listing wikipedia (enabled) and bing
+// (disabled), and search results recording the engines parameter
+func newEnginesInstance(t *testing.T, publishConfig bool, gotEngines *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/config":
+			if !publishConfig {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"engines": []searxng.Engine{
+				{Name: "wikipedia", Enabled: true},
+				{Name: "bing", Enabled: false},
+			}})
+		case "/search":
+			*gotEngines = r.URL.Query().Get("engines")
+			_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: r.URL.Query().Get("q")})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestHandleWebSearch_Engines(t *testing.T) {
+	var gotEngines string
+	instance := newEnginesInstance(t, true, &gotEngines)
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+
+	search := func(engines interface{}) *mcp.CallToolResult {
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{"query": "q", "engines": engines}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := search([]interface{}{"Wikipedia"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "wikipedia", gotEngines)
+
+	result = search([]interface{}{"wikipedia", "nosuchengine", "bing"})
+	require.True(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "unknown engines: nosuchengine")
+	assert.Contains(t, text, "engines disabled on this instance: bing")
+	assert.Contains(t, text, "enabled engines: wikipedia")
+}
+
+func TestHandleWebSearch_EnginesWithoutConfig(t *testing.T) {
+	var gotEngines string
+	instance := newEnginesInstance(t, false, &gotEngines)
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+
+	result, err := New(client).handleWebSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{"query": "q", "engines": "anything"}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "anything", gotEngines, "names pass through when the instance hides its engine list")
+}
+
+func TestParseEngineArgs(t *testing.T) {
+	engineArgs, err := parseEngineArgs(map[string]interface{}{
+		"Wikipedia": map[string]interface{}{"language": "de"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]searxng.EngineArgs{"wikipedia": {Language: "de"}}, engineArgs)
+
+	engineArgs, err = parseEngineArgs(nil)
+	require.NoError(t, err)
+	assert.Nil(t, engineArgs)
+
+	_, err = parseEngineArgs("wikipedia")
+	assert.Error(t, err)
+	_, err = parseEngineArgs(map[string]interface{}{"wikipedia": "de"})
+	assert.Error(t, err)
+	_, err = parseEngineArgs(map[string]interface{}{"wikipedia": map[string]interface{}{"language": float64(1)}})
+	assert.Error(t, err)
+	_, err = parseEngineArgs(map[string]interface{}{"wikipedia": map[string]interface{}{"safesearch": "off"}})
+	assert.ErrorContains(t, err, `unsupported setting "safesearch" (supported: language)`)
+}
+
+func TestHandleWebSearch_EngineArgs(t *testing.T) {
+	var gotEngines string
+	instance := newEnginesInstance(t, true, &gotEngines)
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+
+	search := func(engineArgs map[string]interface{}) *mcp.CallToolResult {
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{
+				"query": "q", "engines": []interface{}{"wikipedia"}, "engine_args": engineArgs,
+			}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := search(map[string]interface{}{"wikipedia": map[string]interface{}{"language": "de"}})
+	require.False(t, result.IsError)
+	assert.Equal(t, "wikipedia", gotEngines)
+
+	result = search(map[string]interface{}{"bing": map[string]interface{}{"language": "de"}})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "engines disabled on this instance: bing")
+}
+
+func TestHandleWebSearch_InstanceSupport(t *testing.T) {
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/config":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"version": "0.18.0", "categories": []string{"general", "images"}})
+		case "/search":
+			_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: r.URL.Query().Get("q")})
+		}
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+
+	search := func(args map[string]interface{}) *mcp.CallToolResult {
+		args["query"] = "q"
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: args},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.False(t, search(map[string]interface{}{"time_range": "month", "category": "general"}).IsError)
+
+	result := search(map[string]interface{}{"time_range": "week"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `time_range "week" is not supported`)
+
+	result = search(map[string]interface{}{"category": "science"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "available categories: general, images")
+}
+
+func TestAdaptToInstance(t *testing.T) {
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"categories": []string{"general", "it", "science"}})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+	require.NoError(t, srv.AdaptToInstance(context.Background()))
+
+	for _, name := range []string{"searxng_search", "searxng_search_and_read", "searxng_refine_search"} {
+		tool := srv.mcpServer.GetTool(name)
+		require.NotNil(t, tool, name)
+		category := tool.Tool.InputSchema.Properties["category"].(map[string]interface{})
+		assert.Equal(t, []string{"general", "it", "science"}, category["enum"], name)
+	}
+	tool := srv.mcpServer.GetTool("searxng_read")
+	assert.NotContains(t, tool.Tool.InputSchema.Properties, "category")
+}
+
+func TestAdaptToInstance_NoConfig(t *testing.T) {
+	var gotEngines string
+	instance := newEnginesInstance(t, false, &gotEngines)
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+	assert.Error(t, srv.AdaptToInstance(context.Background()))
+
+	category := srv.mcpServer.GetTool("searxng_search").Tool.InputSchema.Properties["category"].(map[string]interface{})
+	assert.NotContains(t, category, "enum", "the parameter stays a free string")
+}
@@ -0,0 +1,39 @@
+package server_test
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/denysvitali/searxng-mcp/pkg/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// The searxng tools served by a multi-tool MCP server
+func ExampleRegisterTools() {
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: "https://searxng.example.com", Timeout: 10 * time.Second})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hooks := &mcpserver.Hooks{}
+	mcpServer := mcpserver.NewMCPServer("my-server", "1.0.0",
+		mcpserver.WithToolCapabilities(true),
+		mcpserver.WithHooks(hooks))
+	mcpServer.AddTool(mcp.NewTool("echo", mcp.WithString("text", mcp.Required())),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(request.GetString("text", "")), nil
+		})
+
+	srv := server.RegisterTools(mcpServer, client, server.Options{ReadCacheTTL: 10 * time.Minute})
+	srv.AddHooks(hooks)
+	if err := srv.AdaptToInstance(context.Background()); err != nil {
+		log.Printf("not listing the instance categories: %v", err)
+	}
+
+	if err := mcpserver.ServeStdio(mcpServer); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// fileTypeExtensions maps the supported filetype values to the URL
+// extensions accepted for them
+var fileTypeExtensions = map[string][]string{
+	"pdf":  {".pdf"},
+	"doc":  {".doc", ".docx"},
+	"ppt":  {".ppt", ".pptx"},
+	"xls":  {".xls", ".xlsx"},
+	"odt":  {".odt"},
+	"ods":  {".ods"},
+	"odp":  {".odp"},
+	"rtf":  {".rtf"},
+	"txt":  {".txt"},
+	"csv":  {".csv"},
+	"epub": {".epub"},
+}
+
+// fileTypes returns the supported filetype values, sorted
+func fileTypes() []string {
+	types := make([]string, 0, len(fileTypeExtensions))
+	for t := range fileTypeExtensions {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// parseFileType normalizes a filetype argument ("PDF", ".pdf", "docx")
+func parseFileType(s string) (string, error) {
+	t := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), ".")
+	if _, ok := fileTypeExtensions[t]; ok {
+		return t, nil
+	}
+	for name, exts := range fileTypeExtensions {
+		if slices.Contains(exts, "."+t) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported filetype: %s (must be one of %s)", s, strings.Join(fileTypes(), ", "))
+}
+
+// withFileTypeOperator adds a filetype: operator to query unless it already
+// has one
+func withFileTypeOperator(query, fileType string) string {
+	if strings.Contains(strings.ToLower(query), "filetype:") {
+		return query
+	}
+	return query + " filetype:" + fileType
+}
+
+// filterByFileType returns a copy of resp keeping only results whose URL
+// path ends with an extension of fileType, since many engines ignore the
+// filetype: operator. It also returns the number of removed results.
+func filterByFileType(resp *searxng.SearchResponse, fileType string) (*searxng.SearchResponse, int) {
+	exts := fileTypeExtensions[fileType]
+	results := make([]searxng.SearchResult, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		parsed, err := url.Parse(result.URL)
+		if err != nil {
+			continue
+		}
+		if slices.Contains(exts, strings.ToLower(path.Ext(parsed.Path))) {
+			results = append(results, result)
+		}
+	}
+
+	filtered := *resp
+	filtered.Results = results
+	return &filtered, len(resp.Results) - len(results)
+}
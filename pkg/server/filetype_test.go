@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileType(t *testing.T) {
+	for input, want := range map[string]string{"pdf": "pdf", "PDF": "pdf", ".pdf": "pdf", "docx": "doc", "pptx": "ppt"} {
+		got, err := parseFileType(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := parseFileType("exe")
+	assert.ErrorContains(t, err, "unsupported filetype")
+}
+
+func TestWithFileTypeOperator(t *testing.T) {
+	assert.Equal(t, "http spec filetype:pdf", withFileTypeOperator("http spec", "pdf"))
+	assert.Equal(t, "http spec filetype:doc", withFileTypeOperator("http spec filetype:doc", "pdf"))
+}
+
+func TestFilterByFileType(t *testing.T) {
+	resp := &searxng.SearchResponse{Results: []searxng.SearchResult{
+		{URL: "https://example.com/spec.PDF"},
+		{URL: "https://example.com/spec.html"},
+		{URL: "https://example.com/slides.pptx?download=1"},
+		{URL: "https://example.com/paper.pdf#page=2"},
+	}}
+
+	got, removed := filterByFileType(resp, "pdf")
+	require.Len(t, got.Results, 2)
+	assert.Equal(t, 2, removed)
+	assert.Len(t, resp.Results, 4)
+
+	got, _ = filterByFileType(resp, "ppt")
+	require.Len(t, got.Results, 1)
+	assert.Equal(t, "https://example.com/slides.pptx?download=1", got.Results[0].URL)
+}
+
+func TestHandleWebSearch_FileType(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "http/2 spec filetype:pdf").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "http/2 spec filetype:pdf", Results: []searxng.APIResult{
+			{URL: "https://www.rfc-editor.org/rfc/rfc9113.pdf", Title: "RFC 9113"},
+			{URL: "https://http2.github.io/", Title: "HTTP/2"},
+		}})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "searxng_search",
+			Arguments: map[string]interface{}{"query": "http/2 spec", "filetype": "pdf"},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	results := output["results"].([]interface{})
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://www.rfc-editor.org/rfc/rfc9113.pdf", results[0].(map[string]interface{})["url"])
+	assert.Equal(t, float64(1), output["filetype_filtered"])
+	assert.True(t, gock.IsDone())
+}
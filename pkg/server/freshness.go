@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// freshness tells how long fetched content can be reused without
+// refetching, derived from the page's Cache-Control or Expires header
+type freshness struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	// MaxAge is the remaining freshness lifetime in seconds (0: stale, or
+	// must be revalidated before reuse)
+	MaxAge         int        `json:"max_age_seconds"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	NoStore        bool       `json:"no_store,omitempty"`
+	MustRevalidate bool       `json:"must_revalidate,omitempty"`
+	// Source is the header the lifetime was derived from
+	Source string `json:"source"`
+}
+
+// parseFreshness derives the freshness of a response fetched at now from
+// its headers, following the private cache rules of RFC 9111: max-age
+// takes precedence over Expires and the Age header is subtracted. It
+// returns nil when the response has no caching headers.
+func parseFreshness(header http.Header, now time.Time) *freshness {
+	f := &freshness{FetchedAt: now.UTC()}
+
+	maxAge, noCache := -1, false
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			f.NoStore = true
+		case "no-cache":
+			noCache = true
+			f.MustRevalidate = true
+		case "must-revalidate":
+			f.MustRevalidate = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				maxAge = max(seconds, 0)
+			}
+		}
+	}
+
+	switch {
+	case f.NoStore || noCache:
+		f.Source = "cache-control"
+		return f
+	case maxAge >= 0:
+		f.Source = "cache-control"
+		f.MaxAge = max(maxAge-age(header), 0)
+		return f.withExpiry()
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		f.Source = "expires"
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// Invalid dates such as "0" mean already expired
+			return f
+		}
+		date := now
+		if d, err := http.ParseTime(header.Get("Date")); err == nil {
+			date = d
+		}
+		f.MaxAge = max(int(expiresAt.Sub(date).Seconds())-age(header), 0)
+		return f.withExpiry()
+	}
+
+	if f.MustRevalidate {
+		f.Source = "cache-control"
+		return f
+	}
+	return nil
+}
+
+// withExpiry sets ExpiresAt from MaxAge
+func (f *freshness) withExpiry() *freshness {
+	if f.MaxAge > 0 {
+		expiresAt := f.FetchedAt.Add(time.Duration(f.MaxAge) * time.Second)
+		f.ExpiresAt = &expiresAt
+	}
+	return f
+}
+
+// age returns the Age header in seconds (0 when missing or invalid)
+func age(header http.Header) int {
+	seconds, err := strconv.Atoi(header.Get("Age"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return seconds
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFreshness(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	tests := []struct {
+		name           string
+		header         http.Header
+		maxAge         int
+		source         string
+		noStore        bool
+		mustRevalidate bool
+	}{
+		{"max-age", header("Cache-Control", "public, max-age=3600"), 3600, "cache-control", false, false},
+		{"max-age minus age", header("Cache-Control", "max-age=3600", "Age", "600"), 3000, "cache-control", false, false},
+		{"max-age wins over expires", header("Cache-Control", "max-age=60", "Expires", "Fri, 02 Jan 2026 13:00:00 GMT"), 60, "cache-control", false, false},
+		{"no-store", header("Cache-Control", "no-store"), 0, "cache-control", true, false},
+		{"no-cache", header("Cache-Control", "no-cache, max-age=3600"), 0, "cache-control", false, true},
+		{"must-revalidate", header("Cache-Control", "max-age=10, must-revalidate"), 10, "cache-control", false, true},
+		{"expires relative to date", header("Expires", "Fri, 02 Jan 2026 13:00:00 GMT", "Date", "Fri, 02 Jan 2026 12:30:00 GMT"), 1800, "expires", false, false},
+		{"expires relative to now", header("Expires", "Fri, 02 Jan 2026 13:00:00 GMT"), 3600, "expires", false, false},
+		{"expires in the past", header("Expires", "Thu, 01 Jan 2026 00:00:00 GMT"), 0, "expires", false, false},
+		{"invalid expires", header("Expires", "0"), 0, "expires", false, false},
+		{"directives without lifetime", header("Cache-Control", "public", "Expires", "Fri, 02 Jan 2026 12:01:00 GMT"), 60, "expires", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := parseFreshness(tt.header, now)
+			require.NotNil(t, f)
+			assert.Equal(t, tt.maxAge, f.MaxAge)
+			assert.Equal(t, tt.source, f.Source)
+			assert.Equal(t, tt.noStore, f.NoStore)
+			assert.Equal(t, tt.mustRevalidate, f.MustRevalidate)
+			assert.Equal(t, now, f.FetchedAt)
+			if tt.maxAge > 0 {
+				require.NotNil(t, f.ExpiresAt)
+				assert.Equal(t, now.Add(time.Duration(tt.maxAge)*time.Second), *f.ExpiresAt)
+			} else {
+				assert.Nil(t, f.ExpiresAt)
+			}
+		})
+	}
+
+	assert.Nil(t, parseFreshness(http.Header{}, now))
+	assert.Nil(t, parseFreshness(header("Cache-Control", "public"), now))
+}
+
+func TestHandleWebRead_Freshness(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Cache-Control", "max-age=300")
+		_, _ = w.Write([]byte("cached content"))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	result, err := New(client).handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": ts.URL}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	metadata := result.StructuredContent.(readMetadata)
+	assert.Nil(t, metadata.readChunk)
+	require.NotNil(t, metadata.Freshness)
+	assert.Equal(t, 300, metadata.Freshness.MaxAge)
+
+	data, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"freshness":{"fetched_at":`)
+	assert.NotContains(t, string(data), "total_length")
+}
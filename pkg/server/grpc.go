@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// grpcServiceName is the gRPC service of ServeGRPC, described by
+// proto/searxng/v1/searxng.proto
+const grpcServiceName = "searxng.v1.Searxng"
+
+// grpcProtoFile is the name of the proto file of grpcServiceName
+const grpcProtoFile = "searxng/v1/searxng.proto"
+
+// grpcService serves the RPCs of grpcServiceName. Requests and responses
+// are google.protobuf.Struct values holding the arguments and results of
+// the matching tools, so the service needs no generated code.
+type grpcService interface {
+	Search(context.Context, *structpb.Struct) (*structpb.Struct, error)
+	Read(context.Context, *structpb.Struct) (*structpb.Struct, error)
+}
+
+// grpcServiceDesc is what protoc-gen-go-grpc would generate for
+// grpcServiceName
+var grpcServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcServiceName,
+	HandlerType: (*grpcService)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Search", Handler: grpcUnaryHandler("Search", grpcService.Search)},
+		{MethodName: "Read", Handler: grpcUnaryHandler("Read", grpcService.Read)},
+	},
+	Metadata: grpcProtoFile,
+}
+
+// grpcUnaryHandler adapts a method of grpcService to grpc.MethodDesc
+func grpcUnaryHandler(method string, call func(grpcService, context.Context, *structpb.Struct) (*structpb.Struct, error)) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(structpb.Struct)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return call(srv.(grpcService), ctx, in)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + grpcServiceName + "/" + method}
+		return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+			return call(srv.(grpcService), ctx, req.(*structpb.Struct))
+		})
+	}
+}
+
+func init() {
+	// Register the service descriptor for server reflection (e.g. grpcurl)
+	file, err := protodesc.NewFile(grpcFileDescriptor(), protoregistry.GlobalFiles)
+	if err == nil {
+		err = protoregistry.GlobalFiles.RegisterFile(file)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("registering %s: %v", grpcProtoFile, err))
+	}
+}
+
+// grpcFileDescriptor describes proto/searxng/v1/searxng.proto
+func grpcFileDescriptor() *descriptorpb.FileDescriptorProto {
+	str := func(s string) *string { return &s }
+	method := func(name string) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       str(name),
+			InputType:  str(".google.protobuf.Struct"),
+			OutputType: str(".google.protobuf.Struct"),
+		}
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:       str(grpcProtoFile),
+		Package:    str("searxng.v1"),
+		Dependency: []string{"google/protobuf/struct.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   str("Searxng"),
+			Method: []*descriptorpb.MethodDescriptorProto{method("Search"), method("Read")},
+		}},
+		Syntax: str("proto3"),
+	}
+}
+
+// grpcFacade implements grpcService by calling the tools of the server
+type grpcFacade struct {
+	s *Server
+}
+
+// Search runs searxng_search with the request fields as arguments and
+// returns its structured results
+func (f grpcFacade) Search(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	result, err := f.s.callTool(ctx, f.s.toolName("searxng_search"), in.AsMap())
+	if err != nil {
+		return nil, err
+	}
+	return toStruct(result.StructuredContent)
+}
+
+// Read runs searxng_read with the request fields as arguments and returns
+// the page as content, its HTML (include_html) as html, and the read
+// metadata
+func (f grpcFacade) Read(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	result, err := f.s.callTool(ctx, f.s.toolName("searxng_read"), in.AsMap())
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			out["content"] = c.Text
+		case mcp.EmbeddedResource:
+			if resource, ok := c.Resource.(mcp.TextResourceContents); ok {
+				out["html"] = resource.Text
+			}
+		}
+	}
+	return toStruct(out)
+}
+
+// callTool calls a tool through the MCP server, as a client would, so the
+// tool middleware (stats, shutdown draining, idle restore and logging)
+// applies. Errors are gRPC errors: disabled tools are Unimplemented, and
+// tool errors are converted by toolError.
+func (s *Server) callTool(ctx context.Context, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	msg, err := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      1,
+		"method":  string(mcp.MethodToolsCall),
+		"params":  map[string]any{"name": name, "arguments": args},
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	switch resp := s.mcpServer.HandleMessage(ctx, msg).(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := resp.Result.(*mcp.CallToolResult)
+		if !ok {
+			return nil, status.Error(codes.Internal, "unexpected tool result")
+		}
+		if result.IsError {
+			return nil, toolError(result)
+		}
+		return result, nil
+	case mcp.JSONRPCError:
+		if resp.Error.Code == mcp.INVALID_PARAMS && strings.Contains(resp.Error.Message, "not found") {
+			return nil, status.Error(codes.Unimplemented, resp.Error.Message)
+		}
+		return nil, status.Error(codes.Internal, resp.Error.Message)
+	default:
+		return nil, status.Error(codes.Internal, "unexpected tool response")
+	}
+}
+
+// toolError converts the error result of a tool to a gRPC error: failures
+// to reach the instance or the page are Unavailable, failures to format
+// the results Internal, and other errors (invalid arguments) InvalidArgument
+func toolError(result *mcp.CallToolResult) error {
+	var message string
+	if len(result.Content) > 0 {
+		if text, ok := result.Content[0].(mcp.TextContent); ok {
+			message = text.Text
+		}
+	}
+	switch {
+	case strings.HasPrefix(message, "search failed"),
+		strings.HasPrefix(message, "failed to fetch URL"),
+		message == "server is shutting down":
+		return status.Error(codes.Unavailable, message)
+	case strings.HasPrefix(message, "failed to format results"):
+		return status.Error(codes.Internal, message)
+	default:
+		return status.Error(codes.InvalidArgument, message)
+	}
+}
+
+// toStruct converts structured tool output to a Struct through JSON, which
+// also turns typed slices and numbers into Struct values
+func toStruct(v any) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out := &structpb.Struct{}
+	if err := out.UnmarshalJSON(data); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return out, nil
+}
+
+// grpcAuth rejects calls without one of Options.AuthTokens or
+// Options.AdminTokens, given as "authorization: Bearer <token>" or
+// "x-api-key" metadata
+func (s *Server) grpcAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if keys := md.Get("x-api-key"); len(keys) > 0 {
+		token = keys[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if bearer, ok := strings.CutPrefix(values[0], "Bearer "); ok {
+			token = strings.TrimSpace(bearer)
+		}
+	}
+	if !matchToken(token, s.options.AuthTokens) && !matchToken(token, s.options.AdminTokens) {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return handler(ctx, req)
+}
+
+// newGRPCServer creates the gRPC server of ServeGRPC
+func (s *Server) newGRPCServer() *grpc.Server {
+	var opts []grpc.ServerOption
+	if len(s.options.AuthTokens) > 0 {
+		opts = append(opts, grpc.UnaryInterceptor(s.grpcAuth))
+	}
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&grpcServiceDesc, grpcFacade{s})
+	reflection.Register(server)
+	return server
+}
+
+// ServeGRPC serves the searxng.v1.Searxng gRPC service on addr, alongside
+// the MCP transport, until Shutdown is called. Its Search and Read RPCs
+// take the arguments of searxng_search and searxng_read and share their
+// client, caches and limits. Options.AuthTokens apply.
+func (s *Server) ServeGRPC(addr string) error {
+	log.WithField("address", addr).Info("starting gRPC server")
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+	server := s.newGRPCServer()
+	if !s.lifecycle.servingGRPC(server) {
+		listener.Close()
+		return nil
+	}
+	if err := server.Serve(listener); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
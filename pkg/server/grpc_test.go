@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// dialGRPC serves the gRPC service of srv in memory and returns a
+// connection to it
+func dialGRPC(t *testing.T, srv *Server) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := srv.newGRPCServer()
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// invokeGRPC calls a method of the searxng.v1.Searxng service
+func invokeGRPC(ctx context.Context, conn *grpc.ClientConn, method string, args map[string]any) (*structpb.Struct, error) {
+	in, err := structpb.NewStruct(args)
+	if err != nil {
+		return nil, err
+	}
+	out := &structpb.Struct{}
+	err = conn.Invoke(ctx, "/"+grpcServiceName+"/"+method, in, out)
+	return out, err
+}
+
+func TestGRPC_Search(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "golang").
+		Reply(200).
+		JSON(searxng.APIResponse{
+			Query:   "golang",
+			Results: []searxng.APIResult{{URL: "https://go.dev", Title: "Go", Content: "The Go language"}},
+		})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+	conn := dialGRPC(t, srv)
+
+	out, err := invokeGRPC(context.Background(), conn, "Search", map[string]any{"query": "golang", "limit": 3})
+	require.NoError(t, err)
+	results := out.GetFields()["results"].GetListValue().GetValues()
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://go.dev", results[0].GetStructValue().GetFields()["url"].GetStringValue())
+	assert.EqualValues(t, 1, srv.Stats().ToolCalls["searxng_search"], "RPCs count as tool calls")
+
+	_, err = invokeGRPC(context.Background(), conn, "Search", map[string]any{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_SearchUnavailable(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Persist().
+		Reply(500).
+		BodyString("Internal Server Error")
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	conn := dialGRPC(t, New(client))
+
+	_, err = invokeGRPC(context.Background(), conn, "Search", map[string]any{"query": "golang"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestGRPC_Read(t *testing.T) {
+	gock.Off()
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><h1>Title</h1><p>Body text.</p></body></html>`))
+	}))
+	defer site.Close()
+
+	conn := dialGRPC(t, New(nil))
+	out, err := invokeGRPC(context.Background(), conn, "Read", map[string]any{"url": site.URL, "include_html": true})
+	require.NoError(t, err)
+	assert.Contains(t, out.GetFields()["content"].GetStringValue(), "# Title")
+	assert.Contains(t, out.GetFields()["html"].GetStringValue(), "<h1>Title</h1>")
+
+	_, err = invokeGRPC(context.Background(), conn, "Read", map[string]any{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_DisabledTool(t *testing.T) {
+	srv := New(nil)
+	srv.MCPServer().DeleteTools("searxng_read")
+	conn := dialGRPC(t, srv)
+
+	_, err := invokeGRPC(context.Background(), conn, "Read", map[string]any{"url": "https://example.com"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGRPC_Auth(t *testing.T) {
+	conn := dialGRPC(t, NewWithOptions(nil, Options{AuthTokens: []string{"secret"}}))
+
+	_, err := invokeGRPC(context.Background(), conn, "Read", map[string]any{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	_, err = invokeGRPC(ctx, conn, "Read", map[string]any{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Authenticated, the missing url is the error
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = invokeGRPC(ctx, conn, "Read", map[string]any{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	_, err = invokeGRPC(ctx, conn, "Read", map[string]any{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServeGRPC_Shutdown(t *testing.T) {
+	srv := New(nil)
+	done := make(chan error, 1)
+	go func() { done <- srv.ServeGRPC("127.0.0.1:0") }()
+
+	// Wait for the server to be recorded, then shut it down
+	require.Eventually(t, func() bool {
+		srv.lifecycle.mu.Lock()
+		defer srv.lifecycle.mu.Unlock()
+		return srv.lifecycle.grpcServer != nil
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, srv.Shutdown(context.Background()))
+	assert.NoError(t, <-done, "stopping is not an error")
+}
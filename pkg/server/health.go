@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+)
+
+// Paths of the probe endpoints of the http and sse transports
+const (
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+)
+
+// readyCheckTimeout bounds the instance health check of ReadyHandler
+const readyCheckTimeout = 5 * time.Second
+
+// HealthHandler returns an HTTP handler for liveness probes: it answers
+// 200 while the process serves HTTP, whatever the state of the instance,
+// so an unhealthy instance doesn't get the server restarted
+func (s *Server) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, http.StatusOK, "ok")
+	})
+}
+
+// ReadyHandler returns an HTTP handler for readiness probes: it answers
+// 200 when the instance answers a trivial search, and 503 when it doesn't
+// or Shutdown has started. Each request searches the instance.
+func (s *Server) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.lifecycle.closed() {
+			writeProbe(w, http.StatusServiceUnavailable, "shutting down")
+			return
+		}
+		if s.searxngClient != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), readyCheckTimeout)
+			defer cancel()
+			if err := s.searxngClient.HealthCheck(ctx); err != nil {
+				// The error names the instance, so it's only logged
+				log.WithField("error", err).Warn("readiness check failed")
+				writeProbe(w, http.StatusServiceUnavailable, "instance unavailable")
+				return
+			}
+		}
+		writeProbe(w, http.StatusOK, "ok")
+	})
+}
+
+// writeProbe writes the plain-text answer of a probe endpoint
+func writeProbe(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body + "\n"))
+}
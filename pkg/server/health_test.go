@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeHandlers(t *testing.T) {
+	gock.Off()
+
+	healthy := true
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: "searxng"})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{AuthTokens: []string{"secret"}})
+	mux := http.NewServeMux()
+	srv.handleStatus(mux)
+	handler := srv.httpMiddleware(mux)
+
+	probe := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, probe(HealthPath).Code, "probes don't need a token")
+	assert.Equal(t, http.StatusOK, probe(ReadyPath).Code)
+
+	healthy = false
+	assert.Equal(t, http.StatusOK, probe(HealthPath).Code, "liveness doesn't depend on the instance")
+	rec := probe(ReadyPath)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), instance.URL)
+
+	healthy = true
+	require.NoError(t, srv.Shutdown(context.Background()))
+	rec = probe(ReadyPath)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "shutting down\n", rec.Body.String())
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// sessionHistory remembers the result URLs returned to each MCP session, so
+// searches with novel_only can skip results the agent has already seen
+type sessionHistory struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]struct{}
+	order    []string            // session IDs, oldest first
+	imported map[string]struct{} // URLs seen by every session, from ImportHistory
+}
+
+// maxHistorySessions bounds the number of remembered sessions in case the
+// unregister hook is replaced by extra server options
+const maxHistorySessions = 1024
+
+// maxImportedHistory bounds the URLs kept by ImportHistory, so a history
+// that is saved and restored on every run doesn't grow without bound
+const maxImportedHistory = 10000
+
+func newSessionHistory() *sessionHistory {
+	return &sessionHistory{
+		sessions: make(map[string]map[string]struct{}),
+		imported: make(map[string]struct{}),
+	}
+}
+
+// sessionID returns the ID of the MCP session of ctx, or "" outside of a
+// session (e.g. when a handler is called directly)
+func sessionID(ctx context.Context) string {
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// record remembers the result URLs of resp for the session and, with
+// novelOnly, returns a copy of resp without results the session has seen
+// before. It also returns the number of removed results.
+func (h *sessionHistory) record(session string, resp *searxng.SearchResponse, novelOnly bool) (*searxng.SearchResponse, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen, ok := h.sessions[session]
+	if !ok {
+		seen = make(map[string]struct{})
+		h.sessions[session] = seen
+		h.order = append(h.order, session)
+		if len(h.order) > maxHistorySessions {
+			delete(h.sessions, h.order[0])
+			h.order = h.order[1:]
+		}
+	}
+
+	results := make([]searxng.SearchResult, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		key := historyKey(result.URL)
+		_, dup := seen[key]
+		if _, ok := h.imported[key]; ok {
+			dup = true
+		}
+		if dup && novelOnly {
+			continue
+		}
+		seen[key] = struct{}{}
+		results = append(results, result)
+	}
+
+	filtered := *resp
+	filtered.Results = results
+	return &filtered, len(resp.Results) - len(results)
+}
+
+// forget drops the history of a session; it is registered as an
+// unregister-session hook
+func (h *sessionHistory) forget(_ context.Context, session mcpserver.ClientSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, session.SessionID())
+	h.order = slices.DeleteFunc(h.order, func(id string) bool { return id == session.SessionID() })
+}
+
+// urls returns every URL seen by any session, including imported ones,
+// sorted
+func (h *sessionHistory) urls() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	all := make(map[string]struct{}, len(h.imported))
+	for key := range h.imported {
+		all[key] = struct{}{}
+	}
+	for _, seen := range h.sessions {
+		for key := range seen {
+			all[key] = struct{}{}
+		}
+	}
+
+	urls := make([]string, 0, len(all))
+	for key := range all {
+		urls = append(urls, key)
+	}
+	slices.Sort(urls)
+	return urls
+}
+
+// ExportHistory returns the result URLs returned to any session so far,
+// including previously imported ones, for persisting with ImportHistory
+func (s *Server) ExportHistory() []string {
+	return s.history.urls()
+}
+
+// ImportHistory marks urls as seen in every current and future session, so
+// novel_only searches skip them. It is meant for servers whose sessions
+// belong to one client: on a shared server, the URLs seen by one client
+// would be hidden from the others. At most 10000 URLs are kept; the first
+// ones of urls win.
+func (s *Server) ImportHistory(urls []string) {
+	s.history.mu.Lock()
+	defer s.history.mu.Unlock()
+	for i, u := range urls {
+		if len(s.history.imported) >= maxImportedHistory {
+			log.WithField("dropped", len(urls)-i).Warn("imported history is full, dropping URLs")
+			return
+		}
+		s.history.imported[historyKey(u)] = struct{}{}
+	}
+}
+
+// historyKey normalizes a result URL so trivial variants (fragment, trailing
+// slash, host case) count as the same page
+func historyKey(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.Fragment = ""
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	return parsed.String()
+}
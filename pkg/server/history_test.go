@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHistory_Record(t *testing.T) {
+	h := newSessionHistory()
+	resp := &searxng.SearchResponse{Results: []searxng.SearchResult{
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/b"},
+	}}
+
+	got, removed := h.record("s1", resp, true)
+	assert.Len(t, got.Results, 2)
+	assert.Equal(t, 0, removed)
+
+	next := &searxng.SearchResponse{Results: []searxng.SearchResult{
+		{URL: "https://EXAMPLE.com/a/#intro"},
+		{URL: "https://example.com/c"},
+	}}
+	got, removed = h.record("s1", next, true)
+	require.Len(t, got.Results, 1)
+	assert.Equal(t, "https://example.com/c", got.Results[0].URL)
+	assert.Equal(t, 1, removed)
+	assert.Len(t, next.Results, 2, "the original response must not be modified")
+
+	// Other sessions and non-novel searches are unaffected
+	got, _ = h.record("s2", next, true)
+	assert.Len(t, got.Results, 2)
+	got, _ = h.record("s1", next, false)
+	assert.Len(t, got.Results, 2)
+}
+
+func TestHandleWebSearch_NovelOnly(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "golang").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "golang", Results: []searxng.APIResult{
+			{URL: "https://go.dev", Title: "Go"},
+		}})
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "golang tutorial").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "golang tutorial", Results: []searxng.APIResult{
+			{URL: "https://go.dev", Title: "Go"},
+			{URL: "https://go.dev/tour", Title: "A Tour of Go"},
+		}})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	search := func(args map[string]interface{}) map[string]interface{} {
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: args},
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		var output map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output
+	}
+
+	search(map[string]interface{}{"query": "golang"})
+	output := search(map[string]interface{}{"query": "golang tutorial", "novel_only": true})
+
+	results := output["results"].([]interface{})
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://go.dev/tour", results[0].(map[string]interface{})["url"])
+	assert.Equal(t, float64(1), output["seen_results_filtered"])
+	assert.True(t, gock.IsDone())
+}
+
+func TestServer_ExportImportHistory(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	source := New(client)
+	source.history.record("s1", &searxng.SearchResponse{Results: []searxng.SearchResult{
+		{URL: "https://example.com/b"},
+		{URL: "https://example.com/a/"},
+	}}, false)
+	urls := source.ExportHistory()
+	assert.Equal(t, []string{"https://example.com/a", "https://example.com/b"}, urls)
+
+	target := New(client)
+	target.ImportHistory(urls)
+	got, removed := target.history.record("new-session", &searxng.SearchResponse{Results: []searxng.SearchResult{
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/c"},
+	}}, true)
+	require.Len(t, got.Results, 1)
+	assert.Equal(t, "https://example.com/c", got.Results[0].URL)
+	assert.Equal(t, 1, removed)
+}
+
+func TestServer_ImportHistory_Capped(t *testing.T) {
+	urls := make([]string, maxImportedHistory+10)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	srv := New(nil)
+	srv.ImportHistory(urls)
+	srv.ImportHistory([]string{"https://example.com/late"})
+	assert.Len(t, srv.history.imported, maxImportedHistory)
+	assert.Contains(t, srv.history.imported, "https://example.com/0")
+	assert.NotContains(t, srv.history.imported, "https://example.com/late")
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+)
+
+// corsAllowedHeaders are the request headers MCP clients send cross-origin
+const corsAllowedHeaders = "Authorization, Content-Type, X-API-Key, Mcp-Session-Id, Mcp-Protocol-Version, Last-Event-ID"
+
+// httpMiddleware wraps the handler of the http and sse transports with
+// request logging (Options.LogRequests), panic recovery, CORS
+// (Options.CORSOrigins) and authentication (Options.AuthTokens and
+// Options.AdminTokens), outermost first
+func (s *Server) httpMiddleware(next http.Handler) http.Handler {
+	handler := recoverPanics(s.cors(s.requireAuth(next)))
+	if s.options.LogRequests {
+		handler = logRequests(handler)
+	}
+	return handler
+}
+
+// requireAuth rejects requests without one of Options.AuthTokens or
+// Options.AdminTokens, given as a bearer token or an X-API-Key header, and
+// marks the requests with an admin token for isAdmin. Without AuthTokens,
+// requests without a token are let through. The image proxy is exempt: its
+// URLs are signed and fetched by clients that can't authenticate. So are
+// the probe endpoints, for orchestrators.
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	if len(s.options.AuthTokens) == 0 && len(s.options.AdminTokens) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.imageProxy != nil && r.URL.Path == s.imageProxy.path || r.URL.Path == HealthPath || r.URL.Path == ReadyPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := requestToken(r)
+		if matchToken(token, s.options.AdminTokens) {
+			next.ServeHTTP(w, r.WithContext(withAdmin(r.Context())))
+			return
+		}
+		if len(s.options.AuthTokens) > 0 && !matchToken(token, s.options.AuthTokens) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="searxng-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestToken returns the bearer token or X-API-Key of r
+func requestToken(r *http.Request) string {
+	token := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = strings.TrimSpace(bearer)
+	}
+	return token
+}
+
+// matchToken reports whether token is one of tokens
+func matchToken(token string, tokens []string) bool {
+	if token == "" {
+		return false
+	}
+	matched := false
+	for _, allowed := range tokens {
+		// Compare every token in constant time, not stopping at a match
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// cors sets the CORS headers for requests from Options.CORSOrigins ("*"
+// allows any origin) and answers their preflight requests, which browsers
+// send without credentials
+func (s *Server) cors(next http.Handler) http.Handler {
+	if len(s.options.CORSOrigins) == 0 {
+		return next
+	}
+	anyOrigin := slices.Contains(s.options.CORSOrigins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || (!anyOrigin && !slices.Contains(s.options.CORSOrigins, origin)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// logRequests logs every request with its status and duration. Queries
+// aren't logged, as they may hold credentials.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		log.WithFields(logrus.Fields{
+			"method":   r.Method,
+			"path":     r.URL.Path,
+			"status":   recorder.status(),
+			"duration": time.Since(start),
+			"remote":   r.RemoteAddr,
+		}).Info("http request")
+	})
+}
+
+// recoverPanics answers 500 to requests whose handler panics instead of
+// dropping the connection, and logs the panic
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(p)
+			}
+			log.WithFields(logrus.Fields{
+				"path":  r.URL.Path,
+				"panic": p,
+				"stack": string(debug.Stack()),
+			}).Error("panic serving http request")
+			if recorder.code == 0 {
+				http.Error(recorder, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// statusRecorder records the status code written to a ResponseWriter. It
+// keeps the streaming of the SSE and StreamableHTTP transports working by
+// implementing http.Flusher.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	http.NewResponseController(r.ResponseWriter).Flush() //nolint:errcheck
+}
+
+// Unwrap gives http.ResponseController access to the wrapped writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// status returns the recorded status; handlers that write nothing answer 200
+func (r *statusRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// okHandler answers every request with 200
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte("ok"))
+})
+
+func serveMiddleware(srv *Server, next http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	srv.httpMiddleware(next).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHTTPMiddleware_Auth(t *testing.T) {
+	srv := NewWithOptions(nil, Options{
+		AuthTokens:    []string{"first", "second"},
+		ImageProxyURL: "https://mcp.example.com/image_proxy",
+	})
+
+	rec := serveMiddleware(srv, okHandler, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	assert.Equal(t, http.StatusUnauthorized, serveMiddleware(srv, okHandler, req).Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer second")
+	assert.Equal(t, http.StatusOK, serveMiddleware(srv, okHandler, req).Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("X-API-Key", "first")
+	assert.Equal(t, http.StatusOK, serveMiddleware(srv, okHandler, req).Code)
+
+	rec = serveMiddleware(srv, okHandler, httptest.NewRequest(http.MethodGet, "/image_proxy?url=x", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "the signed image proxy needs no token")
+}
+
+func TestHTTPMiddleware_AdminTokens(t *testing.T) {
+	var admin bool
+	adminHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin = isAdmin(r.Context())
+	})
+
+	srv := NewWithOptions(nil, Options{AuthTokens: []string{"user"}, AdminTokens: []string{"admin"}})
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	assert.Equal(t, http.StatusOK, serveMiddleware(srv, adminHandler, req).Code)
+	assert.True(t, admin)
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("X-API-Key", "user")
+	assert.Equal(t, http.StatusOK, serveMiddleware(srv, adminHandler, req).Code)
+	assert.False(t, admin)
+
+	// Without auth tokens, anonymous requests are let through
+	srv = NewWithOptions(nil, Options{AdminTokens: []string{"admin"}})
+	assert.Equal(t, http.StatusOK, serveMiddleware(srv, adminHandler, httptest.NewRequest(http.MethodPost, "/mcp", nil)).Code)
+	assert.False(t, admin)
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	serveMiddleware(srv, adminHandler, req)
+	assert.True(t, admin)
+}
+
+func TestHTTPMiddleware_NoAuth(t *testing.T) {
+	srv := New(nil)
+	rec := serveMiddleware(srv, okHandler, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestHTTPMiddleware_CORS(t *testing.T) {
+	srv := NewWithOptions(nil, Options{
+		AuthTokens:  []string{"token"},
+		CORSOrigins: []string{"https://app.example.com"},
+	})
+
+	// Preflight requests carry no credentials
+	req := httptest.NewRequest(http.MethodOptions, "/mcp", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := serveMiddleware(srv, okHandler, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Mcp-Session-Id")
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Authorization", "Bearer token")
+	rec = serveMiddleware(srv, okHandler, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Mcp-Session-Id", rec.Header().Get("Access-Control-Expose-Headers"))
+
+	req = httptest.NewRequest(http.MethodOptions, "/mcp", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec = serveMiddleware(srv, okHandler, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	srv = NewWithOptions(nil, Options{CORSOrigins: []string{"*"}})
+	req = httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	rec = serveMiddleware(srv, okHandler, req)
+	assert.Equal(t, "https://any.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestHTTPMiddleware_RecoverAndLog(t *testing.T) {
+	hook := logtest.NewLocal(log.Get())
+	defer hook.Reset()
+
+	srv := NewWithOptions(nil, Options{LogRequests: true})
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	rec := serveMiddleware(srv, panicking, httptest.NewRequest(http.MethodPost, "/mcp?token=secret", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	var logged []*logrus.Entry
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "http request" || entry.Message == "panic serving http request" {
+			logged = append(logged, entry)
+		}
+	}
+	require.Len(t, logged, 2)
+	assert.Equal(t, "panic serving http request", logged[0].Message)
+	assert.Equal(t, "boom", logged[0].Data["panic"])
+	assert.Equal(t, "http request", logged[1].Message, "logged after the recovery")
+	assert.Equal(t, "/mcp", logged[1].Data["path"], "queries are not logged")
+	assert.Equal(t, http.StatusInternalServerError, logged[1].Data["status"])
+}
+
+func TestStatusRecorder_Flush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	recorder := &statusRecorder{ResponseWriter: rec}
+	var w http.ResponseWriter = recorder
+	flusher, ok := w.(http.Flusher)
+	require.True(t, ok, "streaming transports need a Flusher")
+	flusher.Flush()
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusOK, recorder.status())
+}
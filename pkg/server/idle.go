@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// IdleHooks let the caller persist state before ReleaseWhenIdle drops the
+// caches, and bring it back when a tool call wakes the server up
+type IdleHooks struct {
+	// Flush runs before the caches are dropped; when it fails, the search
+	// cache is kept rather than lost
+	Flush func() error
+
+	// Restore runs before the first tool call after a release
+	Restore func() error
+}
+
+// idleState tracks tool call activity for ReleaseWhenIdle
+type idleState struct {
+	mu       sync.Mutex
+	lastCall time.Time
+	active   int
+	released bool
+	restore  func() error
+}
+
+func newIdleState() *idleState {
+	return &idleState{lastCall: time.Now()}
+}
+
+// middleware records tool calls and restores the released state before the
+// first one after a release; concurrent calls wait for the restore
+func (st *idleState) middleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		st.mu.Lock()
+		st.active++
+		st.lastCall = time.Now()
+		if st.released {
+			st.released = false
+			if st.restore != nil {
+				if err := st.restore(); err != nil {
+					log.WithField("error", err).Warn("failed to restore state after idling")
+				}
+			}
+			log.Debug("waking up from idle mode")
+		}
+		st.mu.Unlock()
+
+		defer func() {
+			st.mu.Lock()
+			st.active--
+			st.lastCall = time.Now()
+			st.mu.Unlock()
+		}()
+		return next(ctx, request)
+	}
+}
+
+// ReleaseWhenIdle releases the server's resources once no tool call has
+// arrived for timeout: pooled connections are closed, caches flushed
+// through hooks.Flush and dropped, and freed memory is returned to the
+// operating system. Everything warms up again lazily with the next tool
+// call. It blocks until ctx is cancelled and is meant to be run in a
+// goroutine.
+func (s *Server) ReleaseWhenIdle(ctx context.Context, timeout time.Duration, hooks IdleHooks) {
+	if timeout <= 0 {
+		return
+	}
+	st := s.idle
+	st.mu.Lock()
+	st.restore = hooks.Restore
+	st.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		st.mu.Lock()
+		idleFor := time.Since(st.lastCall)
+		if st.released || st.active > 0 || idleFor < timeout {
+			st.mu.Unlock()
+			timer.Reset(max(timeout-idleFor, timeout/10))
+			continue
+		}
+		// Holding the lock makes tool calls arriving meanwhile wait for the
+		// release to finish before restoring
+		s.release(hooks.Flush)
+		st.released = true
+		st.mu.Unlock()
+		timer.Reset(timeout)
+	}
+}
+
+// release flushes the caches through flush, drops them, closes idle
+// connections and returns freed memory to the operating system
+func (s *Server) release(flush func() error) {
+	flushed := true
+	if flush != nil {
+		if err := flush(); err != nil {
+			log.WithField("error", err).Warn("failed to flush state, keeping the search cache")
+			flushed = false
+		}
+	}
+
+	dropped := 0
+	if s.searxngClient != nil {
+		if flushed {
+			dropped = s.searxngClient.ClearCache()
+		}
+		s.searxngClient.CloseIdleConnections()
+	}
+	s.instances.release()
+	if s.readCache != nil {
+		s.readCache.clear()
+	}
+	if s.robots != nil {
+		s.robots.clear()
+	}
+	pageClients.Range(func(_, client any) bool {
+		client.(*http.Client).CloseIdleConnections()
+		return true
+	})
+	if s.options.Transport != nil {
+		s.options.Transport.CloseIdleConnections()
+	}
+
+	debug.FreeOSMemory()
+	log.WithField("dropped_cache_entries", dropped).Info("idle, released connections and caches")
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseWhenIdle(t *testing.T) {
+	var searches atomic.Int32
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: r.URL.Query().Get("q")})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL, CacheTTL: time.Minute})
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{ReadCacheTTL: time.Minute})
+
+	require.False(t, callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "q"}).IsError)
+	srv.readCache.put("https://example.com", readOptions{}, &readResult{Markdown: "page"})
+
+	var flushed []searxng.CacheEntry
+	var restored atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.ReleaseWhenIdle(ctx, 50*time.Millisecond, IdleHooks{
+		Flush: func() error {
+			flushed = client.ExportCache()
+			return nil
+		},
+		Restore: func() error {
+			restored.Add(1)
+			client.ImportCache(flushed)
+			return nil
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		srv.idle.mu.Lock()
+		defer srv.idle.mu.Unlock()
+		return srv.idle.released
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, flushed, 1)
+	assert.Empty(t, client.ExportCache(), "the search cache is dropped once flushed")
+	_, ok := srv.readCache.content("https://example.com")
+	assert.False(t, ok)
+
+	require.False(t, callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "q"}).IsError)
+	assert.Equal(t, int32(1), restored.Load())
+	assert.Equal(t, int32(1), searches.Load(), "served from the restored cache")
+
+	require.False(t, callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "q"}).IsError)
+	assert.Equal(t, int32(1), restored.Load(), "restored only once per release")
+}
+
+func TestReleaseWhenIdle_FlushFailure(t *testing.T) {
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: "https://searxng.example.com", CacheTTL: time.Minute})
+	require.NoError(t, err)
+	client.ImportCache([]searxng.CacheEntry{{Key: "GET q", Response: &searxng.SearchResponse{}, Expires: time.Now().Add(time.Minute)}})
+	srv := New(client)
+
+	srv.release(func() error { return errors.New("disk full") })
+	assert.Len(t, client.ExportCache(), 1, "unflushed entries are kept")
+
+	srv.release(nil)
+	assert.Empty(t, client.ExportCache())
+}
+
+func TestReleaseWhenIdle_Busy(t *testing.T) {
+	srv := New(nil)
+	srv.idle.mu.Lock()
+	srv.idle.active = 1
+	srv.idle.lastCall = time.Now().Add(-time.Hour)
+	srv.idle.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	srv.ReleaseWhenIdle(ctx, 10*time.Millisecond, IdleHooks{})
+
+	srv.idle.mu.Lock()
+	defer srv.idle.mu.Unlock()
+	assert.False(t, srv.idle.released, "not released during a tool call")
+}
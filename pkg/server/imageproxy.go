@@ -0,0 +1,140 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/sirupsen/logrus"
+)
+
+// maxProxiedImageSize bounds the images served by the image proxy
+const maxProxiedImageSize = 10 << 20
+
+// imageProxy signs result image URLs for the server's own proxy endpoint
+// and serves them. Signatures use a per-process key, so only URLs handed
+// out by this server are fetched and they stop working after a restart.
+type imageProxy struct {
+	publicURL string
+	path      string // path of publicURL, served by ServeHTTP
+	key       []byte
+	proxy     *url.URL        // used to fetch images when set
+	transport *http.Transport // carries image fetches; nil: http.DefaultTransport
+}
+
+// newImageProxy returns nil when publicURL is empty or invalid
+func newImageProxy(publicURL string) *imageProxy {
+	if publicURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(publicURL)
+	if err != nil || parsed.Path == "" {
+		log.WithField("url", publicURL).Warn("invalid image proxy URL, not proxying images")
+		return nil
+	}
+	return &imageProxy{publicURL: publicURL, path: parsed.Path, key: []byte(rand.Text())}
+}
+
+func (p *imageProxy) sign(src string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(src))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// proxyURL returns src routed through the proxy endpoint. URLs that aren't
+// http(s) are returned unchanged.
+func (p *imageProxy) proxyURL(src string) string {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return src
+	}
+	sep := "?"
+	if strings.Contains(p.publicURL, "?") {
+		sep = "&"
+	}
+	return p.publicURL + sep + url.Values{"url": {src}, "h": {p.sign(src)}}.Encode()
+}
+
+// proxifyImages returns a copy of resp whose image and thumbnail URLs go
+// through the proxy endpoint. resp itself may be shared with the client's
+// cache, so it is not modified.
+func (p *imageProxy) proxifyImages(resp *searxng.SearchResponse) *searxng.SearchResponse {
+	if p == nil {
+		return resp
+	}
+	proxied := *resp
+	proxied.Results = make([]searxng.SearchResult, len(resp.Results))
+	for i, r := range resp.Results {
+		if r.Thumbnail != "" {
+			r.Thumbnail = p.proxyURL(r.Thumbnail)
+		}
+		if r.ImageSrc != "" {
+			r.ImageSrc = p.proxyURL(r.ImageSrc)
+		}
+		proxied.Results[i] = r
+	}
+	return &proxied
+}
+
+// ServeHTTP fetches the signed image URL of the request and streams it back
+func (p *imageProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	src := r.URL.Query().Get("url")
+	if !hmac.Equal([]byte(r.URL.Query().Get("h")), []byte(p.sign(src))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	if _, err := validateURL(src); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req, err := newRequest(r.Context(), src, "image/*")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := pageClient(p.transport, p.proxy).Do(req)
+	if err != nil {
+		log.WithFields(logrus.Fields{"url": src, "error": err}).Debug("image proxy request failed")
+		http.Error(w, "failed to fetch image", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(contentType, "image/") || resp.ContentLength > maxProxiedImageSize {
+		http.Error(w, "upstream did not return an image", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, io.LimitReader(resp.Body, maxProxiedImageSize)); err != nil {
+		log.WithFields(logrus.Fields{"url": src, "error": err}).Debug("image proxy copy failed")
+	}
+}
+
+// ImageProxyHandler returns the handler of the image proxy endpoint, or nil
+// when Options.ImageProxyURL is unset
+func (s *Server) ImageProxyHandler() http.Handler {
+	if s.imageProxy == nil {
+		return nil
+	}
+	return s.imageProxy
+}
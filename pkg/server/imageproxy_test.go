@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewImageProxy(t *testing.T) {
+	assert.Nil(t, newImageProxy(""))
+	assert.Nil(t, newImageProxy("https://mcp.example.com"), "a path is required")
+
+	p := newImageProxy("https://mcp.example.com/image_proxy")
+	require.NotNil(t, p)
+	assert.Equal(t, "/image_proxy", p.path)
+}
+
+func TestImageProxy_ProxifyImages(t *testing.T) {
+	var p *imageProxy
+	resp := &searxng.SearchResponse{Results: []searxng.SearchResult{{ImageSrc: "https://img.example.org/a.png"}}}
+	assert.Same(t, resp, p.proxifyImages(resp), "nil proxy is a no-op")
+
+	p = newImageProxy("https://mcp.example.com/image_proxy")
+	proxied := p.proxifyImages(resp)
+	assert.Equal(t, "https://img.example.org/a.png", resp.Results[0].ImageSrc, "input must not be modified")
+
+	u, err := url.Parse(proxied.Results[0].ImageSrc)
+	require.NoError(t, err)
+	assert.Equal(t, "mcp.example.com", u.Host)
+	assert.Equal(t, "/image_proxy", u.Path)
+	assert.Equal(t, "https://img.example.org/a.png", u.Query().Get("url"))
+	assert.Equal(t, p.sign("https://img.example.org/a.png"), u.Query().Get("h"))
+	assert.Empty(t, proxied.Results[0].Thumbnail)
+}
+
+func TestImageProxy_ServeHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cat.png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Cache-Control", "max-age=60")
+			_, _ = w.Write([]byte("PNGDATA"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+	defer upstream.Close()
+
+	p := newImageProxy("http://mcp.example.com/image_proxy")
+	serve := func(rawURL string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+		return rec
+	}
+
+	rec := serve(p.proxyURL(upstream.URL + "/cat.png"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "max-age=60", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "PNGDATA", rec.Body.String())
+
+	rec = serve(p.proxyURL(upstream.URL + "/page"))
+	assert.Equal(t, http.StatusBadGateway, rec.Code, "non-image responses are refused")
+
+	rec = serve("/image_proxy?" + url.Values{"url": {upstream.URL + "/cat.png"}, "h": {"bogus"}}.Encode())
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
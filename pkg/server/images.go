@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultImageLimit = 10
+	maxImageLimit     = 50
+)
+
+// handleImageSearch handles the searxng_image_search tool call
+func (s *Server) handleImageSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("handling searxng_image_search")
+
+	// Parse arguments
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+
+	req := searxng.SearchRequest{
+		Query:    query,
+		Category: "images",
+	}
+	limit := defaultImageLimit
+	if l, ok := args["limit"].(float64); ok && l >= 1 {
+		limit = min(int(l), maxImageLimit)
+	}
+	if timeRange, ok := args["time_range"].(string); ok {
+		req.TimeRange = timeRange
+	}
+	if page, ok := args["page"].(float64); ok {
+		req.Page = int(page)
+	}
+	if err := applySafeSearch(args, &req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client, err := s.sessionClient(ctx, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateInstanceSupport(ctx, client, req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resp, err := s.search(ctx, client, req)
+	if err != nil {
+		log.WithFields(logrus.Fields{"error": err}).Error("image search failed")
+		return mcp.NewToolResultError(fmt.Sprintf("image search failed: %v", err)), nil
+	}
+
+	resultJSON, err := json.MarshalIndent(formatImageResults(s.imageProxy.proxifyImages(resp), limit), "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to format results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// formatImageResults formats up to limit image results. Results without an
+// image URL (e.g. from engines that ignore the category) are skipped.
+func formatImageResults(resp *searxng.SearchResponse, limit int) map[string]interface{} {
+	images := make([]map[string]interface{}, 0, min(len(resp.Results), limit))
+	for _, r := range resp.Results {
+		if len(images) == limit {
+			break
+		}
+		if r.ImageSrc == "" {
+			continue
+		}
+		image := map[string]interface{}{
+			"title":       r.Title,
+			"img_src":     r.ImageSrc,
+			"source_page": r.URL,
+		}
+		if r.Thumbnail != "" {
+			image["thumbnail"] = r.Thumbnail
+		}
+		if r.Resolution != "" {
+			image["resolution"] = r.Resolution
+		}
+		if r.ImageFormat != "" {
+			image["format"] = r.ImageFormat
+		}
+		if r.Engine != "" {
+			image["engine"] = r.Engine
+		}
+		images = append(images, image)
+	}
+
+	return map[string]interface{}{
+		"query":   resp.Query,
+		"results": images,
+	}
+}
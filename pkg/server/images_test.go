@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleImageSearch(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "gopher").
+		MatchParam("category", "images").
+		Reply(200).
+		JSON(searxng.APIResponse{
+			Query: "gopher",
+			Results: []searxng.APIResult{
+				{
+					URL:          "https://go.dev/blog/gopher",
+					Title:        "The Go Gopher",
+					ImgSrc:       "https://go.dev/blog/gopher/gopher.png",
+					ThumbnailSrc: "https://thumbs.example.com/gopher.png",
+					Resolution:   "1024 x 768",
+					ImgFormat:    "png",
+					Engine:       "bing images",
+				},
+				{URL: "https://example.com/no-image", Title: "Not an image"},
+				{URL: "https://example.com/2", Title: "Second", ImgSrc: "https://example.com/2.jpg"},
+				{URL: "https://example.com/3", Title: "Third", ImgSrc: "https://example.com/3.jpg"},
+			},
+		})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result, err := srv.handleImageSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "searxng_image_search",
+			Arguments: map[string]interface{}{"query": "gopher", "limit": float64(2)},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+
+	results := output["results"].([]interface{})
+	require.Len(t, results, 2)
+	first := results[0].(map[string]interface{})
+	assert.Equal(t, "https://go.dev/blog/gopher/gopher.png", first["img_src"])
+	assert.Equal(t, "https://thumbs.example.com/gopher.png", first["thumbnail"])
+	assert.Equal(t, "1024 x 768", first["resolution"])
+	assert.Equal(t, "png", first["format"])
+	assert.Equal(t, "https://go.dev/blog/gopher", first["source_page"])
+	assert.Equal(t, "https://example.com/2.jpg", results[1].(map[string]interface{})["img_src"])
+	assert.True(t, gock.IsDone())
+}
+
+func TestHandleImageSearch_MissingQuery(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result, err := srv.handleImageSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_image_search", Arguments: map[string]interface{}{}},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
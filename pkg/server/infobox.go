@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// maxCompactInfobox bounds the content length, in characters, of infoboxes
+// in compact mode
+const maxCompactInfobox = 400
+
+// formatInfoboxes formats the infoboxes of a response, the entity summaries
+// (e.g. from Wikipedia or Wikidata) some engines return for entity queries,
+// for the include_infoboxes option of searxng_search. Image URLs are
+// rewritten to the image proxy when there is one.
+func formatInfoboxes(infoboxes []searxng.Infobox, proxy *imageProxy) []map[string]interface{} {
+	images := func(src string) string {
+		if proxy == nil {
+			return src
+		}
+		return proxy.proxyURL(src)
+	}
+	formatted := make([]map[string]interface{}, 0, len(infoboxes))
+	for _, infobox := range infoboxes {
+		entry := map[string]interface{}{"label": infobox.Label}
+		for key, value := range map[string]string{
+			"content":     strings.TrimSpace(infobox.Content),
+			"engine":      infobox.Engine,
+			"attribution": infobox.Attribution,
+		} {
+			if value != "" {
+				entry[key] = value
+			}
+		}
+
+		var imageList []map[string]interface{}
+		for _, image := range infobox.Images {
+			if image.URL == "" {
+				continue
+			}
+			item := map[string]interface{}{"url": images(image.URL)}
+			if image.Alt != "" {
+				item["alt"] = image.Alt
+			}
+			if image.ThumbnailURL != "" {
+				item["thumbnail"] = images(image.ThumbnailURL)
+			}
+			imageList = append(imageList, item)
+		}
+		if len(imageList) > 0 {
+			entry["images"] = imageList
+		}
+
+		var urls []map[string]interface{}
+		for _, u := range infobox.Urls {
+			if u.URL != "" {
+				urls = append(urls, map[string]interface{}{"title": u.Title, "url": u.URL})
+			}
+		}
+		for _, topic := range infobox.RelatedTopics {
+			if topic.URL != "" {
+				urls = append(urls, map[string]interface{}{"title": topic.Name, "url": topic.URL})
+			}
+		}
+		if len(urls) > 0 {
+			entry["urls"] = urls
+		}
+		formatted = append(formatted, entry)
+	}
+	return formatted
+}
+
+// formatCompactInfobox renders an infobox of formatInfoboxes as one
+// Markdown paragraph: label, content, attribution and the first URL
+func formatCompactInfobox(infobox map[string]interface{}) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Infobox: **%s**", compactText(infobox["label"].(string), 0))
+	if content, ok := infobox["content"].(string); ok {
+		b.WriteString(" " + compactText(content, maxCompactInfobox))
+	}
+	if attribution, ok := infobox["attribution"].(string); ok {
+		b.WriteString(" (" + attribution + ")")
+	}
+	if urls, ok := infobox["urls"].([]map[string]interface{}); ok {
+		fmt.Fprintf(&b, " <%s>", urls[0]["url"])
+	}
+	return b.String()
+}
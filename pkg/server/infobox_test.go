@@ -0,0 +1,88 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var goInfobox = searxng.Infobox{
+	Label:       "Go (programming language)",
+	Content:     " Go is a statically typed, compiled programming language designed at Google. ",
+	Engine:      "wikipedia",
+	Attribution: "Wikipedia",
+	Images: []searxng.InfoboxImage{
+		{URL: "https://upload.wikimedia.org/go.svg", Alt: "Go logo", ThumbnailURL: "https://upload.wikimedia.org/go-thumb.png"},
+		{Alt: "missing URL"},
+	},
+	Urls: []searxng.InfoboxURL{
+		{Title: "Official website", URL: "https://go.dev"},
+		{Title: "no URL"},
+	},
+	RelatedTopics: []searxng.InfoboxRelatedTopic{{Name: "Rob Pike", URL: "https://en.wikipedia.org/wiki/Rob_Pike"}, {Name: "no URL"}},
+}
+
+func TestFormatInfoboxes(t *testing.T) {
+	infoboxes := formatInfoboxes([]searxng.Infobox{goInfobox, {Label: "Gopher"}}, nil)
+	require.Len(t, infoboxes, 2)
+	assert.Equal(t, map[string]interface{}{
+		"label":       "Go (programming language)",
+		"content":     "Go is a statically typed, compiled programming language designed at Google.",
+		"engine":      "wikipedia",
+		"attribution": "Wikipedia",
+		"images": []map[string]interface{}{
+			{"url": "https://upload.wikimedia.org/go.svg", "alt": "Go logo", "thumbnail": "https://upload.wikimedia.org/go-thumb.png"},
+		},
+		"urls": []map[string]interface{}{
+			{"title": "Official website", "url": "https://go.dev"},
+			{"title": "Rob Pike", "url": "https://en.wikipedia.org/wiki/Rob_Pike"},
+		},
+	}, infoboxes[0])
+	assert.Equal(t, map[string]interface{}{"label": "Gopher"}, infoboxes[1], "empty fields are left out")
+
+	proxy := newImageProxy("https://mcp.example.com/image_proxy")
+	proxied := formatInfoboxes([]searxng.Infobox{goInfobox}, proxy)
+	image := proxied[0]["images"].([]map[string]interface{})[0]
+	assert.Equal(t, proxy.proxyURL("https://upload.wikimedia.org/go.svg"), image["url"])
+	assert.Equal(t, proxy.proxyURL("https://upload.wikimedia.org/go-thumb.png"), image["thumbnail"])
+}
+
+func TestHandleWebSearch_IncludeInfoboxes(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(3).
+		Reply(200).
+		JSON(searxng.APIResponse{
+			Query:     "golang",
+			Results:   []searxng.APIResult{{URL: "https://go.dev/", Title: "The Go Programming Language"}},
+			Infoboxes: []searxng.Infobox{goInfobox},
+		})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "golang"})
+	require.False(t, result.IsError)
+	output := result.StructuredContent.(map[string]interface{})
+	assert.NotContains(t, output, "infoboxes", "off by default")
+	assert.Contains(t, output, "infobox_confidence")
+
+	result = callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "golang", "include_infoboxes": true})
+	require.False(t, result.IsError)
+	infoboxes := result.StructuredContent.(map[string]interface{})["infoboxes"].([]map[string]interface{})
+	require.Len(t, infoboxes, 1)
+	assert.Equal(t, "Go (programming language)", infoboxes[0]["label"])
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"attribution": "Wikipedia"`)
+
+	result = callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "golang", "include_infoboxes": true, "mode": "compact"})
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text,
+		"Infobox: **Go (programming language)** Go is a statically typed, compiled programming language designed at Google. (Wikipedia) <https://go.dev>\n\n1. **The Go Programming Language**")
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// instancePool hands out Searxng clients for operator-allowlisted
+// instances, creating them lazily so each instance keeps its own connection
+// pool and rate limiter
+type instancePool struct {
+	base      *searxng.Client
+	allowlist map[string]bool
+
+	mu      sync.Mutex
+	clients map[string]*searxng.Client
+}
+
+func newInstancePool(base *searxng.Client, allowlist []string) *instancePool {
+	pool := &instancePool{
+		base:      base,
+		allowlist: make(map[string]bool, len(allowlist)),
+		clients:   make(map[string]*searxng.Client),
+	}
+	for _, instance := range allowlist {
+		if key, err := normalizeInstanceURL(instance); err == nil {
+			pool.allowlist[key] = true
+		}
+	}
+	return pool
+}
+
+// enabled reports whether any instance may be selected per request
+func (p *instancePool) enabled() bool {
+	return len(p.allowlist) > 0
+}
+
+// client returns the client for instanceURL, or the default client when
+// instanceURL is empty
+func (p *instancePool) client(instanceURL string) (*searxng.Client, error) {
+	if instanceURL == "" {
+		return p.base, nil
+	}
+
+	key, err := normalizeInstanceURL(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+	if !p.allowlist[key] {
+		return nil, fmt.Errorf("instance_url %s is not in the server's instance allowlist", instanceURL)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if client, ok := p.clients[key]; ok {
+		return client, nil
+	}
+	client, err := p.base.WithBaseURL(key)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[key] = client
+	return client, nil
+}
+
+// release closes the idle connections of the allowlisted instance clients
+// and drops them with their caches; client creates them again when needed
+func (p *instancePool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, client := range p.clients {
+		client.CloseIdleConnections()
+	}
+	clear(p.clients)
+}
+
+// normalizeInstanceURL lowercases scheme and host and drops trailing
+// slashes, query and fragment, so allowlist entries match regardless of
+// formatting
+func normalizeInstanceURL(instanceURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(instanceURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid instance_url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid instance_url: %s (must be an absolute http or https URL)", instanceURL)
+	}
+	return strings.ToLower(parsed.Scheme) + "://" + strings.ToLower(parsed.Host) + strings.TrimRight(parsed.Path, "/"), nil
+}
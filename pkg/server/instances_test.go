@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeInstanceURL(t *testing.T) {
+	got, err := normalizeInstanceURL("HTTPS://Search.Example.org/searxng/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://search.example.org/searxng", got)
+
+	_, err = normalizeInstanceURL("search.example.org")
+	assert.Error(t, err)
+
+	_, err = normalizeInstanceURL("file:///etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestInstancePool(t *testing.T) {
+	base, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	pool := newInstancePool(base, []string{"https://other.example.org/"})
+	assert.True(t, pool.enabled())
+
+	client, err := pool.client("")
+	require.NoError(t, err)
+	assert.Same(t, base, client)
+
+	other, err := pool.client("https://other.example.org")
+	require.NoError(t, err)
+	assert.Equal(t, "https://other.example.org", other.BaseURL())
+
+	again, err := pool.client("https://OTHER.example.org/")
+	require.NoError(t, err)
+	assert.Same(t, other, again)
+
+	_, err = pool.client("https://evil.example.org")
+	assert.ErrorContains(t, err, "not in the server's instance allowlist")
+}
+
+func TestHandleWebSearch_InstanceURL(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://other.example.org").
+		Get("/search").
+		MatchParam("q", "golang").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "golang"})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{InstanceAllowlist: []string{"https://other.example.org"}})
+
+	_, ok := srv.MCPServer().GetTool("searxng_search").Tool.InputSchema.Properties["instance_url"]
+	assert.True(t, ok)
+
+	result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "searxng_search",
+			Arguments: map[string]interface{}{
+				"query":        "golang",
+				"instance_url": "https://other.example.org",
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.True(t, gock.IsDone())
+
+	result, err = srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "searxng_search",
+			Arguments: map[string]interface{}{
+				"query":        "golang",
+				"instance_url": "https://evil.example.org",
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleWebSearch_InstanceURLDisabled(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	_, ok := srv.MCPServer().GetTool("searxng_search").Tool.InputSchema.Properties["instance_url"]
+	assert.False(t, ok)
+
+	result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "searxng_search",
+			Arguments: map[string]interface{}{
+				"query":        "golang",
+				"instance_url": "https://other.example.org",
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// scriptLanguages maps scripts written by a single major language to it.
+// Cyrillic and Han, shared by several languages, are handled apart.
+var scriptLanguages = []struct {
+	script *unicode.RangeTable
+	lang   string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Arabic, "ar"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// stopwords are frequent words that tell Latin script languages apart.
+// Words shared by several languages count for each.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "for", "how", "what", "with", "why", "best", "does"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "wie", "was", "für", "ein", "eine", "ich", "auf"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "pour", "comment", "pourquoi", "avec", "dans", "du"},
+	"es": {"el", "la", "los", "las", "y", "es", "de", "para", "cómo", "qué", "por", "con", "una", "del"},
+	"it": {"il", "la", "di", "che", "e", "è", "per", "come", "perché", "con", "una", "gli", "della", "del"},
+	"pt": {"o", "a", "os", "as", "de", "e", "é", "para", "como", "que", "com", "uma", "não", "do", "da"},
+	"nl": {"de", "het", "een", "en", "is", "van", "voor", "hoe", "wat", "niet", "met", "zijn"},
+}
+
+// accents are letters that each count as a stopword of the languages
+// using them
+var accents = map[string]string{
+	"de": "äöüß",
+	"fr": "çèêàùœ",
+	"es": "ñ¿¡áíóú",
+	"it": "àèìòù",
+	"pt": "ãõçâê",
+}
+
+// detectLanguage guesses the language of a search query: from its script,
+// or from its stopwords and accented letters for Latin script. It returns
+// "" when the query gives no clear answer, e.g. for a few keywords.
+func detectLanguage(query string) string {
+	var letters, cyrillic, ukrainian, han, kana int
+	scripts := map[string]int{}
+	for _, r := range query {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				ukrainian++
+			}
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		default:
+			for _, sl := range scriptLanguages {
+				if unicode.Is(sl.script, r) {
+					scripts[sl.lang]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	switch {
+	case cyrillic*2 > letters && ukrainian > 0:
+		return "uk"
+	case cyrillic*2 > letters:
+		return "ru"
+	case (han+kana)*2 > letters && kana > 0:
+		return "ja"
+	case han*2 > letters:
+		return "zh"
+	}
+	for lang, n := range scripts {
+		if n*2 > letters {
+			return lang
+		}
+	}
+
+	lower := strings.ToLower(query)
+	scores := map[string]int{}
+	for _, word := range strings.FieldsFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for lang, words := range stopwords {
+			for _, w := range words {
+				if word == w {
+					scores[lang]++
+				}
+			}
+		}
+	}
+	for lang, chars := range accents {
+		for _, r := range chars {
+			scores[lang] += strings.Count(lower, string(r))
+		}
+	}
+
+	best, bestScore, tie := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = lang, score, false
+		case score == bestScore:
+			tie = true
+		}
+	}
+	if tie || bestScore < 2 {
+		return ""
+	}
+	return best
+}
+
+// queryLanguage returns the detected language of query when the instance
+// of client supports it (or doesn't publish its locales), "" otherwise
+func queryLanguage(ctx context.Context, client *searxng.Client, query string) string {
+	lang := detectLanguage(query)
+	if lang == "" {
+		return ""
+	}
+	if info, err := client.InstanceInfo(ctx); err == nil && !info.SupportsLanguage(lang) {
+		log.WithField("language", lang).Debug("detected language not supported by the instance")
+		return ""
+	}
+	return lang
+}
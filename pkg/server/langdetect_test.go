@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"how does the garbage collector work", "en"},
+		{"wie funktioniert die Müllabfuhr", "de"},
+		{"comment installer la fibre dans une maison", "fr"},
+		{"¿cómo se prepara una paella?", "es"},
+		{"perché il cielo è blu", "it"},
+		{"como fazer pão de queijo", "pt"},
+		{"hoe werkt een warmtepomp", "nl"},
+		{"как приготовить борщ", "ru"},
+		{"як приготувати борщ їжа", "uk"},
+		{"東京の天気", "ja"},
+		{"北京天气预报", "zh"},
+		{"서울 날씨", "ko"},
+		{"Αθήνα καιρός", "el"},
+		{"golang generics", ""},
+		{"kubernetes ingress nginx", ""},
+		{"1234 5678", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, detectLanguage(tt.query), tt.query)
+	}
+}
+
+func TestHandleWebSearch_Language(t *testing.T) {
+	var gotLanguage string
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/config":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"locales": map[string]string{"en": "English", "de": "Deutsch", "fr-CA": "Français (Canada)"}})
+		case "/search":
+			gotLanguage = r.URL.Query().Get("language")
+			_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: r.URL.Query().Get("q")})
+		}
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+
+	search := func(srv *Server, args map[string]interface{}) *mcp.CallToolResult {
+		gotLanguage = ""
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: args},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	srv := New(client)
+	assert.False(t, search(srv, map[string]interface{}{"query": "q", "language": "de-CH"}).IsError)
+	assert.Equal(t, "de-CH", gotLanguage)
+
+	result := search(srv, map[string]interface{}{"query": "q", "language": "it"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `language "it" is not supported by this searxng instance (available languages: de, en, fr-CA)`)
+
+	assert.False(t, search(srv, map[string]interface{}{"query": "wie funktioniert die Müllabfuhr"}).IsError)
+	assert.Empty(t, gotLanguage, "detection is disabled by default")
+
+	srv = NewWithOptions(client, Options{DetectLanguage: true})
+	result = search(srv, map[string]interface{}{"query": "wie funktioniert die Müllabfuhr"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "de", gotLanguage)
+	assert.Equal(t, "de", result.StructuredContent.(map[string]interface{})["detected_language"])
+
+	result = search(srv, map[string]interface{}{"query": "perché il cielo è blu"})
+	require.False(t, result.IsError)
+	assert.Empty(t, gotLanguage, "unsupported detected languages are dropped")
+	assert.NotContains(t, result.StructuredContent.(map[string]interface{}), "detected_language")
+
+	assert.False(t, search(srv, map[string]interface{}{"query": "wie funktioniert die Müllabfuhr", "language": "en"}).IsError)
+	assert.Equal(t, "en", gotLanguage, "an explicit language wins")
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxConcurrentLinkChecks bounds the requests sent by verifyLinks
+	maxConcurrentLinkChecks = 8
+	// linkCheckTimeout bounds a single link check
+	linkCheckTimeout = 5 * time.Second
+)
+
+// linkStatus is the outcome of a link check
+type linkStatus int
+
+const (
+	linkAlive linkStatus = iota
+	// linkDead means the page is gone (HTTP 404/410 or an unknown host)
+	linkDead
+	// linkUnverified means the check was inconclusive, e.g. the site blocks
+	// HEAD requests or timed out
+	linkUnverified
+)
+
+// checkLink sends a HEAD request to rawURL, falling back to GET when the
+// server doesn't support HEAD, and classifies the outcome. The returned
+// reason describes dead and unverified links.
+func checkLink(ctx context.Context, client *http.Client, rawURL string) (linkStatus, string) {
+	ctx, cancel := context.WithTimeout(ctx, linkCheckTimeout)
+	defer cancel()
+
+	status, err := linkRequest(ctx, client, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = linkRequest(ctx, client, http.MethodGet, rawURL)
+	}
+
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return linkDead, "unknown host"
+	case err != nil:
+		return linkUnverified, err.Error()
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return linkDead, fmt.Sprintf("HTTP %d", status)
+	case status >= 400:
+		return linkUnverified, fmt.Sprintf("HTTP %d", status)
+	}
+	return linkAlive, ""
+}
+
+func linkRequest(ctx context.Context, client *http.Client, method, rawURL string) (int, error) {
+	req, err := newRequest(ctx, rawURL, "")
+	if err != nil {
+		return 0, err
+	}
+	req.Method = method
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.CopyN(io.Discard, resp.Body, 4<<10)
+	return resp.StatusCode, nil
+}
+
+// verifyLinks checks the result URLs of resp concurrently and returns a copy
+// of resp without dead links, the number of dropped results and the reasons
+// of the links that couldn't be verified, keyed by URL. Checks are sent
+// with client.
+func verifyLinks(ctx context.Context, resp *searxng.SearchResponse, client *http.Client) (*searxng.SearchResponse, int, map[string]string) {
+	statuses := make([]linkStatus, len(resp.Results))
+	reasons := make([]string, len(resp.Results))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentLinkChecks)
+	for i, result := range resp.Results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			statuses[i], reasons[i] = checkLink(ctx, client, result.URL)
+		}()
+	}
+	wg.Wait()
+
+	results := make([]searxng.SearchResult, 0, len(resp.Results))
+	unverified := make(map[string]string)
+	for i, result := range resp.Results {
+		switch statuses[i] {
+		case linkDead:
+			log.WithFields(logrus.Fields{"url": result.URL, "reason": reasons[i]}).Debug("dropping dead link")
+			continue
+		case linkUnverified:
+			unverified[result.URL] = reasons[i]
+		}
+		results = append(results, result)
+	}
+
+	verified := *resp
+	verified.Results = results
+	return &verified, len(resp.Results) - len(results), unverified
+}
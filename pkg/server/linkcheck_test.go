@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLinkServer serves /ok, /gone (404), /blocked (403) and /nohead (405
+// for HEAD, 200 for GET)
+func newLinkServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "/blocked":
+			w.WriteHeader(http.StatusForbidden)
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}
+	}))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestCheckLink(t *testing.T) {
+	links := newLinkServer()
+	defer links.Close()
+
+	ctx := context.Background()
+	client := newHTTPClient()
+	tests := []struct {
+		path   string
+		status linkStatus
+		reason string
+	}{
+		{"/ok", linkAlive, ""},
+		{"/gone", linkDead, "HTTP 404"},
+		{"/blocked", linkUnverified, "HTTP 403"},
+		{"/nohead", linkAlive, ""},
+	}
+	for _, tt := range tests {
+		status, reason := checkLink(ctx, client, links.URL+tt.path)
+		assert.Equal(t, tt.status, status, tt.path)
+		assert.Equal(t, tt.reason, reason, tt.path)
+	}
+
+	unknownHost := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: "gone.example", IsNotFound: true}
+	})}
+	status, reason := checkLink(ctx, unknownHost, "https://gone.example/")
+	assert.Equal(t, linkDead, status)
+	assert.Equal(t, "unknown host", reason)
+
+	refused := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "timeout", IsTimeout: true}}
+	})}
+	status, _ = checkLink(ctx, refused, "https://slow.example/")
+	assert.Equal(t, linkUnverified, status)
+}
+
+func TestHandleWebSearch_VerifyLinks(t *testing.T) {
+	links := newLinkServer()
+	defer links.Close()
+
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: "q", Results: []searxng.APIResult{
+			{URL: links.URL + "/ok", Title: "OK"},
+			{URL: links.URL + "/gone", Title: "Gone"},
+			{URL: links.URL + "/blocked", Title: "Blocked"},
+		}})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+
+	result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{"query": "q", "verify_links": true}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var output struct {
+		DeadLinksRemoved int `json:"dead_links_removed"`
+		Results          []struct {
+			Title          string `json:"title"`
+			LinkUnverified string `json:"link_unverified"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	assert.Equal(t, 1, output.DeadLinksRemoved)
+	require.Len(t, output.Results, 2)
+	assert.Equal(t, "OK", output.Results[0].Title)
+	assert.Empty(t, output.Results[0].LinkUnverified)
+	assert.Equal(t, "Blocked", output.Results[1].Title)
+	assert.Equal(t, "HTTP 403", output.Results[1].LinkUnverified)
+}
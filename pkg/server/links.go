@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults and bounds of searxng_extract_links
+const (
+	defaultLinksLimit = 100
+	maxLinksLimit     = 500
+	// maxLinkTextLength cuts the text of links wrapping whole paragraphs
+	maxLinkTextLength = 200
+)
+
+// extractLinksTool returns the definition of searxng_extract_links
+func extractLinksTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "searxng_extract_links",
+		Description: "Fetch a web page and list its links with their text, absolute URL and rel attribute (e.g. 'next'), in page order and without duplicates. Useful to navigate a site: find the next page of a listing, the sections of documentation or related pages, without parsing them out of searxng_read output. Only HTML pages have links.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"url"},
+			Properties: map[string]interface{}{
+				"url": map[string]interface{}{
+					"type":        "string",
+					"description": "The URL of the page",
+				},
+				"same_domain": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Only list links to the page's host and its subdomains (default: false)",
+				},
+				"pattern": map[string]interface{}{
+					"type":        "string",
+					"description": "Only list links whose absolute URL matches this regular expression, e.g. '/docs/' or 'page=\\d+'",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": fmt.Sprintf("Maximum number of links to return (default: %d, max: %d)", defaultLinksLimit, maxLinksLimit),
+					"minimum":     1,
+					"maximum":     maxLinksLimit,
+				},
+			},
+		},
+	}
+}
+
+// pageLink is a link of a page listed by searxng_extract_links
+type pageLink struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+	Rel  string `json:"rel,omitempty"`
+}
+
+// linkFilter selects the links listed by searxng_extract_links
+type linkFilter struct {
+	host    string         // with sameDomain, the page's host without "www."
+	pattern *regexp.Regexp // nil: any URL
+}
+
+// allows reports whether the filter keeps the link to u
+func (f linkFilter) allows(u *url.URL) bool {
+	if f.host != "" && !onDomain(strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), []string{f.host}) {
+		return false
+	}
+	return f.pattern == nil || f.pattern.MatchString(u.String())
+}
+
+// handleExtractLinks handles the searxng_extract_links tool call
+func (s *Server) handleExtractLinks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("handling searxng_extract_links")
+	ctx, span := startSpan(ctx, "searxng_extract_links")
+	defer span.End()
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+	pageURL, _ := args["url"].(string)
+	if pageURL == "" {
+		return mcp.NewToolResultError("url is required"), nil
+	}
+	base, err := validateURL(pageURL)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var filter linkFilter
+	if sameDomain, _ := args["same_domain"].(bool); sameDomain {
+		filter.host = strings.TrimPrefix(strings.ToLower(base.Hostname()), "www.")
+	}
+	if pattern, _ := args["pattern"].(string); pattern != "" {
+		if filter.pattern, err = regexp.Compile(pattern); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid pattern: %v", err)), nil
+		}
+	}
+	limit := defaultLinksLimit
+	if l, ok := args["limit"].(float64); ok && l >= 1 {
+		limit = min(int(l), maxLinksLimit)
+	}
+
+	if err := s.checkRead(ctx, pageURL); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts := s.defaultReadOptions()
+	opts.Mode = ReadModeFull
+	opts.IncludeHTML = true
+	page, err := s.fetchPage(ctx, pageURL, opts)
+	if err != nil {
+		log.WithFields(logrus.Fields{"url": pageURL, "error": err}).Debug("reading page for its links failed")
+		spanError(ctx, err)
+		return mcp.NewToolResultError(fmt.Sprintf("failed to fetch URL: %v", err)), nil
+	}
+	if page.HTML == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not an HTML page, so it has no links to extract", pageURL)), nil
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.HTML))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse HTML: %v", err)), nil
+	}
+
+	links, total := extractLinks(doc, base, filter, limit)
+	output := map[string]interface{}{
+		"url":   pageURL,
+		"links": links,
+		"total": total,
+	}
+	if total > len(links) {
+		output["truncated"] = true
+	}
+	resultJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to format links: %v", err)), nil
+	}
+	return mcp.NewToolResultStructured(output, string(resultJSON)), nil
+}
+
+// extractLinks returns the first limit links of doc the filter keeps, and
+// their total number: the http(s) targets of <a> elements and of the
+// <link rel="next"> and rel="prev" elements of the head, resolved against
+// base (or the page's <base href>). Links to the page itself, e.g. to
+// its own sections, and repeated URLs are skipped.
+func extractLinks(doc *goquery.Document, base *url.URL, filter linkFilter, limit int) ([]pageLink, int) {
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := base.Parse(strings.TrimSpace(href)); err == nil {
+			base = u
+		}
+	}
+	self := *base
+	self.Fragment = ""
+
+	links := []pageLink{}
+	seen := map[string]bool{self.String(): true}
+	total := 0
+	doc.Find("a[href], link[href][rel]").Each(func(_ int, s *goquery.Selection) {
+		rel := strings.Join(strings.Fields(strings.ToLower(s.AttrOr("rel", ""))), " ")
+		if goquery.NodeName(s) == "link" && !isNavigationRel(rel) {
+			return
+		}
+		u, err := base.Parse(strings.TrimSpace(s.AttrOr("href", "")))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return
+		}
+		u.Fragment = ""
+		target := u.String()
+		if seen[target] || !filter.allows(u) {
+			return
+		}
+		seen[target] = true
+		total++
+		if len(links) < limit {
+			links = append(links, pageLink{Text: linkText(s), URL: target, Rel: rel})
+		}
+	})
+	return links, total
+}
+
+// isNavigationRel reports whether the rel of a <link> element points to
+// another page of a sequence
+func isNavigationRel(rel string) bool {
+	for _, r := range strings.Fields(rel) {
+		if r == "next" || r == "prev" || r == "previous" {
+			return true
+		}
+	}
+	return false
+}
+
+// linkText returns the collapsed text of a link, falling back to its
+// title or the alt text of its images
+func linkText(s *goquery.Selection) string {
+	text := compactText(s.Text(), maxLinkTextLength)
+	if text == "" {
+		text = compactText(s.AttrOr("title", ""), maxLinkTextLength)
+	}
+	if text == "" {
+		text = compactText(s.Find("img[alt]").First().AttrOr("alt", ""), maxLinkTextLength)
+	}
+	return text
+}
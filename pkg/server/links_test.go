@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const linksPage = `<html><head>
+<link rel="stylesheet" href="/style.css">
+<link rel="next" href="/docs/page/2">
+</head><body>
+<nav><a href="/">Home</a> <a href="#install">Install</a> <a href="/docs/page/1#top">This page</a></nav>
+<a href="/docs/intro">  Introduction
+  to the docs </a>
+<a href="intro">Relative</a>
+<a href="https://www.example.org/docs/intro">Duplicate</a>
+<a href="https://blog.example.org/post" rel="nofollow Noopener">Blog</a>
+<a href="https://other.example.com/" title="Elsewhere"></a>
+<a href="https://cdn.example.net/"><img src="logo.png" alt="Sponsor logo"></a>
+<a href="mailto:docs@example.org">Mail</a>
+<a href="javascript:void(0)">Click</a>
+</body></html>`
+
+func TestExtractLinks(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(linksPage))
+	require.NoError(t, err)
+	base, _ := url.Parse("https://www.example.org/docs/page/1")
+
+	links, total := extractLinks(doc, base, linkFilter{}, 100)
+	assert.Equal(t, []pageLink{
+		{Text: "", URL: "https://www.example.org/docs/page/2", Rel: "next"},
+		{Text: "Home", URL: "https://www.example.org/"},
+		{Text: "Introduction to the docs", URL: "https://www.example.org/docs/intro"},
+		{Text: "Relative", URL: "https://www.example.org/docs/page/intro"},
+		{Text: "Blog", URL: "https://blog.example.org/post", Rel: "nofollow noopener"},
+		{Text: "Elsewhere", URL: "https://other.example.com/"},
+		{Text: "Sponsor logo", URL: "https://cdn.example.net/"},
+	}, links, "anchors to the page itself, duplicates, stylesheets and other schemes are skipped")
+	assert.Equal(t, 7, total)
+
+	links, total = extractLinks(doc, base, linkFilter{host: "example.org"}, 2)
+	assert.Equal(t, []pageLink{
+		{Text: "", URL: "https://www.example.org/docs/page/2", Rel: "next"},
+		{Text: "Home", URL: "https://www.example.org/"},
+	}, links)
+	assert.Equal(t, 5, total, "subdomains are on the same domain")
+
+	links, _ = extractLinks(doc, base, linkFilter{pattern: regexp.MustCompile(`/docs/[a-z]+$`)}, 100)
+	assert.Equal(t, []pageLink{{Text: "Introduction to the docs", URL: "https://www.example.org/docs/intro"}}, links)
+}
+
+func TestExtractLinks_BaseHref(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<head><base href="https://mirror.example.com/v2/"></head><a href="guide">Guide</a>`))
+	require.NoError(t, err)
+	base, _ := url.Parse("https://example.com/")
+	links, _ := extractLinks(doc, base, linkFilter{}, 10)
+	assert.Equal(t, []pageLink{{Text: "Guide", URL: "https://mirror.example.com/v2/guide"}}, links)
+}
+
+func TestHandleExtractLinks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/data.json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"links": []}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(linksPage))
+	}))
+	defer ts.Close()
+	srv := New(nil)
+
+	result := callToolResult(t, srv, "searxng_extract_links", map[string]interface{}{
+		"url": ts.URL + "/docs/page/1", "same_domain": true, "pattern": "/docs/", "limit": 2.0,
+	})
+	require.False(t, result.IsError)
+	output := result.StructuredContent.(map[string]interface{})
+	assert.Equal(t, []pageLink{
+		{URL: ts.URL + "/docs/page/2", Rel: "next"},
+		{Text: "Introduction to the docs", URL: ts.URL + "/docs/intro"},
+	}, output["links"])
+	assert.Equal(t, 3, output["total"])
+	assert.Equal(t, true, output["truncated"])
+
+	for _, tc := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"url": ""}, "url is required"},
+		{map[string]interface{}{"url": "ftp://example.com/"}, "unsupported URL scheme"},
+		{map[string]interface{}{"url": ts.URL, "pattern": "("}, "invalid pattern"},
+		{map[string]interface{}{"url": ts.URL + "/data.json"}, "is not an HTML page"},
+	} {
+		result := callToolResult(t, srv, "searxng_extract_links", tc.args)
+		require.True(t, result.IsError, tc.want)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tc.want)
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultToolLocale is the language tool descriptions are written in
+const DefaultToolLocale = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// toolTranslation holds the translated description of a tool and of its
+// input parameters, keyed by parameter name
+type toolTranslation struct {
+	Description string            `json:"description"`
+	Parameters  map[string]string `json:"parameters"`
+}
+
+// SupportedToolLocales returns the locales tool descriptions can be
+// registered in, including the built-in English.
+func SupportedToolLocales() []string {
+	locales := []string{DefaultToolLocale}
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		return locales
+	}
+	for _, entry := range entries {
+		locales = append(locales, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// ParseToolLocale normalizes a tool locale and checks that translations
+// for it are embedded. An empty string maps to DefaultToolLocale.
+func ParseToolLocale(s string) (string, error) {
+	locale := strings.ToLower(strings.TrimSpace(s))
+	if locale == "" {
+		return DefaultToolLocale, nil
+	}
+	if _, err := loadToolTranslations(locale); err != nil {
+		return "", err
+	}
+	return locale, nil
+}
+
+// loadToolTranslations loads the embedded translations for locale. English
+// (or an empty locale) yields no translations.
+func loadToolTranslations(locale string) (map[string]toolTranslation, error) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" || locale == DefaultToolLocale {
+		return nil, nil
+	}
+
+	payload, err := localeFS.ReadFile(path.Join("locales", locale+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported tool locale: %s (supported: %s)", locale, strings.Join(SupportedToolLocales(), ", "))
+	}
+
+	var translations map[string]toolTranslation
+	if err := json.Unmarshal(payload, &translations); err != nil {
+		return nil, fmt.Errorf("invalid translations for locale %s: %w", locale, err)
+	}
+	return translations, nil
+}
+
+// localizeTool replaces the tool and parameter descriptions with their
+// translations. Missing entries keep the English text.
+func localizeTool(tool *mcp.Tool, translations map[string]toolTranslation) {
+	translation, ok := translations[tool.Name]
+	if !ok {
+		return
+	}
+
+	if translation.Description != "" {
+		tool.Description = translation.Description
+	}
+	for name, description := range translation.Parameters {
+		property, ok := tool.InputSchema.Properties[name].(map[string]interface{})
+		if !ok || description == "" {
+			continue
+		}
+		property["description"] = description
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolLocale(t *testing.T) {
+	locale, err := ParseToolLocale("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultToolLocale, locale)
+
+	locale, err = ParseToolLocale(" DE ")
+	require.NoError(t, err)
+	assert.Equal(t, "de", locale)
+
+	_, err = ParseToolLocale("xx")
+	assert.ErrorContains(t, err, "unsupported tool locale")
+}
+
+func TestSupportedToolLocales(t *testing.T) {
+	assert.Equal(t, []string{"de", "en", "es", "fr", "it"}, SupportedToolLocales())
+}
+
+// TestToolTranslations_Complete ensures every embedded locale translates
+// every registered tool and parameter, so new tools don't silently fall
+// back to English.
+func TestToolTranslations_Complete(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	// Enable every optional parameter so its translation is checked too
+	tools := NewWithOptions(client, Options{
+		InstanceAllowlist: []string{"https://searxng.example.org"},
+		AdminTokens:       []string{"admin"},
+	}).MCPServer().ListTools()
+
+	for _, locale := range SupportedToolLocales() {
+		if locale == DefaultToolLocale {
+			continue
+		}
+		t.Run(locale, func(t *testing.T) {
+			translations, err := loadToolTranslations(locale)
+			require.NoError(t, err)
+
+			for name, tool := range tools {
+				translation, ok := translations[name]
+				if !assert.True(t, ok, "missing translation for tool %s", name) {
+					continue
+				}
+				assert.NotEmpty(t, translation.Description, "tool %s", name)
+				for param := range tool.Tool.InputSchema.Properties {
+					assert.NotEmpty(t, translation.Parameters[param], "tool %s parameter %s", name, param)
+				}
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_ToolLocale(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	srv := NewWithOptions(client, Options{ToolLocale: "de"})
+	tool := srv.MCPServer().GetTool("searxng_search").Tool
+
+	assert.Contains(t, tool.Description, "Durchsucht das Web")
+	query := tool.InputSchema.Properties["query"].(map[string]interface{})
+	assert.Equal(t, "Die Suchanfrage", query["description"])
+
+	english := New(client).MCPServer().GetTool("searxng_search").Tool
+	assert.Contains(t, english.Description, "Search the web")
+}
+
+func TestNewWithOptions_UnknownToolLocaleFallsBack(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	srv := NewWithOptions(client, Options{ToolLocale: "xx"})
+	assert.Contains(t, srv.MCPServer().GetTool("searxng_search").Tool.Description, "Search the web")
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// setLogLevelTool returns the definition of searxng_set_log_level, which is
+// only registered with Options.AdminTokens
+func setLogLevelTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "searxng_set_log_level",
+		Description: "Change the log level of the server at runtime, e.g. to debug an issue without a restart. Admin only: requires an admin token.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"level"},
+			Properties: map[string]interface{}{
+				"level": map[string]interface{}{
+					"type":        "string",
+					"description": "The new log level: 'trace', 'debug', 'info', 'warn' or 'error'",
+					"enum":        log.Levels,
+				},
+			},
+		},
+	}
+}
+
+// handleSetLogLevel handles the searxng_set_log_level tool call, refusing
+// callers without an admin token
+func (s *Server) handleSetLogLevel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !isAdmin(ctx) {
+		return mcp.NewToolResultError(fmt.Sprintf("%s requires an admin token", s.toolName("searxng_set_log_level"))), nil
+	}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+	level, _ := args["level"].(string)
+	if level == "" {
+		return mcp.NewToolResultError("level is required"), nil
+	}
+
+	previous := log.Level()
+	if err := log.SetLevel(level); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Logged as a warning, so it shows at every level but error
+	log.WithFields(logrus.Fields{"previous_level": previous, "new_level": level}).Warn("log level changed")
+	return mcp.NewToolResultStructured(
+		map[string]interface{}{"previous_level": previous, "level": level},
+		fmt.Sprintf("Log level changed from %s to %s.", previous, level),
+	), nil
+}
+
+// adminKey is the context key marking requests authenticated with one of
+// Options.AdminTokens
+type adminKey struct{}
+
+// withAdmin marks ctx as carrying an admin token
+func withAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey{}, true)
+}
+
+// isAdmin reports whether ctx was marked by withAdmin
+func isAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey{}).(bool)
+	return admin
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	previous := log.Level()
+	t.Cleanup(func() { _ = log.SetLevel(previous) })
+	require.NoError(t, log.SetLevel("info"))
+
+	assert.Nil(t, New(nil).MCPServer().GetTool("searxng_set_log_level"), "only registered with admin tokens")
+
+	srv := NewWithOptions(nil, Options{AdminTokens: []string{"admin"}})
+	call := func(ctx context.Context, level string) *mcp.CallToolResult {
+		msg, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "tools/call",
+			"params":  map[string]interface{}{"name": "searxng_set_log_level", "arguments": map[string]interface{}{"level": level}},
+		})
+		require.NoError(t, err)
+		resp, ok := srv.MCPServer().HandleMessage(ctx, msg).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		return resp.Result.(*mcp.CallToolResult)
+	}
+
+	result := call(context.Background(), "debug")
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "requires an admin token")
+	assert.Equal(t, "info", log.Level())
+
+	result = call(withAdmin(context.Background()), "debug")
+	require.False(t, result.IsError)
+	assert.Equal(t, "debug", log.Level())
+	assert.Equal(t, map[string]interface{}{"previous_level": "info", "level": "debug"}, result.StructuredContent)
+
+	result = call(withAdmin(context.Background()), "verbose")
+	assert.True(t, result.IsError)
+	assert.Equal(t, "debug", log.Level())
+}
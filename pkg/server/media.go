@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// mediaCategories maps the searxng_media_search types to Searxng categories
+var mediaCategories = map[string]string{
+	"image": "images",
+	"video": "videos",
+}
+
+// handleMediaSearch handles the searxng_media_search tool call. Type "all"
+// searches the images and videos categories concurrently and interleaves
+// their results.
+func (s *Server) handleMediaSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("handling searxng_media_search")
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+
+	types := []string{"image", "video"}
+	if mediaType, ok := args["type"].(string); ok && mediaType != "" && mediaType != "all" {
+		if _, ok := mediaCategories[mediaType]; !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid type %q (must be 'all', 'image' or 'video')", mediaType)), nil
+		}
+		types = []string{mediaType}
+	}
+	limit := defaultImageLimit
+	if l, ok := args["limit"].(float64); ok && l >= 1 {
+		limit = min(int(l), maxImageLimit)
+	}
+
+	reqs := make([]searxng.SearchRequest, len(types))
+	client, err := s.sessionClient(ctx, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	for i, mediaType := range types {
+		reqs[i] = searxng.SearchRequest{Query: query, Category: mediaCategories[mediaType]}
+		if timeRange, ok := args["time_range"].(string); ok {
+			reqs[i].TimeRange = timeRange
+		}
+		if page, ok := args["page"].(float64); ok {
+			reqs[i].Page = int(page)
+		}
+		if err := applySafeSearch(args, &reqs[i]); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := validateInstanceSupport(ctx, client, reqs[i]); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	resps := make([]*searxng.SearchResponse, len(reqs))
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resps[i], errs[i] = s.search(ctx, client, req)
+		}()
+	}
+	wg.Wait()
+
+	media := make([][]map[string]interface{}, 0, len(types))
+	var failed []string
+	for i, mediaType := range types {
+		if errs[i] != nil {
+			log.WithFields(logrus.Fields{"error": errs[i], "type": mediaType}).Error("media search failed")
+			failed = append(failed, fmt.Sprintf("%s search failed: %v", mediaType, errs[i]))
+			continue
+		}
+		media = append(media, formatMediaResults(s.imageProxy.proxifyImages(resps[i]), mediaType))
+	}
+	if len(media) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("media search failed: %v", errs[0])), nil
+	}
+
+	output := map[string]interface{}{
+		"query":   query,
+		"results": interleaveMedia(media, limit),
+	}
+	if len(failed) > 0 {
+		// The other type still answered; say what is missing
+		output["errors"] = failed
+	}
+	resultJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to format results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// formatMediaResults formats the results of a search of mediaType, "image"
+// or "video". Images without an image URL (e.g. from engines that ignore
+// the category) are skipped.
+func formatMediaResults(resp *searxng.SearchResponse, mediaType string) []map[string]interface{} {
+	media := make([]map[string]interface{}, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if mediaType == "image" && r.ImageSrc == "" {
+			continue
+		}
+		item := map[string]interface{}{
+			"type":        mediaType,
+			"title":       r.Title,
+			"source_page": r.URL,
+		}
+		optional := map[string]string{
+			"img_src":    r.ImageSrc,
+			"thumbnail":  r.Thumbnail,
+			"resolution": r.Resolution,
+			"format":     r.ImageFormat,
+			"duration":   r.Duration,
+			"embed_url":  r.EmbedURL,
+			"author":     r.Author,
+			"engine":     r.Engine,
+		}
+		for key, value := range optional {
+			if value != "" {
+				item[key] = value
+			}
+		}
+		if r.PublishedDate != nil {
+			item["published_date"] = r.PublishedDate.Format("2006-01-02")
+		}
+		media = append(media, item)
+	}
+	return media
+}
+
+// interleaveMedia alternates between the result lists, so neither type
+// crowds out the other, and returns up to limit results
+func interleaveMedia(lists [][]map[string]interface{}, limit int) []map[string]interface{} {
+	merged := make([]map[string]interface{}, 0, limit)
+	for i := 0; len(merged) < limit; i++ {
+		added := false
+		for _, list := range lists {
+			if i < len(list) && len(merged) < limit {
+				merged = append(merged, list[i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return merged
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleMediaSearch(t *testing.T) {
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		resp := searxng.APIResponse{Query: r.URL.Query().Get("q")}
+		switch r.URL.Query().Get("category") {
+		case "images":
+			resp.Results = []searxng.APIResult{
+				{URL: "https://go.dev/blog/gopher", Title: "Gopher", ImgSrc: "https://go.dev/gopher.png", ThumbnailSrc: "https://thumbs.example.com/gopher.png", Resolution: "1024 x 768", ImgFormat: "png"},
+				{URL: "https://example.com/no-image", Title: "Not an image"},
+				{URL: "https://example.com/2", Title: "Second image", ImgSrc: "https://example.com/2.jpg"},
+			}
+		case "videos":
+			resp.Results = []searxng.APIResult{
+				{URL: "https://www.youtube.com/watch?v=1", Title: "Gopher talk", Thumbnail: "https://i.ytimg.com/1.jpg", Length: json.RawMessage(`"12:34"`), IframeSrc: "https://www.youtube-nocookie.com/embed/1", Author: "Go", Engine: "youtube"},
+				{URL: "https://vimeo.com/2", Title: "Second video", Length: json.RawMessage(`3723`)},
+			}
+		default:
+			http.Error(w, "unexpected category", http.StatusBadRequest)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+
+	search := func(args map[string]interface{}) []map[string]interface{} {
+		t.Helper()
+		result := callToolResult(t, srv, "searxng_media_search", args)
+		require.False(t, result.IsError, result.Content)
+		var output struct {
+			Results []map[string]interface{} `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output.Results
+	}
+
+	results := search(map[string]interface{}{"query": "gopher"})
+	require.Len(t, results, 4)
+	assert.Equal(t, []interface{}{"image", "video", "image", "video"},
+		[]interface{}{results[0]["type"], results[1]["type"], results[2]["type"], results[3]["type"]}, "interleaved")
+	assert.Equal(t, map[string]interface{}{
+		"type": "image", "title": "Gopher", "source_page": "https://go.dev/blog/gopher", "img_src": "https://go.dev/gopher.png",
+		"thumbnail": "https://thumbs.example.com/gopher.png", "resolution": "1024 x 768", "format": "png",
+	}, results[0])
+	assert.Equal(t, map[string]interface{}{
+		"type": "video", "title": "Gopher talk", "source_page": "https://www.youtube.com/watch?v=1", "thumbnail": "https://i.ytimg.com/1.jpg",
+		"duration": "12:34", "embed_url": "https://www.youtube-nocookie.com/embed/1", "author": "Go", "engine": "youtube",
+	}, results[1])
+	assert.Equal(t, "1:02:03", results[3]["duration"], "seconds are formatted")
+
+	results = search(map[string]interface{}{"query": "gopher", "type": "video", "limit": float64(1)})
+	require.Len(t, results, 1)
+	assert.Equal(t, "Gopher talk", results[0]["title"])
+
+	results = search(map[string]interface{}{"query": "gopher", "type": "image"})
+	require.Len(t, results, 2)
+	assert.Equal(t, "Second image", results[1]["title"])
+
+	result := callToolResult(t, srv, "searxng_media_search", map[string]interface{}{"query": "gopher", "type": "audio"})
+	assert.True(t, result.IsError)
+}
+
+func TestHandleMediaSearch_PartialFailure(t *testing.T) {
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("category") == "videos" {
+			http.Error(w, "no video engines", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: "q", Results: []searxng.APIResult{
+			{URL: "https://example.com", Title: "Image", ImgSrc: "https://example.com/1.jpg"},
+		}})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL, MaxRetries: 1})
+	require.NoError(t, err)
+
+	result := callToolResult(t, New(client), "searxng_media_search", map[string]interface{}{"query": "q"})
+	require.False(t, result.IsError)
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	assert.Len(t, output["results"], 1)
+	require.Len(t, output["errors"], 1)
+	assert.Contains(t, output["errors"].([]interface{})[0], "video search failed")
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// Histogram bucket upper bounds, in seconds and bytes respectively
+var (
+	durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	sizeBuckets     = []float64{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20}
+)
+
+// histogram is a Prometheus-style histogram with fixed buckets. It is safe
+// for concurrent use.
+type histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // per bucket, not cumulative; the last one is +Inf
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i, _ := slices.BinarySearch(h.bounds, v)
+	h.counts[i]++
+	h.sum += v
+}
+
+// write writes the bucket, sum and count samples of the histogram, adding
+// labels (already formatted, e.g. `tool="x"`) to each of them
+func (h *histogram) write(w io.Writer, name, labels string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cumulative uint64
+	for i, count := range h.counts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatFloat(h.bounds[i], 'g', -1, 64)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, le, cumulative)
+	}
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, cumulative)
+}
+
+// serverMetrics holds the histograms exported by MetricsHandler that aren't
+// part of ServerStats
+type serverMetrics struct {
+	searchDuration *histogram
+	readBytes      *histogram
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		searchDuration: newHistogram(durationBuckets),
+		readBytes:      newHistogram(sizeBuckets),
+	}
+}
+
+// search runs a search on client, recording its latency. The session's
+// defaults fill its unset language and safe search level, which is then
+// raised to Options.SafeSearch, and results outside the domains
+// of Options.IncludeDomains and ExcludeDomains are dropped.
+func (s *Server) search(ctx context.Context, client *searxng.Client, req searxng.SearchRequest) (*searxng.SearchResponse, error) {
+	applySessionDefaults(ctx, &req)
+	if req.SafeSearch < s.options.SafeSearch {
+		req.SafeSearch = s.options.SafeSearch
+	}
+	start := time.Now()
+	resp, err := client.Search(ctx, req)
+	s.metrics.searchDuration.observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	resp, _ = s.domains.apply(resp)
+	return resp, nil
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func toolLabel(tool string) string {
+	return `tool="` + labelEscaper.Replace(tool) + `"`
+}
+
+func engineLabel(engine string) string {
+	return `engine="` + labelEscaper.Replace(engine) + `"`
+}
+
+// MetricsHandler returns an HTTP handler exposing the server and client
+// counters, tool and search latency histograms and searxng_read content
+// sizes in the Prometheus text format
+func (s *Server) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		s.writeMetrics(bw)
+		if err := bw.Flush(); err != nil {
+			log.WithField("error", err).Debug("failed to write metrics")
+		}
+	})
+}
+
+func (s *Server) writeMetrics(w io.Writer) {
+	stats := s.Stats()
+	tools := slices.Sorted(maps.Keys(stats.ToolCalls))
+
+	header := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	sample := func(name string, v interface{}) {
+		fmt.Fprintf(w, "%s %v\n", name, v)
+	}
+
+	header("searxng_mcp_tool_calls_total", "counter", "Tool invocations.")
+	for _, tool := range tools {
+		sample("searxng_mcp_tool_calls_total{"+toolLabel(tool)+"}", stats.ToolCalls[tool])
+	}
+	header("searxng_mcp_tool_errors_total", "counter", "Tool invocations that returned an error.")
+	for _, tool := range tools {
+		sample("searxng_mcp_tool_errors_total{"+toolLabel(tool)+"}", stats.ToolErrors[tool])
+	}
+	header("searxng_mcp_tool_duration_seconds", "histogram", "Tool invocation latency.")
+	for _, tool := range tools {
+		if h := s.toolStats.duration(tool); h != nil {
+			h.write(w, "searxng_mcp_tool_duration_seconds", toolLabel(tool))
+		}
+	}
+
+	header("searxng_mcp_search_duration_seconds", "histogram", "Searxng search latency, including cache hits.")
+	s.metrics.searchDuration.write(w, "searxng_mcp_search_duration_seconds", "")
+
+	client := stats.Client
+	header("searxng_mcp_searxng_searches_total", "counter", "Searches sent to the default Searxng client.")
+	sample("searxng_mcp_searxng_searches_total", client.Searches)
+	header("searxng_mcp_searxng_requests_total", "counter", "HTTP requests sent to the Searxng instance, including retries.")
+	sample("searxng_mcp_searxng_requests_total", client.Requests)
+	header("searxng_mcp_searxng_errors_total", "counter", "Searches that failed after all retries.")
+	sample("searxng_mcp_searxng_errors_total", client.Failures)
+	header("searxng_mcp_searxng_retries_total", "counter", "Retried search requests.")
+	sample("searxng_mcp_searxng_retries_total", client.Retries)
+	header("searxng_mcp_rate_limit_waits_total", "counter", "Searches that waited for a rate limiter token.")
+	sample("searxng_mcp_rate_limit_waits_total", client.RateLimitWaits)
+	header("searxng_mcp_rate_limit_wait_seconds_total", "counter", "Time spent waiting for rate limiter tokens.")
+	sample("searxng_mcp_rate_limit_wait_seconds_total", client.RateLimitWaitTime.Seconds())
+	header("searxng_mcp_rate_limit_tokens", "gauge", "Rate limiter tokens currently available.")
+	sample("searxng_mcp_rate_limit_tokens", client.RateLimitTokens)
+	header("searxng_mcp_cache_hits_total", "counter", "Searches served from the response cache.")
+	sample("searxng_mcp_cache_hits_total", client.CacheHits)
+	header("searxng_mcp_cache_misses_total", "counter", "Cacheable searches not found in the response cache.")
+	sample("searxng_mcp_cache_misses_total", client.CacheMisses)
+	header("searxng_mcp_cache_entries", "gauge", "Responses currently cached.")
+	sample("searxng_mcp_cache_entries", client.CacheEntries)
+
+	engines := slices.Sorted(maps.Keys(client.EngineResults))
+	header("searxng_mcp_engine_results_total", "counter", "Results returned by each engine, not counting cache hits.")
+	for _, engine := range engines {
+		sample("searxng_mcp_engine_results_total{"+engineLabel(engine)+"}", client.EngineResults[engine])
+	}
+	header("searxng_mcp_engine_unique_results_total", "counter", "Results returned by no engine but this one.")
+	for _, engine := range engines {
+		sample("searxng_mcp_engine_unique_results_total{"+engineLabel(engine)+"}", client.EngineUniqueResults[engine])
+	}
+
+	if s.readLimiter != nil {
+		waits, waitTime, tokens := s.readLimiter.Stats()
+		header("searxng_mcp_read_rate_limit_waits_total", "counter", "Page reads that waited for a read rate limiter token.")
+		sample("searxng_mcp_read_rate_limit_waits_total", waits)
+		header("searxng_mcp_read_rate_limit_wait_seconds_total", "counter", "Time spent waiting for read rate limiter tokens.")
+		sample("searxng_mcp_read_rate_limit_wait_seconds_total", waitTime.Seconds())
+		header("searxng_mcp_read_rate_limit_tokens", "gauge", "Read rate limiter tokens currently available.")
+		sample("searxng_mcp_read_rate_limit_tokens", tokens)
+	}
+
+	header("searxng_mcp_read_content_bytes", "histogram", "Size of the content fetched by searxng_read, after conversion.")
+	s.metrics.readBytes.write(w, "searxng_mcp_read_content_bytes", "")
+}
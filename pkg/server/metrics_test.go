@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{1, 5})
+	h.observe(0.5)
+	h.observe(1)
+	h.observe(3)
+	h.observe(10)
+
+	var buf bytes.Buffer
+	h.write(&buf, "test_seconds", `tool="x"`)
+	assert.Equal(t, `test_seconds_bucket{tool="x",le="1"} 2
+test_seconds_bucket{tool="x",le="5"} 3
+test_seconds_bucket{tool="x",le="+Inf"} 4
+test_seconds_sum{tool="x"} 14.5
+test_seconds_count{tool="x"} 4
+`, buf.String())
+
+	buf.Reset()
+	newHistogram([]float64{1}).write(&buf, "empty", "")
+	assert.Equal(t, "empty_bucket{le=\"1\"} 0\nempty_bucket{le=\"+Inf\"} 0\nempty_sum 0\nempty_count 0\n", buf.String())
+}
+
+func TestMetricsHandler(t *testing.T) {
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"q","results":[{"url":"https://a.example","engines":["google","bing"]},{"url":"https://b.example","engines":["bing"]}]}`))
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+	callTool(t, srv, "searxng_search", map[string]interface{}{"query": "q"})
+	callTool(t, srv, "searxng_search", map[string]interface{}{})
+
+	rec := httptest.NewRecorder()
+	srv.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	body := rec.Body.String()
+	assert.Contains(t, body, "# TYPE searxng_mcp_tool_calls_total counter\n")
+	assert.Contains(t, body, `searxng_mcp_tool_calls_total{tool="searxng_search"} 2`)
+	assert.Contains(t, body, `searxng_mcp_tool_errors_total{tool="searxng_search"} 1`)
+	assert.Contains(t, body, `searxng_mcp_tool_duration_seconds_count{tool="searxng_search"} 2`)
+	assert.Contains(t, body, "searxng_mcp_search_duration_seconds_count 1\n")
+	assert.Contains(t, body, "searxng_mcp_searxng_searches_total 1\n")
+	assert.Contains(t, body, "searxng_mcp_searxng_errors_total 0\n")
+	assert.Contains(t, body, "searxng_mcp_read_content_bytes_count 0\n")
+	assert.Contains(t, body, `searxng_mcp_engine_results_total{engine="bing"} 2`)
+	assert.Contains(t, body, `searxng_mcp_engine_results_total{engine="google"} 1`)
+	assert.Contains(t, body, `searxng_mcp_engine_unique_results_total{engine="bing"} 1`)
+	assert.Contains(t, body, `searxng_mcp_engine_unique_results_total{engine="google"} 0`)
+}
+
+func TestToolLabel(t *testing.T) {
+	assert.Equal(t, `tool="a\"b\\c\nd"`, toolLabel("a\"b\\c\nd"))
+}
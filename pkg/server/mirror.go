@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+)
+
+// Placeholders of read mirror templates: the page URL as is, e.g.
+// https://r.jina.ai/{url}, or query-escaped, e.g.
+// https://reader.example.com/?url={url_escaped}
+const (
+	mirrorPlaceholder        = "{url}"
+	mirrorEscapedPlaceholder = "{url_escaped}"
+)
+
+// ValidateReadMirrors checks that the read mirror templates hold a page URL
+// placeholder and expand to absolute http(s) URLs
+func ValidateReadMirrors(templates []string) error {
+	for _, template := range templates {
+		if !strings.Contains(template, mirrorPlaceholder) && !strings.Contains(template, mirrorEscapedPlaceholder) {
+			return fmt.Errorf("invalid read mirror %q (must contain %s or %s)", template, mirrorPlaceholder, mirrorEscapedPlaceholder)
+		}
+		// Expanded with a placeholder URL that can't supply a scheme or host
+		u, err := url.Parse(mirrorURL(template, "page"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid read mirror %q (must be an absolute http(s) URL)", template)
+		}
+	}
+	return nil
+}
+
+// mirrorURL expands a read mirror template for the page at urlStr
+func mirrorURL(template, urlStr string) string {
+	return strings.NewReplacer(
+		mirrorPlaceholder, urlStr,
+		mirrorEscapedPlaceholder, url.QueryEscape(urlStr),
+	).Replace(template)
+}
+
+// mirrorable reports whether a generic page that failed to fetch with err
+// may be read through a mirror: on network errors, 403s and server errors.
+// Pages refused by the read policy (errBlocked) never are, so mirrors can't
+// route around the domain lists or robots.txt.
+func mirrorable(err error) bool {
+	if errors.Is(err, errBlocked) {
+		return false
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusForbidden || statusErr.code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// fetchFromMirrors reads a generic page that failed to fetch with err
+// through each of opts.Mirrors in turn, returning the first page read. The
+// page records the mirror in readResult.Mirror. When every mirror fails,
+// err is returned. The page URL goes through the check of ctx (see
+// withRedirectCheck) again first, as the mirrors fetch it out of reach of
+// the checks of the fetch.
+func fetchFromMirrors(ctx context.Context, client *http.Client, urlStr string, opts readOptions, err error) (*readResult, error) {
+	if check := redirectCheck(ctx); check != nil {
+		if checkErr := check(ctx, urlStr); checkErr != nil {
+			return nil, fmt.Errorf("mirror %w: %w", errBlocked, checkErr)
+		}
+	}
+	for _, template := range opts.Mirrors {
+		if ctx.Err() != nil {
+			break
+		}
+		mirror := mirrorURL(template, urlStr)
+		log.WithFields(logrus.Fields{"url": urlStr, "mirror": mirror, "error": err}).Debug("fetch failed, trying a mirror")
+		page, mirrorErr := fetchGenericHTML(ctx, client, mirror, opts)
+		if mirrorErr != nil {
+			log.WithFields(logrus.Fields{"mirror": mirror, "error": mirrorErr}).Debug("mirror failed")
+			continue
+		}
+		page.Mirror = mirror
+		return page, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateReadMirrors(t *testing.T) {
+	assert.NoError(t, ValidateReadMirrors(nil))
+	assert.NoError(t, ValidateReadMirrors([]string{"https://r.jina.ai/{url}", "https://reader.example.com/?url={url_escaped}"}))
+	assert.ErrorContains(t, ValidateReadMirrors([]string{"https://r.jina.ai/"}), "must contain {url}")
+	assert.ErrorContains(t, ValidateReadMirrors([]string{"{url}"}), "absolute http(s) URL")
+	assert.ErrorContains(t, ValidateReadMirrors([]string{"ftp://mirror/{url}"}), "absolute http(s) URL")
+}
+
+func TestMirrorURL(t *testing.T) {
+	assert.Equal(t, "https://r.jina.ai/https://example.com/a?b=c", mirrorURL("https://r.jina.ai/{url}", "https://example.com/a?b=c"))
+	assert.Equal(t, "https://reader.example.com/?url=https%3A%2F%2Fexample.com%2Fa%3Fb%3Dc",
+		mirrorURL("https://reader.example.com/?url={url_escaped}", "https://example.com/a?b=c"))
+}
+
+func TestHandleWebRead_Mirrors(t *testing.T) {
+	gock.Off()
+
+	var mirrored []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/page":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case strings.HasPrefix(r.URL.Path, "/broken/"):
+			mirrored = append(mirrored, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasPrefix(r.URL.Path, "/reader/"):
+			mirrored = append(mirrored, r.URL.Path)
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("Mirrored content"))
+		}
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	page := ts.URL + "/page"
+
+	result := callToolResult(t, New(client), "searxng_read", map[string]interface{}{"url": page})
+	require.True(t, result.IsError, "no mirrors by default")
+	assert.Empty(t, mirrored)
+
+	srv := NewWithOptions(client, Options{ReadMirrors: []string{ts.URL + "/broken/{url_escaped}", ts.URL + "/reader/{url}"}})
+	result = callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": page})
+	require.False(t, result.IsError)
+	assert.Equal(t, "Mirrored content", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, ts.URL+"/reader/"+page, result.StructuredContent.(readMetadata).Mirror)
+	assert.Len(t, mirrored, 2, "the mirrors are tried in order")
+
+	srv = NewWithOptions(client, Options{ReadMirrors: []string{ts.URL + "/broken/{url}"}})
+	result = callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": page})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "HTTP 503", "the page's own error is reported")
+}
+
+func TestHandleWebRead_MirrorsKeepReadPolicy(t *testing.T) {
+	var mirrored atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.Path {
+		case "allowed.example/to-excluded":
+			http.Redirect(w, r, "http://excluded.example/page", http.StatusFound)
+		case "allowed.example/missing":
+			http.NotFound(w, r)
+		default:
+			if strings.HasPrefix(r.URL.Path, "/reader/") {
+				mirrored.Add(1)
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("Mirrored content"))
+		}
+	}))
+	defer site.Close()
+	// Every host is served by site
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, site.Listener.Addr().String())
+	}}
+
+	srv := NewWithOptions(nil, Options{
+		ExcludeDomains: []string{"excluded.example"},
+		ReadMirrors:    []string{"http://mirror.example/reader/{url}"},
+		Transport:      transport,
+	})
+	result := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": "http://allowed.example/to-excluded"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "redirect blocked")
+
+	result = callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": "http://allowed.example/missing"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "HTTP 404")
+	assert.Zero(t, mirrored.Load(), "blocked and missing pages aren't mirrored")
+}
+
+func TestFetchFromMirrors_ChecksPageURL(t *testing.T) {
+	ctx := withRedirectCheck(context.Background(), func(_ context.Context, rawURL string) error {
+		return fmt.Errorf("%s is not on a domain this server may read", rawURL)
+	})
+	_, err := fetchFromMirrors(ctx, http.DefaultClient, "http://excluded.example/page",
+		readOptions{Mirrors: []string{"http://mirror.example/{url}"}}, errors.New("HTTP 503"))
+	assert.ErrorIs(t, err, errBlocked)
+	assert.ErrorContains(t, err, "not on a domain this server may read")
+}
+
+func TestMirrorable(t *testing.T) {
+	assert.True(t, mirrorable(&statusError{code: http.StatusForbidden}))
+	assert.True(t, mirrorable(&statusError{code: http.StatusBadGateway}))
+	assert.False(t, mirrorable(&statusError{code: http.StatusNotFound}))
+	assert.True(t, mirrorable(fmt.Errorf("HTTP request failed: %w", &url.Error{Op: "Get", Err: errors.New("connection refused")})))
+	assert.False(t, mirrorable(fmt.Errorf("HTTP request failed: %w", &url.Error{Op: "Get", Err: fmt.Errorf("redirect %w", errBlocked)})))
+	assert.False(t, mirrorable(errors.New("unsupported content type")))
+}
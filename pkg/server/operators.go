@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// queryOperators are the structured query operators of searxng_search,
+// compiled into the query by apply so callers never write operator syntax
+type queryOperators struct {
+	ExactPhrase string
+	MustInclude []string
+	MustExclude []string
+}
+
+// parseQueryOperators reads the query operator arguments. A single string
+// is accepted for must_include and must_exclude.
+func parseQueryOperators(args map[string]interface{}) (queryOperators, error) {
+	var ops queryOperators
+	if phrase, ok := args["exact_phrase"]; ok {
+		s, ok := phrase.(string)
+		if !ok {
+			return ops, fmt.Errorf("exact_phrase must be a string")
+		}
+		ops.ExactPhrase = operatorTerm(s)
+	}
+	var err error
+	if ops.MustInclude, err = parseOperatorTerms(args, "must_include"); err != nil {
+		return ops, err
+	}
+	if ops.MustExclude, err = parseOperatorTerms(args, "must_exclude"); err != nil {
+		return ops, err
+	}
+	return ops, nil
+}
+
+// parseOperatorTerms reads the terms of a list argument, dropping empty ones
+func parseOperatorTerms(args map[string]interface{}, name string) ([]string, error) {
+	var raw []interface{}
+	switch v := args[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		raw = []interface{}{v}
+	case []interface{}:
+		raw = v
+	default:
+		return nil, fmt.Errorf("%s must be a list of words or phrases", name)
+	}
+
+	var terms []string
+	for _, item := range raw {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of words or phrases", name)
+		}
+		if term := operatorTerm(s); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms, nil
+}
+
+// operatorTerm strips the operator syntax callers may have added anyway:
+// quotes, a leading + or - and surrounding whitespace
+func operatorTerm(s string) string {
+	s = strings.ReplaceAll(s, `"`, " ")
+	s = strings.TrimLeft(strings.TrimSpace(s), "+-")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// apply appends the operators to query: the exact phrase and included
+// terms quoted, the excluded terms prefixed with -. Quoting also keeps
+// Searxng from reading terms as !bang or :language prefixes.
+func (o queryOperators) apply(query string) string {
+	parts := []string{strings.TrimSpace(query)}
+	if o.ExactPhrase != "" {
+		parts = append(parts, `"`+o.ExactPhrase+`"`)
+	}
+	for _, term := range o.MustInclude {
+		parts = append(parts, `"`+term+`"`)
+	}
+	for _, term := range o.MustExclude {
+		if strings.Contains(term, " ") || strings.ContainsAny(term[:1], "!:") {
+			term = `"` + term + `"`
+		}
+		parts = append(parts, "-"+term)
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOperators(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{"none", map[string]interface{}{}, "golang"},
+		{"exact phrase", map[string]interface{}{"exact_phrase": "context deadline exceeded"}, `golang "context deadline exceeded"`},
+		{"quotes stripped", map[string]interface{}{"exact_phrase": `"context  deadline"`}, `golang "context deadline"`},
+		{"include", map[string]interface{}{"must_include": []interface{}{"grpc", "+ retry policy"}}, `golang "grpc" "retry policy"`},
+		{"include string", map[string]interface{}{"must_include": "grpc"}, `golang "grpc"`},
+		{"exclude", map[string]interface{}{"must_exclude": []interface{}{"-pinterest", "sponsored content", "!bing", " "}}, `golang -pinterest -"sponsored content" -"!bing"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := parseQueryOperators(tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ops.apply("golang"))
+		})
+	}
+
+	_, err := parseQueryOperators(map[string]interface{}{"must_exclude": []interface{}{"ok", 3.0}})
+	assert.EqualError(t, err, "must_exclude must be a list of words or phrases")
+	_, err = parseQueryOperators(map[string]interface{}{"exact_phrase": true})
+	assert.EqualError(t, err, "exact_phrase must be a string")
+}
+
+func TestHandleWebSearch_QueryOperators(t *testing.T) {
+	defer gock.OffAll()
+
+	query := `http/2 "server push" "nginx" -apache filetype:pdf`
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "^"+regexp.QuoteMeta(query)+"$").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: query, Results: []searxng.APIResult{
+			{URL: "https://example.com/push.pdf", Title: "Server push"},
+		}})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_search", map[string]interface{}{
+		"query":        "http/2",
+		"exact_phrase": "server push",
+		"must_include": []interface{}{"nginx"},
+		"must_exclude": []interface{}{"apache"},
+		"filetype":     "pdf",
+	})
+	require.False(t, result.IsError)
+	assert.True(t, gock.IsDone())
+
+	result = callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "http/2", "must_include": 1.0})
+	assert.True(t, result.IsError)
+}
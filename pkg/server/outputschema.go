@@ -0,0 +1,117 @@
+package server
+
+import "github.com/mark3labs/mcp-go/mcp"
+
+// schemaArray returns the JSON schema of an array of items
+func schemaArray(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+var (
+	schemaString  = map[string]interface{}{"type": "string"}
+	schemaNumber  = map[string]interface{}{"type": "number"}
+	schemaInteger = map[string]interface{}{"type": "integer"}
+	schemaBoolean = map[string]interface{}{"type": "boolean"}
+)
+
+// confidenceSchema describes the entries of answer_confidence and
+// infobox_confidence (see assessAnswers and assessInfoboxes)
+var confidenceSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"answer":       schemaString,
+		"label":        schemaString,
+		"source_url":   schemaString,
+		"engines":      schemaArray(schemaString),
+		"corroborated": schemaBoolean,
+		"confidence":   map[string]interface{}{"type": "string", "enum": []string{"high", "medium", "low"}},
+	},
+	"required": []string{"engines", "corroborated", "confidence"},
+}
+
+// infoboxSchema describes the entries of infoboxes (see formatInfoboxes)
+var infoboxSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"label":       schemaString,
+		"content":     schemaString,
+		"engine":      schemaString,
+		"attribution": schemaString,
+		"images": schemaArray(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"url":       schemaString,
+				"alt":       schemaString,
+				"thumbnail": schemaString,
+			},
+			"required": []string{"url"},
+		}),
+		"urls": schemaArray(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"title": schemaString,
+				"url":   schemaString,
+			},
+			"required": []string{"title", "url"},
+		}),
+	},
+	"required": []string{"label"},
+}
+
+// searchOutputSchema describes the structured content of searxng_search and
+// searxng_refine_search, built by formatSearchResults. Fields that only
+// appear for some arguments (e.g. filetype_filtered) are optional.
+func searchOutputSchema() mcp.ToolOutputSchema {
+	return mcp.ToolOutputSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"query":         schemaString,
+			"total_results": schemaNumber,
+			"results": schemaArray(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title":           schemaString,
+					"url":             schemaString,
+					"snippet":         schemaString,
+					"published_date":  map[string]interface{}{"type": "string", "format": "date"},
+					"link_unverified": schemaString,
+					"snippet_source":  schemaString,
+					"domain_label":    schemaString,
+				},
+				"required": []string{"title", "url", "snippet"},
+			}),
+			"suggestions":        schemaArray(schemaString),
+			"answers":            schemaArray(schemaString),
+			"answer_confidence":  schemaArray(confidenceSchema),
+			"infobox_confidence": schemaArray(confidenceSchema),
+			"infoboxes":          schemaArray(infoboxSchema),
+			"corrections":        schemaArray(schemaString),
+			"unresponsive_engines": schemaArray(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":  schemaString,
+					"error": schemaString,
+				},
+			}),
+			"filetype_filtered":     schemaInteger,
+			"domain_filtered":       schemaInteger,
+			"enriched":              schemaInteger,
+			"dead_links_removed":    schemaInteger,
+			"seen_results_filtered": schemaInteger,
+			"detected_language":     schemaString,
+			"partial":               schemaBoolean,
+			"decode_warnings":       schemaArray(schemaString),
+			"refinement": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"original_query": schemaString,
+					"refined_query":  schemaString,
+					"applied":        schemaArray(schemaString),
+					"time_range":     schemaString,
+					"category":       schemaString,
+				},
+			},
+		},
+		Required: []string{"query", "total_results", "results"},
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertMatchesSchema checks that the keys of output are declared by the
+// object schema and that its required keys are present, recursing into
+// arrays of objects
+func assertMatchesSchema(t *testing.T, schema map[string]interface{}, output map[string]interface{}, path string) {
+	t.Helper()
+	properties, _ := schema["properties"].(map[string]interface{})
+	for key, value := range output {
+		property, ok := properties[key].(map[string]interface{})
+		if !assert.True(t, ok, "%s%s is not in the output schema", path, key) {
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			assertMatchesSchema(t, property, v, path+key+".")
+		case []interface{}:
+			items, _ := property["items"].(map[string]interface{})
+			for _, item := range v {
+				if obj, ok := item.(map[string]interface{}); ok {
+					assertMatchesSchema(t, items, obj, path+key+"[].")
+				}
+			}
+		}
+	}
+	required, _ := schema["required"].([]interface{})
+	for _, key := range required {
+		assert.Contains(t, output, key, "%s%s is required", path, key)
+	}
+}
+
+func TestSearchOutputSchema(t *testing.T) {
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"query":                r.URL.Query().Get("q"),
+			"number_of_results":    10,
+			"results":              []map[string]interface{}{{"url": "https://example.com/a.pdf", "title": "A", "content": "a", "publishedDate": "2024-01-02T00:00:00"}},
+			"answers":              []map[string]interface{}{{"answer": "42", "engine": "wikipedia"}},
+			"infoboxes":            []map[string]interface{}{{"infobox": "Answer", "engine": "wikipedia"}},
+			"suggestions":          []string{"s"},
+			"corrections":          []string{"c"},
+			"unresponsive_engines": [][]string{{"bing", "timeout"}},
+		})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+
+	schemaJSON, err := json.Marshal(searchOutputSchema())
+	require.NoError(t, err)
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(schemaJSON, &schema))
+
+	for name, call := range map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		"searxng_search":        srv.handleWebSearch,
+		"searxng_refine_search": srv.handleRefineSearch,
+	} {
+		result, err := call(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: name, Arguments: map[string]interface{}{
+				"query": "q", "feedback": "too broad", "filetype": "pdf", "novel_only": true,
+			}},
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError, name)
+		require.NotNil(t, result.StructuredContent, name)
+
+		// The structured content carries the same data as the text
+		structuredJSON, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		assert.JSONEq(t, result.Content[0].(mcp.TextContent).Text, string(structuredJSON), name)
+
+		var output map[string]interface{}
+		require.NoError(t, json.Unmarshal(structuredJSON, &output))
+		assertMatchesSchema(t, schema, output, name+": ")
+	}
+}
+
+func TestSearchOutputSchema_Listed(t *testing.T) {
+	srv := New(nil)
+	for _, name := range []string{"searxng_search", "searxng_refine_search"} {
+		tool := srv.MCPServer().GetTool(name)
+		require.NotNil(t, tool, name)
+		assert.Equal(t, "object", tool.Tool.OutputSchema.Type, name)
+		assert.Contains(t, tool.Tool.OutputSchema.Properties, "results", name)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// builtinTools lists the names of the tools registered by the server,
+// searxng_set_log_level only with Options.AdminTokens
+var builtinTools = []string{"searxng_search", "searxng_read", "searxng_refine_search", "searxng_image_search", "searxng_media_search", "searxng_search_and_read", "searxng_suggestions", "searxng_compare_pages", "searxng_extract_links", "searxng_set_log_level"}
+
+// toolNamePattern matches valid MCP tool names
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ToolOverride replaces parts of a built-in tool definition, so operators
+// can adjust how tools steer agents (e.g. "only use for the public web")
+// without a code change. Empty fields keep the built-in or localized text.
+type ToolOverride struct {
+	// Name is the name the tool is registered under
+	Name string
+	// Description replaces the tool description
+	Description string
+	// Parameters replaces parameter descriptions, keyed by parameter name
+	Parameters map[string]string
+}
+
+// ValidateToolOverrides checks that overrides, keyed by built-in tool name,
+// only refer to built-in tools and don't produce invalid or clashing names
+func ValidateToolOverrides(overrides map[string]ToolOverride) error {
+	names := make(map[string]string, len(builtinTools))
+	for _, builtin := range builtinTools {
+		names[builtin] = builtin
+	}
+	for builtin, override := range overrides {
+		if !slices.Contains(builtinTools, builtin) {
+			return fmt.Errorf("unknown tool in overrides: %s (must be one of %s)", builtin, strings.Join(builtinTools, ", "))
+		}
+		if override.Name == "" {
+			continue
+		}
+		if !toolNamePattern.MatchString(override.Name) {
+			return fmt.Errorf("invalid name for tool %s: %q (use up to 64 letters, digits, '_', '-' or '.')", builtin, override.Name)
+		}
+		names[builtin] = override.Name
+	}
+
+	seen := make(map[string]string, len(names))
+	for _, builtin := range builtinTools {
+		if other, ok := seen[names[builtin]]; ok {
+			return fmt.Errorf("tools %s and %s would both be named %s", other, builtin, names[builtin])
+		}
+		seen[names[builtin]] = builtin
+	}
+	return nil
+}
+
+// ValidateDisabledTools checks that the names of Options.DisabledTools are
+// built-in tools
+func ValidateDisabledTools(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(builtinTools, name) {
+			return fmt.Errorf("unknown tool to disable: %s (must be one of %s)", name, strings.Join(builtinTools, ", "))
+		}
+	}
+	return nil
+}
+
+// toolDisabled reports whether the built-in tool is in Options.DisabledTools
+func (s *Server) toolDisabled(builtin string) bool {
+	return slices.Contains(s.options.DisabledTools, builtin)
+}
+
+// overrideTool applies the override configured for the tool, if any
+func (s *Server) overrideTool(tool *mcp.Tool) {
+	override, ok := s.options.ToolOverrides[tool.Name]
+	if !ok {
+		return
+	}
+	localizeTool(tool, map[string]toolTranslation{
+		tool.Name: {Description: override.Description, Parameters: override.Parameters},
+	})
+	if override.Name != "" {
+		tool.Name = override.Name
+	}
+}
+
+// toolName returns the name the built-in tool is registered under
+func (s *Server) toolName(builtin string) string {
+	if name := s.options.ToolOverrides[builtin].Name; name != "" {
+		return name
+	}
+	return builtin
+}
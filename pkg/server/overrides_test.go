@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinTools(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	srv := NewWithOptions(client, Options{AdminTokens: []string{"admin"}})
+	registered := slices.Sorted(maps.Keys(srv.MCPServer().ListTools()))
+	assert.Equal(t, slices.Sorted(slices.Values(builtinTools)), registered)
+}
+
+func TestValidateToolOverrides(t *testing.T) {
+	assert.NoError(t, ValidateToolOverrides(nil))
+	assert.NoError(t, ValidateToolOverrides(map[string]ToolOverride{
+		"searxng_search": {Name: "web_search", Description: "Search the public web only"},
+		"searxng_read":   {Description: "Read a page"},
+	}))
+	// Swapping names is fine as long as the result is unique
+	assert.NoError(t, ValidateToolOverrides(map[string]ToolOverride{
+		"searxng_search": {Name: "searxng_read"},
+		"searxng_read":   {Name: "searxng_search"},
+	}))
+
+	err := ValidateToolOverrides(map[string]ToolOverride{"web_fetch": {Name: "fetch"}})
+	assert.ErrorContains(t, err, "unknown tool in overrides: web_fetch")
+
+	err = ValidateToolOverrides(map[string]ToolOverride{"searxng_search": {Name: "web search"}})
+	assert.ErrorContains(t, err, "invalid name for tool searxng_search")
+
+	err = ValidateToolOverrides(map[string]ToolOverride{"searxng_read": {Name: "searxng_search"}})
+	assert.ErrorContains(t, err, "would both be named searxng_search")
+}
+
+func TestToolOverrides(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	srv := NewWithOptions(client, Options{
+		ToolLocale: "de",
+		ToolOverrides: map[string]ToolOverride{
+			"searxng_search": {
+				Name:        "web_search",
+				Description: "Search the public web. Never use for internal hosts.",
+				Parameters:  map[string]string{"query": "Public web query"},
+			},
+		},
+	})
+
+	assert.Nil(t, srv.MCPServer().GetTool("searxng_search"))
+	tool := srv.MCPServer().GetTool("web_search")
+	require.NotNil(t, tool)
+	assert.Equal(t, "Search the public web. Never use for internal hosts.", tool.Tool.Description)
+	assert.Equal(t, "Public web query", tool.Tool.InputSchema.Properties["query"].(map[string]interface{})["description"])
+
+	// Parameters without an override keep the localized description
+	german := NewWithOptions(client, Options{ToolLocale: "de"}).MCPServer().GetTool("searxng_search").Tool
+	assert.Equal(t,
+		german.InputSchema.Properties["limit"].(map[string]interface{})["description"],
+		tool.Tool.InputSchema.Properties["limit"].(map[string]interface{})["description"])
+}
+
+func TestToolOverrides_ReadFooterUsesOverriddenName(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{ToolOverrides: map[string]ToolOverride{"searxng_read": {Name: "fetch_page"}}})
+
+	result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "fetch_page", Arguments: map[string]interface{}{"url": ts.URL, "max_length": float64(4)}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Call fetch_page with offset=4")
+}
+
+func TestValidateDisabledTools(t *testing.T) {
+	assert.NoError(t, ValidateDisabledTools(nil))
+	assert.NoError(t, ValidateDisabledTools([]string{"searxng_read", "searxng_extract_links"}))
+	assert.ErrorContains(t, ValidateDisabledTools([]string{"web_read"}), "unknown tool to disable: web_read")
+}
+
+func TestDisabledTools(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	srv := NewWithOptions(client, Options{
+		DisabledTools: []string{"searxng_read", "searxng_search_and_read"},
+		ToolOverrides: map[string]ToolOverride{"searxng_read": {Name: "fetch"}},
+	})
+	tools := srv.MCPServer().ListTools()
+	assert.Contains(t, tools, "searxng_search")
+	assert.NotContains(t, tools, "searxng_read")
+	assert.NotContains(t, tools, "fetch", "renamed tools are disabled by their built-in name")
+	assert.NotContains(t, tools, "searxng_search_and_read")
+
+	result := callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "go", "enrich": true})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "searxng_read is disabled")
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// researchDepth is how much searching and reading a prompt asks for
+type researchDepth struct {
+	searches string
+	reads    string
+}
+
+// researchDepths are the values of the depth argument of the prompts
+var researchDepths = map[string]researchDepth{
+	"quick":    {searches: "a single search", reads: "the 2 most relevant results"},
+	"standard": {searches: "about 3 searches", reads: "the 5 most relevant pages"},
+	"deep":     {searches: "6 or more searches", reads: "10 or more pages"},
+}
+
+// defaultResearchDepth is the depth of prompts that don't set one
+const defaultResearchDepth = "standard"
+
+// depthArgument is the depth argument shared by the prompts
+var depthArgument = mcp.WithArgument("depth",
+	mcp.ArgumentDescription("How thoroughly to research: 'quick', 'standard' or 'deep' (default: 'standard')"))
+
+// registerPrompts registers the research prompt templates, which guide the
+// model through the tools of this server
+func (s *Server) registerPrompts() {
+	s.mcpServer.AddPrompt(mcp.NewPrompt("research_topic",
+		mcp.WithPromptDescription("Research a topic on the web and write a summary citing its sources"),
+		mcp.WithArgument("topic", mcp.ArgumentDescription("The topic or question to research"), mcp.RequiredArgument()),
+		depthArgument,
+	), handleResearchTopicPrompt)
+
+	s.mcpServer.AddPrompt(mcp.NewPrompt("fact_check",
+		mcp.WithPromptDescription("Check a claim against web sources and give a verdict with the evidence"),
+		mcp.WithArgument("claim", mcp.ArgumentDescription("The claim to check"), mcp.RequiredArgument()),
+		depthArgument,
+	), handleFactCheckPrompt)
+
+	s.mcpServer.AddPrompt(mcp.NewPrompt("compare_sources",
+		mcp.WithPromptDescription("Compare how different sources cover a topic: what they claim, where they agree and how reliable they are"),
+		mcp.WithArgument("topic", mcp.ArgumentDescription("The topic to compare coverage of"), mcp.RequiredArgument()),
+		mcp.WithArgument("sources", mcp.ArgumentDescription("Comma-separated sites to compare, e.g. 'bbc.com, reuters.com' (default: any independent sources)")),
+		depthArgument,
+	), handleCompareSourcesPrompt)
+}
+
+// promptArguments returns the required argument name and the depth of a
+// prompt request
+func promptArguments(request mcp.GetPromptRequest, name string) (string, researchDepth, error) {
+	value := strings.TrimSpace(request.Params.Arguments[name])
+	if value == "" {
+		return "", researchDepth{}, fmt.Errorf("%s is required", name)
+	}
+	depthName := strings.ToLower(strings.TrimSpace(request.Params.Arguments["depth"]))
+	if depthName == "" {
+		depthName = defaultResearchDepth
+	}
+	depth, ok := researchDepths[depthName]
+	if !ok {
+		return "", researchDepth{}, fmt.Errorf("invalid depth: %s (must be 'quick', 'standard' or 'deep')", depthName)
+	}
+	return value, depth, nil
+}
+
+// promptResult wraps the text of a prompt into a single user message
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	})
+}
+
+func handleResearchTopicPrompt(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	topic, depth, err := promptArguments(request, "topic")
+	if err != nil {
+		return nil, err
+	}
+	return promptResult("Research: "+topic, fmt.Sprintf(`Research the following topic on the web, then write a well-sourced summary.
+
+Topic: %s
+
+1. Run %s with searxng_search, starting broad and narrowing down to cover the different aspects of the topic; searxng_refine_search rewrites a search from what was missing. Set time_range when recent information matters.
+2. Read %s with searxng_read, or search and read in one call with searxng_search_and_read. Prefer primary sources, official documentation and reputable publications over aggregators and forums.
+3. Summarize the findings: the key facts, where sources agree or disagree, and the open questions. Cite the URL supporting each claim and say clearly when something could not be verified.`,
+		topic, depth.searches, depth.reads)), nil
+}
+
+func handleFactCheckPrompt(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	claim, depth, err := promptArguments(request, "claim")
+	if err != nil {
+		return nil, err
+	}
+	return promptResult("Fact check: "+claim, fmt.Sprintf(`Fact-check the following claim using web sources.
+
+Claim: %s
+
+1. Search for evidence both for and against the claim with searxng_search, e.g. the claim itself, its opposite, and the claim with "fact check". Run %s in total.
+2. Read %s with searxng_read, preferring primary sources, official statistics and established fact-checkers. Note when each source was published: a claim may have been true at one time and not at another.
+3. Give a verdict (true, mostly true, misleading, mostly false, false or unverifiable), followed by the evidence for and against with the URL of each source. Don't rely on direct answers with a low answer_confidence without reading a source.`,
+		claim, depth.searches, depth.reads)), nil
+}
+
+func handleCompareSourcesPrompt(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	topic, depth, err := promptArguments(request, "topic")
+	if err != nil {
+		return nil, err
+	}
+
+	var sites []string
+	for _, site := range strings.Split(request.Params.Arguments["sources"], ",") {
+		if site = strings.TrimSpace(site); site != "" {
+			sites = append(sites, site)
+		}
+	}
+	intro := fmt.Sprintf("Compare how different sources cover the following topic.\n\nTopic: %s\n", topic)
+	find := fmt.Sprintf("Find coverage of the topic from several independent sources with searxng_search. Run %s.", depth.searches)
+	if len(sites) > 0 {
+		intro += fmt.Sprintf("Sources: %s\n", strings.Join(sites, ", "))
+		find = fmt.Sprintf("Find each source's coverage with searxng_search, adding a site: operator to the query (e.g. \"site:%s %s\"). Run %s.", sites[0], topic, depth.searches)
+	}
+	return promptResult("Compare sources: "+topic, fmt.Sprintf(`%s
+1. %s
+2. Read %s with searxng_read.
+3. Compare the sources: what each one claims, its evidence, when it was published and any apparent bias or conflict of interest. Point out where they agree, where they contradict each other and which ones are the most reliable, citing URLs.`,
+		intro, find, depth.reads)), nil
+}
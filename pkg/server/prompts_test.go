@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getPrompt sends a prompts/get message through the MCP server
+func getPrompt(t *testing.T, srv *Server, name string, args map[string]string) mcp.JSONRPCMessage {
+	t.Helper()
+	msg, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "prompts/get",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	require.NoError(t, err)
+	return srv.MCPServer().HandleMessage(context.Background(), msg)
+}
+
+// promptText returns the text of the single message of a prompt
+func promptText(t *testing.T, resp mcp.JSONRPCMessage) string {
+	t.Helper()
+	result, ok := resp.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", resp)
+	prompt, ok := result.Result.(mcp.GetPromptResult)
+	require.True(t, ok)
+	require.Len(t, prompt.Messages, 1)
+	assert.Equal(t, mcp.RoleUser, prompt.Messages[0].Role)
+	return prompt.Messages[0].Content.(mcp.TextContent).Text
+}
+
+func TestPrompts_List(t *testing.T) {
+	srv := New(nil)
+	resp := srv.MCPServer().HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`))
+	result, ok := resp.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	list := result.Result.(mcp.ListPromptsResult)
+
+	arguments := map[string][]string{}
+	for _, prompt := range list.Prompts {
+		assert.NotEmpty(t, prompt.Description, prompt.Name)
+		for _, arg := range prompt.Arguments {
+			arguments[prompt.Name] = append(arguments[prompt.Name], arg.Name)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"research_topic":  {"topic", "depth"},
+		"fact_check":      {"claim", "depth"},
+		"compare_sources": {"topic", "sources", "depth"},
+	}, arguments)
+}
+
+func TestPrompts_Get(t *testing.T) {
+	srv := New(nil)
+
+	text := promptText(t, getPrompt(t, srv, "research_topic", map[string]string{"topic": "Go generics"}))
+	assert.Contains(t, text, "Topic: Go generics")
+	assert.Contains(t, text, "about 3 searches", "standard depth by default")
+	assert.Contains(t, text, "searxng_read")
+
+	text = promptText(t, getPrompt(t, srv, "fact_check", map[string]string{"claim": "The moon is made of cheese", "depth": "Deep"}))
+	assert.Contains(t, text, "Claim: The moon is made of cheese")
+	assert.Contains(t, text, "10 or more pages")
+
+	text = promptText(t, getPrompt(t, srv, "compare_sources", map[string]string{"topic": "rust 2024", "sources": "lwn.net, ,theregister.com", "depth": "quick"}))
+	assert.Contains(t, text, "Sources: lwn.net, theregister.com")
+	assert.Contains(t, text, `"site:lwn.net rust 2024"`)
+	assert.Contains(t, text, "a single search")
+
+	text = promptText(t, getPrompt(t, srv, "compare_sources", map[string]string{"topic": "rust 2024"}))
+	assert.NotContains(t, text, "site:")
+}
+
+func TestPrompts_InvalidArguments(t *testing.T) {
+	srv := New(nil)
+
+	resp, ok := getPrompt(t, srv, "research_topic", map[string]string{"topic": " "}).(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Contains(t, resp.Error.Message, "topic is required")
+
+	resp, ok = getPrompt(t, srv, "fact_check", map[string]string{"claim": "x", "depth": "exhaustive"}).(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Contains(t, resp.Error.Message, "invalid depth: exhaustive")
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+)
+
+// proxyDirect is the proxy argument value reading pages without a proxy;
+// it is refused when the server has a proxy of its own
+const proxyDirect = "direct"
+
+// proxyDescription documents the proxy argument of the reading tools,
+// which are only offered it with Options.ProxyAllowlist
+const proxyDescription = "Proxy to fetch pages through; must be one of the proxies allowed by the server operator, e.g. 'socks5://127.0.0.1:9050' (default: server setting)"
+
+// proxyProperty returns the schema of the proxy argument
+func proxyProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": proxyDescription,
+	}
+}
+
+// newProxyAllowlist parses the proxies of Options.ProxyAllowlist, keyed by
+// their normalized URL. Invalid entries are logged and skipped.
+func newProxyAllowlist(allowlist []string) map[string]*url.URL {
+	proxies := make(map[string]*url.URL, len(allowlist))
+	for _, raw := range allowlist {
+		proxy, err := searxng.ParseProxyURL(raw)
+		if err != nil {
+			log.WithField("error", err).Error("ignoring proxy allowlist entry")
+			continue
+		}
+		proxies[proxy.String()] = proxy
+	}
+	return proxies
+}
+
+// refusingTransport fails every request with err. It replaces the page
+// transport when Options.ProxyURL is invalid, so pages aren't fetched
+// without the proxy the operator asked for (e.g. a Tor egress).
+func refusingTransport(err error) *http.Transport {
+	return &http.Transport{Proxy: func(*http.Request) (*url.URL, error) {
+		return nil, fmt.Errorf("page fetches are disabled: %w", err)
+	}}
+}
+
+// applyProxy sets the proxies of opts from the proxy argument of a tool
+// call: the server's proxies when it is absent or empty, else one of
+// Options.ProxyAllowlist. "direct" is only accepted when the server has no
+// proxy, so clients can't bypass it.
+func (s *Server) applyProxy(args map[string]interface{}, opts *readOptions) error {
+	raw, _ := args["proxy"].(string)
+	switch raw {
+	case "":
+		opts.Proxy, opts.RetryProxy = s.proxy, s.retryProxy
+	case proxyDirect:
+		if s.options.ProxyURL != "" {
+			return errors.New("proxy 'direct' is not allowed: the server reads pages through its proxy")
+		}
+		opts.Proxy, opts.RetryProxy = nil, nil
+	default:
+		proxy, err := searxng.ParseProxyURL(raw)
+		if err != nil {
+			return err
+		}
+		allowed, ok := s.proxies[proxy.String()]
+		if !ok {
+			return fmt.Errorf("proxy %s is not in the server's proxy allowlist", raw)
+		}
+		opts.Proxy, opts.RetryProxy = allowed, nil
+	}
+	return nil
+}
+
+// defaultReadOptions returns the read options of pages read without
+// arguments: the server's boilerplate level, proxy and transport
+func (s *Server) defaultReadOptions() readOptions {
+	return readOptions{
+		Boilerplate: s.options.Boilerplate,
+		Proxy:       s.proxy,
+		RetryProxy:  s.retryProxy,
+		Transport:   s.options.Transport,
+		DiskCache:   s.diskCache,
+		MaxBytes:    s.options.ReadMaxBytes,
+		Mirrors:     s.options.ReadMirrors,
+	}
+}
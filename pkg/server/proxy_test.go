@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProxy starts an HTTP proxy that answers every request itself with a
+// page naming the requested host, counting the requests it receives
+func newTestProxy(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body><p>proxied " + r.URL.Host + "</p></body></html>"))
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy, &hits
+}
+
+func TestHandleWebRead_Proxy(t *testing.T) {
+	proxy, hits := newTestProxy(t)
+	read := func(srv *Server, args map[string]interface{}) *mcp.CallToolResult {
+		result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: args},
+		})
+		require.NoError(t, err)
+		return result
+	}
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body><p>direct</p></body></html>"))
+	}))
+	defer site.Close()
+
+	// Per-call proxies must be allowlisted
+	result := read(New(nil), map[string]interface{}{"url": "http://proxied.invalid/page", "proxy": proxy.URL})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not in the server's proxy allowlist")
+	result = read(NewWithOptions(nil, Options{ProxyAllowlist: []string{proxy.URL}}),
+		map[string]interface{}{"url": "http://proxied.invalid/page", "proxy": proxy.URL})
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "proxied proxied.invalid")
+	assert.Equal(t, int32(1), hits.Load())
+
+	// Server-wide proxy
+	srv := NewWithOptions(nil, Options{ProxyURL: proxy.URL})
+	result = read(srv, map[string]interface{}{"url": "http://proxied.invalid/other"})
+	require.False(t, result.IsError)
+	assert.Equal(t, int32(2), hits.Load())
+
+	// "direct" can't bypass the server-wide proxy
+	result = read(srv, map[string]interface{}{"url": site.URL, "proxy": "direct"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not allowed")
+	result = read(New(nil), map[string]interface{}{"url": site.URL, "proxy": "direct"})
+	require.False(t, result.IsError, "without a server proxy, it is the default")
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestProxyArgument_OnlyOfferedWithAllowlist(t *testing.T) {
+	for _, tc := range []struct {
+		options Options
+		offered bool
+	}{
+		{Options{}, false},
+		{Options{ProxyURL: "socks5://127.0.0.1:9050"}, false},
+		{Options{ProxyAllowlist: []string{"socks5://127.0.0.1:9050"}}, true},
+	} {
+		tools := NewWithOptions(nil, tc.options).MCPServer().ListTools()
+		for _, name := range []string{"searxng_read", "searxng_search_and_read"} {
+			_, ok := tools[name].Tool.InputSchema.Properties["proxy"]
+			assert.Equal(t, tc.offered, ok, "%s %+v", name, tc.options)
+		}
+	}
+}
+
+func TestHandleWebRead_InvalidProxy(t *testing.T) {
+	result, err := NewWithOptions(nil, Options{ProxyAllowlist: []string{"http://proxy:3128"}}).handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{
+			"url":   "https://example.com",
+			"proxy": "ftp://proxy:21",
+		}},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid proxy URL")
+}
+
+func TestHandleWebRead_InvalidServerProxyFailsClosed(t *testing.T) {
+	var requests atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer site.Close()
+
+	for _, options := range []Options{
+		{ProxyURL: "ftp://proxy:21"},
+		{ProxyURL: "ftp://proxy:21", RespectRobots: true},
+	} {
+		result, err := NewWithOptions(nil, options).handleWebRead(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": site.URL}},
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		if !options.RespectRobots {
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "page fetches are disabled")
+		}
+	}
+	assert.Zero(t, requests.Load(), "neither pages nor robots.txt are fetched without the proxy")
+}
+
+func TestFetchGenericHTML_RetryOn403(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == defaultUserAgent {
+			http.Error(w, "bots not welcome", http.StatusForbidden)
+			return
+		}
+		assert.Equal(t, minimalUserAgent, r.Header.Get("User-Agent"))
+		assert.Empty(t, r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body><p>welcome</p></body></html>"))
+	}))
+	defer site.Close()
+
+	page, err := fetchURLPage(context.Background(), site.URL, readOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, page.Markdown, "welcome")
+	assert.Equal(t, fetchVariantMinimal, page.FetchVariant)
+
+	result, err := New(nil).handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": site.URL}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	metadata, ok := result.StructuredContent.(readMetadata)
+	require.True(t, ok)
+	assert.Equal(t, fetchVariantMinimal, metadata.FetchVariant)
+}
+
+func TestFetchGenericHTML_RetryOn403ViaProxy(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	defer site.Close()
+	proxy, hits := newTestProxy(t)
+
+	srv := NewWithOptions(nil, Options{ProxyURL: proxy.URL, ProxyFallback: true})
+	result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": site.URL}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, int32(1), hits.Load(), "only the retry goes through the proxy")
+	assert.Equal(t, fetchVariantMinimalProxy, result.StructuredContent.(readMetadata).FetchVariant)
+}
+
+func TestFetchGenericHTML_ForbiddenAfterRetry(t *testing.T) {
+	var requests atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	defer site.Close()
+
+	_, err := fetchURLPage(context.Background(), site.URL, readOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HTTP 403")
+	assert.Contains(t, err.Error(), "after retrying")
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestPageClient(t *testing.T) {
+	assert.Same(t, pageClient(nil, nil), pageClient(nil, nil), "clients are reused")
+	assert.Nil(t, pageClient(nil, nil).Transport, "http.DefaultTransport")
+
+	shared := &http.Transport{}
+	assert.Same(t, shared, pageClient(shared, nil).Transport)
+
+	proxyURL, err := url.Parse("http://proxy.example:3128")
+	require.NoError(t, err)
+	proxied := pageClient(shared, proxyURL)
+	assert.Same(t, proxied, pageClient(shared, proxyURL))
+	transport, ok := proxied.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.NotSame(t, shared, transport, "proxies get a copy")
+	got, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, proxyURL, got)
+}
+
+func TestHandleWebRead_Transport(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer site.Close()
+
+	var dials atomic.Int32
+	dialer := &net.Dialer{}
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		return dialer.DialContext(ctx, network, addr)
+	}}
+	defer transport.CloseIdleConnections()
+
+	srv := NewWithOptions(nil, Options{Transport: transport})
+	for range 3 {
+		result := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+		require.False(t, result.IsError)
+	}
+	assert.Equal(t, int32(1), dials.Load(), "reads share the transport's connections")
+}
@@ -0,0 +1,22 @@
+package server
+
+import "time"
+
+// ReadRateLimitFullAt returns when the page reads' rate limiter will have
+// its whole burst available again, for persisting with
+// RestoreReadRateLimit. It returns the zero time when it has, or when reads
+// aren't rate limited.
+func (s *Server) ReadRateLimitFullAt() time.Time {
+	if s.readLimiter == nil {
+		return time.Time{}
+	}
+	return s.readLimiter.FullAt()
+}
+
+// RestoreReadRateLimit drains the page reads' rate limiter as it was when
+// ReadRateLimitFullAt returned fullAt, so a restart doesn't refill it
+func (s *Server) RestoreReadRateLimit(fullAt time.Time) {
+	if s.readLimiter != nil {
+		s.readLimiter.Restore(fullAt)
+	}
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ReadMode selects how much of a generic HTML page searxng_read converts
+type ReadMode string
+
+const (
+	// ReadModeFull converts the whole page minus navigation chrome
+	ReadModeFull ReadMode = "full"
+	// ReadModeArticle extracts only the main article with its title, byline
+	// and published date
+	ReadModeArticle ReadMode = "article"
+)
+
+// ParseReadMode parses a read mode name. An empty string maps to
+// ReadModeFull.
+func ParseReadMode(s string) (ReadMode, error) {
+	switch ReadMode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ReadModeFull:
+		return ReadModeFull, nil
+	case ReadModeArticle:
+		return ReadModeArticle, nil
+	default:
+		return "", fmt.Errorf("invalid read mode: %s (must be 'article' or 'full')", s)
+	}
+}
+
+var (
+	// unlikelyCandidate matches class/id values of page chrome
+	unlikelyCandidate = regexp.MustCompile(`(?i)comment|sidebar|footer|header|nav|menu|share|social|related|promo|advert|banner|cookie|newsletter|popup|subscribe|sponsor`)
+	// likelyCandidate matches class/id values that override unlikelyCandidate
+	likelyCandidate = regexp.MustCompile(`(?i)article|content|main|body|post|entry|story`)
+)
+
+// minArticleText is the minimum text length of an extracted article; shorter
+// extractions fall back to full-page conversion
+const minArticleText = 140
+
+// article is the main content of a page as found by extractArticle
+type article struct {
+	Title     string
+	Byline    string
+	Published string
+	Content   *goquery.Selection
+}
+
+// extractArticle finds the main content of doc with readability-style
+// scoring: paragraphs award points to their parent and grandparent, and
+// containers are penalized by their link density. It returns nil when no
+// convincing candidate is found. doc itself is not modified.
+func extractArticle(doc *goquery.Document) *article {
+	body := doc.Find("body").First().Clone()
+	if body.Length() == 0 {
+		return nil
+	}
+
+	body.Find("style, nav, footer, header, aside, form").Remove()
+	body.Find("*").Each(func(i int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "article" || goquery.NodeName(s) == "main" {
+			return
+		}
+		hint := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyCandidate.MatchString(hint) && !likelyCandidate.MatchString(hint) {
+			s.Remove()
+		}
+	})
+
+	scores := make(map[*html.Node]float64)
+	var candidates []*goquery.Selection
+	addScore := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 {
+			return
+		}
+		node := s.Get(0)
+		if _, ok := scores[node]; !ok {
+			switch goquery.NodeName(s) {
+			case "article":
+				scores[node] = 10
+			case "main":
+				scores[node] = 5
+			}
+			candidates = append(candidates, s)
+		}
+		scores[node] += score
+	}
+
+	body.Find("p, pre, blockquote, td").Each(func(i int, s *goquery.Selection) {
+		text := strings.TrimSpace(s.Text())
+		if len(text) < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		addScore(s.Parent(), score)
+		addScore(s.Parent().Parent(), score/2)
+	})
+
+	var best *goquery.Selection
+	bestScore := 0.0
+	for _, candidate := range candidates {
+		score := scores[candidate.Get(0)] * (1 - linkDensity(candidate))
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if best == nil || len(strings.TrimSpace(best.Text())) < minArticleText {
+		return nil
+	}
+
+	return &article{
+		Title:     articleTitle(doc),
+		Byline:    articleByline(doc),
+		Published: articlePublished(doc),
+		Content:   best,
+	}
+}
+
+// linkDensity returns the share of s's text that is link text
+func linkDensity(s *goquery.Selection) float64 {
+	textLen := len(strings.TrimSpace(s.Text()))
+	if textLen == 0 {
+		return 0
+	}
+	linkLen := 0
+	s.Find("a").Each(func(i int, a *goquery.Selection) {
+		linkLen += len(strings.TrimSpace(a.Text()))
+	})
+	return min(float64(linkLen)/float64(textLen), 1)
+}
+
+func articleTitle(doc *goquery.Document) string {
+	if title := metaContent(doc, `meta[property="og:title"]`, `meta[name="twitter:title"]`); title != "" {
+		return title
+	}
+	if h1 := strings.TrimSpace(doc.Find("h1").First().Text()); h1 != "" {
+		return h1
+	}
+	return strings.TrimSpace(doc.Find("title").First().Text())
+}
+
+func articleByline(doc *goquery.Document) string {
+	if author := metaContent(doc, `meta[name="author"]`, `meta[property="article:author"]`); author != "" && !strings.HasPrefix(author, "http") {
+		return author
+	}
+	for _, selector := range []string{`[rel="author"]`, `[itemprop="author"]`, ".byline", ".author"} {
+		if byline := strings.Join(strings.Fields(doc.Find(selector).First().Text()), " "); byline != "" {
+			return strings.TrimPrefix(strings.TrimPrefix(byline, "By "), "by ")
+		}
+	}
+	return ""
+}
+
+func articlePublished(doc *goquery.Document) string {
+	published := metaContent(doc,
+		`meta[property="article:published_time"]`,
+		`meta[itemprop="datePublished"]`,
+		`meta[name="date"]`,
+	)
+	if published == "" {
+		published = strings.TrimSpace(doc.Find("time[datetime]").First().AttrOr("datetime", ""))
+	}
+	if t, err := time.Parse(time.RFC3339, published); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return published
+}
+
+// metaContent returns the content attribute of the first matching selector
+func metaContent(doc *goquery.Document, selectors ...string) string {
+	for _, selector := range selectors {
+		if content := strings.TrimSpace(doc.Find(selector).First().AttrOr("content", "")); content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+// articleMarkdown renders an extracted article as Markdown with a title and
+// byline header
+func articleMarkdown(a *article) (string, error) {
+	// The title is rendered in the header, so drop a matching heading
+	a.Content.Find("h1").Each(func(i int, s *goquery.Selection) {
+		if strings.TrimSpace(s.Text()) == a.Title {
+			s.Remove()
+		}
+	})
+
+	content, err := goquery.OuterHtml(a.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize HTML: %w", err)
+	}
+	markdown, err := htmlToMarkdown(content)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if a.Title != "" {
+		sb.WriteString("# " + a.Title + "\n\n")
+	}
+	var meta []string
+	if a.Byline != "" {
+		meta = append(meta, "By "+a.Byline)
+	}
+	if a.Published != "" {
+		meta = append(meta, "Published "+a.Published)
+	}
+	if len(meta) > 0 {
+		sb.WriteString("*" + strings.Join(meta, " · ") + "*\n\n")
+	}
+	sb.WriteString(markdown)
+	return cleanMarkdown(sb.String()), nil
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const articlePage = `<html>
+<head>
+	<title>Site | Understanding Go Channels</title>
+	<meta property="og:title" content="Understanding Go Channels">
+	<meta name="author" content="Jane Doe">
+	<meta property="article:published_time" content="2024-03-05T10:00:00Z">
+</head>
+<body>
+	<div class="menu"><a href="/">Home</a> <a href="/blog">Blog</a> <a href="/about">About</a></div>
+	<div class="layout">
+		<div class="sidebar-widget"><p>Subscribe to our newsletter, get weekly updates, tips and more.</p></div>
+		<div class="post-body">
+			<h1>Understanding Go Channels</h1>
+			<p>Channels are the pipes that connect concurrent goroutines, and you can send values into them.</p>
+			<p>Unbuffered channels block the sender until the receiver is ready, which synchronizes goroutines.</p>
+			<p>Buffered channels accept a limited number of values without a corresponding receiver, up to capacity.</p>
+		</div>
+		<div class="links"><a href="/a">A very long related link title</a> <a href="/b">Another related link title here</a></div>
+	</div>
+</body>
+</html>`
+
+func TestParseReadMode(t *testing.T) {
+	mode, err := ParseReadMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ReadModeFull, mode)
+
+	mode, err = ParseReadMode(" Article ")
+	require.NoError(t, err)
+	assert.Equal(t, ReadModeArticle, mode)
+
+	_, err = ParseReadMode("summary")
+	assert.Error(t, err)
+}
+
+func TestExtractArticle(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(articlePage))
+	require.NoError(t, err)
+
+	a := extractArticle(doc)
+	require.NotNil(t, a)
+	assert.Equal(t, "Understanding Go Channels", a.Title)
+	assert.Equal(t, "Jane Doe", a.Byline)
+	assert.Equal(t, "2024-03-05", a.Published)
+	assert.Equal(t, "post-body", a.Content.AttrOr("class", ""))
+
+	// The document itself is left untouched
+	assert.Equal(t, 1, doc.Find(".sidebar-widget").Length())
+}
+
+func TestExtractArticle_NoArticle(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><a href="/">Home</a></body></html>`))
+	require.NoError(t, err)
+	assert.Nil(t, extractArticle(doc))
+}
+
+func TestFetchURLContent_ArticleMode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(articlePage))
+	}))
+	defer ts.Close()
+
+	markdown, err := fetchURLContent(context.Background(), ts.URL, readOptions{Mode: ReadModeArticle})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(markdown, "# Understanding Go Channels\n\n*By Jane Doe · Published 2024-03-05*"), markdown)
+	assert.Equal(t, 1, strings.Count(markdown, "Understanding Go Channels"))
+	assert.Contains(t, markdown, "Buffered channels")
+	assert.NotContains(t, markdown, "newsletter")
+	assert.NotContains(t, markdown, "related link")
+
+	markdown, err = fetchURLContent(context.Background(), ts.URL, readOptions{Mode: ReadModeFull})
+	require.NoError(t, err)
+	assert.Contains(t, markdown, "newsletter")
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultReadCacheSize is the default maximum number of cached pages
+const DefaultReadCacheSize = 64
+
+// readCache is an LRU cache of read pages, keyed by URL, whose entries
+// expire after a fixed TTL. A page is reused by reads with the same
+// conversion options; any cached conversion serves snippet expansion. It is
+// safe for concurrent use.
+type readCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List // front: most recently used
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+type readCacheEntry struct {
+	url     string
+	options string // see readCacheOptions
+	page    *readResult
+	expires time.Time
+}
+
+// newReadCache creates a cache, or returns nil when ttl disables caching
+func newReadCache(ttl time.Duration, size int) *readCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = DefaultReadCacheSize
+	}
+	return &readCache{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// readCacheOptions identifies the options that change the content of a
+// read page; proxies don't
+func readCacheOptions(opts readOptions) string {
+	return fmt.Sprintf("%s/%s/%t", opts.Mode, opts.Boilerplate, opts.IncludeHTML)
+}
+
+// get returns a copy of the page cached for url if it is fresh and was
+// read with options equivalent to opts
+func (c *readCache) get(url string, opts readOptions) (*readResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(url)
+	if !ok || entry.options != readCacheOptions(opts) {
+		return nil, false
+	}
+	page := *entry.page
+	return &page, true
+}
+
+// content returns the Markdown of the page cached for url, whatever the
+// options it was read with
+func (c *readCache) content(url string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(url)
+	if !ok {
+		return "", false
+	}
+	return entry.page.Markdown, true
+}
+
+// lookup returns the fresh entry of url, marking it as recently used.
+// c.mu must be held.
+func (c *readCache) lookup(url string) (*readCacheEntry, bool) {
+	elem, ok := c.entries[url]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*readCacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, url)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry, true
+}
+
+// put stores page as read from url with opts, replacing the previous read
+// of url and evicting the least recently used page when the cache is full
+func (c *readCache) put(url string, opts readOptions, page *readResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *page
+	entry := &readCacheEntry{url: url, options: readCacheOptions(opts), page: &stored, expires: c.now().Add(c.ttl)}
+	if elem, ok := c.entries[url]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[url] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*readCacheEntry).url)
+	}
+}
+
+// clear drops every cached page
+func (c *readCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCache(t *testing.T) {
+	assert.Nil(t, newReadCache(0, 10), "disabled without a TTL")
+
+	cache := newReadCache(time.Minute, 2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	article := readOptions{Mode: ReadModeArticle}
+
+	cache.put("https://a.example", readOptions{}, &readResult{Markdown: "A"})
+	page, ok := cache.get("https://a.example", readOptions{})
+	require.True(t, ok)
+	assert.Equal(t, "A", page.Markdown)
+	page.Markdown = "modified"
+
+	_, ok = cache.get("https://a.example", article)
+	assert.False(t, ok, "other conversion options miss")
+	content, ok := cache.content("https://a.example")
+	require.True(t, ok, "snippets take any conversion")
+	assert.Equal(t, "A", content, "entries are copied")
+
+	// b is evicted as the least recently used page
+	cache.put("https://b.example", readOptions{}, &readResult{Markdown: "B"})
+	_, _ = cache.content("https://a.example")
+	cache.put("https://c.example", readOptions{}, &readResult{Markdown: "C"})
+	_, ok = cache.content("https://b.example")
+	assert.False(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.content("https://a.example")
+	assert.False(t, ok, "expired")
+}
+
+func TestFetchPage_ReadCache(t *testing.T) {
+	var requests atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer site.Close()
+
+	srv := NewWithOptions(nil, Options{ReadCacheTTL: time.Minute})
+	for range 2 {
+		page, err := srv.fetchPage(context.Background(), site.URL, readOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "page", page.Markdown)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	_, err := srv.fetchPage(context.Background(), site.URL, readOptions{Mode: ReadModeArticle})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load(), "other options fetch again")
+}
@@ -1,13 +1,16 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
@@ -15,6 +18,8 @@ import (
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -25,29 +30,327 @@ const (
 	maxHTTPRedirectCount = 10
 )
 
+// DefaultReadMaxBytes is the default maximum size of a downloaded page;
+// larger pages are cut
+const DefaultReadMaxBytes = 5 << 20
+
+// DefaultReadMaxLength is the default maximum number of characters of a
+// page returned by searxng_read; the rest is left to further reads with an
+// offset
+const DefaultReadMaxLength = 100000
+
+// minimalUserAgent is sent, with no headers but Accept, when retrying a page
+// that answered 403 to the browser headers: many sites block the spoofed
+// Chrome user agent but serve plain HTTP clients
+const minimalUserAgent = "Go-http-client/1.1"
+
+// Fetch variants of a page that answered 403 to the first request, reported
+// in readResult.FetchVariant
+const (
+	fetchVariantMinimal      = "minimal_headers"
+	fetchVariantMinimalProxy = "minimal_headers_proxy"
+)
+
 var supportedSchemes = []string{"http", "https"}
 
+// dangerousSchemes are URL schemes removed from fetched pages: they run
+// code, embed arbitrary content or point at the local filesystem, and are
+// never followed. Script schemes are removed from any attribute, the
+// others from urlAttributes.
+var dangerousSchemes = []string{"javascript", "vbscript", "data", "file"}
+
+// urlAttributes are the HTML attributes holding URLs
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "srcset": true, "action": true, "formaction": true,
+	"poster": true, "background": true, "cite": true, "data": true, "xlink:href": true,
+}
+
+// urlScheme returns the lowercased scheme of a URL found in a page, the way
+// browsers read it: ignoring leading spaces and control characters, and
+// tabs and newlines anywhere (e.g. "java\tscript:")
+func urlScheme(raw string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, strings.TrimLeftFunc(raw, func(r rune) bool { return r <= ' ' }))
+	scheme, _, found := strings.Cut(cleaned, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return ""
+	}
+	return strings.ToLower(scheme)
+}
+
+// errBlocked marks fetches refused by the read policy: redirects to
+// unsupported schemes or failing the check of withRedirectCheck (domain
+// lists, robots.txt). Mirrors aren't tried for them.
+var errBlocked = errors.New("blocked")
+
+// checkRedirect stops redirect chains that are too long or lead to a
+// scheme other than http(s), such as file://, even on transports that
+// register handlers for other schemes. Redirect targets also go through the
+// check of the request's context (see withRedirectCheck).
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxHTTPRedirectCount {
+		return fmt.Errorf("too many redirects")
+	}
+	if !slices.Contains(supportedSchemes, req.URL.Scheme) {
+		return fmt.Errorf("redirect to unsupported URL scheme %s %w", req.URL.Scheme, errBlocked)
+	}
+	if check := redirectCheck(req.Context()); check != nil {
+		if err := check(req.Context(), req.URL.String()); err != nil {
+			return fmt.Errorf("redirect %w: %w", errBlocked, err)
+		}
+	}
+	return nil
+}
+
+type redirectCheckKey struct{}
+
+// withRedirectCheck returns ctx making page fetches run check on every
+// redirect target, so a page can't redirect past the checks the requested
+// URL passed. A nil check removes the check of ctx.
+func withRedirectCheck(ctx context.Context, check func(context.Context, string) error) context.Context {
+	return context.WithValue(ctx, redirectCheckKey{}, check)
+}
+
+// redirectCheck returns the check of ctx set by withRedirectCheck, or nil
+func redirectCheck(ctx context.Context) func(context.Context, string) error {
+	check, _ := ctx.Value(redirectCheckKey{}).(func(context.Context, string) error)
+	return check
+}
+
+// statusError is the error of a generic page answering a status other
+// than 200
+type statusError struct {
+	code   int
+	status string
+	// retried is set when the retry with minimal headers got the status
+	// too
+	retried bool
+}
+
+func (e *statusError) Error() string {
+	if e.retried {
+		return fmt.Sprintf("HTTP %d: %s (also after retrying with minimal headers)", e.code, e.status)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.code, e.status)
+}
+
+// readOptions tunes how fetchURLContent post-processes a page
+type readOptions struct {
+	// Boilerplate controls trailing boilerplate removal for generic HTML pages
+	Boilerplate BoilerplateLevel
+	// IncludeHTML keeps the sanitized HTML of generic HTML pages
+	IncludeHTML bool
+	// Mode selects full-page or article-only conversion of generic HTML pages
+	Mode ReadMode
+	// Proxy routes the fetch through an HTTP(S) or SOCKS5 proxy; nil uses
+	// the proxy environment variables
+	Proxy *url.URL
+	// RetryProxy, when set, routes the retry of a generic page answering
+	// 403 through a proxy
+	RetryProxy *url.URL
+	// Transport carries the fetch; nil uses http.DefaultTransport
+	Transport *http.Transport
+	// DiskCache keeps generic pages across restarts and revalidates them;
+	// nil disables it
+	DiskCache *diskCache
+	// MaxBytes cuts generic pages downloaded beyond it (0:
+	// DefaultReadMaxBytes)
+	MaxBytes int64
+	// Mirrors are the URL templates generic pages that fail to fetch are
+	// read through (see ValidateReadMirrors)
+	Mirrors []string
+	// Timeout replaces defaultHTTPTimeout for the requests of the read
+	// (0: defaultHTTPTimeout)
+	Timeout time.Duration
+}
+
+// readResult is a fetched page
+type readResult struct {
+	Markdown string
+	// HTML is the sanitized page HTML; only set for generic HTML pages when
+	// readOptions.IncludeHTML is set
+	HTML string
+	// Freshness is derived from the caching headers of generic pages; nil
+	// when the page sent none
+	Freshness *freshness
+	// FetchVariant names the retry that got a generic page after a 403
+	// (fetchVariantMinimal or fetchVariantMinimalProxy); empty when the
+	// first request succeeded
+	FetchVariant string
+	// StructuredData is the JSON-LD, microdata and OpenGraph data of
+	// generic HTML pages; nil when there is none
+	StructuredData *structuredData
+	// Truncated is set when a generic page was cut at readOptions.MaxBytes
+	Truncated bool
+	// Mirror is the URL of the readOptions.Mirrors mirror a generic page
+	// was read through; empty when the page itself was fetched
+	Mirror string
+	// ContentType is the media type of generic pages, e.g. text/html or
+	// application/json
+	ContentType string
+	// CodeLanguage is the language of the code block Markdown holds for
+	// data and source files (see codeLanguage); empty for prose
+	CodeLanguage string
+	// Range is the part of the resource a ranged read returned; nil for
+	// whole pages
+	Range *contentRange
+}
+
 // fetchURLContent fetches content from a URL and converts it to Markdown.
-func fetchURLContent(ctx context.Context, urlStr string) (string, error) {
-	parsedURL, err := validateURL(urlStr)
+func fetchURLContent(ctx context.Context, urlStr string, opts readOptions) (string, error) {
+	page, err := fetchURLPage(ctx, urlStr, opts)
 	if err != nil {
 		return "", err
 	}
+	return page.Markdown, nil
+}
+
+// fetchURLPage fetches content from a URL and converts it to Markdown,
+// optionally keeping the sanitized HTML as well.
+func fetchURLPage(ctx context.Context, urlStr string, opts readOptions) (*readResult, error) {
+	parsedURL, err := validateURL(urlStr)
+	if err != nil {
+		return nil, err
+	}
 
 	log.WithField("url", urlStr).Debug("fetching URL")
 
-	client := newHTTPClient()
-	if isRedditThreadURL(parsedURL) {
-		return fetchRedditContentAsMarkdown(ctx, client, parsedURL)
+	client := opts.client(opts.Proxy)
+	var markdown string
+	switch {
+	case isRedditThreadURL(parsedURL):
+		markdown, err = fetchRedditContentAsMarkdown(ctx, client, parsedURL)
+	case isGitHubIssueOrPRURL(parsedURL):
+		markdown, err = fetchGitHubContentAsMarkdown(ctx, client, parsedURL)
+	case isGitHubRepoURL(parsedURL):
+		markdown, err = fetchGitHubRepoAsMarkdown(ctx, client, parsedURL)
+	default:
+		page, err := fetchGenericHTML(ctx, client, parsedURL.String(), opts)
+		if err != nil && len(opts.Mirrors) > 0 && mirrorable(err) {
+			page, err = fetchFromMirrors(ctx, client, parsedURL.String(), opts, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if page.CodeLanguage == "" {
+			page.Markdown = stripTrailingBoilerplate(page.Markdown, opts.Boilerplate)
+		}
+		return page, nil
 	}
-	if isGitHubIssueOrPRURL(parsedURL) {
-		return fetchGitHubContentAsMarkdown(ctx, client, parsedURL)
+	if err != nil {
+		return nil, err
 	}
-	if isGitHubRepoURL(parsedURL) {
-		return fetchGitHubRepoAsMarkdown(ctx, client, parsedURL)
+	return &readResult{Markdown: markdown}, nil
+}
+
+// fetchPage is fetchURLPage going through the read cache and waiting for
+// the page read rate limiter first. Fetched pages are exposed as page://
+// resources.
+func (s *Server) fetchPage(ctx context.Context, urlStr string, opts readOptions) (page *readResult, err error) {
+	ctx, span := startSpan(ctx, "fetch_page", attribute.String("url.full", urlStr))
+	defer func() {
+		if err != nil {
+			spanError(ctx, err)
+		} else {
+			span.SetAttributes(
+				attribute.Int("read.markdown_bytes", len(page.Markdown)),
+				attribute.Bool("read.truncated", page.Truncated),
+			)
+			if page.Mirror != "" {
+				span.SetAttributes(attribute.String("read.mirror", page.Mirror))
+			}
+			if page.FetchVariant != "" {
+				span.SetAttributes(attribute.String("read.fetch_variant", page.FetchVariant))
+			}
+		}
+		span.End()
+	}()
+
+	if s.readCache != nil {
+		if page, ok := s.readCache.get(urlStr, opts); ok {
+			log.WithField("url", urlStr).Debug("page served from the read cache")
+			span.SetAttributes(attribute.Bool("read.cache_hit", true))
+			return page, nil
+		}
+	}
+	if err := s.waitRead(ctx); err != nil {
+		return nil, err
+	}
+	page, err = fetchURLPage(withRedirectCheck(ctx, s.checkRead), urlStr, opts)
+	if err != nil {
+		return nil, err
 	}
+	if s.readCache != nil {
+		s.readCache.put(urlStr, opts, page)
+	}
+	s.resources.addPage(ctx, urlStr, page)
+	return page, nil
+}
+
+// waitRead waits for the page read rate limiter, if any
+func (s *Server) waitRead(ctx context.Context) error {
+	if s.readLimiter == nil {
+		return nil
+	}
+	waitCtx, waitSpan := startSpan(ctx, "read_rate_limit_wait")
+	defer waitSpan.End()
+	if err := s.readLimiter.Wait(waitCtx); err != nil {
+		return fmt.Errorf("read rate limit: %w", err)
+	}
+	return nil
+}
+
+// readMaxLength returns the maximum number of characters of a page returned
+// by a read (see Options.ReadMaxLength)
+func (s *Server) readMaxLength() int {
+	if s.options.ReadMaxLength > 0 {
+		return s.options.ReadMaxLength
+	}
+	return DefaultReadMaxLength
+}
+
+// ReadRequest holds the settings of Server.Read, those of the searxng_read
+// tool
+type ReadRequest struct {
+	URL         string
+	Mode        ReadMode         // empty: ReadModeFull
+	Boilerplate BoilerplateLevel // empty: Options.Boilerplate
+	// IncludeHTML also returns the sanitized HTML of generic HTML pages
+	IncludeHTML bool
+}
+
+// Page is a page read by Server.Read
+type Page struct {
+	Markdown string
+	// HTML is the sanitized page HTML; only set for generic HTML pages when
+	// ReadRequest.IncludeHTML is set
+	HTML string
+	// Truncated is set when the page was cut at Options.ReadMaxBytes
+	Truncated bool
+}
 
-	return fetchGenericHTMLAsMarkdown(ctx, client, parsedURL.String())
+// Read fetches a page and converts it to Markdown like the searxng_read
+// tool, honoring the domain lists, robots.txt, the read rate limit and
+// cache and the proxy Options
+func (s *Server) Read(ctx context.Context, req ReadRequest) (*Page, error) {
+	opts := s.defaultReadOptions()
+	opts.Mode = req.Mode
+	if req.Boilerplate != "" {
+		opts.Boilerplate = req.Boilerplate
+	}
+	opts.IncludeHTML = req.IncludeHTML
+	if err := s.checkRead(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	page, err := s.fetchPage(ctx, req.URL, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Markdown: page.Markdown, HTML: page.HTML, Truncated: page.Truncated}, nil
 }
 
 func validateURL(urlStr string) (*url.URL, error) {
@@ -62,16 +365,65 @@ func validateURL(urlStr string) (*url.URL, error) {
 }
 
 func newHTTPClient() *http.Client {
+	return pageClient(nil, nil)
+}
+
+// pageClientKey identifies the page client of a transport and proxy
+type pageClientKey struct {
+	transport *http.Transport
+	proxy     string
+}
+
+// pageClients holds one client per transport and proxy, so page fetches
+// reuse clients and the connections of their transports. Proxies only come
+// from the operator (Options.ProxyURL and Options.ProxyAllowlist), which
+// bounds the clients; the idle release closes their idle connections.
+var pageClients sync.Map // pageClientKey -> *http.Client
+
+// pageClient returns the client for fetching pages through transport (nil:
+// http.DefaultTransport) and proxy. Proxies get a copy of the transport.
+func pageClient(transport *http.Transport, proxy *url.URL) *http.Client {
+	key := pageClientKey{transport: transport}
+	if proxy != nil {
+		key.proxy = proxy.String()
+	}
+	if client, ok := pageClients.Load(key); ok {
+		return client.(*http.Client)
+	}
+
 	client := &http.Client{
-		Timeout: defaultHTTPTimeout,
+		Timeout:       defaultHTTPTimeout,
+		CheckRedirect: checkRedirect,
 	}
-	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		if len(via) >= maxHTTPRedirectCount {
-			return fmt.Errorf("too many redirects")
+	switch {
+	case proxy != nil:
+		base := transport
+		if base == nil {
+			var isTransport bool
+			if base, isTransport = http.DefaultTransport.(*http.Transport); !isTransport {
+				base = &http.Transport{ForceAttemptHTTP2: true}
+			}
 		}
-		return nil
+		clone := base.Clone()
+		clone.Proxy = http.ProxyURL(proxy)
+		client.Transport = clone
+	case transport != nil:
+		client.Transport = transport
 	}
-	return client
+	actual, _ := pageClients.LoadOrStore(key, client)
+	return actual.(*http.Client)
+}
+
+// client returns the page client of the read through proxy, with the
+// read's Timeout
+func (o readOptions) client(proxy *url.URL) *http.Client {
+	client := pageClient(o.Transport, proxy)
+	if o.Timeout <= 0 {
+		return client
+	}
+	withTimeout := *client
+	withTimeout.Timeout = o.Timeout
+	return &withTimeout
 }
 
 func newRequest(ctx context.Context, urlStr, accept string) (*http.Request, error) {
@@ -89,44 +441,156 @@ func newRequest(ctx context.Context, urlStr, accept string) (*http.Request, erro
 	return req, nil
 }
 
-func fetchGenericHTMLAsMarkdown(ctx context.Context, client *http.Client, urlStr string) (string, error) {
-	req, err := newRequest(ctx, urlStr, defaultAccept)
+// fetchGenericHTML fetches a page and converts it to Markdown according to
+// opts.Mode. With opts.IncludeHTML, the sanitized HTML is returned as well.
+func fetchGenericHTML(ctx context.Context, client *http.Client, urlStr string, opts readOptions) (*readResult, error) {
+	resp, variant, err := getPage(ctx, client, urlStr, opts)
 	if err != nil {
-		return "", err
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("HTTP request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, &statusError{code: resp.StatusCode, status: resp.Status, retried: variant != ""}
 	}
 
+	fresh := parseFreshness(resp.Header, time.Now())
 	contentType := resp.Header.Get("Content-Type")
-	if !strings.Contains(contentType, "text/html") && !strings.Contains(contentType, "application/xhtml") {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return "", fmt.Errorf("failed to read response body: %w", err)
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultReadMaxBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	truncated := int64(len(body)) > maxBytes
+	if truncated {
+		body = body[:maxBytes]
+		log.WithFields(logrus.Fields{"url": urlStr, "max_bytes": maxBytes}).Debug("page exceeds the download limit, cutting it")
+	}
+	media := mediaType(contentType)
+	if media != "text/html" && media != "application/xhtml+xml" {
+		if isPDF(contentType, body) {
+			if truncated {
+				// A cut PDF loses its cross-reference table
+				return nil, fmt.Errorf("PDF exceeds the maximum download size of %d bytes", maxBytes)
+			}
+			markdown, err := pdfToMarkdown(body)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract PDF text: %w", err)
+			}
+			return &readResult{Markdown: markdown, Freshness: fresh, FetchVariant: variant, ContentType: "application/pdf"}, nil
+		}
+		if !isTextual(media, body) {
+			return nil, fmt.Errorf("unsupported content type %s (only HTML, PDF and text pages can be read)", media)
+		}
+		if strings.HasPrefix(media, "text/") {
+			body = toUTF8(body, contentType)
 		}
-		return string(body), nil
+		language := codeLanguage(media, body)
+		return &readResult{
+			Markdown:     textToMarkdown(body, language),
+			Freshness:    fresh,
+			FetchVariant: variant,
+			Truncated:    truncated,
+			ContentType:  media,
+			CodeLanguage: language,
+		}, nil
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(toUTF8(body, contentType)))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML: %w", err)
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
-	doc.Find("script, style, nav, footer, header, aside").Each(func(i int, s *goquery.Selection) {
+
+	result := &readResult{Freshness: fresh, FetchVariant: variant, StructuredData: extractStructuredData(doc), Truncated: truncated, ContentType: media}
+	sanitizeHTML(doc)
+	if opts.IncludeHTML {
+		if result.HTML, err = doc.Html(); err != nil {
+			return nil, fmt.Errorf("failed to serialize HTML: %w", err)
+		}
+	}
+
+	if opts.Mode == ReadModeArticle {
+		if a := extractArticle(doc); a != nil {
+			if result.Markdown, err = articleMarkdown(a); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
+		log.WithField("url", urlStr).Debug("no article found, converting the full page")
+	}
+
+	doc.Find("style, nav, footer, header, aside").Each(func(i int, s *goquery.Selection) {
 		s.Remove()
 	})
 
 	html, err := doc.Html()
 	if err != nil {
-		return "", fmt.Errorf("failed to serialize HTML: %w", err)
+		return nil, fmt.Errorf("failed to serialize HTML: %w", err)
+	}
+
+	markdown, err := htmlToMarkdown(html)
+	if err != nil {
+		return nil, err
 	}
 
+	result.Markdown = cleanMarkdown(markdown)
+	return result, nil
+}
+
+// getPage requests a generic page with browser headers. A 403 gets one retry
+// with minimalUserAgent and minimal headers, through opts.RetryProxy when
+// set; variant names that retry and is empty when there was none.
+func getPage(ctx context.Context, client *http.Client, urlStr string, opts readOptions) (resp *http.Response, variant string, err error) {
+	req, err := newRequest(ctx, urlStr, defaultAccept)
+	if err != nil {
+		return nil, "", err
+	}
+	cached := opts.DiskCache.load(urlStr)
+	if cached != nil {
+		if now := time.Now(); cached.fresh(now) {
+			log.WithField("url", urlStr).Debug("page served from the disk cache")
+			return cached.response(req, now), "", nil
+		}
+		cached.addValidators(req)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		log.WithField("url", urlStr).Debug("page revalidated from the disk cache")
+		return opts.DiskCache.revalidated(cached, resp.Header).response(req, time.Now()), "", nil
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		return opts.DiskCache.store(urlStr, resp), "", nil
+	}
+	resp.Body.Close()
+
+	variant = fetchVariantMinimal
+	if opts.RetryProxy != nil {
+		client, variant = opts.client(opts.RetryProxy), fetchVariantMinimalProxy
+	}
+	log.WithFields(logrus.Fields{"url": urlStr, "variant": variant}).Debug("HTTP 403, retrying")
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", minimalUserAgent)
+	req.Header.Set("Accept", "*/*")
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("HTTP request failed after 403: %w", err)
+	}
+	return resp, variant, nil
+}
+
+// htmlToMarkdown converts an HTML document or fragment to CommonMark
+func htmlToMarkdown(html string) (string, error) {
 	conv := converter.NewConverter(
 		converter.WithPlugins(
 			base.NewBasePlugin(),
@@ -137,8 +601,33 @@ func fetchGenericHTMLAsMarkdown(ctx context.Context, client *http.Client, urlStr
 	if err != nil {
 		return "", fmt.Errorf("failed to convert to Markdown: %w", err)
 	}
+	return markdown, nil
+}
 
-	return cleanMarkdown(markdown), nil
+// sanitizeHTML removes executable and embedded content from doc: scripts,
+// frames, plugin objects, inline event handlers and URLs with a dangerous
+// scheme (see dangerousSchemes), so links to them are converted to plain
+// text. Structure and other attributes are kept for structured extraction.
+func sanitizeHTML(doc *goquery.Document) {
+	doc.Find("script, noscript, iframe, frame, object, embed, template").Remove()
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		node := s.Get(0)
+		attrs := node.Attr[:0]
+		for _, attr := range node.Attr {
+			if strings.HasPrefix(strings.ToLower(attr.Key), "on") {
+				continue
+			}
+			scheme := urlScheme(attr.Val)
+			if scheme == "javascript" || scheme == "vbscript" {
+				continue
+			}
+			if urlAttributes[strings.ToLower(attr.Key)] && slices.Contains(dangerousSchemes, scheme) {
+				continue
+			}
+			attrs = append(attrs, attr)
+		}
+		node.Attr = attrs
+	})
 }
 
 func pathSegments(path string) []string {
@@ -187,3 +676,51 @@ func cleanMarkdown(markdown string) string {
 
 	return strings.Join(cleaned, "\n")
 }
+
+// readMetadata is the structured output of searxng_read
+type readMetadata struct {
+	*readChunk                     // set when offset or max_length is given
+	Freshness      *freshness      `json:"freshness,omitempty"`
+	FetchVariant   string          `json:"fetch_variant,omitempty"`
+	StructuredData *structuredData `json:"structured_data,omitempty"`
+	// Truncated is set when the page was cut at the download limit or at
+	// Options.ReadMaxLength
+	Truncated bool `json:"truncated,omitempty"`
+	// Mirror is the Options.ReadMirrors URL the page was read through
+	Mirror string `json:"mirror,omitempty"`
+	// ContentType is the media type of the page, e.g. application/json
+	ContentType string `json:"content_type,omitempty"`
+	// Range is the part of the resource returned for byte_range or
+	// line_range
+	Range *contentRange `json:"range,omitempty"`
+}
+
+// readChunk describes the part of a page returned by a paginated read.
+// Offsets and lengths count characters (runes), not bytes.
+type readChunk struct {
+	Offset      int  `json:"offset"`
+	Length      int  `json:"length"`
+	TotalLength int  `json:"total_length"`
+	NextOffset  *int `json:"next_offset,omitempty"` // nil on the last chunk
+}
+
+// paginateContent returns up to maxLength characters of content starting at
+// offset (maxLength <= 0: everything after offset)
+func paginateContent(content string, offset, maxLength int) (string, readChunk, error) {
+	runes := []rune(content)
+	total := len(runes)
+	if offset < 0 || (offset > 0 && offset >= total) {
+		return "", readChunk{}, fmt.Errorf("offset %d is out of range (content has %d characters)", offset, total)
+	}
+
+	end := total
+	if maxLength > 0 {
+		end = min(offset+maxLength, total)
+	}
+
+	chunk := readChunk{Offset: offset, Length: end - offset, TotalLength: total}
+	if end < total {
+		chunk.NextOffset = &end
+	}
+	return string(runes[offset:end]), chunk, nil
+}
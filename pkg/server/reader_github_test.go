@@ -59,7 +59,7 @@ func TestFetchURLContent_GitHubIssueMarkdown(t *testing.T) {
 		Reply(200).
 		JSON(loadJSONFixture(t, "github_issue_22368_comments.json"))
 
-	markdown, err := fetchURLContent(context.Background(), "https://github.com/kubernetes/kubernetes/issues/22368")
+	markdown, err := fetchURLContent(context.Background(), "https://github.com/kubernetes/kubernetes/issues/22368", readOptions{})
 	require.NoError(t, err)
 	assert.Contains(t, markdown, "# kubernetes/kubernetes #22368: Feature request: example issue")
 	assert.Contains(t, markdown, "## Comments (2)")
@@ -93,7 +93,7 @@ func TestFetchURLContent_GitHubRepoMarkdown(t *testing.T) {
 		Reply(200).
 		BodyString("# searxng-mcp\n\nA test README.")
 
-	markdown, err := fetchURLContent(context.Background(), "https://github.com/denysvitali/searxng-mcp")
+	markdown, err := fetchURLContent(context.Background(), "https://github.com/denysvitali/searxng-mcp", readOptions{})
 	require.NoError(t, err)
 	assert.Contains(t, markdown, "# denysvitali/searxng-mcp")
 	assert.Contains(t, markdown, "MCP server for Searxng")
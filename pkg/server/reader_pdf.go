@@ -0,0 +1,1050 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// maxPDFStreamSize bounds the decoded size of a single PDF stream
+	maxPDFStreamSize = 64 << 20
+	// maxPDFFormDepth bounds the nesting of form XObjects drawn by a page
+	maxPDFFormDepth = 8
+	// pdfSpaceAdjustment is the TJ adjustment, in thousandths of an em,
+	// beyond which a gap between two strings is read as a word space
+	pdfSpaceAdjustment = 200
+)
+
+// errPDFEncrypted is returned for encrypted documents, whose streams can't be
+// read without decrypting them
+var errPDFEncrypted = errors.New("encrypted PDFs are not supported")
+
+var (
+	// pdfObjectHeader matches the "12 0 obj" header of an indirect object
+	pdfObjectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	// pdfTrailer matches the start of a classic trailer dictionary
+	pdfTrailer = regexp.MustCompile(`trailer\s*<<`)
+)
+
+type (
+	pdfName    string
+	pdfKeyword string // operators in content streams, and true/false/null
+	pdfString  []byte
+	pdfDict    map[pdfName]interface{}
+	pdfRef     struct{ Num, Gen int }
+	pdfStream  struct {
+		Dict pdfDict
+		Data []byte // still encoded, see pdfDocument.decodeStream
+	}
+)
+
+// isPDF reports whether a response is a PDF document, by content type or
+// by its "%PDF-" signature for servers sending a generic content type
+func isPDF(contentType string, body []byte) bool {
+	return strings.Contains(contentType, "application/pdf") || bytes.HasPrefix(body, []byte("%PDF-"))
+}
+
+// pdfToMarkdown extracts the text of a PDF document, page by page, as
+// Markdown: the document title as a heading, then a "## Page N" heading
+// before the text of each page with any.
+func pdfToMarkdown(data []byte) (string, error) {
+	doc, err := parsePDF(data)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if info, ok := doc.resolve(doc.trailer["Info"]).(pdfDict); ok {
+		if title, ok := doc.resolve(info["Title"]).(pdfString); ok {
+			if title := strings.TrimSpace(decodePDFTextString(title)); title != "" {
+				fmt.Fprintf(&b, "# %s\n\n", title)
+			}
+		}
+	}
+
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return "", errors.New("PDF has no pages")
+	}
+	found := false
+	for i, page := range pages {
+		text := doc.pageText(page)
+		if text == "" {
+			continue
+		}
+		found = true
+		fmt.Fprintf(&b, "## Page %d\n\n%s\n\n", i+1, text)
+	}
+	if !found {
+		return "", errors.New("PDF has no extractable text (it may be a scanned document)")
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// pdfDocument holds the indirect objects of a PDF file
+type pdfDocument struct {
+	objects map[int]interface{}
+	trailer pdfDict
+}
+
+// parsePDF scans data for indirect objects rather than following the cross
+// reference table, so documents with a broken or missing one still parse.
+// Objects packed into object streams are unpacked as well.
+func parsePDF(data []byte) (*pdfDocument, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return nil, errors.New("not a PDF document")
+	}
+	doc := &pdfDocument{objects: map[int]interface{}{}, trailer: pdfDict{}}
+
+	end := 0
+	for _, m := range pdfObjectHeader.FindAllSubmatchIndex(data, -1) {
+		if m[0] < end {
+			continue // inside the previous object, e.g. in stream data
+		}
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		lex := &pdfLexer{data: data, pos: m[1]}
+		obj, err := lex.object()
+		if err != nil || obj == pdfKeyword("endobj") {
+			continue
+		}
+		if dict, ok := obj.(pdfDict); ok {
+			if stream, ok := lex.stream(dict); ok {
+				obj = stream
+				if stream.Dict["Type"] == pdfName("XRef") {
+					mergePDFTrailer(doc.trailer, stream.Dict)
+				}
+			}
+		}
+		doc.objects[num] = obj
+		end = lex.pos
+	}
+	for _, m := range pdfTrailer.FindAllIndex(data, -1) {
+		lex := &pdfLexer{data: data, pos: m[1] - 2}
+		if dict, ok := mustPDFObject(lex).(pdfDict); ok {
+			mergePDFTrailer(doc.trailer, dict)
+		}
+	}
+	if _, ok := doc.trailer["Encrypt"]; ok {
+		return nil, errPDFEncrypted
+	}
+
+	for _, obj := range doc.objects {
+		if stream, ok := obj.(*pdfStream); ok && stream.Dict["Type"] == pdfName("ObjStm") {
+			// An invalid object stream only loses its objects
+			_ = doc.unpackObjectStream(stream)
+		}
+	}
+	return doc, nil
+}
+
+// mergePDFTrailer copies the entries of a trailer into merged. Incremental
+// updates append trailers, so later entries win.
+func mergePDFTrailer(merged, trailer pdfDict) {
+	for _, key := range []pdfName{"Root", "Info", "Encrypt"} {
+		if v, ok := trailer[key]; ok {
+			merged[key] = v
+		}
+	}
+}
+
+// unpackObjectStream adds the objects compressed into an object stream.
+// The stream comes from the fetched document, so its header is checked
+// against the data before slicing it.
+func (d *pdfDocument) unpackObjectStream(stream *pdfStream) error {
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return err
+	}
+	n, _ := pdfInt(stream.Dict["N"])
+	first, _ := pdfInt(stream.Dict["First"])
+	if first < 0 || first > len(data) {
+		return fmt.Errorf("object stream /First %d outside its %d bytes", first, len(data))
+	}
+	header := &pdfLexer{data: data[:first]}
+	for range n {
+		num, ok1 := pdfInt(mustPDFObject(header))
+		offset, ok2 := pdfInt(mustPDFObject(header))
+		if !ok1 || !ok2 {
+			return errors.New("truncated object stream header")
+		}
+		if offset < 0 || offset >= len(data)-first {
+			return fmt.Errorf("object stream offset %d outside its %d bytes", offset, len(data)-first)
+		}
+		if _, defined := d.objects[num]; defined {
+			continue
+		}
+		lex := &pdfLexer{data: data, pos: first + offset}
+		if obj, err := lex.object(); err == nil {
+			d.objects[num] = obj
+		}
+	}
+	return nil
+}
+
+// resolve follows references, returning nil for missing objects
+func (d *pdfDocument) resolve(obj interface{}) interface{} {
+	for range 32 {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[ref.Num]
+	}
+	return nil
+}
+
+// dict resolves obj to a dictionary, that of a stream included
+func (d *pdfDocument) dict(obj interface{}) pdfDict {
+	switch v := d.resolve(obj).(type) {
+	case pdfDict:
+		return v
+	case *pdfStream:
+		return v.Dict
+	}
+	return nil
+}
+
+// pdfPage is a page with the resources it inherits from the page tree
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages lists the pages in document order by walking the page tree. When
+// there is no usable tree, the page objects are listed by object number.
+func (d *pdfDocument) pages() []pdfPage {
+	var pages []pdfPage
+	seen := map[int]bool{}
+	var walk func(node interface{}, resources pdfDict)
+	walk = func(node interface{}, resources pdfDict) {
+		if ref, ok := node.(pdfRef); ok {
+			if seen[ref.Num] {
+				return
+			}
+			seen[ref.Num] = true
+		}
+		dict := d.dict(node)
+		if dict == nil {
+			return
+		}
+		if r := d.dict(dict["Resources"]); r != nil {
+			resources = r
+		}
+		if kids, ok := d.resolve(dict["Kids"]).([]interface{}); ok {
+			for _, kid := range kids {
+				walk(kid, resources)
+			}
+			return
+		}
+		if dict["Type"] == pdfName("Page") || dict["Contents"] != nil {
+			pages = append(pages, pdfPage{dict: dict, resources: resources})
+		}
+	}
+	if root := d.dict(d.trailer["Root"]); root != nil {
+		walk(root["Pages"], nil)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	nums := make([]int, 0, len(d.objects))
+	for num := range d.objects {
+		nums = append(nums, num)
+	}
+	slices.Sort(nums)
+	for _, num := range nums {
+		if dict, ok := d.objects[num].(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: dict, resources: d.dict(dict["Resources"])})
+		}
+	}
+	return pages
+}
+
+// pageText returns the text drawn by a page's content streams
+func (d *pdfDocument) pageText(page pdfPage) string {
+	var content []byte
+	contents := d.resolve(page.dict["Contents"])
+	if parts, ok := contents.([]interface{}); ok {
+		for _, part := range parts {
+			if stream, ok := d.resolve(part).(*pdfStream); ok {
+				if data, err := d.decodeStream(stream); err == nil {
+					content = append(append(content, data...), '\n')
+				}
+			}
+		}
+	} else if stream, ok := contents.(*pdfStream); ok {
+		content, _ = d.decodeStream(stream)
+	}
+
+	t := &pdfTextWriter{doc: d}
+	t.run(content, page.resources, 0)
+	return t.text()
+}
+
+// decodeStream applies the filters of a stream. Only the filters used for
+// text and structure are supported; image filters fail.
+func (d *pdfDocument) decodeStream(stream *pdfStream) ([]byte, error) {
+	var filters []interface{}
+	switch f := d.resolve(stream.Dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{f}
+	case []interface{}:
+		filters = f
+	}
+
+	data := stream.Data
+	for _, filter := range filters {
+		var err error
+		switch d.resolve(filter) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			data, err = inflatePDF(data)
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			data, err = decodePDFHex(data)
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data, err = decodePDFASCII85(data)
+		default:
+			err = fmt.Errorf("unsupported PDF filter %v", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflatePDF decompresses FlateDecode data, tolerating a missing zlib
+// header and a truncated end
+func inflatePDF(data []byte) ([]byte, error) {
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	out, err := io.ReadAll(io.LimitReader(r, maxPDFStreamSize))
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("failed to inflate PDF stream: %w", err)
+	}
+	return out, nil
+}
+
+func decodePDFHex(data []byte) ([]byte, error) {
+	digits := make([]byte, 0, len(data))
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	return hex.DecodeString(string(digits))
+}
+
+func decodePDFASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, 4*len(data)+4) // 'z' expands to four bytes
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ASCII85 PDF stream: %w", err)
+	}
+	return out[:n], nil
+}
+
+// pdfTextWriter interprets content streams, collecting the text they draw
+type pdfTextWriter struct {
+	doc   *pdfDocument
+	b     strings.Builder
+	fonts map[pdfRef]*pdfFont // fonts loaded from indirect objects
+	lastY float64
+}
+
+// run interprets a content stream drawn with the given resources. Form
+// XObjects are drawn recursively, up to maxPDFFormDepth deep.
+func (t *pdfTextWriter) run(content []byte, resources pdfDict, depth int) {
+	lex := &pdfLexer{data: content, content: true}
+	var operands []interface{}
+	var font *pdfFont
+	for {
+		obj, err := lex.object()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok || op == "true" || op == "false" || op == "null" {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "BI":
+			lex.skipInlineImage()
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					font = t.font(resources, name)
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if y, ok := pdfFloat(operands[len(operands)-1]); ok && y != 0 {
+					t.newline()
+				} else {
+					t.space()
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				y, _ := pdfFloat(operands[len(operands)-1])
+				if y != t.lastY {
+					t.newline()
+				} else {
+					t.space()
+				}
+				t.lastY = y
+			}
+		case "T*":
+			t.newline()
+		case "ET":
+			t.space()
+		case "Tj", "'", "\"":
+			if op != "Tj" {
+				t.newline()
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					t.b.WriteString(font.decode(s))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				items, _ := operands[len(operands)-1].([]interface{})
+				for _, item := range items {
+					switch v := item.(type) {
+					case pdfString:
+						t.b.WriteString(font.decode(v))
+					default:
+						if adj, ok := pdfFloat(v); ok && adj < -pdfSpaceAdjustment {
+							t.space()
+						}
+					}
+				}
+			}
+		case "Do":
+			if len(operands) > 0 && depth < maxPDFFormDepth {
+				if name, ok := operands[len(operands)-1].(pdfName); ok {
+					t.drawForm(resources, name, depth)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// drawForm interprets the form XObject named name in resources
+func (t *pdfTextWriter) drawForm(resources pdfDict, name pdfName, depth int) {
+	xobjects := t.doc.dict(resources["XObject"])
+	stream, ok := t.doc.resolve(xobjects[name]).(*pdfStream)
+	if !ok || stream.Dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	data, err := t.doc.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	formResources := resources
+	if r := t.doc.dict(stream.Dict["Resources"]); r != nil {
+		formResources = r
+	}
+	t.run(data, formResources, depth+1)
+	t.newline()
+}
+
+// font returns the font named name in resources, loading fonts stored in
+// indirect objects once
+func (t *pdfTextWriter) font(resources pdfDict, name pdfName) *pdfFont {
+	entry := t.doc.dict(resources["Font"])[name]
+	ref, isRef := entry.(pdfRef)
+	if font, ok := t.fonts[ref]; isRef && ok {
+		return font
+	}
+	dict := t.doc.dict(entry)
+	if dict == nil {
+		return nil
+	}
+	font := loadPDFFont(t.doc, dict)
+	if isRef {
+		if t.fonts == nil {
+			t.fonts = map[pdfRef]*pdfFont{}
+		}
+		t.fonts[ref] = font
+	}
+	return font
+}
+
+func (t *pdfTextWriter) space() {
+	s := t.b.String()
+	if s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		t.b.WriteByte(' ')
+	}
+}
+
+func (t *pdfTextWriter) newline() {
+	if t.b.Len() > 0 && !strings.HasSuffix(t.b.String(), "\n") {
+		t.b.WriteByte('\n')
+	}
+}
+
+// text returns the collected lines, trimmed, with runs of blank lines
+// collapsed
+func (t *pdfTextWriter) text() string {
+	var lines []string
+	for _, line := range strings.Split(t.b.String(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// pdfFont maps the character codes of a font to text
+type pdfFont struct {
+	codeLength int               // bytes per character code
+	toUnicode  map[string]string // from the ToUnicode CMap, keyed by code
+	encoding   [256]rune         // for simple fonts without ToUnicode
+}
+
+func loadPDFFont(doc *pdfDocument, dict pdfDict) *pdfFont {
+	font := &pdfFont{codeLength: 1, encoding: winAnsiEncoding()}
+	if dict["Subtype"] == pdfName("Type0") {
+		font.codeLength = 2
+	}
+	if stream, ok := doc.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := doc.decodeStream(stream); err == nil {
+			font.parseCMap(data)
+		}
+	}
+	if enc := doc.dict(dict["Encoding"]); enc != nil {
+		if differences, ok := doc.resolve(enc["Differences"]).([]interface{}); ok {
+			code := 0
+			for _, item := range differences {
+				if n, ok := pdfInt(item); ok {
+					code = n
+					continue
+				}
+				if name, ok := item.(pdfName); ok && code >= 0 && code < 256 {
+					if r := glyphRune(string(name)); r != 0 {
+						font.encoding[code] = r
+					}
+					code++
+				}
+			}
+		}
+	}
+	return font
+}
+
+// parseCMap reads the code space and bfchar/bfrange mappings of a ToUnicode
+// CMap
+func (f *pdfFont) parseCMap(data []byte) {
+	f.toUnicode = map[string]string{}
+	lex := &pdfLexer{data: data, content: true}
+	var operands []interface{}
+	for {
+		obj, err := lex.object()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if lo, ok := operands[0].(pdfString); ok && len(lo) > 0 {
+					f.codeLength = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					f.toUnicode[string(src)] = decodeUTF16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				f.addRange(operands[i], operands[i+1], operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// addRange adds a bfrange mapping: consecutive codes map either to
+// consecutive code points or to the strings of an array
+func (f *pdfFont) addRange(loObj, hiObj, dst interface{}) {
+	lo, ok1 := loObj.(pdfString)
+	hi, ok2 := hiObj.(pdfString)
+	if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+		return
+	}
+	start, end := pdfCode(lo), pdfCode(hi)
+	if end < start || end-start > 0xFFFF {
+		return
+	}
+	for code := start; code <= end; code++ {
+		key := make([]byte, len(lo))
+		for i, c := len(key)-1, code; i >= 0; i, c = i-1, c>>8 {
+			key[i] = byte(c)
+		}
+		switch v := dst.(type) {
+		case pdfString:
+			text := []rune(decodeUTF16BE(v))
+			if len(text) == 0 {
+				return
+			}
+			text[len(text)-1] += rune(code - start)
+			f.toUnicode[string(key)] = string(text)
+		case []interface{}:
+			if i := code - start; i < len(v) {
+				if s, ok := v[i].(pdfString); ok {
+					f.toUnicode[string(key)] = decodeUTF16BE(s)
+				}
+			}
+		}
+	}
+}
+
+// decode converts a shown string to text. A nil font (no Tf yet) is read
+// as WinAnsi; two-byte codes without a ToUnicode map can't be read.
+func (f *pdfFont) decode(s pdfString) string {
+	if f == nil {
+		f = defaultPDFFont
+	}
+	var b strings.Builder
+	if f.toUnicode != nil {
+		for i := 0; i+f.codeLength <= len(s); i += f.codeLength {
+			b.WriteString(f.toUnicode[string(s[i:i+f.codeLength])])
+		}
+		return b.String()
+	}
+	if f.codeLength != 1 {
+		return ""
+	}
+	for _, c := range s {
+		if r := f.encoding[c]; r != 0 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func pdfCode(s pdfString) int {
+	code := 0
+	for _, c := range s {
+		code = code<<8 | int(c)
+	}
+	return code
+}
+
+// defaultPDFFont decodes strings shown before any font is selected
+var defaultPDFFont = &pdfFont{codeLength: 1, encoding: winAnsiEncoding()}
+
+// winAnsiEncoding returns the WinAnsiEncoding table: Latin-1 with the
+// Windows-1252 characters in 0x80-0x9F
+func winAnsiEncoding() [256]rune {
+	var enc [256]rune
+	for c := 0x20; c < 256; c++ {
+		enc[c] = rune(c)
+	}
+	enc['\t'], enc['\n'], enc['\r'] = ' ', ' ', ' '
+	for i, r := range []rune("€\x00‚ƒ„…†‡ˆ‰Š‹Œ\x00Ž\x00\x00‘’“”•–—˜™š›œ\x00žŸ") {
+		enc[0x80+i] = r
+	}
+	enc[0xA0], enc[0xAD] = ' ', 0
+	return enc
+}
+
+// pdfGlyphNames maps glyph names of /Differences arrays that aren't a single
+// character or uniXXXX
+var pdfGlyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "parenleft": '(',
+	"parenright": ')', "asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-',
+	"period": '.', "slash": '/', "zero": '0', "one": '1', "two": '2', "three": '3',
+	"four": '4', "five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+	"colon": ':', "semicolon": ';', "less": '<', "equal": '=', "greater": '>',
+	"question": '?', "at": '@', "bracketleft": '[', "backslash": '\\',
+	"bracketright": ']', "underscore": '_', "braceleft": '{', "bar": '|',
+	"braceright": '}', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+	"quotedblright": '”', "endash": '–', "emdash": '—', "bullet": '•',
+	"ellipsis": '…', "fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ',
+	"copyright": '©', "registered": '®', "trademark": '™', "degree": '°',
+	"eacute": 'é', "egrave": 'è', "agrave": 'à', "udieresis": 'ü', "odieresis": 'ö',
+	"adieresis": 'ä', "germandbls": 'ß', "ccedilla": 'ç',
+}
+
+// glyphRune returns the character of a glyph name, or 0 when unknown
+func glyphRune(name string) rune {
+	if r, ok := pdfGlyphNames[name]; ok {
+		return r
+	}
+	if runes := []rune(name); len(runes) == 1 {
+		return runes[0]
+	}
+	if hexCode, ok := strings.CutPrefix(name, "uni"); ok && len(hexCode) == 4 {
+		if n, err := strconv.ParseUint(hexCode, 16, 16); err == nil {
+			return rune(n)
+		}
+	}
+	return 0
+}
+
+// decodeUTF16BE decodes the UTF-16BE strings of ToUnicode CMaps
+func decodeUTF16BE(s pdfString) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodePDFTextString decodes document strings such as the title: UTF-16BE
+// with a byte order mark, UTF-8 with one, or else PDFDocEncoding (read as
+// Latin-1)
+func decodePDFTextString(s pdfString) string {
+	switch {
+	case bytes.HasPrefix(s, []byte{0xFE, 0xFF}):
+		return decodeUTF16BE(s[2:])
+	case bytes.HasPrefix(s, []byte{0xEF, 0xBB, 0xBF}):
+		return string(s[3:])
+	}
+	runes := make([]rune, len(s))
+	for i, c := range s {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func pdfInt(obj interface{}) (int, bool) {
+	f, ok := pdfFloat(obj)
+	return int(f), ok
+}
+
+func pdfFloat(obj interface{}) (float64, bool) {
+	f, ok := obj.(float64)
+	return f, ok
+}
+
+// pdfLexer reads PDF objects. In content streams (content set), bare words
+// are operators and "n g R" isn't read as a reference.
+type pdfLexer struct {
+	data    []byte
+	pos     int
+	content bool
+}
+
+// errPDFEnd is returned at the end of the data and for closing delimiters
+// without an opening one
+var errPDFEnd = errors.New("unexpected end of PDF object")
+
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// mustPDFObject reads an object, returning nil on errors
+func mustPDFObject(l *pdfLexer) interface{} {
+	obj, _ := l.object()
+	return obj
+}
+
+// object reads the next object
+func (l *pdfLexer) object() (interface{}, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, errPDFEnd
+	}
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		return l.name(), nil
+	case c == '(':
+		return l.literalString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		return l.dict()
+	case c == '<':
+		return l.hexString(), nil
+	case c == '[':
+		return l.array()
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		if c == '>' && l.pos < len(l.data) && l.data[l.pos] == '>' {
+			l.pos++
+		}
+		if l.content && (c == '{' || c == '}') {
+			return l.object() // PostScript calculator braces
+		}
+		return nil, errPDFEnd
+	}
+
+	word := l.word()
+	n, err := strconv.ParseFloat(word, 64)
+	if err != nil {
+		if word == "" {
+			l.pos++
+			return l.object()
+		}
+		return pdfKeyword(word), nil
+	}
+	if !l.content && !strings.ContainsAny(word, ".+-") {
+		if ref, ok := l.reference(int(n)); ok {
+			return ref, nil
+		}
+	}
+	return n, nil
+}
+
+// reference reads the "g R" following num when present
+func (l *pdfLexer) reference(num int) (pdfRef, bool) {
+	start := l.pos
+	l.skipSpace()
+	gen, err := strconv.Atoi(l.word())
+	if err == nil {
+		l.skipSpace()
+		if l.word() == "R" {
+			return pdfRef{Num: num, Gen: gen}, true
+		}
+	}
+	l.pos = start
+	return pdfRef{}, false
+}
+
+// word reads regular characters up to the next space or delimiter
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) name() pdfName {
+	l.pos++ // '/'
+	word := l.word()
+	if !strings.Contains(word, "#") {
+		return pdfName(word)
+	}
+	var b strings.Builder
+	for i := 0; i < len(word); i++ {
+		if word[i] == '#' && i+2 < len(word) {
+			if c, err := strconv.ParseUint(word[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(word[i])
+	}
+	return pdfName(b.String())
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // '('
+	var s []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return s
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return s
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					n := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				}
+			}
+		}
+		s = append(s, c)
+	}
+	return s
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++ // '<'
+	end := bytes.IndexByte(l.data[l.pos:], '>')
+	if end < 0 {
+		end = len(l.data) - l.pos
+	}
+	s, _ := decodePDFHex(l.data[l.pos : l.pos+end])
+	l.pos += end + 1
+	return s
+}
+
+func (l *pdfLexer) array() ([]interface{}, error) {
+	l.pos++ // '['
+	items := []interface{}{}
+	for {
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == ']' {
+			l.pos++
+			return items, nil
+		}
+		item, err := l.object()
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+}
+
+func (l *pdfLexer) dict() (pdfDict, error) {
+	l.pos += 2 // "<<"
+	dict := pdfDict{}
+	for {
+		l.skipSpace()
+		if l.pos+1 < len(l.data) && l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return dict, nil
+		}
+		key, err := l.object()
+		if err != nil {
+			return dict, err
+		}
+		value, err := l.object()
+		if err != nil {
+			return dict, err
+		}
+		if name, ok := key.(pdfName); ok {
+			dict[name] = value
+		}
+	}
+}
+
+// stream reads the stream data following dict, if any. A direct /Length is
+// trusted when "endstream" follows it; otherwise the data runs up to the
+// next "endstream".
+func (l *pdfLexer) stream(dict pdfDict) (*pdfStream, bool) {
+	l.skipSpace()
+	if !bytes.HasPrefix(l.data[l.pos:], []byte("stream")) {
+		return nil, false
+	}
+	start := l.pos + len("stream")
+	if start < len(l.data) && l.data[start] == '\r' {
+		start++
+	}
+	if start < len(l.data) && l.data[start] == '\n' {
+		start++
+	}
+
+	if length, ok := pdfInt(dict["Length"]); ok && length >= 0 && length <= len(l.data)-start {
+		rest := bytes.TrimLeft(l.data[start+length:], " \t\r\n")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			l.pos = len(l.data) - len(rest) + len("endstream")
+			return &pdfStream{Dict: dict, Data: l.data[start : start+length]}, true
+		}
+	}
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		l.pos = len(l.data)
+		return &pdfStream{Dict: dict, Data: l.data[start:]}, true
+	}
+	l.pos = start + end + len("endstream")
+	data := bytes.TrimSuffix(l.data[start:start+end], []byte("\n"))
+	return &pdfStream{Dict: dict, Data: bytes.TrimSuffix(data, []byte("\r"))}, true
+}
+
+// skipInlineImage skips the data of an inline image, up to its EI operator
+func (l *pdfLexer) skipInlineImage() {
+	i := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if i < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += i + 3
+	for l.pos < len(l.data) {
+		i := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if i < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		l.pos += i + 2
+		if isPDFSpace(l.data[l.pos-3]) && (l.pos >= len(l.data) || isPDFSpace(l.data[l.pos]) || isPDFDelimiter(l.data[l.pos])) {
+			return
+		}
+	}
+}
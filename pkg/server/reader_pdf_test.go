@@ -0,0 +1,192 @@
+package server
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTestPDF assembles a PDF file from the bodies of objects 1..n, with a
+// cross reference table and a trailer pointing at the catalog (object 1)
+func buildTestPDF(objects []string, trailerExtra string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailerExtra, xref)
+	return b.Bytes()
+}
+
+// pdfStreamObject returns a stream object, Flate-compressed when compress
+// is set
+func pdfStreamObject(content string, extra string, compress bool) string {
+	if !compress {
+		return fmt.Sprintf("<< /Length %d %s >>\nstream\n%s\nendstream", len(content), extra, content)
+	}
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	_, _ = w.Write([]byte(content))
+	_ = w.Close()
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode %s >>\nstream\n%s\nendstream", z.Len(), extra, z.String())
+}
+
+func testSimplePDF() []byte {
+	return buildTestPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [7 0 R 8 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		pdfStreamObject("BT /F1 12 Tf 72 720 Td (Hello, PDF world!) Tj 0 -14 Td (Second \\(line\\)) Tj ET", "", true),
+		pdfStreamObject("BT /F1 12 Tf 72 720 Td [(Kerned)-300(words)] TJ", "", false),
+		pdfStreamObject("T* (Caf\\351 \\223quoted\\224) Tj ET", "", true),
+		"<< /Title <FEFF00540065007300740020005000440046> >>",
+	}, "/Info 9 0 R")
+}
+
+func TestPDFToMarkdown(t *testing.T) {
+	markdown, err := pdfToMarkdown(testSimplePDF())
+	require.NoError(t, err)
+	assert.Equal(t, "# Test PDF\n\n"+
+		"## Page 1\n\nHello, PDF world!\nSecond (line)\n\n"+
+		"## Page 2\n\nKerned words\nCafé “quoted”", markdown)
+}
+
+func TestPDFToMarkdown_ObjectStreamAndToUnicode(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin 12 dict begin begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar <0001> <0048> <0002> <0069> endbfchar
+1 beginbfrange <0010> <0012> <0061> endbfrange
+1 beginbfrange <0020> <0021> [<00E9> <D83DDE00>] endbfrange
+endcmap end end`
+	// Objects 2-4 are packed into the object stream (object 5)
+	packed := []string{
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R /Resources << /Font << /F1 4 0 R >> >> >>",
+		"<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /ToUnicode 7 0 R >>",
+	}
+	var header, body bytes.Buffer
+	for i, obj := range packed {
+		fmt.Fprintf(&header, "%d %d ", i+2, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	objStm := pdfStreamObject(header.String()+body.String(),
+		fmt.Sprintf("/Type /ObjStm /N %d /First %d", len(packed), header.Len()), true)
+
+	data := buildTestPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"null", "null", "null",
+		objStm,
+		pdfStreamObject("BT /F1 10 Tf <00010002> Tj 1 0 0 1 72 700 Tm <001000110012 00200021> Tj ET", "", true),
+		pdfStreamObject(cmap, "", false),
+	}, "")
+	// Drop the placeholders so the packed objects are used
+	data = bytes.ReplaceAll(data, []byte("obj\nnull\nendobj"), []byte("obj\nendobj"))
+
+	markdown, err := pdfToMarkdown(data)
+	require.NoError(t, err)
+	assert.Equal(t, "## Page 1\n\nHi\nabcé😀", markdown)
+}
+
+func TestPDFToMarkdown_Errors(t *testing.T) {
+	_, err := pdfToMarkdown([]byte("<html></html>"))
+	assert.ErrorContains(t, err, "not a PDF")
+
+	encrypted := buildTestPDF([]string{"<< /Type /Catalog /Pages 2 0 R >>"}, "/Encrypt << /Filter /Standard >>")
+	_, err = pdfToMarkdown(encrypted)
+	assert.ErrorIs(t, err, errPDFEncrypted)
+
+	scanned := buildTestPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStreamObject("q 612 0 0 792 0 0 cm /Im1 Do Q", "", true),
+	}, "")
+	_, err = pdfToMarkdown(scanned)
+	assert.ErrorContains(t, err, "no extractable text")
+}
+
+func TestUnpackObjectStream_Bounds(t *testing.T) {
+	const body = "<< /Type /Page >>\n"
+	for _, tc := range []struct {
+		header  string
+		first   int
+		wantErr string
+	}{
+		{"3 0 ", 4, ""},
+		{"3 0 ", -1, "/First -1 outside"},
+		{"3 0 ", 1000, "/First 1000 outside"},
+		{"3 -5 ", 5, "offset -5 outside"},
+		{"3 18 ", 5, "offset 18 outside"},
+		{"3 9999999999999999999 ", 22, "outside"},
+		{"3 ", 2, "truncated object stream header"},
+	} {
+		doc := &pdfDocument{objects: map[int]interface{}{}}
+		stream := &pdfStream{
+			Dict: pdfDict{"N": float64(1), "First": float64(tc.first)},
+			Data: []byte(tc.header + body),
+		}
+		err := doc.unpackObjectStream(stream)
+		if tc.wantErr == "" {
+			require.NoError(t, err)
+			assert.Contains(t, doc.objects, 3)
+		} else {
+			assert.ErrorContains(t, err, tc.wantErr, tc.header)
+		}
+	}
+}
+
+func FuzzPDFToMarkdown(f *testing.F) {
+	f.Add(testSimplePDF())
+	f.Add(buildTestPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		pdfStreamObject("2 -1 ", "/Type /ObjStm /N 1 /First -4", false),
+	}, ""))
+	f.Add(buildTestPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Length 9999999999999999999 >>\nstream\nxx\nendstream",
+	}, ""))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = pdfToMarkdown(data) // must not panic
+	})
+}
+
+func TestPDFTextWriter_SkipsInlineImages(t *testing.T) {
+	w := &pdfTextWriter{doc: &pdfDocument{objects: map[int]interface{}{}}}
+	w.run([]byte("BT (before) Tj ET BI /W 2 /H 2 /BPC 8 /CS /G ID \x00(Tj)\xff EI BT (after) Tj ET"), nil, 0)
+	assert.Equal(t, "before after", w.text())
+}
+
+func TestHandleWebRead_PDF(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Servers often send PDFs as a generic binary type
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(testSimplePDF())
+	}))
+	defer ts.Close()
+
+	result, err := New(nil).handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": ts.URL + "/paper.pdf"}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "## Page 2")
+	assert.Contains(t, text, "Hello, PDF world!")
+}
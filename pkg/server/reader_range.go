@@ -0,0 +1,348 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+)
+
+// readRange selects part of a large plain-text resource (a log, a raw
+// source file) read by searxng_read, so the rest of it is neither
+// transferred nor converted. Exactly one of bytes and lines is set.
+type readRange struct {
+	bytes *byteRange
+	lines *lineRange
+}
+
+// byteRange is a byte_range: bytes first to last, both included, or with
+// suffix > 0 the last suffix bytes. last is -1 for "to the end".
+type byteRange struct {
+	first, last, suffix int64
+}
+
+// lineRange is a line_range: lines first to last, 1-based and both
+// included. last is 0 for "to the end".
+type lineRange struct {
+	first, last int
+}
+
+// contentRange reports the part of a resource a ranged read returned
+type contentRange struct {
+	Unit  string `json:"unit"` // "bytes" or "lines"
+	Start int64  `json:"start"`
+	End   int64  `json:"end"`
+	// Total is the size of the resource in the unit, when known
+	Total int64 `json:"total,omitempty"`
+}
+
+// parseRangeBounds parses "first-last", "first-" and, with allowSuffix,
+// "-suffix"; first is -1 for a suffix and last is -1 when open
+func parseRangeBounds(s string, allowSuffix bool) (first, last int64, err error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok || from == "" && (to == "" || !allowSuffix) {
+		return 0, 0, errors.New("expected start-end")
+	}
+	first, last = -1, -1
+	if from != "" {
+		if first, err = strconv.ParseInt(from, 10, 64); err != nil || first < 0 {
+			return 0, 0, fmt.Errorf("invalid start %q", from)
+		}
+	}
+	if to != "" {
+		if last, err = strconv.ParseInt(to, 10, 64); err != nil || last < 0 {
+			return 0, 0, fmt.Errorf("invalid end %q", to)
+		}
+	}
+	if first >= 0 && last >= 0 && last < first {
+		return 0, 0, errors.New("end is before start")
+	}
+	return first, last, nil
+}
+
+// parseByteRange parses a byte_range: "0-65535", "1024-" or "-4096" (the
+// last 4096 bytes), as in an HTTP Range header
+func parseByteRange(s string) (*byteRange, error) {
+	first, last, err := parseRangeBounds(s, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid byte_range %q: %w", s, err)
+	}
+	if first < 0 {
+		if last == 0 {
+			return nil, fmt.Errorf("invalid byte_range %q: empty suffix", s)
+		}
+		return &byteRange{last: -1, suffix: last}, nil
+	}
+	return &byteRange{first: first, last: last}, nil
+}
+
+// parseLineRange parses a line_range: "100-200" or "100-", 1-based
+func parseLineRange(s string) (*lineRange, error) {
+	first, last, err := parseRangeBounds(s, false)
+	if err == nil && first == 0 {
+		err = errors.New("lines start at 1")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid line_range %q: %w", s, err)
+	}
+	return &lineRange{first: int(first), last: int(max(last, 0))}, nil
+}
+
+// header returns the value of the Range header requesting r
+func (r *byteRange) header() string {
+	switch {
+	case r.suffix > 0:
+		return fmt.Sprintf("bytes=-%d", r.suffix)
+	case r.last < 0:
+		return fmt.Sprintf("bytes=%d-", r.first)
+	}
+	return fmt.Sprintf("bytes=%d-%d", r.first, r.last)
+}
+
+// fetchURLRange reads the part rng selects of the plain-text resource at
+// urlStr. Byte ranges are requested with a Range header and cut locally
+// from servers that ignore it; line ranges stop the download after their
+// last line. At most opts.MaxBytes are kept. HTML pages are refused: their
+// Markdown can't be sliced by bytes or lines of the source.
+func fetchURLRange(ctx context.Context, urlStr string, opts readOptions, rng readRange) (*readResult, error) {
+	parsedURL, err := validateURL(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newRequest(ctx, parsedURL.String(), "text/plain, */*;q=0.8")
+	if err != nil {
+		return nil, err
+	}
+	if rng.bytes != nil {
+		req.Header.Set("Range", rng.bytes.header())
+	}
+	log.WithFields(logrus.Fields{"url": urlStr, "range": req.Header.Get("Range")}).Debug("fetching URL range")
+	resp, err := opts.client(opts.Proxy).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && rng.bytes != nil:
+		return nil, fmt.Errorf("byte_range %s is beyond the end of the resource", strings.TrimPrefix(rng.bytes.header(), "bytes="))
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	media := mediaType(contentType)
+	if media == "text/html" || media == "application/xhtml+xml" {
+		return nil, fmt.Errorf("%s is an HTML page; byte_range and line_range only apply to plain-text resources, use offset and max_length instead", urlStr)
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultReadMaxBytes
+	}
+
+	var body []byte
+	var span *contentRange
+	var truncated bool
+	if rng.bytes != nil {
+		body, span, truncated, err = readByteRange(resp, rng.bytes, maxBytes)
+		body = trimPartialRunes(body)
+	} else {
+		body, span, truncated, err = readLineRange(resp.Body, rng.lines, maxBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isTextual(media, body) {
+		return nil, fmt.Errorf("unsupported content type %s (byte_range and line_range only apply to text)", media)
+	}
+	if strings.HasPrefix(media, "text/") {
+		body = toUTF8(body, contentType)
+	}
+	language := codeLanguage(media, body)
+	return &readResult{
+		Markdown:     textToMarkdown(body, language),
+		Freshness:    parseFreshness(resp.Header, time.Now()),
+		Truncated:    truncated,
+		ContentType:  media,
+		CodeLanguage: language,
+		Range:        span,
+	}, nil
+}
+
+// readByteRange reads the bytes r selects from resp, a 206 answer to its
+// Range header or a 200 with the whole resource
+func readByteRange(resp *http.Response, r *byteRange, maxBytes int64) ([]byte, *contentRange, bool, error) {
+	if resp.StatusCode == http.StatusPartialContent {
+		first, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok {
+			return nil, nil, false, errors.New("invalid Content-Range in partial response")
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("failed to read response body: %w", err)
+		}
+		truncated := int64(len(body)) > maxBytes
+		if truncated {
+			body = body[:maxBytes]
+		}
+		return body, &contentRange{Unit: "bytes", Start: first, End: first + int64(len(body)) - 1, Total: total}, truncated, nil
+	}
+
+	log.Debug("server ignored the Range header, cutting the range locally")
+	if r.suffix > 0 {
+		// The size is only known at the end: keep the last bytes read
+		tail, total, err := readTail(resp.Body, min(r.suffix, maxBytes))
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return tail, &contentRange{Unit: "bytes", Start: total - int64(len(tail)), End: total - 1, Total: total}, r.suffix > maxBytes && total > maxBytes, nil
+	}
+	skipped, err := io.CopyN(io.Discard, resp.Body, r.first)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, false, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if skipped < r.first {
+		return nil, nil, false, fmt.Errorf("byte_range %s is beyond the end of the resource (%d bytes)", strings.TrimPrefix(r.header(), "bytes="), skipped)
+	}
+	limit := maxBytes
+	if r.last >= 0 {
+		limit = min(r.last-r.first+1, maxBytes)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to read response body: %w", err)
+	}
+	span := &contentRange{Unit: "bytes", Start: r.first, Total: max(resp.ContentLength, 0)}
+	truncated := false
+	if int64(len(body)) > limit {
+		body = body[:limit]
+		truncated = r.last < 0 || r.last-r.first+1 > maxBytes
+	} else {
+		span.Total = r.first + int64(len(body)) // read to the end
+	}
+	span.End = r.first + int64(len(body)) - 1
+	return body, span, truncated, nil
+}
+
+// readTail reads r to the end, returning its last n bytes and its size
+func readTail(r io.Reader, n int64) ([]byte, int64, error) {
+	var tail []byte
+	var total int64
+	buf := make([]byte, 32*1024)
+	for {
+		k, err := r.Read(buf)
+		total += int64(k)
+		tail = append(tail, buf[:k]...)
+		if int64(len(tail)) > 2*n {
+			tail = append(tail[:0], tail[int64(len(tail))-n:]...)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+	if int64(len(tail)) > n {
+		tail = tail[int64(len(tail))-n:]
+	}
+	return tail, total, nil
+}
+
+// readLineRange reads the lines r selects from body, stopping after the
+// last one
+func readLineRange(body io.Reader, r *lineRange, maxBytes int64) ([]byte, *contentRange, bool, error) {
+	reader := bufio.NewReader(body)
+	var out []byte
+	line := 0
+	truncated := false
+	for r.last == 0 || line < r.last {
+		text, err := reader.ReadBytes('\n')
+		if len(text) > 0 {
+			line++
+		}
+		if line >= r.first && len(text) > 0 {
+			if int64(len(out)+len(text)) > maxBytes {
+				out = append(out, text[:maxBytes-int64(len(out))]...)
+				truncated = true
+				break
+			}
+			out = append(out, text...)
+		}
+		if errors.Is(err, io.EOF) {
+			if line < r.first {
+				return nil, nil, false, fmt.Errorf("line_range starts at line %d but the resource has %d lines", r.first, line)
+			}
+			return out, &contentRange{Unit: "lines", Start: int64(r.first), End: int64(line), Total: int64(line)}, false, nil
+		}
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+	return out, &contentRange{Unit: "lines", Start: int64(r.first), End: int64(line)}, truncated, nil
+}
+
+// parseContentRange parses "bytes first-last/total" of a 206 response;
+// total is 0 when the server sent "*"
+func parseContentRange(header string) (first, total int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	bounds, size, found := strings.Cut(spec, "/")
+	from, _, found2 := strings.Cut(bounds, "-")
+	if !found || !found2 {
+		return 0, 0, false
+	}
+	first, err := strconv.ParseInt(from, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return first, total, true
+}
+
+// trimPartialRunes drops the bytes of UTF-8 sequences a byte range cut at
+// either end
+func trimPartialRunes(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0 && !utf8.RuneStart(b[0]); i++ {
+		b = b[1:]
+	}
+	for i := 1; i <= utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				b = b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// fetchRange is fetchURLRange waiting for the page read rate limiter
+// first. Ranged reads bypass the read and disk caches and the mirrors,
+// which hold whole pages.
+func (s *Server) fetchRange(ctx context.Context, urlStr string, opts readOptions, rng readRange) (*readResult, error) {
+	ctx, span := startSpan(ctx, "fetch_range")
+	defer span.End()
+	if err := s.waitRead(ctx); err != nil {
+		return nil, err
+	}
+	page, err := fetchURLRange(ctx, urlStr, opts, rng)
+	if err != nil {
+		spanError(ctx, err)
+	}
+	return page, err
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteRange(t *testing.T) {
+	for input, want := range map[string]byteRange{
+		"0-65535": {first: 0, last: 65535},
+		" 1024- ": {first: 1024, last: -1},
+		"-4096":   {last: -1, suffix: 4096},
+	} {
+		r, err := parseByteRange(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, *r, input)
+	}
+	for input, want := range map[string]string{
+		"":      "expected start-end",
+		"100":   "expected start-end",
+		"-":     "expected start-end",
+		"-0":    "empty suffix",
+		"a-10":  `invalid start "a"`,
+		"10-5":  "end is before start",
+		"1--10": `invalid end "-10"`,
+	} {
+		_, err := parseByteRange(input)
+		assert.ErrorContains(t, err, want, input)
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	r, err := parseLineRange("100-200")
+	require.NoError(t, err)
+	assert.Equal(t, lineRange{first: 100, last: 200}, *r)
+	r, err = parseLineRange("5-")
+	require.NoError(t, err)
+	assert.Equal(t, lineRange{first: 5}, *r)
+
+	_, err = parseLineRange("-10")
+	assert.ErrorContains(t, err, "expected start-end")
+	_, err = parseLineRange("0-10")
+	assert.ErrorContains(t, err, "lines start at 1")
+}
+
+func TestTrimPartialRunes(t *testing.T) {
+	s := []byte("héllo wörld")
+	assert.Equal(t, "llo w", string(trimPartialRunes(s[2:9])), "cut sequences are dropped at both ends")
+	assert.Equal(t, "héllo wörld", string(trimPartialRunes(s)))
+	assert.Empty(t, trimPartialRunes(s[2:2]))
+}
+
+func TestParseContentRange(t *testing.T) {
+	first, total, ok := parseContentRange("bytes 100-199/1000")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), first)
+	assert.Equal(t, int64(1000), total)
+	_, total, ok = parseContentRange("bytes 0-9/*")
+	assert.True(t, ok)
+	assert.Zero(t, total)
+	_, _, ok = parseContentRange("items 0-9/10")
+	assert.False(t, ok)
+}
+
+func TestHandleWebRead_Ranges(t *testing.T) {
+	var lines strings.Builder
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&lines, "line %04d\n", i) // 10 bytes per line
+	}
+	content := lines.String()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ranged.log":
+			w.Header().Set("Content-Type", "text/plain")
+			http.ServeContent(w, r, "ranged.log", time.Time{}, strings.NewReader(content))
+		case "/plain.log": // ignores Range headers
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			_, _ = w.Write([]byte(content))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<p>hello</p>"))
+		}
+	}))
+	defer ts.Close()
+	srv := New(nil)
+	read := func(args map[string]interface{}) *mcp.CallToolResult {
+		result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: args},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	for _, path := range []string{"/ranged.log", "/plain.log"} {
+		result := read(map[string]interface{}{"url": ts.URL + path, "byte_range": "10-29"})
+		require.False(t, result.IsError, path)
+		assert.Equal(t, "line 0002\nline 0003\n", result.Content[0].(mcp.TextContent).Text, path)
+		assert.Equal(t, &contentRange{Unit: "bytes", Start: 10, End: 29, Total: 10000}, result.StructuredContent.(readMetadata).Range, path)
+
+		result = read(map[string]interface{}{"url": ts.URL + path, "byte_range": "-10"})
+		require.False(t, result.IsError, path)
+		assert.Equal(t, "line 1000\n", result.Content[0].(mcp.TextContent).Text, path)
+		assert.Equal(t, &contentRange{Unit: "bytes", Start: 9990, End: 9999, Total: 10000}, result.StructuredContent.(readMetadata).Range, path)
+
+		result = read(map[string]interface{}{"url": ts.URL + path, "byte_range": "20000-"})
+		require.True(t, result.IsError, path)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "beyond the end of the resource", path)
+	}
+
+	result := read(map[string]interface{}{"url": ts.URL + "/plain.log", "line_range": "499-500"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "line 0499\nline 0500\n", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, &contentRange{Unit: "lines", Start: 499, End: 500}, result.StructuredContent.(readMetadata).Range, "the total is unknown when reading stops early")
+
+	result = read(map[string]interface{}{"url": ts.URL + "/plain.log", "line_range": "999-"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "line 0999\nline 1000\n", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, &contentRange{Unit: "lines", Start: 999, End: 1000, Total: 1000}, result.StructuredContent.(readMetadata).Range)
+
+	for _, tc := range []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"url": ts.URL + "/plain.log", "line_range": "2000-"}, "the resource has 1000 lines"},
+		{map[string]interface{}{"url": ts.URL + "/plain.log", "line_range": "1-2", "byte_range": "0-1"}, "can't be combined"},
+		{map[string]interface{}{"url": ts.URL + "/plain.log", "byte_range": "x"}, "invalid byte_range"},
+		{map[string]interface{}{"url": ts.URL + "/page.html", "line_range": "1-2"}, "is an HTML page"},
+	} {
+		result := read(tc.args)
+		require.True(t, result.IsError, tc.want)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tc.want)
+	}
+}
@@ -40,7 +40,7 @@ func TestFetchURLContent_RedditThreadUsesJSONEndpoint(t *testing.T) {
 		Reply(200).
 		JSON(loadJSONFixture(t, "reddit_thread_claudeai.json"))
 
-	markdown, err := fetchURLContent(context.Background(), "https://www.reddit.com/r/ClaudeAI/comments/1r2zjgl/anyone_feel_everything_has_changed_over_the_last/")
+	markdown, err := fetchURLContent(context.Background(), "https://www.reddit.com/r/ClaudeAI/comments/1r2zjgl/anyone_feel_everything_has_changed_over_the_last/", readOptions{})
 	require.NoError(t, err)
 	assert.Contains(t, markdown, "Anyone feel everything has changed over the last year?")
 	assert.True(t, gock.IsDone(), "expected mocked Reddit JSON endpoint to be called")
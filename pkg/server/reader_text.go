@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// codeLanguages maps the media types of data and source files to the
+// language of the Markdown code block they're returned in
+var codeLanguages = map[string]string{
+	"application/json":          "json",
+	"application/xml":           "xml",
+	"text/xml":                  "xml",
+	"text/csv":                  "csv",
+	"text/tab-separated-values": "tsv",
+	"application/yaml":          "yaml",
+	"application/x-yaml":        "yaml",
+	"text/yaml":                 "yaml",
+	"application/toml":          "toml",
+	"application/javascript":    "javascript",
+	"text/javascript":           "javascript",
+	"text/css":                  "css",
+}
+
+// mediaType returns the lowercased media type of a Content-Type header,
+// without its parameters
+func mediaType(contentType string) string {
+	if t, _, err := mime.ParseMediaType(contentType); err == nil {
+		return t
+	}
+	t, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// codeLanguage returns the code block language of a page of type
+// mediaType, sniffing JSON and XML sent as plain text or without a type.
+// It returns the empty string for prose.
+func codeLanguage(mediaType string, body []byte) string {
+	if language, ok := codeLanguages[mediaType]; ok {
+		return language
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return "json"
+	case strings.HasSuffix(mediaType, "+xml"):
+		return "xml"
+	case mediaType != "" && mediaType != "text/plain" && mediaType != "application/octet-stream":
+		return ""
+	}
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed):
+		return "json"
+	case bytes.HasPrefix(trimmed, []byte("<?xml")):
+		return "xml"
+	}
+	return ""
+}
+
+// isTextual reports whether a non-HTML page of type mediaType can be
+// returned as text: text/* types, data formats and untyped UTF-8 bodies
+func isTextual(mediaType string, body []byte) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"), codeLanguage(mediaType, nil) != "":
+		return true
+	case mediaType == "" || mediaType == "application/octet-stream":
+		return utf8.Valid(body)
+	}
+	return false
+}
+
+// textToMarkdown returns a text page as Markdown: prose as is, data and
+// source files fenced in a code block of their language. JSON is
+// pretty-printed unless it's invalid, e.g. cut at the download limit.
+func textToMarkdown(body []byte, language string) string {
+	if language == "" {
+		return string(body)
+	}
+	if language == "json" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, bytes.TrimSpace(body), "", "  "); err == nil {
+			body = indented.Bytes()
+		}
+	}
+	return codeFence(strings.TrimRight(string(body), "\r\n"), language)
+}
+
+// codeFence wraps text in a fenced code block of language, with a fence
+// longer than any backtick run in text
+func codeFence(text, language string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fmt.Sprintf("%s%s\n%s\n%s", fence, language, text, fence)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeLanguage(t *testing.T) {
+	for _, tc := range []struct {
+		mediaType, body, want string
+	}{
+		{"application/json", `{"a": 1}`, "json"},
+		{"application/vnd.api+json", `{}`, "json"},
+		{"application/atom+xml", `<feed/>`, "xml"},
+		{"text/csv", "a,b\n1,2", "csv"},
+		{"text/plain", ` [1, 2] `, "json"},
+		{"text/plain", `{not json`, ""},
+		{"", `<?xml version="1.0"?><a/>`, "xml"},
+		{"text/plain", "just some text", ""},
+		{"text/markdown", `{"a": 1}`, ""}, // prose types aren't sniffed
+	} {
+		assert.Equal(t, tc.want, codeLanguage(tc.mediaType, []byte(tc.body)), "%s %s", tc.mediaType, tc.body)
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	assert.Equal(t, "application/json", mediaType("Application/JSON; charset=utf-8"))
+	assert.Equal(t, "text/plain", mediaType("text/plain; charset"))
+	assert.Empty(t, mediaType(""))
+}
+
+func TestTextToMarkdown(t *testing.T) {
+	assert.Equal(t, "```json\n{\n  \"a\": [\n    1,\n    2\n  ]\n}\n```", textToMarkdown([]byte(`{"a":[1,2]}`+"\n"), "json"))
+	assert.Equal(t, "```json\n{\"a\": [1, 2\n```", textToMarkdown([]byte(`{"a": [1, 2`), "json"), "invalid JSON is kept as is")
+	assert.Equal(t, "````md\nuse ```go fences\n````", textToMarkdown([]byte("use ```go fences"), "md"))
+	assert.Equal(t, "plain text\n", textToMarkdown([]byte("plain text\n"), ""))
+}
+
+func TestHandleWebRead_TextFormats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"name":"searxng","stars":1}`))
+		case "/data.csv":
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = w.Write([]byte("name,stars\nsearxng,1\n"))
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+		}
+	}))
+	defer ts.Close()
+	srv := New(nil)
+	read := func(path string) *mcp.CallToolResult {
+		result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": ts.URL + path}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := read("/data.json")
+	require.False(t, result.IsError)
+	assert.Equal(t, "```json\n{\n  \"name\": \"searxng\",\n  \"stars\": 1\n}\n```", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, "application/json", result.StructuredContent.(readMetadata).ContentType)
+
+	result = read("/data.csv")
+	require.False(t, result.IsError)
+	assert.Equal(t, "```csv\nname,stars\nsearxng,1\n```", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, "text/csv", result.StructuredContent.(readMetadata).ContentType)
+
+	result = read("/image.png")
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "unsupported content type image/png")
+}
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// timeRangeLadder orders time ranges from widest to narrowest
+var timeRangeLadder = []string{"", "year", "month", "week", "day"}
+
+var (
+	sitePattern    = regexp.MustCompile(`(?i)\bsite:([a-z0-9.-]+\.[a-z]{2,})`)
+	excludePattern = regexp.MustCompile(`(?i)\b(?:exclude|excluding|without|not about)\s+"?([\p{L}\p{N}.+#-]+)"?`)
+)
+
+// refinementRule maps feedback phrases to a rewrite of the search request
+type refinementRule struct {
+	name    string
+	phrases []string
+	apply   func(req *searxng.SearchRequest)
+}
+
+var refinementRules = []refinementRule{
+	{
+		name:    "narrow: quoted phrase",
+		phrases: []string{"too broad", "more specific", "narrow down", "narrower", "exact"},
+		apply: func(req *searxng.SearchRequest) {
+			if !strings.Contains(req.Query, `"`) && len(strings.Fields(req.Query)) > 1 {
+				req.Query = `"` + req.Query + `"`
+			}
+		},
+	},
+	{
+		name:    "broaden: dropped quotes, exclusions and time filter",
+		phrases: []string{"too narrow", "too specific", "broader", "no results", "nothing found"},
+		apply: func(req *searxng.SearchRequest) {
+			var kept []string
+			for _, field := range strings.Fields(strings.ReplaceAll(req.Query, `"`, "")) {
+				if strings.HasPrefix(field, "-") || strings.HasPrefix(strings.ToLower(field), "site:") {
+					continue
+				}
+				kept = append(kept, field)
+			}
+			req.Query = strings.Join(kept, " ")
+			req.TimeRange = ""
+		},
+	},
+	{
+		name:    "recent: narrowed time range",
+		phrases: []string{"recent", "latest", "newer", "up to date", "outdated", "too old"},
+		apply: func(req *searxng.SearchRequest) {
+			for i, tr := range timeRangeLadder {
+				if tr == req.TimeRange {
+					if i == 0 {
+						req.TimeRange = "month"
+					} else if i+1 < len(timeRangeLadder) {
+						req.TimeRange = timeRangeLadder[i+1]
+					}
+					return
+				}
+			}
+			req.TimeRange = "month"
+		},
+	},
+	{
+		name:    "any time: cleared time range",
+		phrases: []string{"any time", "older", "historical", "all time"},
+		apply: func(req *searxng.SearchRequest) {
+			req.TimeRange = ""
+		},
+	},
+	{
+		name:    "official docs: added documentation terms",
+		phrases: []string{"official", "docs", "documentation", "reference"},
+		apply: func(req *searxng.SearchRequest) {
+			if !strings.Contains(strings.ToLower(req.Query), "documentation") {
+				req.Query += " official documentation"
+			}
+		},
+	},
+	{
+		name:    "news: category news",
+		phrases: []string{"news", "announcement", "press"},
+		apply: func(req *searxng.SearchRequest) {
+			req.Category = "news"
+		},
+	},
+	{
+		name:    "academic: category science",
+		phrases: []string{"academic", "paper", "research", "scientific", "study", "studies"},
+		apply: func(req *searxng.SearchRequest) {
+			req.Category = "science"
+		},
+	},
+	{
+		name:    "code: category it",
+		phrases: []string{"code", "programming", "technical", "developer"},
+		apply: func(req *searxng.SearchRequest) {
+			req.Category = "it"
+		},
+	},
+	{
+		name:    "more results: raised limit",
+		phrases: []string{"more results", "not enough"},
+		apply: func(req *searxng.SearchRequest) {
+			req.Limit = min(max(req.Limit, 5)*2, 20)
+		},
+	},
+}
+
+// refineSearchRequest deterministically rewrites req according to free-form
+// feedback. It returns the rewritten request and the names of the applied
+// rules, in application order.
+func refineSearchRequest(req searxng.SearchRequest, feedback string) (searxng.SearchRequest, []string) {
+	// Match phrases at word starts only, so "code" does not match "decode"
+	words := strings.FieldsFunc(strings.ToLower(feedback), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	normalized := " " + strings.Join(words, " ") + " "
+	var applied []string
+
+	for _, rule := range refinementRules {
+		for _, phrase := range rule.phrases {
+			if strings.Contains(normalized, " "+phrase) {
+				rule.apply(&req)
+				applied = append(applied, rule.name)
+				break
+			}
+		}
+	}
+
+	for _, m := range sitePattern.FindAllStringSubmatch(feedback, -1) {
+		operator := "site:" + strings.ToLower(m[1])
+		if !strings.Contains(strings.ToLower(req.Query), operator) {
+			req.Query += " " + operator
+			applied = append(applied, "site: "+m[1])
+		}
+	}
+
+	for _, m := range excludePattern.FindAllStringSubmatch(feedback, -1) {
+		operator := "-" + m[1]
+		if !strings.Contains(req.Query, operator) {
+			req.Query += " " + operator
+			applied = append(applied, "exclude: "+m[1])
+		}
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+	return req, applied
+}
+
+// handleRefineSearch handles the searxng_refine_search tool call
+func (s *Server) handleRefineSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("handling searxng_refine_search")
+
+	// Parse arguments
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+	feedback, ok := args["feedback"].(string)
+	if !ok || feedback == "" {
+		return mcp.NewToolResultError("feedback is required"), nil
+	}
+
+	req := searxng.SearchRequest{
+		Query: query,
+	}
+	if limit, ok := args["limit"].(float64); ok {
+		req.Limit = int(limit)
+	}
+	if timeRange, ok := args["time_range"].(string); ok {
+		req.TimeRange = timeRange
+	}
+	if category, ok := args["category"].(string); ok {
+		req.Category = category
+	}
+
+	client, err := s.sessionClient(ctx, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateInstanceSupport(ctx, client, req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	refined, applied := refineSearchRequest(req, feedback)
+	if refined.TimeRange == "week" && validateInstanceSupport(ctx, client, searxng.SearchRequest{TimeRange: "week"}) != nil {
+		// Searx releases before the SearXNG fork have no week range
+		refined.TimeRange = "day"
+	}
+	// Log the rule names only: the query and site:/exclude: terms stay out
+	// of the logs
+	rules := make([]string, len(applied))
+	for i, change := range applied {
+		rules[i], _, _ = strings.Cut(change, ":")
+	}
+	log.WithField("rules", rules).Debug("refined search")
+
+	resp, err := s.search(ctx, client, refined)
+	if err != nil {
+		log.WithFields(logrus.Fields{"error": err}).Error("search failed")
+		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
+	}
+
+	resp, _ = s.history.record(sessionID(ctx), resp, false)
+	output := formatSearchResults(resp)
+	s.annotations.annotate(output)
+	if applied == nil {
+		applied = []string{}
+	}
+	refinement := map[string]interface{}{
+		"original_query": query,
+		"refined_query":  refined.Query,
+		"applied":        applied,
+	}
+	if refined.TimeRange != "" {
+		refinement["time_range"] = refined.TimeRange
+	}
+	if refined.Category != "" {
+		refinement["category"] = refined.Category
+	}
+	output["refinement"] = refinement
+
+	resultJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to format results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultStructured(output, string(resultJSON)), nil
+}
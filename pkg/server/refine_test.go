@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefineSearchRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       searxng.SearchRequest
+		feedback  string
+		wantQuery string
+		wantRange string
+		wantCat   string
+		wantLimit int
+	}{
+		{
+			name:      "too broad quotes the query",
+			req:       searxng.SearchRequest{Query: "context cancellation"},
+			feedback:  "too broad",
+			wantQuery: `"context cancellation"`,
+		},
+		{
+			name:      "need recent narrows time range",
+			req:       searxng.SearchRequest{Query: "go release", TimeRange: "year"},
+			feedback:  "need recent",
+			wantQuery: "go release",
+			wantRange: "month",
+		},
+		{
+			name:      "need recent narrows month to week",
+			req:       searxng.SearchRequest{Query: "go release", TimeRange: "month"},
+			feedback:  "need recent",
+			wantQuery: "go release",
+			wantRange: "week",
+		},
+		{
+			name:      "need recent narrows week to day",
+			req:       searxng.SearchRequest{Query: "go release", TimeRange: "week"},
+			feedback:  "too old",
+			wantQuery: "go release",
+			wantRange: "day",
+		},
+		{
+			name:      "need recent without time range",
+			req:       searxng.SearchRequest{Query: "go release"},
+			feedback:  "Need RECENT results",
+			wantQuery: "go release",
+			wantRange: "month",
+		},
+		{
+			name:      "only official docs",
+			req:       searxng.SearchRequest{Query: "net/http client"},
+			feedback:  "only official docs",
+			wantQuery: "net/http client official documentation",
+		},
+		{
+			name:      "site and exclusion",
+			req:       searxng.SearchRequest{Query: "generics"},
+			feedback:  "only site:go.dev, exclude java",
+			wantQuery: "generics site:go.dev -java",
+		},
+		{
+			name:      "too narrow drops operators",
+			req:       searxng.SearchRequest{Query: `"exact phrase" -foo site:example.com`, TimeRange: "day"},
+			feedback:  "too narrow",
+			wantQuery: "exact phrase",
+		},
+		{
+			name:      "papers and more results",
+			req:       searxng.SearchRequest{Query: "transformers", Limit: 5},
+			feedback:  "want papers, more results",
+			wantQuery: "transformers",
+			wantCat:   "science",
+			wantLimit: 10,
+		},
+		{
+			name:      "word start matching",
+			req:       searxng.SearchRequest{Query: "base64"},
+			feedback:  "how to decode",
+			wantQuery: "base64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := refineSearchRequest(tt.req, tt.feedback)
+			assert.Equal(t, tt.wantQuery, got.Query)
+			assert.Equal(t, tt.wantRange, got.TimeRange)
+			assert.Equal(t, tt.wantCat, got.Category)
+			if tt.wantLimit != 0 {
+				assert.Equal(t, tt.wantLimit, got.Limit)
+			}
+		})
+	}
+}
+
+func TestRefineSearchRequest_Deterministic(t *testing.T) {
+	req := searxng.SearchRequest{Query: "rust async"}
+	first, firstApplied := refineSearchRequest(req, "too broad, need recent, only official docs")
+	second, secondApplied := refineSearchRequest(req, "too broad, need recent, only official docs")
+	assert.Equal(t, first, second)
+	assert.Equal(t, firstApplied, secondApplied)
+	assert.Len(t, firstApplied, 3)
+}
+
+func TestHandleRefineSearch(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", `"golang generics"`).
+		MatchParam("time_range", "month").
+		Reply(200).
+		JSON(searxng.APIResponse{
+			Query: `"golang generics"`,
+			Results: []searxng.APIResult{
+				{URL: "https://go.dev/doc/tutorial/generics", Title: "Tutorial: Getting started with generics"},
+			},
+		})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result, err := srv.handleRefineSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "searxng_refine_search",
+			Arguments: map[string]interface{}{
+				"query":    "golang generics",
+				"feedback": "too broad and need recent",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+
+	refinement := output["refinement"].(map[string]interface{})
+	assert.Equal(t, "golang generics", refinement["original_query"])
+	assert.Equal(t, `"golang generics"`, refinement["refined_query"])
+	assert.Equal(t, "month", refinement["time_range"])
+	assert.Len(t, output["results"], 1)
+	assert.True(t, gock.IsDone())
+}
+
+func TestHandleRefineSearch_TimeRangeSupport(t *testing.T) {
+	version := "2024.5.24"
+	var gotRange string
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/config":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"version": version})
+		case "/search":
+			gotRange = r.URL.Query().Get("time_range")
+			_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: r.URL.Query().Get("q")})
+		}
+	}))
+	defer instance.Close()
+
+	refine := func(timeRange string) *mcp.CallToolResult {
+		client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+		require.NoError(t, err)
+		gotRange = ""
+		result, err := New(client).handleRefineSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "searxng_refine_search",
+				Arguments: map[string]interface{}{"query": "go release", "feedback": "need recent", "time_range": timeRange},
+			},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.False(t, refine("week").IsError)
+	assert.Equal(t, "day", gotRange)
+	assert.False(t, refine("month").IsError)
+	assert.Equal(t, "week", gotRange)
+
+	version = "0.18.0"
+	result := refine("week")
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `time_range "week" is not supported by this searxng instance`)
+	assert.Empty(t, gotRange)
+	assert.False(t, refine("month").IsError)
+	assert.Equal(t, "day", gotRange, "searx has no week range")
+}
+
+func TestHandleRefineSearch_MissingFeedback(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result, err := srv.handleRefineSearch(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "searxng_refine_search",
+			Arguments: map[string]interface{}{"query": "golang"},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "feedback is required")
+}
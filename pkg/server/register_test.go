@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterTools(t *testing.T) {
+	gock.Off()
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><h1>Embedded</h1></body></html>`))
+	}))
+	defer site.Close()
+
+	mcpServer := mcpserver.NewMCPServer("host", "1.0.0", mcpserver.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := RegisterTools(mcpServer, client, Options{})
+	assert.Same(t, mcpServer, srv.MCPServer())
+
+	tools := mcpServer.ListTools()
+	assert.Contains(t, tools, "echo")
+	assert.Contains(t, tools, "searxng_search")
+	assert.Contains(t, tools, "searxng_read")
+
+	result := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "# Embedded")
+	callToolResult(t, srv, "echo", nil)
+
+	stats := srv.Stats()
+	assert.EqualValues(t, 1, stats.ToolCalls["searxng_read"], "the middleware applies to the searxng tools")
+	assert.NotContains(t, stats.ToolCalls, "echo", "and only to them")
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	result = callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+	assert.True(t, result.IsError, "shut down")
+	assert.False(t, callToolResult(t, srv, "echo", nil).IsError, "the host's tools keep working")
+}
+
+func TestServer_AddHooks(t *testing.T) {
+	srv := New(nil)
+	hooks := &mcpserver.Hooks{}
+	srv.AddHooks(hooks)
+	assert.Len(t, hooks.OnUnregisterSession, 2)
+	assert.Len(t, hooks.OnAfterInitialize, 1)
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// maxRecentResources bounds the number of recent searches, and separately
+// of recent pages, exposed as MCP resources
+const maxRecentResources = 32
+
+// maxResultResources bounds the number of search results registered as MCP
+// resources by searxng_search's as_resources argument
+const maxResultResources = 100
+
+// URI schemes of the recent searches and pages resources
+const (
+	searchResourceScheme = "search://"
+	pageResourceScheme   = "page://"
+)
+
+// recentResources exposes the latest search result sets and fetched pages
+// as MCP resources, so clients can list and re-read them without searching
+// or fetching again, and search results for clients browsing resources
+// rather than tool output. The oldest resources are removed once there are
+// more than maxRecentResources searches or pages, or maxResultResources
+// results.
+//
+// Resources are only visible to the session that searched or fetched them:
+// they are session resources on transports serving several clients
+// (streamable HTTP, SSE). Sessions without session resources (stdio,
+// in-process) serve a single client, so theirs are registered on the
+// server. Calls outside of a session register none.
+type recentResources struct {
+	mu        sync.Mutex
+	mcpServer *mcpserver.MCPServer
+	sessions  map[string]*sessionResources // by session ID
+}
+
+// sessionResources holds the URIs of the resources of a session, oldest
+// first
+type sessionResources struct {
+	searches []string
+	pages    []string
+	results  []string
+}
+
+// resourceKind selects the URI list of a kind of resource
+type resourceKind func(*sessionResources) *[]string
+
+func searchResources(r *sessionResources) *[]string { return &r.searches }
+func pageResources(r *sessionResources) *[]string   { return &r.pages }
+func resultResources(r *sessionResources) *[]string { return &r.results }
+
+// resourceKey returns the hash identifying a query or URL in resource URIs
+func resourceKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// addSearch exposes the JSON formatted results of query as
+// search://<query-hash>, replacing the previous results of the query
+func (r *recentResources) addSearch(ctx context.Context, query, resultJSON string) {
+	resource := mcp.NewResource(searchResourceScheme+resourceKey(query), "Search: "+query,
+		mcp.WithResourceDescription("Results of the search for "+query),
+		mcp.WithMIMEType("application/json"))
+	r.add(ctx, searchResources, maxRecentResources, resource, textResource(resource, resultJSON))
+}
+
+// addPage exposes the Markdown of the page at url as page://<url-hash>,
+// replacing the previous read of the page
+func (r *recentResources) addPage(ctx context.Context, url string, page *readResult) {
+	resource := mcp.NewResource(pageResourceScheme+resourceKey(url), url,
+		mcp.WithResourceDescription("Content of "+url),
+		mcp.WithMIMEType("text/markdown"))
+	r.add(ctx, pageResources, maxRecentResources, resource, textResource(resource, page.Markdown))
+}
+
+// textResource returns a handler serving text as the content of resource
+func textResource(resource mcp.Resource, text string) mcpserver.ResourceHandlerFunc {
+	contents := mcp.TextResourceContents{URI: resource.URI, MIMEType: resource.MIMEType, Text: text}
+	return func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{contents}, nil
+	}
+}
+
+// add registers resource for the session of ctx as the most recent entry
+// of its kind and removes the oldest entries beyond limit
+func (r *recentResources) add(ctx context.Context, kind resourceKind, limit int, resource mcp.Resource, handler mcpserver.ResourceHandlerFunc) {
+	session := mcpserver.ClientSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+	var id string // "": the server-wide resources of single-client sessions
+	if _, ok := session.(mcpserver.SessionWithResources); ok {
+		id = session.SessionID()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sessions == nil {
+		r.sessions = make(map[string]*sessionResources)
+	}
+	state, ok := r.sessions[id]
+	if !ok {
+		state = &sessionResources{}
+		r.sessions[id] = state
+	}
+	uris := kind(state)
+	for i, uri := range *uris {
+		if uri == resource.URI {
+			*uris = append((*uris)[:i], (*uris)[i+1:]...)
+			break
+		}
+	}
+
+	if id == "" {
+		r.mcpServer.AddResource(resource, handler)
+	} else if err := r.mcpServer.AddSessionResource(id, resource, handler); err != nil {
+		// e.g. stateless streamable HTTP, whose sessions aren't kept
+		log.WithField("error", err).Debug("not exposing resource to session")
+		return
+	}
+	*uris = append(*uris, resource.URI)
+
+	if excess := len(*uris) - limit; excess > 0 {
+		if id == "" {
+			r.mcpServer.DeleteResources((*uris)[:excess]...)
+		} else {
+			_ = r.mcpServer.DeleteSessionResources(id, (*uris)[:excess]...)
+		}
+		*uris = append([]string(nil), (*uris)[excess:]...)
+	}
+}
+
+// forget drops the resource bookkeeping of a session; it is registered as
+// an unregister-session hook. The MCP server drops the session resources
+// with the session.
+func (r *recentResources) forget(_ context.Context, session mcpserver.ClientSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, session.SessionID())
+}
+
+// addResults registers search results as resources whose URI is the result
+// URL, named after the title and described by the snippet. Reading one
+// fetches the page like searxng_read. It returns links to the resources, to
+// add to the tool result.
+func (s *Server) addResults(ctx context.Context, results []map[string]interface{}) []mcp.Content {
+	links := make([]mcp.Content, 0, len(results))
+	for _, result := range results {
+		url := result["url"].(string)
+		name, _ := result["title"].(string)
+		if name == "" {
+			name = url
+		}
+		snippet, _ := result["snippet"].(string)
+		resource := mcp.NewResource(url, name, mcp.WithResourceDescription(snippet), mcp.WithMIMEType("text/markdown"))
+		s.resources.add(ctx, resultResources, maxResultResources, resource, s.readResult(url))
+		links = append(links, mcp.NewResourceLink(url, name, snippet, "text/markdown"))
+	}
+	return links
+}
+
+// readResult returns the handler of the resource of the search result at
+// url, which fetches the page with the default read options
+func (s *Server) readResult(url string) mcpserver.ResourceHandlerFunc {
+	return func(ctx context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if err := s.checkRead(ctx, url); err != nil {
+			return nil, err
+		}
+		page, err := s.fetchPage(ctx, url, s.defaultReadOptions())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch URL: %w", err)
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: url, MIMEType: "text/markdown", Text: page.Markdown}}, nil
+	}
+}
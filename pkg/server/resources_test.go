@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resourceSession is a session with session resources, like those of the
+// streamable HTTP and SSE transports
+type resourceSession struct {
+	*mcpserver.InProcessSession
+	mu        sync.Mutex
+	resources map[string]mcpserver.ServerResource
+}
+
+func (s *resourceSession) GetSessionResources() map[string]mcpserver.ServerResource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.resources)
+}
+
+func (s *resourceSession) SetSessionResources(resources map[string]mcpserver.ServerResource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources = resources
+}
+
+// inSession returns a context in a new initialized session of srv; with
+// sessionResources, the session has its own resources
+func inSession(t *testing.T, srv *Server, id string, sessionResources bool) context.Context {
+	t.Helper()
+	var session mcpserver.ClientSession = mcpserver.NewInProcessSession(id, nil)
+	if sessionResources {
+		session = &resourceSession{InProcessSession: session.(*mcpserver.InProcessSession)}
+	}
+	session.Initialize()
+	ctx := srv.MCPServer().WithContext(context.Background(), session)
+	require.NoError(t, srv.MCPServer().RegisterSession(ctx, session))
+	t.Cleanup(func() { srv.MCPServer().UnregisterSession(ctx, id) })
+	return ctx
+}
+
+// listResources returns the URIs and names of the resources of srv visible
+// in the session of ctx
+func listResources(t *testing.T, ctx context.Context, srv *Server) map[string]string {
+	t.Helper()
+	resp := srv.MCPServer().HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`))
+	result, ok := resp.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", resp)
+	resources := map[string]string{}
+	for _, resource := range result.Result.(mcp.ListResourcesResult).Resources {
+		resources[resource.URI] = resource.Name
+	}
+	return resources
+}
+
+// readResource returns the text of the resource at uri, read in the session
+// of ctx
+func readResource(t *testing.T, ctx context.Context, srv *Server, uri string) mcp.TextResourceContents {
+	t.Helper()
+	resp := srv.MCPServer().HandleMessage(ctx,
+		[]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)))
+	result, ok := resp.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", resp)
+	contents := result.Result.(mcp.ReadResourceResult).Contents
+	require.Len(t, contents, 1)
+	return contents[0].(mcp.TextResourceContents)
+}
+
+func TestRecentResources(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "golang", Results: []searxng.APIResult{
+			{URL: "https://go.dev/", Title: "Go", Content: "Build simple systems"},
+		}})
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("page content"))
+	}))
+	defer site.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+	ctx := inSession(t, srv, "stdio", false)
+	assert.Empty(t, listResources(t, ctx, srv))
+
+	result := callToolResultIn(t, ctx, srv, "searxng_search", map[string]interface{}{"query": "golang"})
+	require.False(t, result.IsError)
+	gock.Off()
+	result = callToolResultIn(t, ctx, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+	require.False(t, result.IsError)
+
+	searchURI := "search://" + resourceKey("golang")
+	pageURI := "page://" + resourceKey(site.URL)
+	assert.Equal(t, map[string]string{searchURI: "Search: golang", pageURI: site.URL}, listResources(t, ctx, srv))
+
+	search := readResource(t, ctx, srv, searchURI)
+	assert.Equal(t, "application/json", search.MIMEType)
+	assert.Contains(t, search.Text, `"url": "https://go.dev/"`)
+	page := readResource(t, ctx, srv, pageURI)
+	assert.Equal(t, "text/markdown", page.MIMEType)
+	assert.Equal(t, "page content", page.Text)
+}
+
+func TestRecentResources_PerSession(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("private page"))
+	}))
+	defer site.Close()
+	srv := New(nil)
+	alice := inSession(t, srv, "alice", true)
+	bob := inSession(t, srv, "bob", true)
+
+	result := callToolResultIn(t, alice, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+	require.False(t, result.IsError)
+	pageURI := "page://" + resourceKey(site.URL)
+	assert.Contains(t, listResources(t, alice, srv), pageURI)
+	assert.Equal(t, "private page", readResource(t, alice, srv, pageURI).Text)
+	assert.Empty(t, listResources(t, bob, srv), "other sessions don't see the page")
+
+	result = callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": site.URL + "/direct"})
+	require.False(t, result.IsError)
+	assert.NotContains(t, listResources(t, bob, srv), "page://"+resourceKey(site.URL+"/direct"), "calls outside of a session register nothing")
+
+	srv.MCPServer().UnregisterSession(alice, "alice")
+	srv.resources.mu.Lock()
+	assert.NotContains(t, srv.resources.sessions, "alice")
+	srv.resources.mu.Unlock()
+}
+
+func TestRecentResources_Eviction(t *testing.T) {
+	srv := New(nil)
+	ctx := inSession(t, srv, "session", true)
+	for i := range maxRecentResources + 2 {
+		srv.resources.addSearch(ctx, fmt.Sprintf("query %d", i), "{}")
+	}
+	srv.resources.addSearch(ctx, "query 2", `{"again":true}`)
+	srv.resources.addSearch(ctx, "query 40", "{}")
+
+	resources := listResources(t, ctx, srv)
+	assert.Len(t, resources, maxRecentResources)
+	assert.NotContains(t, resources, "search://"+resourceKey("query 0"))
+	assert.NotContains(t, resources, "search://"+resourceKey("query 3"), "oldest after query 2 was refreshed")
+	assert.Equal(t, `{"again":true}`, readResource(t, ctx, srv, "search://"+resourceKey("query 2")).Text)
+}
+
+func TestHandleWebSearch_AsResources(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("result page"))
+	}))
+	defer site.Close()
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(searxng.APIResponse{Query: "q", Results: []searxng.APIResult{
+			{URL: site.URL + "/a", Title: "A", Content: "First result"},
+			{URL: site.URL + "/b", Content: "Untitled result"},
+		}})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+	ctx := inSession(t, srv, "session", true)
+	other := inSession(t, srv, "other", true)
+
+	result := callToolResultIn(t, ctx, srv, "searxng_search", map[string]interface{}{"query": "q"})
+	require.False(t, result.IsError)
+	assert.Len(t, result.Content, 1)
+	assert.Len(t, listResources(t, ctx, srv), 1, "only the search itself")
+
+	result = callToolResultIn(t, ctx, srv, "searxng_search", map[string]interface{}{"query": "q", "as_resources": true, "mode": "compact"})
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 3)
+	assert.Equal(t, mcp.NewResourceLink(site.URL+"/a", "A", "First result", "text/markdown"), result.Content[1])
+	assert.Equal(t, mcp.NewResourceLink(site.URL+"/b", site.URL+"/b", "Untitled result", "text/markdown"), result.Content[2])
+
+	resources := listResources(t, ctx, srv)
+	assert.Equal(t, "A", resources[site.URL+"/a"])
+	assert.Empty(t, listResources(t, other, srv), "results are only exposed to the session that searched")
+	assert.Equal(t, "result page", readResource(t, ctx, srv, site.URL+"/a").Text)
+	assert.Contains(t, listResources(t, ctx, srv), "page://"+resourceKey(site.URL+"/a"), "the read page is exposed too")
+}
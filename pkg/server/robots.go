@@ -0,0 +1,247 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+)
+
+// robotsUserAgent is the product token matched against the user-agent lines
+// of robots.txt files. Groups for it take precedence over the * group.
+const robotsUserAgent = "searxng-mcp"
+
+const (
+	// robotsCacheTTL is how long a fetched robots.txt is used; RFC 9309
+	// recommends refreshing it at least daily
+	robotsCacheTTL = 24 * time.Hour
+	// robotsErrorTTL is how long an unreachable robots.txt disallows the
+	// site before it is fetched again
+	robotsErrorTTL = time.Minute
+	// maxRobotsSize is the part of robots.txt that is parsed, per RFC 9309
+	maxRobotsSize = 500 << 10
+	// maxRobotsEntries bounds the number of cached sites
+	maxRobotsEntries = 1024
+)
+
+// robotsRule is an allow or disallow line of a robots.txt group
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsRules are the rules of the groups applying to robotsUserAgent. No
+// rules allow everything.
+type robotsRules []robotsRule
+
+// disallowAll is used when robots.txt couldn't be fetched because of a
+// server or network error
+var disallowAll = robotsRules{{allow: false, pattern: "/"}}
+
+// parseRobots returns the rules of robots.txt that apply to agent: those of
+// every group naming it, or of the * groups when none does
+func parseRobots(r io.Reader, agent string) robotsRules {
+	var (
+		matched, wildcard robotsRules
+		agents            []string
+		inRules           bool // the current group's user-agent lines ended
+		named             bool // some group names agent
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// An empty disallow allows everything
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			for _, a := range agents {
+				switch a {
+				case strings.ToLower(agent):
+					matched = append(matched, rule)
+					named = true
+				case "*":
+					wildcard = append(wildcard, rule)
+				}
+			}
+		}
+	}
+	if named {
+		return matched
+	}
+	return wildcard
+}
+
+// allowed reports whether path (with its query) may be fetched. The
+// longest matching rule wins, and allow wins ties.
+func (rules robotsRules) allowed(path string) bool {
+	if path == "/robots.txt" {
+		return true
+	}
+	allow, longest := true, -1
+	for _, rule := range rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			allow, longest = rule.allow, n
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches path against a robots.txt pattern, where * matches
+// any sequence of characters and a trailing $ anchors the end of the path
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		j := strings.Index(path[pos:], part)
+		if j < 0 {
+			return false
+		}
+		pos += j + len(part)
+	}
+	return !anchored || pos == len(path)
+}
+
+// checkRobots returns an error when robots.txt is enforced and disallows
+// rawURL. Invalid URLs pass, to be rejected by the fetcher.
+func (s *Server) checkRobots(ctx context.Context, rawURL string) error {
+	if s.robots == nil {
+		return nil
+	}
+	if parsedURL, err := validateURL(rawURL); err == nil && !s.robots.allowed(ctx, parsedURL) {
+		return fmt.Errorf("%s is disallowed by the site's robots.txt", rawURL)
+	}
+	return nil
+}
+
+type robotsEntry struct {
+	rules   robotsRules
+	expires time.Time
+}
+
+// robotsPolicy enforces robots.txt for searxng_read, caching the rules of
+// each site. fetchPage checks redirect targets too.
+type robotsPolicy struct {
+	mu        sync.Mutex
+	entries   map[string]robotsEntry // keyed by scheme://host
+	proxy     *url.URL               // used to fetch robots.txt when set
+	transport *http.Transport        // carries robots.txt fetches; nil: http.DefaultTransport
+}
+
+func newRobotsPolicy() *robotsPolicy {
+	return &robotsPolicy{entries: make(map[string]robotsEntry)}
+}
+
+// allowed reports whether the site's robots.txt allows robotsUserAgent to
+// fetch u
+func (p *robotsPolicy) allowed(ctx context.Context, u *url.URL) bool {
+	site := u.Scheme + "://" + u.Host
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	p.mu.Lock()
+	entry, ok := p.entries[site]
+	p.mu.Unlock()
+	if !ok || time.Now().After(entry.expires) {
+		// robots.txt redirects aren't checked against robots.txt
+		entry = fetchRobots(withRedirectCheck(ctx, nil), site, pageClient(p.transport, p.proxy))
+		p.store(site, entry)
+	}
+	return entry.rules.allowed(path)
+}
+
+func (p *robotsPolicy) store(site string, entry robotsEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.entries) >= maxRobotsEntries {
+		now := time.Now()
+		for s, e := range p.entries {
+			if now.After(e.expires) {
+				delete(p.entries, s)
+			}
+		}
+		// Still full: drop an arbitrary site
+		for s := range p.entries {
+			if len(p.entries) < maxRobotsEntries {
+				break
+			}
+			delete(p.entries, s)
+		}
+	}
+	p.entries[site] = entry
+}
+
+// clear drops the cached robots.txt rules
+func (p *robotsPolicy) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	clear(p.entries)
+}
+
+// fetchRobots fetches and parses the robots.txt of site following RFC 9309:
+// a missing file (4xx) allows everything, and server or network errors
+// disallow the site until robotsErrorTTL has passed
+func fetchRobots(ctx context.Context, site string, client *http.Client) robotsEntry {
+	fields := logrus.Fields{"site": site}
+	req, err := newRequest(ctx, site+"/robots.txt", "text/plain")
+	if err != nil {
+		return robotsEntry{rules: disallowAll, expires: time.Now().Add(robotsErrorTTL)}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.WithFields(fields).WithField("error", err).Debug("robots.txt unreachable, disallowing site")
+		return robotsEntry{rules: disallowAll, expires: time.Now().Add(robotsErrorTTL)}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		log.WithFields(fields).WithField("status", resp.StatusCode).Debug("robots.txt unavailable, disallowing site")
+		return robotsEntry{rules: disallowAll, expires: time.Now().Add(robotsErrorTTL)}
+	case resp.StatusCode != http.StatusOK:
+		return robotsEntry{expires: time.Now().Add(robotsCacheTTL)}
+	}
+	return robotsEntry{
+		rules:   parseRobots(io.LimitReader(resp.Body, maxRobotsSize), robotsUserAgent),
+		expires: time.Now().Add(robotsCacheTTL),
+	}
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRobots = `# comments are ignored
+User-agent: *
+Disallow: /private/
+Allow: /private/public$
+
+User-agent: otherbot
+User-agent: searxng-mcp
+Disallow: /search
+Allow: /search/about
+Disallow: /*.pdf$
+Disallow:
+
+User-agent: *
+Disallow: /tmp
+`
+
+func TestParseRobots(t *testing.T) {
+	rules := parseRobots(strings.NewReader(testRobots), robotsUserAgent)
+	assert.Equal(t, robotsRules{
+		{allow: false, pattern: "/search"},
+		{allow: true, pattern: "/search/about"},
+		{allow: false, pattern: "/*.pdf$"},
+	}, rules)
+
+	rules = parseRobots(strings.NewReader(testRobots), "somebot")
+	assert.Equal(t, robotsRules{
+		{allow: false, pattern: "/private/"},
+		{allow: true, pattern: "/private/public$"},
+		{allow: false, pattern: "/tmp"},
+	}, rules)
+
+	assert.Empty(t, parseRobots(strings.NewReader("garbage\n"), robotsUserAgent))
+}
+
+func TestRobotsRules_Allowed(t *testing.T) {
+	rules := robotsRules{
+		{allow: false, pattern: "/private/"},
+		{allow: true, pattern: "/private/public$"},
+		{allow: false, pattern: "/*.pdf$"},
+		{allow: false, pattern: "/a"},
+		{allow: true, pattern: "/a"},
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/private/", false},
+		{"/private/x", false},
+		{"/private/public", true},
+		{"/private/public/x", false},
+		{"/docs/spec.pdf", false},
+		{"/docs/spec.pdf?x=1", true},
+		{"/a", true}, // allow wins ties
+		{"/robots.txt", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rules.allowed(tt.path), tt.path)
+	}
+	assert.True(t, robotsRules(nil).allowed("/anything"))
+	assert.False(t, disallowAll.allowed("/anything"))
+}
+
+func TestRobotsMatch(t *testing.T) {
+	assert.True(t, robotsMatch("/a*b*c", "/a-b-c-d"))
+	assert.False(t, robotsMatch("/a*b*c$", "/a-b-c-d"))
+	assert.True(t, robotsMatch("/a*c$", "/abcbc"))
+	assert.True(t, robotsMatch("/$", "/"))
+	assert.False(t, robotsMatch("/$", "/x"))
+	assert.False(t, robotsMatch("/x", "/"))
+}
+
+func TestRobotsPolicy(t *testing.T) {
+	var fetches atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fetches.Add(1)
+			_, _ = w.Write([]byte(testRobots))
+			return
+		}
+		_, _ = w.Write([]byte("<html><body><p>page</p></body></html>"))
+	}))
+	defer site.Close()
+
+	p := newRobotsPolicy()
+	allowed := func(rawURL string) bool {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		return p.allowed(context.Background(), u)
+	}
+	assert.True(t, allowed(site.URL))
+	assert.False(t, allowed(site.URL+"/search?q=go"))
+	assert.True(t, allowed(site.URL+"/search/about"))
+	assert.Equal(t, int32(1), fetches.Load(), "robots.txt is cached")
+}
+
+func TestRobotsPolicy_Unavailable(t *testing.T) {
+	status := http.StatusNotFound
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer site.Close()
+
+	u, err := url.Parse(site.URL + "/page")
+	require.NoError(t, err)
+	assert.True(t, newRobotsPolicy().allowed(context.Background(), u), "a missing robots.txt allows everything")
+
+	status = http.StatusServiceUnavailable
+	assert.False(t, newRobotsPolicy().allowed(context.Background(), u), "server errors disallow the site")
+}
+
+func TestHandleWebRead_RespectRobots(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: searxng-mcp\nDisallow: /private\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body><p>page</p></body></html>"))
+	}))
+	defer site.Close()
+
+	read := func(srv *Server, path string) *mcp.CallToolResult {
+		result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": site.URL + path}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	srv := NewWithOptions(nil, Options{RespectRobots: true})
+	assert.False(t, read(srv, "/public").IsError)
+	result := read(srv, "/private/page")
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "disallowed by the site's robots.txt")
+
+	assert.False(t, read(New(nil), "/private/page").IsError, "robots.txt is ignored by default")
+}
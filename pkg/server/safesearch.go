@@ -0,0 +1,26 @@
+package server
+
+import "github.com/denysvitali/searxng-mcp/pkg/searxng"
+
+// safeSearchSchema returns the schema of the safe_search argument of the
+// search tools
+func safeSearchSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Filtering of explicit results: 'off', 'moderate' or 'strict'; the server may enforce a stricter level (default: server setting)",
+		"enum":        searxng.SafeSearchLevels,
+	}
+}
+
+// applySafeSearch sets the safe search level of req from the safe_search
+// argument of a tool call, leaving it unset when the argument is absent.
+// The server's level is enforced by search.
+func applySafeSearch(args map[string]interface{}, req *searxng.SearchRequest) error {
+	name, _ := args["safe_search"].(string)
+	level, err := searxng.ParseSafeSearch(name)
+	if err != nil {
+		return err
+	}
+	req.SafeSearch = level
+	return nil
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults and bounds of searxng_search_and_read
+const (
+	defaultSearchAndReadK         = 3
+	maxSearchAndReadK             = 5
+	defaultSearchAndReadMaxLength = 5000
+)
+
+// searchAndReadTool returns the definition of searxng_search_and_read
+func searchAndReadTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "searxng_search_and_read",
+		Description: "Search the web and read the top results in one call. Returns the search results with the Markdown content of each of the top pages, saving a searxng_read call per result.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"query"},
+			Properties: map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The search query string",
+				},
+				"k": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of top results to read (default: 3, min: 1, max: 5)",
+					"minimum":     1,
+					"maximum":     maxSearchAndReadK,
+				},
+				"time_range": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter results by time period: 'day', 'week', 'month', or 'year'",
+					"enum":        []string{"day", "week", "month", "year"},
+				},
+				"safe_search": safeSearchSchema(),
+				"category": map[string]interface{}{
+					"type":        "string",
+					"description": "Search category, one of those of the instance (default: 'general')",
+				},
+				"include_domains": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Only read results on these domains or their subdomains, e.g. ['go.dev'] (a single domain also adds a site: operator)",
+				},
+				"exclude_domains": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Skip results on these domains or their subdomains, e.g. ['pinterest.com']",
+				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"description": "'full' converts whole pages; 'article' extracts only the main article body of each page (default: 'article')",
+					"enum":        []string{"full", "article"},
+				},
+				"max_length": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of characters returned per page; use searxng_read with an offset to continue a truncated page (default: 5000)",
+					"minimum":     1,
+				},
+			},
+		},
+	}
+}
+
+// handleSearchAndRead handles the searxng_search_and_read tool call
+func (s *Server) handleSearchAndRead(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("handling searxng_search_and_read")
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+
+	req := searxng.SearchRequest{
+		Query:    query,
+		Category: s.options.DefaultCategory,
+	}
+	if timeRange, ok := args["time_range"].(string); ok {
+		req.TimeRange = timeRange
+	}
+	if category, ok := args["category"].(string); ok {
+		req.Category = category
+	}
+	if err := applySafeSearch(args, &req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	domains, err := parseDomainFilter(args)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	req.Query = withSiteOperator(req.Query, domains)
+	k := defaultSearchAndReadK
+	if v, ok := args["k"].(float64); ok && v >= 1 {
+		k = min(int(v), maxSearchAndReadK)
+	}
+	maxLength := defaultSearchAndReadMaxLength
+	if v, ok := args["max_length"].(float64); ok && v >= 1 {
+		maxLength = min(int(v), s.readMaxLength())
+	}
+	opts := s.defaultReadOptions()
+	opts.Mode = ReadModeArticle
+	if mode, ok := args["mode"].(string); ok {
+		readMode, err := ParseReadMode(mode)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		opts.Mode = readMode
+	}
+	if err := s.applyProxy(args, &opts); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	client, err := s.sessionClient(ctx, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateInstanceSupport(ctx, client, req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resp, err := s.search(ctx, client, req)
+	if err != nil {
+		log.WithFields(logrus.Fields{"error": err}).Error("search failed")
+		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
+	}
+	resp, offDomain := domains.apply(resp)
+	resp, _ = s.history.record(sessionID(ctx), resp, false)
+
+	output := formatSearchResults(resp)
+	s.annotations.annotate(output)
+	if domains.active() {
+		output["domain_filtered"] = offDomain
+	}
+	results := output["results"].([]map[string]interface{})
+	output["results"] = s.readResults(ctx, results[:min(k, len(results))], opts, maxLength)
+
+	resultJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to format results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// readResults fetches the pages of formatted search results concurrently,
+// adding their content (cut to maxLength) or the error that prevented
+// reading them
+func (s *Server) readResults(ctx context.Context, results []map[string]interface{}, opts readOptions, maxLength int) []map[string]interface{} {
+	var wg sync.WaitGroup
+	for _, result := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url := result["url"].(string)
+			if err := s.checkRead(ctx, url); err != nil {
+				result["error"] = err.Error()
+				return
+			}
+			page, err := s.fetchPage(ctx, url, opts)
+			if err != nil {
+				log.WithFields(logrus.Fields{"url": url, "error": err}).Debug("reading search result failed")
+				result["error"] = fmt.Sprintf("failed to fetch URL: %v", err)
+				return
+			}
+			s.metrics.readBytes.observe(float64(len(page.Markdown)))
+			if page.FetchVariant != "" {
+				result["fetch_variant"] = page.FetchVariant
+			}
+			if page.Mirror != "" {
+				result["mirror"] = page.Mirror
+			}
+			if page.ContentType != "" {
+				result["content_type"] = page.ContentType
+			}
+			if page.StructuredData != nil {
+				result["structured_data"] = page.StructuredData
+			}
+
+			content, chunk, _ := paginateContent(page.Markdown, 0, maxLength)
+			result["content"] = content
+			if chunk.NextOffset != nil {
+				result["truncated"] = true
+				result["total_length"] = chunk.TotalLength
+			}
+			if page.Truncated {
+				result["truncated"] = true
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSearchAndRead(t *testing.T) {
+	pages := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/short":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body><p>Short page</p></body></html>"))
+		case "/long":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body><p>" + strings.Repeat("word ", 200) + "</p></body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer pages.Close()
+
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(searxng.APIResponse{
+			Query: r.URL.Query().Get("q"),
+			Results: []searxng.APIResult{
+				{URL: pages.URL + "/short", Title: "Short"},
+				{URL: pages.URL + "/long", Title: "Long"},
+				{URL: pages.URL + "/missing", Title: "Missing"},
+				{URL: pages.URL + "/unread", Title: "Unread"},
+			},
+		})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+
+	result, err := New(client).handleSearchAndRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_search_and_read", Arguments: map[string]interface{}{
+			"query": "q", "k": float64(3), "mode": "full", "max_length": float64(100),
+		}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var output struct {
+		Query   string                   `json:"query"`
+		Results []map[string]interface{} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	assert.Equal(t, "q", output.Query)
+	require.Len(t, output.Results, 3, "only the top k results are returned")
+
+	assert.Contains(t, output.Results[0]["content"], "Short page")
+	assert.NotContains(t, output.Results[0], "truncated")
+
+	assert.Len(t, output.Results[1]["content"], 100)
+	assert.Equal(t, true, output.Results[1]["truncated"])
+	assert.Greater(t, output.Results[1]["total_length"], float64(100))
+
+	assert.NotContains(t, output.Results[2], "content")
+	assert.Contains(t, output.Results[2]["error"], "failed to fetch URL")
+}
+
+func TestHandleSearchAndRead_MissingQuery(t *testing.T) {
+	result, err := New(nil).handleSearchAndRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_search_and_read", Arguments: map[string]interface{}{}},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -3,31 +3,228 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
 
 	"github.com/denysvitali/searxng-mcp/internal/log"
 	"github.com/denysvitali/searxng-mcp/pkg/searxng"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Server wraps the MCP server and Searxng client
 type Server struct {
 	mcpServer     *mcpserver.MCPServer
 	searxngClient *searxng.Client
+	options       Options
+	translations  map[string]toolTranslation
+	toolStats     *toolStats
+	metrics       *serverMetrics
+	instances     *instancePool
+	history       *sessionHistory
+	imageProxy    *imageProxy          // nil when Options.ImageProxyURL is unset
+	robots        *robotsPolicy        // nil unless Options.RespectRobots is set
+	proxy         *url.URL             // parsed Options.ProxyURL, nil with Options.ProxyFallback
+	retryProxy    *url.URL             // parsed Options.ProxyURL with Options.ProxyFallback
+	proxies       map[string]*url.URL  // parsed Options.ProxyAllowlist
+	readLimiter   *searxng.RateLimiter // nil unless Options.ReadRateLimit is set
+	domains       domainFilter         // Options.IncludeDomains and ExcludeDomains
+	annotations   domainAnnotations    // Options.DomainAnnotations
+	readCache     *readCache           // nil unless Options.ReadCacheTTL is set
+	diskCache     *diskCache           // nil unless Options.ReadDiskCacheDir is set
+	resources     *recentResources
+	lifecycle     *lifecycle
+	idle          *idleState
+	embedded      bool // the tools were registered by RegisterTools
 }
 
-// New creates a new MCP server. Extra mcpserver.ServerOptions (e.g. tracing
-// middleware) can be appended via extraOpts.
+// Options holds tool-level settings of the MCP server
+type Options struct {
+	// Boilerplate is the default trailing boilerplate removal level for
+	// searxng_read. The empty value disables removal.
+	Boilerplate BoilerplateLevel
+
+	// ToolLocale is the language tool descriptions are registered in (see
+	// SupportedToolLocales). The empty value means English.
+	ToolLocale string
+
+	// DefaultLimit is the searxng_search result limit used when the caller
+	// doesn't pass one (0: the client default of 5)
+	DefaultLimit int
+
+	// DefaultCategory is the searxng_search category used when the caller
+	// doesn't pass one
+	DefaultCategory string
+
+	// SafeSearch is the safe search level of searches, and the least strict
+	// level callers may request with the safe_search argument, so
+	// family-safe deployments can enforce filtering. The zero value leaves
+	// it to the instance and callers.
+	SafeSearch searxng.SafeSearch
+
+	// InstanceAllowlist lists Searxng instance URLs callers may select per
+	// request with searxng_search's instance_url argument, or as the default
+	// of their session (see sessionconfig.go). The argument is only offered
+	// when the list is non-empty.
+	InstanceAllowlist []string
+
+	// StatusPath is the path of the HTML status page in HTTP mode (see
+	// StatusHandler). The empty value disables the page.
+	StatusPath string
+
+	// MetricsPath is the path of the Prometheus metrics endpoint in HTTP
+	// mode (see MetricsHandler). The empty value disables the endpoint.
+	MetricsPath string
+
+	// ImageProxyURL is the public URL of the server's image proxy endpoint
+	// in HTTP mode, e.g. https://mcp.example.com/image_proxy. When set, the
+	// endpoint is served at its path and image URLs of results are
+	// rewritten to it, so clients never connect to result hosts directly.
+	ImageProxyURL string
+
+	// ToolOverrides replaces tool names and descriptions, keyed by
+	// built-in tool name (see ValidateToolOverrides). Overrides are applied
+	// after ToolLocale.
+	ToolOverrides map[string]ToolOverride
+
+	// DisabledTools are built-in tools that aren't registered (see
+	// ValidateDisabledTools), e.g. searxng_read for a search-only server.
+	// Server.Read stays available to embedders.
+	DisabledTools []string
+
+	// RespectRobots makes searxng_read refuse URLs disallowed for the
+	// searxng-mcp user agent by the site's robots.txt
+	RespectRobots bool
+
+	// ProxyURL routes page fetches (searxng_read, link checks, robots.txt
+	// and the image proxy) through an HTTP(S) or SOCKS5 proxy (see
+	// searxng.ParseProxyURL). Searches use searxng.Config.ProxyURL.
+	ProxyURL string
+
+	// ProxyFallback fetches pages directly and only uses ProxyURL to retry
+	// pages answering 403
+	ProxyFallback bool
+
+	// ProxyAllowlist lists the proxies callers may select per read with
+	// the proxy argument of searxng_read and searxng_search_and_read, which
+	// is only offered when it is set. Other proxies are refused, so clients
+	// can't make the server connect to hosts of their choosing.
+	ProxyAllowlist []string
+
+	// ReadRateLimit is the maximum number of pages fetched per second by
+	// searxng_read and searxng_search_and_read, limited independently of
+	// searches (0: unlimited). ReadRateBurst pages may be fetched at once
+	// (0: ReadRateLimit).
+	ReadRateLimit int
+	ReadRateBurst int
+
+	// ReadCacheTTL is how long read pages are reused by identical reads and
+	// by the expand_snippets option of searxng_search (0: no cache).
+	// ReadCacheSize caps the cached pages (0: DefaultReadCacheSize).
+	ReadCacheTTL  time.Duration
+	ReadCacheSize int
+
+	// ReadDiskCacheDir keeps fetched pages in this directory across
+	// restarts, revalidated with If-None-Match/If-Modified-Since and served
+	// without a request while fresh (empty: no disk cache).
+	// ReadDiskCacheSize caps the cached pages (0: DefaultReadDiskCacheSize).
+	ReadDiskCacheDir  string
+	ReadDiskCacheSize int
+
+	// ReadMaxBytes cuts pages downloaded beyond it, so giant pages can't
+	// exhaust memory (0: DefaultReadMaxBytes). ReadMaxLength caps the
+	// characters returned by a searxng_read call, whatever its max_length
+	// (0: DefaultReadMaxLength); the rest is read with an offset.
+	ReadMaxBytes  int64
+	ReadMaxLength int
+
+	// MaxRequestTimeout bounds the timeout_seconds clients may pass to
+	// searxng_search and searxng_read (0: DefaultMaxRequestTimeout)
+	MaxRequestTimeout time.Duration
+
+	// ReadMirrors are URL templates, e.g. https://r.jina.ai/{url}, that
+	// generic pages failing to fetch with a network error, a 403 (after
+	// the 403 retry) or a server error are read through, in order (see
+	// ValidateReadMirrors). Pages refused by the domain lists or robots.txt
+	// aren't. Without any, failed reads aren't retried.
+	ReadMirrors []string
+
+	// IncludeDomains restricts search results and page reads to these
+	// domains and their subdomains (empty: any domain); ExcludeDomains
+	// drops results on and refuses reads of these domains. Clients can
+	// narrow them per search with include_domains and exclude_domains.
+	IncludeDomains []string
+	ExcludeDomains []string
+
+	// DomainAnnotations labels the search results on these domains and
+	// their subdomains, e.g. {"wikipedia.org": "authoritative"}, as their
+	// domain_label, steering agents toward preferred sources without
+	// filtering (see LoadDomainAnnotations)
+	DomainAnnotations map[string]string
+
+	// Transport carries page fetches (searxng_read, link checks, robots.txt
+	// and the image proxy), so they can share a tuned connection pool with
+	// the Searxng client (see searxng.NewPooledTransport). Fetches through a
+	// proxy use a copy of it. nil uses http.DefaultTransport.
+	Transport *http.Transport
+
+	// DetectLanguage makes searxng_search detect the language of queries
+	// without a language argument and search in it when the instance
+	// supports it, so non-English queries get localized results
+	DetectLanguage bool
+
+	// LogToolResults logs the arguments and results of tool calls, which
+	// may hold sensitive queries and large pages, whole or truncated. The
+	// empty value logs none.
+	LogToolResults ToolLogging
+
+	// AuthTokens are the bearer tokens or API keys (X-API-Key header)
+	// accepted by the http and sse transports and ServeGRPC; without any,
+	// requests aren't authenticated. The image proxy, whose URLs are signed, is exempt.
+	AuthTokens []string
+	// AdminTokens are accepted like AuthTokens and additionally allow
+	// calling the admin tools (searxng_set_log_level), which are only
+	// registered with admin tokens. Only the http and sse transports carry
+	// tokens.
+	AdminTokens []string
+	// CORSOrigins are the browser origins allowed to call the http and sse
+	// transports ("*": any); without any, no CORS headers are sent
+	CORSOrigins []string
+	// LogRequests logs every HTTP request with its status and duration
+	LogRequests bool
+}
+
+// New creates a new MCP server with default Options. Extra
+// mcpserver.ServerOptions (e.g. tracing middleware) can be appended via
+// extraOpts.
 func New(client *searxng.Client, extraOpts ...mcpserver.ServerOption) *Server {
-	s := &Server{
-		searxngClient: client,
-	}
+	return NewWithOptions(client, Options{}, extraOpts...)
+}
+
+// NewWithOptions creates a new MCP server with the given Options
+func NewWithOptions(client *searxng.Client, options Options, extraOpts ...mcpserver.ServerOption) *Server {
+	s := newServer(client, options)
+
+	hooks := &mcpserver.Hooks{}
+	s.AddHooks(hooks)
 
 	// Create MCP server
 	opts := []mcpserver.ServerOption{
 		mcpserver.WithToolCapabilities(true),
+		mcpserver.WithPromptCapabilities(false),
+		mcpserver.WithResourceCapabilities(false, true),
+		mcpserver.WithToolHandlerMiddleware(s.toolStats.middleware),
+		mcpserver.WithToolHandlerMiddleware(s.lifecycle.middleware),
+		mcpserver.WithToolHandlerMiddleware(s.idle.middleware),
+		mcpserver.WithToolHandlerMiddleware(s.logToolCalls),
+		mcpserver.WithHooks(hooks),
 	}
 	opts = append(opts, extraOpts...)
 
@@ -36,15 +233,103 @@ func New(client *searxng.Client, extraOpts ...mcpserver.ServerOption) *Server {
 		"1.0.0",
 		opts...,
 	)
+	s.attach(mcpServer)
 
-	s.mcpServer = mcpServer
+	// Register tools and prompts
+	s.registerTools()
+	s.registerPrompts()
 
-	// Register tools
+	return s
+}
+
+// RegisterTools registers the searxng tools onto mcpServer, an MCP server
+// created by the caller, e.g. to serve them alongside tools of its own. The
+// tools get the middleware NewWithOptions installs server-wide (stats,
+// shutdown draining, idle release and tool call logging), applied to them
+// only. Prompts aren't registered.
+//
+// The returned Server holds the state of the tools; its methods (Stats,
+// Shutdown, AdaptToInstance, ...) work as with NewWithOptions. Pass the
+// hooks of mcpServer to AddHooks to release the state of ended sessions.
+func RegisterTools(mcpServer *mcpserver.MCPServer, client *searxng.Client, options Options) *Server {
+	s := newServer(client, options)
+	s.embedded = true
+	s.attach(mcpServer)
 	s.registerTools()
+	return s
+}
+
+// newServer creates a Server without its MCP server
+func newServer(client *searxng.Client, options Options) *Server {
+	s := &Server{
+		searxngClient: client,
+		options:       options,
+		toolStats:     newToolStats(),
+		metrics:       newServerMetrics(),
+		instances:     newInstancePool(client, options.InstanceAllowlist),
+		history:       newSessionHistory(),
+		resources:     &recentResources{},
+		imageProxy:    newImageProxy(options.ImageProxyURL),
+		lifecycle:     newLifecycle(),
+		idle:          newIdleState(),
+		readCache:     newReadCache(options.ReadCacheTTL, options.ReadCacheSize),
+		domains:       newOperatorDomains(options),
+		annotations:   newDomainAnnotations(options.DomainAnnotations),
+		proxies:       newProxyAllowlist(options.ProxyAllowlist),
+	}
+	if options.ProxyURL != "" {
+		proxy, err := searxng.ParseProxyURL(options.ProxyURL)
+		if err != nil {
+			log.WithField("error", err).Error("refusing page fetches")
+			s.options.Transport = refusingTransport(err)
+		}
+		s.proxy = proxy
+		if options.ProxyFallback {
+			s.proxy, s.retryProxy = nil, proxy
+		}
+	}
+	if options.ReadDiskCacheDir != "" {
+		cache, err := newDiskCache(options.ReadDiskCacheDir, options.ReadDiskCacheSize)
+		if err != nil {
+			log.WithField("error", err).Error("not caching read pages on disk")
+		}
+		s.diskCache = cache
+	}
+	if options.ReadRateLimit > 0 {
+		s.readLimiter = searxng.NewRateLimiter(options.ReadRateLimit, options.ReadRateBurst)
+	}
+	if options.RespectRobots {
+		s.robots = newRobotsPolicy()
+		s.robots.proxy, s.robots.transport = s.proxy, s.options.Transport
+	}
+	if s.imageProxy != nil {
+		s.imageProxy.proxy, s.imageProxy.transport = s.proxy, s.options.Transport
+	}
 
+	translations, err := loadToolTranslations(options.ToolLocale)
+	if err != nil {
+		log.WithField("error", err).Warn("falling back to English tool descriptions")
+	}
+	s.translations = translations
 	return s
 }
 
+// attach sets the MCP server the tools are registered onto
+func (s *Server) attach(mcpServer *mcpserver.MCPServer) {
+	s.mcpServer = mcpServer
+	s.resources.mcpServer = mcpServer
+}
+
+// AddHooks adds the session hooks of the server to hooks: the state kept
+// per session (e.g. for novel_only) is released when the session ends, and
+// the session defaults of initializing clients are checked.
+// NewWithOptions does it for its own MCP server.
+func (s *Server) AddHooks(hooks *mcpserver.Hooks) {
+	hooks.AddOnUnregisterSession(s.history.forget)
+	hooks.AddOnUnregisterSession(s.resources.forget)
+	hooks.AddAfterInitialize(s.checkSessionDefaults)
+}
+
 // registerTools registers all available tools
 func (s *Server) registerTools() {
 	// Register searxng_search tool
@@ -67,22 +352,123 @@ func (s *Server) registerTools() {
 				},
 				"time_range": map[string]interface{}{
 					"type":        "string",
-					"description": "Filter results by time period: 'day', 'month', or 'year'",
-					"enum":        []string{"day", "month", "year"},
+					"description": "Filter results by time period: 'day', 'week', 'month', or 'year'",
+					"enum":        []string{"day", "week", "month", "year"},
 				},
+				"safe_search": safeSearchSchema(),
 				"category": map[string]interface{}{
 					"type":        "string",
-					"description": "Search category: 'general' (default), 'images', 'videos', 'news', 'map', 'music', 'it', 'science'",
+					"description": "Search category, one of those of the instance (default: 'general')",
 				},
 				"page": map[string]interface{}{
 					"type":        "number",
 					"description": "Page number for pagination (default: 1)",
 					"minimum":     1,
 				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Language or region of the results as a locale code, e.g. 'de', 'fr-CA' or 'pt-BR', checked against the locales of the instance; 'all' searches every language and 'auto' lets the instance choose (default: the instance's setting)",
+				},
+				"filetype": map[string]interface{}{
+					"type":        "string",
+					"description": "Only return documents of this type, e.g. 'pdf' for specifications and papers (adds a filetype: operator and drops results with other URL extensions)",
+					"enum":        fileTypes(),
+				},
+				"exact_phrase": map[string]interface{}{
+					"type":        "string",
+					"description": "Only return results containing this exact phrase; it is quoted for you, so pass the bare words",
+				},
+				"must_include": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Words or phrases every result must contain, e.g. ['kubernetes', 'pod security']; they are quoted for you",
+				},
+				"must_exclude": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Words or phrases no result may contain, e.g. ['pinterest', 'sponsored']; the - operator is added for you",
+				},
+				"include_domains": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Only return results on these domains or their subdomains, e.g. ['go.dev', 'pkg.go.dev'] for official documentation (a single domain also adds a site: operator)",
+				},
+				"exclude_domains": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Drop results on these domains or their subdomains, e.g. ['pinterest.com'] to skip content farms",
+				},
+				"novel_only": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Drop results already returned earlier in this session, so refined queries only surface new pages (default: false)",
+				},
+				"engines": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Only query these Searxng engines, e.g. ['wikipedia'], ['github', 'stackoverflow'] or ['arxiv']; names are checked against the engines enabled on the instance (default: the instance's engines for the category)",
+				},
+				"engine_args": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": map[string]interface{}{"type": "object", "properties": map[string]interface{}{"language": map[string]interface{}{"type": "string"}}},
+					"description":          "Per-engine settings keyed by engine name, e.g. {'wikipedia': {'language': 'de'}} to search the German Wikipedia while other engines use the request language. Supported settings: language. Each engine with settings is queried separately and its results are merged",
+				},
+				"verify_links": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Check that result URLs are reachable before returning them: dead links (404, 410, unknown host) are dropped and inconclusive checks are flagged with link_unverified. Slower; use it before reading several results (default: false)",
+				},
+				"rank_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Reorder the results: 'score' (instance score), 'consensus' (number of engines agreeing), 'recency' (newest first) or 'weighted' (all signals plus the operator's trusted domains); 'instance' keeps the instance's order (default: the server's setting)",
+					"enum":        []string{"instance", "score", "consensus", "recency", "weighted"},
+				},
+				"include_infoboxes": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Include the infoboxes some engines return for entity queries (e.g. a person, place or software project): label, summary, attribution, images and related URLs, often answering the question directly (default: false)",
+				},
+				"timeout_seconds": map[string]interface{}{
+					"type":        "number",
+					"description": "Timeout of the instance's HTTP requests in seconds, e.g. 60 for a slow instance or engine; fails with a timeout error when exceeded (default: server timeout; maximum: server setting)",
+					"minimum":     1,
+				},
+				"deadline_ms": map[string]interface{}{
+					"type":        "number",
+					"description": "Latency budget of the search in milliseconds. When the instance hasn't answered in full by then, the results read so far (possibly none) are returned with partial: true instead of an error (default: no deadline besides the server timeout)",
+					"minimum":     1,
+				},
+				"as_resources": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also register each result as an MCP resource (URI: the result URL, description: the snippet) and link it from the response; reading a resource fetches the page (default: false)",
+				},
+				"expand_snippets": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Replace the snippets of results whose page was read recently with the passage of the page most relevant to the query (marked snippet_source: 'cached_page'); makes no extra requests (default: false)",
+				},
+				"enrich": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Read the pages of the top results concurrently and replace their snippets with the first ~500 characters of the article text (marked snippet_source: 'page'), for more context before choosing a page to read in full. Slower: waits for the pages (default: false)",
+				},
+				"enrich_count": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of top results enrich reads (default: 3)",
+					"minimum":     1,
+					"maximum":     maxEnrichCount,
+				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"description": "'json' returns the results as JSON; 'compact' returns one short Markdown paragraph per result (title, snippet, URL) with minimal overhead, for small context windows (default: 'json')",
+					"enum":        []string{searchModeJSON, searchModeCompact},
+				},
 			},
 		},
+		OutputSchema: searchOutputSchema(),
 	}
-	s.mcpServer.AddTool(webSearchTool, s.handleWebSearch)
+	if s.instances.enabled() {
+		webSearchTool.InputSchema.Properties["instance_url"] = map[string]interface{}{
+			"type":        "string",
+			"description": "Searxng instance to send this search to; must be one of the instances allowed by the server operator (default: the server's instance)",
+		}
+	}
+	s.addTool(webSearchTool, s.handleWebSearch)
 
 	// Register searxng_read tool
 	webReadTool := mcp.Tool{
@@ -96,15 +482,204 @@ func (s *Server) registerTools() {
 					"type":        "string",
 					"description": "The URL to fetch and read",
 				},
+				"boilerplate": map[string]interface{}{
+					"type":        "string",
+					"description": "Removal of trailing comments, related articles, newsletter signups and cookie notices: 'off', 'normal' or 'aggressive' (default: server setting)",
+					"enum":        []string{"off", "normal", "aggressive"},
+				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"description": "'full' converts the whole page; 'article' extracts only the main article body with its title, byline and published date (default: 'full')",
+					"enum":        []string{"full", "article"},
+				},
+				"offset": map[string]interface{}{
+					"type":        "number",
+					"description": "Character offset to start reading from, for continuing a long page (default: 0)",
+					"minimum":     0,
+				},
+				"max_length": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of characters to return; the response says which offset to continue from (default and maximum: server setting)",
+					"minimum":     1,
+				},
+				"timeout_seconds": map[string]interface{}{
+					"type":        "number",
+					"description": "Timeout of the page fetch in seconds, for slow sites or large files (default: 30; maximum: server setting)",
+					"minimum":     1,
+				},
+				"byte_range": map[string]interface{}{
+					"type":        "string",
+					"description": "Only read these bytes of a large plain-text resource (log, raw source file, big README), e.g. '0-65535', '65536-' or '-4096' for the last 4096 bytes. Uses an HTTP Range request when the server supports it. Not for HTML pages.",
+				},
+				"line_range": map[string]interface{}{
+					"type":        "string",
+					"description": "Only read these lines of a large plain-text resource, 1-based and inclusive, e.g. '100-200' or '5000-'; the download stops after the last line. Not for HTML pages.",
+				},
+				"include_html": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also return the page's sanitized HTML (scripts and event handlers removed) as a second text/html content block, for extraction that needs attributes lost in Markdown (default: false)",
+				},
 			},
 		},
 	}
-	s.mcpServer.AddTool(webReadTool, s.handleWebRead)
+	if len(s.proxies) > 0 {
+		webReadTool.InputSchema.Properties["proxy"] = proxyProperty()
+	}
+	s.addTool(webReadTool, s.handleWebRead)
+
+	// Register searxng_refine_search tool
+	refineSearchTool := mcp.Tool{
+		Name:        "searxng_refine_search",
+		Description: "Rewrite a previous search according to feedback (e.g. 'too broad', 'need recent', 'only official docs', 'site:go.dev', 'exclude java') and run it. Returns the rewritten query, the applied rewrites and the new results.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"query", "feedback"},
+			Properties: map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The previous search query",
+				},
+				"feedback": map[string]interface{}{
+					"type":        "string",
+					"description": "What was wrong with the previous results, e.g. 'too broad, need recent'",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of results used by the previous search (default: 5, min: 1, max: 20)",
+					"minimum":     1,
+					"maximum":     20,
+				},
+				"time_range": map[string]interface{}{
+					"type":        "string",
+					"description": "Time range used by the previous search: 'day', 'week', 'month', or 'year'",
+					"enum":        []string{"day", "week", "month", "year"},
+				},
+				"category": map[string]interface{}{
+					"type":        "string",
+					"description": "Category used by the previous search",
+				},
+			},
+		},
+		OutputSchema: searchOutputSchema(),
+	}
+	s.addTool(refineSearchTool, s.handleRefineSearch)
+
+	// Register searxng_image_search tool
+	imageSearchTool := mcp.Tool{
+		Name:        "searxng_image_search",
+		Description: "Search for images. Returns the image URL, thumbnail, resolution and the page each image appears on.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"query"},
+			Properties: map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The image search query string",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of images to return (default: 10, min: 1, max: 50)",
+					"minimum":     1,
+					"maximum":     50,
+				},
+				"time_range": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter images by time period: 'day', 'week', 'month', or 'year'",
+					"enum":        []string{"day", "week", "month", "year"},
+				},
+				"safe_search": safeSearchSchema(),
+				"page": map[string]interface{}{
+					"type":        "number",
+					"description": "Page number for pagination (default: 1)",
+					"minimum":     1,
+				},
+			},
+		},
+	}
+	s.addTool(imageSearchTool, s.handleImageSearch)
+
+	// Register searxng_media_search tool
+	mediaSearchTool := mcp.Tool{
+		Name:        "searxng_media_search",
+		Description: "Search for images and videos. Returns thumbnails, the image URL and resolution of images, the duration and player URL of videos, and the page each result comes from.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"query"},
+			Properties: map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The media search query string",
+				},
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "Media to search for: 'all' (default, images and videos interleaved), 'image' or 'video'",
+					"enum":        []string{"all", "image", "video"},
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of results to return (default: 10, min: 1, max: 50)",
+					"minimum":     1,
+					"maximum":     50,
+				},
+				"time_range": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter results by time period: 'day', 'week', 'month', or 'year'",
+					"enum":        []string{"day", "week", "month", "year"},
+				},
+				"safe_search": safeSearchSchema(),
+				"page": map[string]interface{}{
+					"type":        "number",
+					"description": "Page number for pagination (default: 1)",
+					"minimum":     1,
+				},
+			},
+		},
+	}
+	s.addTool(mediaSearchTool, s.handleMediaSearch)
+
+	// Register searxng_search_and_read tool
+	searchAndRead := searchAndReadTool()
+	if len(s.proxies) > 0 {
+		searchAndRead.InputSchema.Properties["proxy"] = proxyProperty()
+	}
+	s.addTool(searchAndRead, s.handleSearchAndRead)
+
+	// Register searxng_suggestions tool
+	s.addTool(suggestionsTool(), s.handleSuggestions)
+
+	// Register searxng_compare_pages tool
+	s.addTool(comparePagesTool(), s.handleComparePages)
+
+	// Register searxng_extract_links tool
+	s.addTool(extractLinksTool(), s.handleExtractLinks)
+
+	// Register the admin tools, which need an admin token
+	if len(s.options.AdminTokens) > 0 {
+		s.addTool(setLogLevelTool(), s.handleSetLogLevel)
+	}
+}
+
+// addTool localizes a tool definition, applies the operator's overrides
+// and registers it with the MCP server, unless it is disabled
+func (s *Server) addTool(tool mcp.Tool, handler mcpserver.ToolHandlerFunc) {
+	if s.toolDisabled(tool.Name) {
+		log.WithField("tool", tool.Name).Debug("tool disabled, not registering it")
+		return
+	}
+	localizeTool(&tool, s.translations)
+	s.overrideTool(&tool)
+	if s.embedded {
+		// The MCP server isn't ours, so the middleware is applied per tool
+		handler = s.toolStats.middleware(s.lifecycle.middleware(s.idle.middleware(s.logToolCalls(handler))))
+	}
+	s.mcpServer.AddTool(tool, handler)
 }
 
 // handleWebSearch handles the searxng_search tool call
 func (s *Server) handleWebSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	log.WithField("request", request).Debug("handling searxng_search")
+	log.Debug("handling searxng_search")
+	ctx, span := startSpan(ctx, "searxng_search")
+	defer span.End()
 
 	// Parse arguments
 	args, ok := request.Params.Arguments.(map[string]interface{})
@@ -120,7 +695,9 @@ func (s *Server) handleWebSearch(ctx context.Context, request mcp.CallToolReques
 
 	// Build search request
 	req := searxng.SearchRequest{
-		Query: query,
+		Query:    query,
+		Limit:    s.options.DefaultLimit,
+		Category: s.options.DefaultCategory,
 	}
 
 	// Extract optional parameters
@@ -136,28 +713,197 @@ func (s *Server) handleWebSearch(ctx context.Context, request mcp.CallToolReques
 	if page, ok := args["page"].(float64); ok {
 		req.Page = int(page)
 	}
+	if err := applySafeSearch(args, &req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if language, ok := args["language"].(string); ok {
+		req.Language = language
+	}
+	if rankBy, ok := args["rank_by"].(string); ok && rankBy != "" {
+		strategy, err := searxng.ParseRankStrategy(rankBy)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		req.RankBy = strategy
+	}
+	novelOnly, _ := args["novel_only"].(bool)
+	verify, _ := args["verify_links"].(bool)
+	expand, _ := args["expand_snippets"].(bool)
+	enrich, _ := args["enrich"].(bool)
+	if enrich && s.toolDisabled("searxng_read") {
+		return mcp.NewToolResultError("enrich reads pages, which this server doesn't allow (searxng_read is disabled)"), nil
+	}
+	enrichCount := defaultEnrichCount
+	if n, ok := args["enrich_count"].(float64); ok {
+		if n < 1 {
+			return mcp.NewToolResultError("enrich_count must be at least 1"), nil
+		}
+		enrichCount = min(int(n), maxEnrichCount)
+	}
+	asResources, _ := args["as_resources"].(bool)
+	includeInfoboxes, _ := args["include_infoboxes"].(bool)
+	timeout, err := s.requestTimeout(args)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	req.Timeout = timeout
+	if deadline, ok := args["deadline_ms"].(float64); ok {
+		if deadline < 1 {
+			return mcp.NewToolResultError("deadline_ms must be at least 1"), nil
+		}
+		req.Deadline = time.Duration(deadline * float64(time.Millisecond))
+	}
+	mode := searchModeJSON
+	if m, ok := args["mode"].(string); ok && m != "" {
+		if m != searchModeJSON && m != searchModeCompact {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid mode: %s (must be 'json' or 'compact')", m)), nil
+		}
+		mode = m
+	}
+	ops, err := parseQueryOperators(args)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	req.Query = ops.apply(req.Query)
+	domains, err := parseDomainFilter(args)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	req.Query = withSiteOperator(req.Query, domains)
+	var fileType string
+	if ft, ok := args["filetype"].(string); ok && ft != "" {
+		parsed, err := parseFileType(ft)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		fileType = parsed
+		req.Query = withFileTypeOperator(req.Query, fileType)
+	}
 
-	log.WithField("request", req).Debug("searching")
+	instanceURL, _ := args["instance_url"].(string)
+	client, err := s.sessionClient(ctx, instanceURL)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	applySessionDefaults(ctx, &req)
+
+	var detected string
+	if req.Language == "" && s.options.DetectLanguage {
+		detected = queryLanguage(ctx, client, query)
+		req.Language = detected
+	}
+
+	engines, err := parseEngines(args["engines"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(engines) > 0 {
+		if err := validateEngines(ctx, client, engines); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		req.Engines = engines
+	}
+
+	engineArgs, err := parseEngineArgs(args["engine_args"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(engineArgs) > 0 {
+		if err := validateEngines(ctx, client, slices.Sorted(maps.Keys(engineArgs))); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		req.EngineArgs = engineArgs
+	}
+	if err := validateInstanceSupport(ctx, client, req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	log.WithFields(logrus.Fields{"category": req.Category, "language": req.Language, "page": req.Page}).Debug("searching")
 
 	// Perform search
-	resp, err := s.searxngClient.Search(ctx, req)
+	resp, err := s.search(ctx, client, req)
 	if err != nil {
 		log.WithFields(logrus.Fields{"error": err}).Error("search failed")
+		spanError(ctx, err)
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
+	span.SetAttributes(attribute.Int("searxng.results", len(resp.Results)))
+
+	var wrongType int
+	if fileType != "" {
+		resp, wrongType = filterByFileType(resp, fileType)
+	}
+	resp, offDomain := domains.apply(resp)
+	var dead int
+	var unverified map[string]string
+	if verify {
+		verifyCtx, verifySpan := startSpan(ctx, "verify_links")
+		resp, dead, unverified = verifyLinks(verifyCtx, resp, pageClient(s.options.Transport, s.proxy))
+		verifySpan.SetAttributes(attribute.Int("searxng.dead_links", dead))
+		verifySpan.End()
+	}
+	resp, seen := s.history.record(sessionID(ctx), resp, novelOnly)
+	output := formatSearchResults(resp)
+	s.annotations.annotate(output)
+	if fileType != "" {
+		output["filetype_filtered"] = wrongType
+	}
+	if domains.active() {
+		output["domain_filtered"] = offDomain
+	}
+	if verify {
+		output["dead_links_removed"] = dead
+		for _, result := range output["results"].([]map[string]interface{}) {
+			if reason, ok := unverified[result["url"].(string)]; ok {
+				result["link_unverified"] = reason
+			}
+		}
+	}
+	if novelOnly {
+		output["seen_results_filtered"] = seen
+	}
+	if detected != "" {
+		output["detected_language"] = detected
+	}
+	if includeInfoboxes && len(resp.Infoboxes) > 0 {
+		output["infoboxes"] = formatInfoboxes(resp.Infoboxes, s.imageProxy)
+	}
+	if expand {
+		_, expandSpan := startSpan(ctx, "expand_snippets")
+		s.expandSnippets(output["results"].([]map[string]interface{}), query)
+		expandSpan.End()
+	}
+	if enrich {
+		enrichCtx, enrichSpan := startSpan(ctx, "enrich_snippets")
+		output["enriched"] = s.enrichSnippets(enrichCtx, output["results"].([]map[string]interface{}), enrichCount)
+		enrichSpan.End()
+	}
 
-	// Format results as JSON
-	resultJSON, err := json.MarshalIndent(formatSearchResults(resp), "", "  ")
+	// Format results as JSON, also returned as structured content and
+	// exposed as a search:// resource
+	resultJSON, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to format results: %v", err)), nil
 	}
+	s.resources.addSearch(ctx, query, string(resultJSON))
 
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	var result *mcp.CallToolResult
+	if mode == searchModeCompact {
+		result = mcp.NewToolResultStructured(output, formatCompactResults(output))
+	} else {
+		result = mcp.NewToolResultStructured(output, string(resultJSON))
+	}
+	if asResources {
+		result.Content = append(result.Content, s.addResults(ctx, output["results"].([]map[string]interface{}))...)
+	}
+	return result, nil
 }
 
 // handleWebRead handles the searxng_read tool call
 func (s *Server) handleWebRead(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	log.WithField("request", request).Debug("handling searxng_read")
+	log.Debug("handling searxng_read")
+	ctx, span := startSpan(ctx, "searxng_read")
+	defer span.End()
 
 	// Parse arguments
 	args, ok := request.Params.Arguments.(map[string]interface{})
@@ -171,16 +917,117 @@ func (s *Server) handleWebRead(ctx context.Context, request mcp.CallToolRequest)
 		return mcp.NewToolResultError("url is required"), nil
 	}
 
+	opts := s.defaultReadOptions()
+	if boilerplate, ok := args["boilerplate"].(string); ok {
+		level, err := ParseBoilerplateLevel(boilerplate)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		opts.Boilerplate = level
+	}
+	if mode, ok := args["mode"].(string); ok {
+		readMode, err := ParseReadMode(mode)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		opts.Mode = readMode
+	}
+	if includeHTML, ok := args["include_html"].(bool); ok {
+		opts.IncludeHTML = includeHTML
+	}
+	if err := s.applyProxy(args, &opts); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	timeout, err := s.requestTimeout(args)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts.Timeout = timeout
+	offset, maxLength := 0, 0
+	if o, ok := args["offset"].(float64); ok {
+		offset = int(o)
+	}
+	if l, ok := args["max_length"].(float64); ok {
+		maxLength = int(l)
+	}
+	paginated := offset > 0 || maxLength > 0
+	var rng readRange
+	if byteRange, _ := args["byte_range"].(string); byteRange != "" {
+		r, err := parseByteRange(byteRange)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		rng.bytes = r
+	}
+	if lineRange, _ := args["line_range"].(string); lineRange != "" {
+		if rng.bytes != nil {
+			return mcp.NewToolResultError("byte_range and line_range can't be combined"), nil
+		}
+		r, err := parseLineRange(lineRange)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		rng.lines = r
+	}
+	capped := maxLength <= 0 || maxLength > s.readMaxLength()
+	if capped {
+		maxLength = s.readMaxLength()
+	}
+
+	if err := s.checkRead(ctx, url); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	log.WithField("url", url).Debug("reading URL")
 
 	// Fetch and parse the URL
-	content, err := fetchURLContent(ctx, url)
+	var page *readResult
+	if rng.bytes != nil || rng.lines != nil {
+		page, err = s.fetchRange(ctx, url, opts, rng)
+	} else {
+		page, err = s.fetchPage(ctx, url, opts)
+	}
 	if err != nil {
 		log.WithFields(logrus.Fields{"error": err}).Error("fetch URL failed")
+		spanError(ctx, err)
 		return mcp.NewToolResultError(fmt.Sprintf("failed to fetch URL: %v", err)), nil
 	}
+	s.metrics.readBytes.observe(float64(len(page.Markdown)))
+
+	content, chunk, err := paginateContent(page.Markdown, offset, maxLength)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	truncated := page.Truncated || capped && chunk.NextOffset != nil
+	switch {
+	case page.Truncated:
+		content += "\n\n[content truncated: the page exceeds the download size limit]"
+	case truncated:
+		content += "\n\n[content truncated]"
+	}
+	if chunk.NextOffset != nil {
+		content += fmt.Sprintf("\n\n[Showing characters %d-%d of %d. Call %s with offset=%d to continue.]",
+			chunk.Offset, chunk.Offset+chunk.Length, chunk.TotalLength, s.toolName("searxng_read"), *chunk.NextOffset)
+	}
 
-	return mcp.NewToolResultText(content), nil
+	var result *mcp.CallToolResult
+	if page.HTML != "" {
+		result = mcp.NewToolResultResource(content, mcp.TextResourceContents{
+			URI:      url,
+			MIMEType: "text/html",
+			Text:     page.HTML,
+		})
+	} else {
+		result = mcp.NewToolResultText(content)
+	}
+	metadata := readMetadata{Freshness: page.Freshness, FetchVariant: page.FetchVariant, StructuredData: page.StructuredData, Truncated: truncated, Mirror: page.Mirror, ContentType: page.ContentType, Range: page.Range}
+	if paginated || truncated {
+		metadata.readChunk = &chunk
+	}
+	if metadata.readChunk != nil || metadata.Freshness != nil || metadata.FetchVariant != "" || metadata.StructuredData != nil || metadata.Mirror != "" || metadata.ContentType != "" || metadata.Range != nil {
+		result.StructuredContent = metadata
+	}
+	return result, nil
 }
 
 // ServeStdio runs the server in stdio mode
@@ -189,15 +1036,72 @@ func (s *Server) ServeStdio() error {
 	return mcpserver.ServeStdio(s.mcpServer)
 }
 
-// ServeHTTP runs the server in HTTP mode using StreamableHTTP
+// ServeHTTP runs the server in HTTP mode using StreamableHTTP, until
+// Shutdown is called
 func (s *Server) ServeHTTP(addr string) error {
 	log.WithField("address", addr).Info("starting MCP server in HTTP mode")
 
-	httpServer := mcpserver.NewStreamableHTTPServer(s.mcpServer)
-	return httpServer.Start(addr)
+	mux := http.NewServeMux()
+	server := &http.Server{Handler: s.httpMiddleware(mux)}
+	httpServer := mcpserver.NewStreamableHTTPServer(s.mcpServer,
+		mcpserver.WithStreamableHTTPServer(server),
+	)
+	mux.Handle("/mcp", s.lifecycle.stream(httpServer))
+	s.handleStatus(mux)
+	return s.listen(server, func() error { return httpServer.Start(addr) })
+}
+
+// ServeSSE runs the server in HTTP mode using the legacy SSE transport
+// (GET /sse for the event stream, POST /message for requests), for clients
+// that don't support StreamableHTTP yet
+func (s *Server) ServeSSE(addr string) error {
+	log.WithField("address", addr).Info("starting MCP server in SSE mode")
+
+	mux := http.NewServeMux()
+	server := &http.Server{Handler: s.httpMiddleware(mux)}
+	sseServer := mcpserver.NewSSEServer(s.mcpServer,
+		mcpserver.WithHTTPServer(server),
+	)
+	mux.Handle("/sse", s.lifecycle.stream(sseServer))
+	mux.Handle("/message", sseServer)
+	s.handleStatus(mux)
+	return s.listen(server, func() error { return sseServer.Start(addr) })
+}
+
+// listen runs start, the blocking Start of a transport using server, unless
+// Shutdown has started. The http.ErrServerClosed returned after Shutdown
+// is not an error.
+func (s *Server) listen(server *http.Server, start func() error) error {
+	if !s.lifecycle.serving(server) {
+		return nil
+	}
+	if err := start(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handleStatus registers the probe endpoints on mux, and the status page,
+// metrics and image proxy endpoints when enabled
+func (s *Server) handleStatus(mux *http.ServeMux) {
+	mux.Handle(HealthPath, s.HealthHandler())
+	mux.Handle(ReadyPath, s.ReadyHandler())
+	if s.options.StatusPath != "" {
+		log.WithField("path", s.options.StatusPath).Info("serving status page")
+		mux.Handle(s.options.StatusPath, s.StatusHandler())
+	}
+	if s.options.MetricsPath != "" {
+		log.WithField("path", s.options.MetricsPath).Info("serving metrics")
+		mux.Handle(s.options.MetricsPath, s.MetricsHandler())
+	}
+	if s.imageProxy != nil {
+		log.WithField("path", s.imageProxy.path).Info("serving image proxy")
+		mux.Handle(s.imageProxy.path, s.imageProxy)
+	}
 }
 
-// MCPServer returns the underlying MCP server for advanced usage
+// MCPServer returns the underlying MCP server for advanced usage: the one
+// passed to RegisterTools, or the one created by NewWithOptions
 func (s *Server) MCPServer() *mcpserver.MCPServer {
 	return s.mcpServer
 }
@@ -226,9 +1130,9 @@ func formatSearchResults(resp *searxng.SearchResponse) map[string]interface{} {
 		"results":       results,
 	}
 
-	if len(resp.Suggestions) > 0 {
-		suggestions := make([]interface{}, len(resp.Suggestions))
-		for i, s := range resp.Suggestions {
+	if picked := selectSuggestions(resp.Query, resp.Suggestions, maxResultSuggestions); len(picked) > 0 {
+		suggestions := make([]interface{}, len(picked))
+		for i, s := range picked {
 			suggestions[i] = s
 		}
 		output["suggestions"] = suggestions
@@ -240,16 +1144,29 @@ func formatSearchResults(resp *searxng.SearchResponse) map[string]interface{} {
 			answers[i] = a
 		}
 		output["answers"] = answers
+		output["answer_confidence"] = assessAnswers(resp)
+	}
+
+	if len(resp.Infoboxes) > 0 {
+		output["infobox_confidence"] = assessInfoboxes(resp)
 	}
 
-	if len(resp.Corrections) > 0 {
-		corrections := make([]interface{}, len(resp.Corrections))
-		for i, c := range resp.Corrections {
+	if picked := selectSuggestions(resp.Query, resp.Corrections, maxResultCorrections); len(picked) > 0 {
+		corrections := make([]interface{}, len(picked))
+		for i, c := range picked {
 			corrections[i] = c
 		}
 		output["corrections"] = corrections
 	}
 
+	if resp.Partial {
+		output["partial"] = true
+	}
+
+	if len(resp.DecodeWarnings) > 0 {
+		output["decode_warnings"] = resp.DecodeWarnings
+	}
+
 	if len(resp.UnresponsiveEngines) > 0 {
 		engines := make([]map[string]string, len(resp.UnresponsiveEngines))
 		for i, e := range resp.UnresponsiveEngines {
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+// sessionCapability is the key of the experimental client capability
+// carrying the defaults of a session, e.g.
+//
+//	{"capabilities": {"experimental": {"searxng": {
+//		"instance_url": "https://search.example.org",
+//		"language": "de",
+//		"safe_search": "strict"
+//	}}}}
+const sessionCapability = "searxng"
+
+// sessionDefaults are the search defaults a client sets for its session
+// when initializing. Tool arguments override them.
+type sessionDefaults struct {
+	instanceURL string // must be in the instance allowlist
+	language    string
+	safeSearch  searxng.SafeSearch
+}
+
+// parseSessionDefaults reads the defaults of the searxng experimental
+// capability; clients without it have none
+func parseSessionDefaults(capabilities mcp.ClientCapabilities) (sessionDefaults, error) {
+	var defaults sessionDefaults
+	raw, ok := capabilities.Experimental[sessionCapability]
+	if !ok || raw == nil {
+		return defaults, nil
+	}
+	config, ok := raw.(map[string]any)
+	if !ok {
+		return defaults, fmt.Errorf("the %s capability must be an object", sessionCapability)
+	}
+	for key, value := range config {
+		s, ok := value.(string)
+		if !ok {
+			return defaults, fmt.Errorf("%s.%s must be a string", sessionCapability, key)
+		}
+		switch key {
+		case "instance_url":
+			defaults.instanceURL = s
+		case "language":
+			defaults.language = s
+		case "safe_search":
+			level, err := searxng.ParseSafeSearch(s)
+			if err != nil {
+				return defaults, fmt.Errorf("%s.safe_search: %w", sessionCapability, err)
+			}
+			defaults.safeSearch = level
+		default:
+			return defaults, fmt.Errorf("unknown %s option %q", sessionCapability, key)
+		}
+	}
+	return defaults, nil
+}
+
+// sessionDefaultsOf returns the defaults of the session of ctx, if any
+func sessionDefaultsOf(ctx context.Context) (sessionDefaults, error) {
+	session, ok := mcpserver.ClientSessionFromContext(ctx).(mcpserver.SessionWithClientInfo)
+	if !ok {
+		return sessionDefaults{}, nil
+	}
+	return parseSessionDefaults(session.GetClientCapabilities())
+}
+
+// sessionClient returns the client of instanceURL, else of the session's
+// default instance, else the server's. Either URL must be in the instance
+// allowlist.
+func (s *Server) sessionClient(ctx context.Context, instanceURL string) (*searxng.Client, error) {
+	if instanceURL == "" {
+		defaults, err := sessionDefaultsOf(ctx)
+		if err != nil {
+			return nil, err
+		}
+		instanceURL = defaults.instanceURL
+	}
+	return s.instances.client(instanceURL)
+}
+
+// applySessionDefaults fills the language and safe search level of req the
+// call left unset from the session's defaults. Invalid defaults are
+// reported by sessionClient.
+func applySessionDefaults(ctx context.Context, req *searxng.SearchRequest) {
+	defaults, err := sessionDefaultsOf(ctx)
+	if err != nil {
+		return
+	}
+	if req.Language == "" {
+		req.Language = defaults.language
+	}
+	if req.SafeSearch == searxng.SafeSearchInstance {
+		req.SafeSearch = defaults.safeSearch
+	}
+}
+
+// checkSessionDefaults logs the session defaults of an initializing
+// client, warning when they are invalid or their instance isn't allowed,
+// as tool calls of the session will then fail
+func (s *Server) checkSessionDefaults(ctx context.Context, _ any, message *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+	defaults, err := parseSessionDefaults(message.Params.Capabilities)
+	if err == nil && defaults.instanceURL != "" {
+		_, err = s.instances.client(defaults.instanceURL)
+	}
+	fields := logrus.Fields{"client": message.Params.ClientInfo.Name}
+	if err != nil {
+		log.WithFields(fields).WithField("error", err).Warn("invalid session defaults")
+		return
+	}
+	if defaults != (sessionDefaults{}) {
+		fields["instance_url"], fields["language"], fields["safe_search"] = defaults.instanceURL, defaults.language, defaults.safeSearch.String()
+		log.WithFields(fields).Debug("session defaults")
+	}
+}
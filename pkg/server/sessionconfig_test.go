@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withSessionDefaults(srv *Server, config map[string]any) context.Context {
+	session := mcpserver.NewInProcessSession("session", nil)
+	session.SetClientCapabilities(mcp.ClientCapabilities{Experimental: map[string]any{"searxng": config}})
+	return srv.MCPServer().WithContext(context.Background(), session)
+}
+
+func TestParseSessionDefaults(t *testing.T) {
+	defaults, err := parseSessionDefaults(mcp.ClientCapabilities{})
+	require.NoError(t, err)
+	assert.Equal(t, sessionDefaults{}, defaults)
+
+	defaults, err = parseSessionDefaults(mcp.ClientCapabilities{Experimental: map[string]any{"searxng": map[string]any{
+		"instance_url": "https://search.example.org", "language": "de", "safe_search": "strict",
+	}}})
+	require.NoError(t, err)
+	assert.Equal(t, sessionDefaults{instanceURL: "https://search.example.org", language: "de", safeSearch: searxng.SafeSearchStrict}, defaults)
+
+	for wantErr, config := range map[string]map[string]any{
+		`searxng.safe_search: invalid safe search level "lax" (must be one of: off, moderate, strict)`: {"safe_search": "lax"},
+		`unknown searxng option "engines"`:  {"engines": "wikipedia"},
+		"searxng.language must be a string": {"language": 1.0},
+	} {
+		_, err := parseSessionDefaults(mcp.ClientCapabilities{Experimental: map[string]any{"searxng": config}})
+		assert.EqualError(t, err, wantErr)
+	}
+}
+
+func TestSessionDefaults_Instance(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://other.example.com").
+		Get("/autocompleter").
+		MatchParam("q", "golang").
+		MatchParam("language", "de").
+		Reply(200).
+		BodyString(`["golang", ["golang tutorial"]]`)
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{InstanceAllowlist: []string{"https://other.example.com"}})
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name: "searxng_suggestions", Arguments: map[string]interface{}{"query": "golang"},
+	}}
+
+	ctx := withSessionDefaults(srv, map[string]any{"instance_url": "https://other.example.com/", "language": "de"})
+	result, err := srv.handleSuggestions(ctx, request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, []string{"golang tutorial"}, result.StructuredContent.(map[string]interface{})["suggestions"])
+
+	ctx = withSessionDefaults(srv, map[string]any{"instance_url": "https://unlisted.example.com"})
+	result, err = srv.handleSuggestions(ctx, request)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "is not in the server's instance allowlist")
+}
+
+func TestApplySessionDefaults(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+	ctx := withSessionDefaults(srv, map[string]any{"language": "fr", "safe_search": "moderate"})
+
+	req := searxng.SearchRequest{Query: "q"}
+	applySessionDefaults(ctx, &req)
+	assert.Equal(t, "fr", req.Language)
+	assert.Equal(t, searxng.SafeSearchModerate, req.SafeSearch)
+
+	req = searxng.SearchRequest{Query: "q", Language: "it", SafeSearch: searxng.SafeSearchOff}
+	applySessionDefaults(ctx, &req)
+	assert.Equal(t, "it", req.Language, "arguments win")
+	assert.Equal(t, searxng.SafeSearchOff, req.SafeSearch)
+}
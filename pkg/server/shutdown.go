@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"google.golang.org/grpc"
+)
+
+// lifecycle tracks the in-flight tool calls, the event streams and the
+// HTTP and gRPC servers, so that Shutdown can drain the calls before
+// closing the connections
+type lifecycle struct {
+	mu         sync.Mutex
+	closing    bool
+	active     int
+	calls      sync.WaitGroup
+	httpServer *http.Server // set by ServeHTTP and ServeSSE
+	grpcServer *grpc.Server // set by ServeGRPC
+
+	// streams ends the event streams (see stream) once the tool calls have
+	// drained
+	streams      context.Context
+	closeStreams context.CancelFunc
+}
+
+func newLifecycle() *lifecycle {
+	l := &lifecycle{}
+	l.streams, l.closeStreams = context.WithCancel(context.Background())
+	return l
+}
+
+// middleware rejects tool calls once Shutdown has started and counts the
+// others until they return
+func (l *lifecycle) middleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		l.mu.Lock()
+		if l.closing {
+			l.mu.Unlock()
+			return mcp.NewToolResultError("server is shutting down"), nil
+		}
+		l.active++
+		l.calls.Add(1)
+		l.mu.Unlock()
+
+		defer func() {
+			l.mu.Lock()
+			l.active--
+			l.mu.Unlock()
+			l.calls.Done()
+		}()
+		return next(ctx, request)
+	}
+}
+
+// stream marks the GET requests to next, the long-lived event streams of
+// the StreamableHTTP and SSE transports, to be ended by Shutdown. Other
+// requests are left for http.Server.Shutdown to await.
+func (l *lifecycle) stream(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(l.streams, cancel)
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// serving records the HTTP server of the transport, returning false when
+// Shutdown has already started
+func (l *lifecycle) serving(httpServer *http.Server) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.httpServer = httpServer
+	return !l.closing
+}
+
+// servingGRPC records the gRPC server of ServeGRPC, returning false when
+// Shutdown has already started
+func (l *lifecycle) servingGRPC(grpcServer *grpc.Server) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.grpcServer = grpcServer
+	return !l.closing
+}
+
+// closed reports whether Shutdown has started
+func (l *lifecycle) closed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closing
+}
+
+// Shutdown stops the server gracefully: the HTTP and gRPC listeners are
+// closed and new tool calls are refused, then Shutdown waits for the
+// in-flight calls to return, ends the event streams and waits for the
+// remaining HTTP requests, so the results of the calls reach the clients.
+// When ctx ends first, the connections are closed anyway and the error says
+// how many calls were abandoned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	l := s.lifecycle
+	l.mu.Lock()
+	l.closing = true
+	httpServer := l.httpServer
+	grpcServer := l.grpcServer
+	active := l.active
+	l.mu.Unlock()
+
+	log.WithField("in_flight_calls", active).Info("shutting down, draining tool calls")
+	var httpShutdown chan error
+	if httpServer != nil {
+		// Closes the listeners and idle connections right away, then waits
+		// for the requests in flight; the streams are ended below
+		httpShutdown = make(chan error, 1)
+		go func() { httpShutdown <- httpServer.Shutdown(ctx) }()
+	}
+	if grpcServer != nil {
+		// Stops accepting RPCs; those running are tool calls, drained below
+		go grpcServer.GracefulStop()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		l.calls.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		l.mu.Lock()
+		active = l.active
+		l.mu.Unlock()
+		err = fmt.Errorf("%d tool calls still running: %w", active, ctx.Err())
+	}
+
+	// Streams are never idle, so http.Server.Shutdown would wait for them
+	// until ctx ends. They are ended once the calls whose results they may
+	// carry have returned.
+	l.closeStreams()
+	if httpServer != nil {
+		if shutdownErr := <-httpShutdown; shutdownErr != nil {
+			if closeErr := httpServer.Close(); closeErr != nil {
+				shutdownErr = closeErr
+			}
+			if err == nil {
+				err = fmt.Errorf("HTTP requests still running: %w", shutdownErr)
+			}
+		}
+	}
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+	return err
+}
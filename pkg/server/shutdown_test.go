@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callToolResult sends a tools/call message through the MCP server and
+// returns the tool result
+func callToolResult(t *testing.T, srv *Server, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	return callToolResultIn(t, context.Background(), srv, name, args)
+}
+
+// callToolResultIn is callToolResult in the MCP session of ctx
+func callToolResultIn(t *testing.T, ctx context.Context, srv *Server, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	msg, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	require.NoError(t, err)
+	resp, ok := srv.MCPServer().HandleMessage(ctx, msg).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result, ok := resp.Result.(*mcp.CallToolResult)
+	require.True(t, ok)
+	return result
+}
+
+// slowSite serves pages once release is closed, signalling each request on
+// started
+func slowSite(t *testing.T) (site *httptest.Server, started chan struct{}, release chan struct{}) {
+	started, release = make(chan struct{}, 8), make(chan struct{})
+	site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("done"))
+	}))
+	t.Cleanup(site.Close)
+	return site, started, release
+}
+
+func TestShutdown_DrainsToolCalls(t *testing.T) {
+	site, started, release := slowSite(t)
+	srv := New(nil)
+
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		results <- callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+
+	// New calls are refused while the running one drains
+	require.Eventually(t, func() bool {
+		srv.lifecycle.mu.Lock()
+		defer srv.lifecycle.mu.Unlock()
+		return srv.lifecycle.closing
+	}, time.Second, time.Millisecond)
+	refused := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+	require.True(t, refused.IsError)
+	assert.Equal(t, "server is shutting down", refused.Content[0].(mcp.TextContent).Text)
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned before the in-flight call")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	result := <-results
+	assert.False(t, result.IsError)
+	assert.Equal(t, "done", result.Content[0].(mcp.TextContent).Text)
+	require.NoError(t, <-shutdown)
+}
+
+func TestShutdown_Timeout(t *testing.T) {
+	site, started, release := slowSite(t)
+	defer close(release)
+	srv := New(nil)
+
+	go callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": site.URL})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := srv.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "1 tool calls still running")
+}
+
+// freeAddr returns a local address nothing listens on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return addr
+}
+
+func TestShutdown_DrainsHTTPCalls(t *testing.T) {
+	site, started, release := slowSite(t)
+	addr := freeAddr(t)
+	srv := New(nil)
+	go srv.ServeHTTP(addr) //nolint:errcheck
+
+	post := func(sessionID string, body string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, "http://"+addr+"/mcp", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		return http.DefaultClient.Do(req)
+	}
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	_ = resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+
+	// The event stream of the session is ended by Shutdown
+	streamReq, err := http.NewRequest(http.MethodGet, "http://"+addr+"/mcp", nil)
+	require.NoError(t, err)
+	streamReq.Header.Set("Accept", "text/event-stream")
+	streamReq.Header.Set("Mcp-Session-Id", sessionID)
+	stream, err := http.DefaultClient.Do(streamReq)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	type reply struct {
+		body []byte
+		err  error
+	}
+	replies := make(chan reply, 1)
+	go func() {
+		resp, err := post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"searxng_read","arguments":{"url":"`+site.URL+`"}}}`)
+		if err != nil {
+			replies <- reply{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		replies <- reply{body: body, err: err}
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- srv.Shutdown(ctx)
+	}()
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned before the in-flight call")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	got := <-replies
+	require.NoError(t, got.err, "the connection stays open until the result is sent")
+	assert.True(t, bytes.Contains(got.body, []byte(`"text":"done"`)), string(got.body))
+	select {
+	case err := <-shutdown:
+		require.NoError(t, err, "the stream doesn't hold up Shutdown")
+	case <-time.After(3 * time.Second):
+		t.Fatal("Shutdown didn't return after the call")
+	}
+	_, err = io.ReadAll(stream.Body)
+	assert.NoError(t, err, "the stream is ended")
+}
+
+func TestShutdown_StopsServeHTTP(t *testing.T) {
+	addr := freeAddr(t)
+
+	srv := New(nil)
+	served := make(chan error, 1)
+	go func() { served <- srv.ServeHTTP(addr) }()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			_ = conn.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ServeHTTP didn't return after Shutdown")
+	}
+	_, err := net.Dial("tcp", addr)
+	assert.Error(t, err, "the listener is closed")
+}
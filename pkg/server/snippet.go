@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/sirupsen/logrus"
+)
+
+// maxExpandedSnippet is the maximum length in characters of a snippet
+// expanded from a cached page
+const maxExpandedSnippet = 400
+
+// Bounds of the enrich option of searxng_search
+const (
+	// maxEnrichedSnippet is the maximum length in characters of a snippet
+	// taken from the start of a page
+	maxEnrichedSnippet = 500
+	defaultEnrichCount = 3
+	maxEnrichCount     = 10
+)
+
+var (
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	sentenceEnd   = regexp.MustCompile(`[.!?](\s+|$)`)
+)
+
+// expandSnippets replaces the snippets of results whose page is in the read
+// cache with the passage of the page most relevant to query, marking them
+// with snippet_source "cached_page". It makes no network requests.
+func (s *Server) expandSnippets(results []map[string]interface{}, query string) {
+	if s.readCache == nil {
+		return
+	}
+	terms := queryTerms(query)
+	for _, result := range results {
+		content, ok := s.readCache.content(result["url"].(string))
+		if !ok {
+			continue
+		}
+		if excerpt := relevantExcerpt(content, terms, maxExpandedSnippet); excerpt != "" {
+			result["snippet"] = excerpt
+			result["snippet_source"] = "cached_page"
+		}
+	}
+}
+
+// enrichSnippets reads the pages of the first count results concurrently
+// and replaces their snippets with the start of the article text, marking
+// them with snippet_source "page". Results already expanded from the read
+// cache are skipped, and results whose page can't be read keep the
+// engine's snippet. It returns the number of enriched results.
+func (s *Server) enrichSnippets(ctx context.Context, results []map[string]interface{}, count int) int {
+	opts := s.defaultReadOptions()
+	opts.Mode = ReadModeArticle
+
+	var enriched atomic.Int64
+	var wg sync.WaitGroup
+	for _, result := range results[:min(count, len(results))] {
+		if _, ok := result["snippet_source"]; ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url := result["url"].(string)
+			if err := s.checkRead(ctx, url); err != nil {
+				log.WithFields(logrus.Fields{"url": url, "error": err}).Debug("not enriching search result")
+				return
+			}
+			page, err := s.fetchPage(ctx, url, opts)
+			if err != nil {
+				log.WithFields(logrus.Fields{"url": url, "error": err}).Debug("reading search result to enrich failed")
+				return
+			}
+			if text := compactText(plainText(page.Markdown), maxEnrichedSnippet); text != "" {
+				result["snippet"] = text
+				result["snippet_source"] = "page"
+				enriched.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	return int(enriched.Load())
+}
+
+// queryTerms returns the lowercased words of a query worth looking for,
+// without search operators (site:, filetype:, -excluded) and one-letter
+// words
+func queryTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(field, "-") || strings.Contains(field, ":") {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if utf8.RuneCountInString(word) > 1 {
+				terms = append(terms, word)
+			}
+		}
+	}
+	return terms
+}
+
+// relevantExcerpt returns the sentence of content matching the most
+// distinct terms, followed by the next sentences of its paragraph up to
+// maxLength characters. Sentences of a few words, typically headings, lose
+// ties. It returns "" when no sentence matches.
+func relevantExcerpt(content string, terms []string, maxLength int) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	sentences := splitSentences(plainText(content))
+	best, bestScore, bestShort := -1, 0, true
+	for i, candidate := range sentences {
+		lower := strings.ToLower(candidate.text)
+		score := 0
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				score++
+			}
+		}
+		short := len(strings.Fields(candidate.text)) < 5
+		if score > bestScore || (score == bestScore && score > 0 && bestShort && !short) {
+			best, bestScore, bestShort = i, score, short
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+
+	excerpt := sentences[best].text
+	for _, next := range sentences[best+1:] {
+		if next.paragraph != sentences[best].paragraph ||
+			utf8.RuneCountInString(excerpt)+1+utf8.RuneCountInString(next.text) > maxLength {
+			break
+		}
+		excerpt += " " + next.text
+	}
+	return compactText(excerpt, maxLength)
+}
+
+// plainText strips the Markdown syntax that would clutter a snippet:
+// images, link targets, headings, emphasis and list markers
+func plainText(markdown string) string {
+	markdown = markdownImage.ReplaceAllString(markdown, "")
+	markdown = markdownLink.ReplaceAllString(markdown, "$1")
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		line = strings.TrimLeft(strings.TrimSpace(line), "#>*-+ ")
+		lines[i] = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sentence is a sentence of a page and the index of its paragraph
+type sentence struct {
+	text      string
+	paragraph int
+}
+
+// splitSentences splits text into sentences. Every line (paragraph,
+// heading, list item) is a paragraph of its own.
+func splitSentences(text string) []sentence {
+	var sentences []sentence
+	for paragraph, line := range strings.Split(text, "\n") {
+		start := 0
+		for _, end := range sentenceEnd.FindAllStringIndex(line, -1) {
+			if part := strings.TrimSpace(line[start : end[0]+1]); part != "" {
+				sentences = append(sentences, sentence{part, paragraph})
+			}
+			start = end[1]
+		}
+		if rest := strings.TrimSpace(line[start:]); rest != "" {
+			sentences = append(sentences, sentence{rest, paragraph})
+		}
+	}
+	return sentences
+}
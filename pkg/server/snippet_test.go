@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cachedGoPage = `# The Go Programming Language
+
+![gopher](https://go.dev/gopher.png)
+
+Go is an open source programming language. It makes it simple to build software.
+
+## Generics
+
+Go 1.18 added **generics**, also known as [type parameters](https://go.dev/doc/tutorial/generics). Type parameters let functions work on several types. They were the most requested feature.
+
+- Download Go
+- Read the docs`
+
+func TestQueryTerms(t *testing.T) {
+	assert.Equal(t, []string{"go", "generics", "tutorial"}, queryTerms(`Go "generics" tutorial site:go.dev -rust a`))
+	assert.Empty(t, queryTerms("filetype:pdf"))
+}
+
+func TestRelevantExcerpt(t *testing.T) {
+	excerpt := relevantExcerpt(cachedGoPage, queryTerms("go generics type parameters"), 200)
+	assert.Equal(t, "Go 1.18 added generics, also known as type parameters. Type parameters let functions work on several types. They were the most requested feature.", excerpt)
+
+	excerpt = relevantExcerpt(cachedGoPage, queryTerms("generics"), 40)
+	assert.Equal(t, "Go 1.18 added generics, also known as…", excerpt, "headings lose ties")
+
+	assert.Empty(t, relevantExcerpt(cachedGoPage, queryTerms("haskell monads"), 200))
+	assert.Empty(t, relevantExcerpt(cachedGoPage, nil, 200))
+}
+
+func TestSplitSentences(t *testing.T) {
+	assert.Equal(t, []sentence{{"One.", 0}, {"Two?", 0}, {"Heading", 1}, {"v1.2 is out!", 3}},
+		splitSentences("One. Two?\nHeading\n\nv1.2 is out!"))
+}
+
+func TestHandleWebSearch_ExpandSnippets(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(2).
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "go type parameters", Results: []searxng.APIResult{
+			{URL: "https://go.dev/", Title: "Go", Content: "Build simple systems"},
+			{URL: "https://example.com/", Title: "Example", Content: "Not read yet"},
+		}})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{ReadCacheTTL: time.Minute})
+	srv.readCache.put("https://go.dev/", readOptions{}, &readResult{Markdown: cachedGoPage})
+
+	search := func(expand bool) []map[string]interface{} {
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{
+				"query": "go type parameters", "expand_snippets": expand, "limit": float64(2),
+			}},
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		return result.StructuredContent.(map[string]interface{})["results"].([]map[string]interface{})
+	}
+
+	results := search(false)
+	assert.Equal(t, "Build simple systems", results[0]["snippet"])
+
+	results = search(true)
+	assert.Contains(t, results[0]["snippet"], "type parameters")
+	assert.Equal(t, "cached_page", results[0]["snippet_source"])
+	assert.Equal(t, "Not read yet", results[1]["snippet"])
+	assert.NotContains(t, results[1], "snippet_source")
+}
+
+func TestHandleWebSearch_Enrich(t *testing.T) {
+	gock.Off()
+
+	pages := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/article":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><body><nav><a href="/">Home</a></nav><article><h1>Generics</h1><p>Go 1.18 added <strong>type parameters</strong>. ` +
+				strings.Repeat("More text. ", 100) + `</p></article></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer pages.Close()
+
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(searxng.APIResponse{
+			Query: r.URL.Query().Get("q"),
+			Results: []searxng.APIResult{
+				{URL: pages.URL + "/article", Title: "Article", Content: "engine snippet"},
+				{URL: pages.URL + "/missing", Title: "Missing", Content: "kept snippet"},
+				{URL: pages.URL + "/article?beyond", Title: "Beyond", Content: "not read"},
+			},
+		})
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL})
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_search", map[string]interface{}{
+		"query": "generics", "enrich": true, "enrich_count": 2.0,
+	})
+	require.False(t, result.IsError)
+	output := result.StructuredContent.(map[string]interface{})
+	assert.Equal(t, 1, output["enriched"])
+	results := output["results"].([]map[string]interface{})
+	require.Len(t, results, 3)
+	assert.Equal(t, "page", results[0]["snippet_source"])
+	assert.True(t, strings.HasPrefix(results[0]["snippet"].(string), "Generics Go 1.18 added type parameters."), results[0]["snippet"])
+	assert.LessOrEqual(t, len([]rune(results[0]["snippet"].(string))), maxEnrichedSnippet+1)
+	assert.Equal(t, "kept snippet", results[1]["snippet"])
+	assert.NotContains(t, results[1], "snippet_source")
+	assert.Equal(t, "not read", results[2]["snippet"])
+
+	result = callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "generics", "enrich": true, "enrich_count": 0.0})
+	assert.True(t, result.IsError)
+}
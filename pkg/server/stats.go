@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// ServerStats is a point-in-time snapshot of server counters, keyed by tool
+// name. Counters are monotonic for the lifetime of the server.
+type ServerStats struct {
+	// ToolCalls is the number of calls per tool
+	ToolCalls map[string]uint64
+	// ToolErrors is the number of calls per tool that returned an error
+	// result or a protocol error
+	ToolErrors map[string]uint64
+	// RecentCalls holds the most recent tool calls, newest first
+	RecentCalls []ToolCall
+	// Client holds the stats of the underlying Searxng client
+	Client searxng.ClientStats
+}
+
+// ToolCall describes a finished tool call
+type ToolCall struct {
+	Tool     string
+	Start    time.Time
+	Duration time.Duration
+	Error    bool
+}
+
+// maxRecentCalls is the number of tool calls kept for ServerStats.RecentCalls
+const maxRecentCalls = 20
+
+// toolStats holds the live counters behind ServerStats
+type toolStats struct {
+	mu        sync.Mutex
+	calls     map[string]uint64
+	errors    map[string]uint64
+	durations map[string]*histogram
+	recent    []ToolCall // oldest first
+}
+
+func newToolStats() *toolStats {
+	return &toolStats{
+		calls:     make(map[string]uint64),
+		errors:    make(map[string]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// middleware counts tool calls and errors and records their latency
+func (ts *toolStats) middleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		failed := err != nil || (result != nil && result.IsError)
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		ts.calls[request.Params.Name]++
+		if failed {
+			ts.errors[request.Params.Name]++
+		}
+		call := ToolCall{
+			Tool:     request.Params.Name,
+			Start:    start,
+			Duration: time.Since(start),
+			Error:    failed,
+		}
+		h, ok := ts.durations[call.Tool]
+		if !ok {
+			h = newHistogram(durationBuckets)
+			ts.durations[call.Tool] = h
+		}
+		h.observe(call.Duration.Seconds())
+		ts.recent = append(ts.recent, call)
+		if len(ts.recent) > maxRecentCalls {
+			ts.recent = ts.recent[1:]
+		}
+
+		return result, err
+	}
+}
+
+// duration returns the latency histogram of tool, or nil if it was never
+// called
+func (ts *toolStats) duration(tool string) *histogram {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.durations[tool]
+}
+
+// Stats returns a snapshot of the server's counters, including those of the
+// Searxng client. It is safe for concurrent use.
+func (s *Server) Stats() ServerStats {
+	s.toolStats.mu.Lock()
+	defer s.toolStats.mu.Unlock()
+
+	recent := slices.Clone(s.toolStats.recent)
+	slices.Reverse(recent)
+
+	return ServerStats{
+		ToolCalls:   maps.Clone(s.toolStats.calls),
+		ToolErrors:  maps.Clone(s.toolStats.errors),
+		RecentCalls: recent,
+		Client:      s.searxngClient.Stats(),
+	}
+}
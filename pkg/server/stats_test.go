@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callTool sends a tools/call JSON-RPC message through the MCP server so
+// that server-level middleware runs
+func callTool(t *testing.T, srv *Server, name string, args map[string]interface{}) {
+	t.Helper()
+
+	msg, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      name,
+			"arguments": args,
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, srv.MCPServer().HandleMessage(context.Background(), msg))
+}
+
+func TestServer_Stats(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		MatchParam("q", "golang").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "golang"})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	stats := srv.Stats()
+	assert.Empty(t, stats.ToolCalls)
+	assert.NotNil(t, stats.ToolCalls)
+
+	callTool(t, srv, "searxng_search", map[string]interface{}{"query": "golang"})
+	callTool(t, srv, "searxng_search", map[string]interface{}{})
+	callTool(t, srv, "searxng_read", map[string]interface{}{"url": "ftp://example.com"})
+
+	stats = srv.Stats()
+	assert.Equal(t, uint64(2), stats.ToolCalls["searxng_search"])
+	assert.Equal(t, uint64(1), stats.ToolErrors["searxng_search"])
+	assert.Equal(t, uint64(1), stats.ToolCalls["searxng_read"])
+	assert.Equal(t, uint64(1), stats.ToolErrors["searxng_read"])
+	assert.Equal(t, uint64(1), stats.Client.Searches)
+	assert.Equal(t, uint64(1), stats.Client.Requests)
+	assert.Zero(t, stats.Client.Failures)
+}
+
+func TestServer_Stats_RecentCalls(t *testing.T) {
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	for i := 0; i < maxRecentCalls+5; i++ {
+		callTool(t, srv, "searxng_search", map[string]interface{}{})
+	}
+	callTool(t, srv, "searxng_read", map[string]interface{}{})
+
+	recent := srv.Stats().RecentCalls
+	require.Len(t, recent, maxRecentCalls)
+	assert.Equal(t, "searxng_read", recent[0].Tool, "newest first")
+	assert.True(t, recent[0].Error)
+	assert.False(t, recent[0].Start.IsZero())
+}
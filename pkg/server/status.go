@@ -0,0 +1,137 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+)
+
+// statusPingTimeout bounds the instance health check of the status page
+const statusPingTimeout = 3 * time.Second
+
+var statusTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>searxng-mcp status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+.ok { color: #2a7d2a; } .err { color: #b22; }
+</style>
+</head>
+<body>
+<h1>searxng-mcp</h1>
+
+<h2>Instance</h2>
+<table>
+<tr><th>URL</th><td>{{.InstanceURL}}</td></tr>
+<tr><th>Health</th><td>{{if .HealthErr}}<span class="err">{{.HealthErr}}</span>{{else}}<span class="ok">healthy</span>{{end}} ({{.HealthLatency}})</td></tr>
+<tr><th>Searches</th><td>{{.Stats.Client.Searches}}</td></tr>
+<tr><th>Failed searches</th><td>{{.Stats.Client.Failures}}</td></tr>
+<tr><th>Retries</th><td>{{.Stats.Client.Retries}}</td></tr>
+<tr><th>Cache hit rate</th><td>{{.CacheHitRate}}</td></tr>
+</table>
+
+<h2>Tools</h2>
+<table>
+<tr><th>Tool</th><th>Calls</th><th>Errors</th></tr>
+{{range .Tools}}<tr><td>{{.Name}}</td><td>{{.Calls}}</td><td>{{.Errors}}</td></tr>
+{{else}}<tr><td colspan="3">no calls yet</td></tr>
+{{end}}</table>
+
+<h2>Engines</h2>
+<table>
+<tr><th>Engine</th><th>Results</th><th>Only found by it</th></tr>
+{{range .Engines}}<tr><td>{{.Name}}</td><td>{{.Results}}</td><td>{{.Unique}}</td></tr>
+{{else}}<tr><td colspan="3">no results yet</td></tr>
+{{end}}</table>
+
+<h2>Recent calls</h2>
+<table>
+<tr><th>Time</th><th>Tool</th><th>Duration</th><th>Result</th></tr>
+{{range .Stats.RecentCalls}}<tr><td>{{.Start.Format "2006-01-02 15:04:05"}}</td><td>{{.Tool}}</td><td>{{.Duration}}</td><td>{{if .Error}}<span class="err">error</span>{{else}}<span class="ok">ok</span>{{end}}</td></tr>
+{{else}}<tr><td colspan="4">no calls yet</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type statusTool struct {
+	Name   string
+	Calls  uint64
+	Errors uint64
+}
+
+type statusEngine struct {
+	Name    string
+	Results uint64
+	Unique  uint64
+}
+
+type statusData struct {
+	InstanceURL   string
+	HealthErr     error
+	HealthLatency time.Duration
+	CacheHitRate  string
+	Tools         []statusTool
+	Engines       []statusEngine
+	Stats         ServerStats
+}
+
+// StatusHandler returns an HTTP handler rendering a minimal HTML status page
+// with instance health, tool call counts, per-engine result counts, recent
+// calls and cache hit rate.
+// Each request pings the instance.
+func (s *Server) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), statusPingTimeout)
+		defer cancel()
+
+		start := time.Now()
+		healthErr := s.searxngClient.Ping(ctx)
+		data := statusData{
+			InstanceURL:   s.searxngClient.BaseURL(),
+			HealthErr:     healthErr,
+			HealthLatency: time.Since(start).Round(time.Millisecond),
+			Stats:         s.Stats(),
+		}
+
+		client := data.Stats.Client
+		if lookups := client.CacheHits + client.CacheMisses; lookups > 0 {
+			data.CacheHitRate = fmt.Sprintf("%.1f%% (%d of %d)", 100*float64(client.CacheHits)/float64(lookups), client.CacheHits, lookups)
+		} else {
+			data.CacheHitRate = "n/a"
+		}
+		for name, calls := range data.Stats.ToolCalls {
+			data.Tools = append(data.Tools, statusTool{Name: name, Calls: calls, Errors: data.Stats.ToolErrors[name]})
+		}
+		slices.SortFunc(data.Tools, func(a, b statusTool) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		for name, results := range client.EngineResults {
+			data.Engines = append(data.Engines, statusEngine{Name: name, Results: results, Unique: client.EngineUniqueResults[name]})
+		}
+		slices.SortFunc(data.Engines, func(a, b statusEngine) int {
+			if c := cmp.Compare(b.Results, a.Results); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := statusTemplate.Execute(w, data); err != nil {
+			log.WithField("error", err).Error("failed to render status page")
+		}
+	})
+}
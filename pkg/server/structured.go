@@ -0,0 +1,169 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/denysvitali/searxng-mcp/internal/log"
+)
+
+// maxStructuredItems caps the JSON-LD objects and microdata items kept per
+// page, so pages listing hundreds of products don't flood the output
+const maxStructuredItems = 20
+
+// structuredData is the schema.org and OpenGraph data embedded in a page.
+// Prices, recipes, events and article dates are more reliable from it than
+// from the converted text.
+type structuredData struct {
+	// JSONLD holds the objects of application/ld+json scripts, with
+	// @graph containers flattened
+	JSONLD []map[string]interface{} `json:"json_ld,omitempty"`
+	// Microdata holds the top-level itemscope items
+	Microdata []microdataItem `json:"microdata,omitempty"`
+	// OpenGraph maps og:, article: and product: meta properties to their
+	// content, e.g. "product:price:amount"
+	OpenGraph map[string]string `json:"open_graph,omitempty"`
+}
+
+// microdataItem is an itemscope element. Properties holds a single value
+// for properties given once and a list otherwise; nested items are
+// microdataItems.
+type microdataItem struct {
+	Type       string                 `json:"type,omitempty"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// extractStructuredData collects the structured data of doc, nil when there
+// is none. It must run before sanitizeHTML, which removes the scripts.
+func extractStructuredData(doc *goquery.Document) *structuredData {
+	data := &structuredData{}
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		data.JSONLD = append(data.JSONLD, parseJSONLD(s.Text())...)
+		return len(data.JSONLD) < maxStructuredItems
+	})
+	if len(data.JSONLD) > maxStructuredItems {
+		data.JSONLD = data.JSONLD[:maxStructuredItems]
+	}
+
+	doc.Find("[itemscope]").Not("[itemprop]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		data.Microdata = append(data.Microdata, microdata(s))
+		return len(data.Microdata) < maxStructuredItems
+	})
+
+	doc.Find("meta[property]").Each(func(i int, s *goquery.Selection) {
+		property := strings.ToLower(strings.TrimSpace(s.AttrOr("property", "")))
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+		if content == "" || !(strings.HasPrefix(property, "og:") ||
+			strings.HasPrefix(property, "article:") || strings.HasPrefix(property, "product:")) {
+			return
+		}
+		if data.OpenGraph == nil {
+			data.OpenGraph = map[string]string{}
+		}
+		if _, seen := data.OpenGraph[property]; !seen {
+			data.OpenGraph[property] = content
+		}
+	})
+
+	if len(data.JSONLD) == 0 && len(data.Microdata) == 0 && len(data.OpenGraph) == 0 {
+		return nil
+	}
+	return data
+}
+
+// parseJSONLD decodes the content of a JSON-LD script into its objects.
+// Invalid blocks are skipped: they are common and not worth failing a read.
+func parseJSONLD(text string) []map[string]interface{} {
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "<!--"), "-->")
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		log.WithField("error", err).Debug("skipping invalid JSON-LD")
+		return nil
+	}
+
+	var objects []map[string]interface{}
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		case map[string]interface{}:
+			if graph, ok := v["@graph"]; ok {
+				collect(graph)
+				return
+			}
+			objects = append(objects, v)
+		}
+	}
+	collect(value)
+	return objects
+}
+
+// microdata converts the itemscope element s into an item
+func microdata(s *goquery.Selection) microdataItem {
+	item := microdataItem{
+		Type:       strings.TrimSpace(s.AttrOr("itemtype", "")),
+		Properties: map[string]interface{}{},
+	}
+	values := map[string][]interface{}{}
+	var order []string
+	s.Find("[itemprop]").Each(func(i int, prop *goquery.Selection) {
+		// Only properties of this item, not of nested ones
+		if owner := prop.Parent().Closest("[itemscope]"); owner.Length() == 0 || owner.Get(0) != s.Get(0) {
+			return
+		}
+		var value interface{}
+		if _, nested := prop.Attr("itemscope"); nested {
+			value = microdata(prop)
+		} else {
+			value = microdataValue(prop)
+		}
+		for _, name := range strings.Fields(prop.AttrOr("itemprop", "")) {
+			if _, seen := values[name]; !seen {
+				order = append(order, name)
+			}
+			values[name] = append(values[name], value)
+		}
+	})
+	for _, name := range order {
+		if len(values[name]) == 1 {
+			item.Properties[name] = values[name][0]
+		} else {
+			item.Properties[name] = values[name]
+		}
+	}
+	return item
+}
+
+// microdataValue returns the value of a property element, following the
+// microdata rules for the elements whose value is an attribute
+func microdataValue(prop *goquery.Selection) string {
+	attr := ""
+	switch goquery.NodeName(prop) {
+	case "meta":
+		attr = "content"
+	case "a", "link", "area":
+		attr = "href"
+	case "img", "audio", "video", "source", "iframe", "embed", "track":
+		attr = "src"
+	case "object":
+		attr = "data"
+	case "time":
+		attr = "datetime"
+	case "data", "meter":
+		attr = "value"
+	}
+	if attr != "" {
+		if value, ok := prop.Attr(attr); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	if content, ok := prop.Attr("content"); ok {
+		return strings.TrimSpace(content)
+	}
+	return strings.Join(strings.Fields(prop.Text()), " ")
+}
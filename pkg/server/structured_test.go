@@ -0,0 +1,100 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const structuredPage = `<!DOCTYPE html>
+<html><head>
+<title>Espresso machine</title>
+<meta property="og:title" content="Espresso machine">
+<meta property="og:type" content="product">
+<meta property="product:price:amount" content="249.00">
+<meta property="product:price:currency" content="EUR">
+<meta name="description" content="not OpenGraph">
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "Product", "name": "Espresso machine", "offers": {"@type": "Offer", "price": "249.00", "priceCurrency": "EUR"}},
+  {"@type": "BreadcrumbList", "itemListElement": []}
+]}
+</script>
+<script type="application/ld+json">{"@type": "Organization", "name": "Shop",}</script>
+<script type="application/ld+json">[{"@type": "WebSite", "name": "Shop"}]</script>
+</head><body>
+<div itemscope itemtype="https://schema.org/Recipe">
+  <h1 itemprop="name">Pancakes</h1>
+  <time itemprop="cookTime" datetime="PT15M">15 minutes</time>
+  <span itemprop="recipeIngredient">Flour</span>
+  <span itemprop="recipeIngredient">Milk</span>
+  <div itemprop="author" itemscope itemtype="https://schema.org/Person">
+    <span itemprop="name">Ann</span>
+    <a itemprop="url" href="https://example.com/ann">profile</a>
+  </div>
+</div>
+<p>Buy it now.</p>
+</body></html>`
+
+func TestExtractStructuredData(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(structuredPage))
+	require.NoError(t, err)
+	data := extractStructuredData(doc)
+	require.NotNil(t, data)
+
+	require.Len(t, data.JSONLD, 3, "@graph is flattened, invalid blocks skipped")
+	assert.Equal(t, "Product", data.JSONLD[0]["@type"])
+	assert.Equal(t, "249.00", data.JSONLD[0]["offers"].(map[string]interface{})["price"])
+	assert.Equal(t, "BreadcrumbList", data.JSONLD[1]["@type"])
+	assert.Equal(t, "WebSite", data.JSONLD[2]["@type"])
+
+	require.Len(t, data.Microdata, 1, "nested items belong to their parent")
+	recipe := data.Microdata[0]
+	assert.Equal(t, "https://schema.org/Recipe", recipe.Type)
+	assert.Equal(t, "Pancakes", recipe.Properties["name"])
+	assert.Equal(t, "PT15M", recipe.Properties["cookTime"])
+	assert.Equal(t, []interface{}{"Flour", "Milk"}, recipe.Properties["recipeIngredient"])
+	assert.Equal(t, microdataItem{
+		Type:       "https://schema.org/Person",
+		Properties: map[string]interface{}{"name": "Ann", "url": "https://example.com/ann"},
+	}, recipe.Properties["author"])
+
+	assert.Equal(t, map[string]string{
+		"og:title":               "Espresso machine",
+		"og:type":                "product",
+		"product:price:amount":   "249.00",
+		"product:price:currency": "EUR",
+	}, data.OpenGraph)
+}
+
+func TestExtractStructuredData_None(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><p>Plain</p></body></html>`))
+	require.NoError(t, err)
+	assert.Nil(t, extractStructuredData(doc))
+}
+
+func TestHandleWebRead_StructuredData(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(structuredPage))
+	}))
+	defer site.Close()
+
+	result := callToolResult(t, New(nil), "searxng_read", map[string]interface{}{"url": site.URL})
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Buy it now.")
+	assert.NotContains(t, text, "@graph")
+	metadata, ok := result.StructuredContent.(readMetadata)
+	require.True(t, ok)
+	require.NotNil(t, metadata.StructuredData)
+	assert.Len(t, metadata.StructuredData.JSONLD, 3)
+	assert.Len(t, metadata.StructuredData.Microdata, 1)
+	assert.Equal(t, "EUR", metadata.StructuredData.OpenGraph["product:price:currency"])
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults and bounds of searxng_suggestions
+const (
+	defaultSuggestionsLimit = 10
+	maxSuggestionsLimit     = 20
+)
+
+// Caps of the suggestions and corrections of search results: instances
+// can return dozens, of little value next to the results
+const (
+	maxResultSuggestions = 5
+	maxResultCorrections = 3
+)
+
+// selectSuggestions returns the first limit of items that aren't
+// near-duplicates of query or of an earlier item, i.e. made of the same
+// words in any order, ignoring case and punctuation
+func selectSuggestions(query string, items []string, limit int) []string {
+	seen := map[string]bool{wordSetKey(query): true}
+	selected := make([]string, 0, min(limit, len(items)))
+	for _, item := range items {
+		if len(selected) == limit {
+			break
+		}
+		key := wordSetKey(item)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		selected = append(selected, strings.TrimSpace(item))
+	}
+	return selected
+}
+
+// wordSetKey returns the sorted distinct words of s as normalized by
+// normalizeText
+func wordSetKey(s string) string {
+	words := strings.Fields(normalizeText(s))
+	slices.Sort(words)
+	return strings.Join(slices.Compact(words), " ")
+}
+
+// suggestionsTool returns the definition of searxng_suggestions
+func suggestionsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "searxng_suggestions",
+		Description: "Get autocomplete suggestions for a query from the Searxng instance. Useful to expand or refine a vague query before running a full search; much cheaper than a search.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:     "object",
+			Required: []string{"query"},
+			Properties: map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The partial or vague query to complete",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Language of the suggestions as a locale code, e.g. 'de' (default: the instance's setting)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of suggestions to return (default: 10, min: 1, max: 20)",
+					"minimum":     1,
+					"maximum":     maxSuggestionsLimit,
+				},
+			},
+		},
+	}
+}
+
+// handleSuggestions handles the searxng_suggestions tool call
+func (s *Server) handleSuggestions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debug("handling searxng_suggestions")
+	ctx, span := startSpan(ctx, "searxng_suggestions")
+	defer span.End()
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments format"), nil
+	}
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+	language, _ := args["language"].(string)
+	limit := defaultSuggestionsLimit
+	if l, ok := args["limit"].(float64); ok && l >= 1 {
+		limit = min(int(l), maxSuggestionsLimit)
+	}
+
+	client, err := s.sessionClient(ctx, "")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if language == "" {
+		defaults, _ := sessionDefaultsOf(ctx)
+		language = defaults.language
+	}
+	suggestions, err := client.Suggestions(ctx, query, language)
+	if err != nil {
+		spanError(ctx, err)
+		log.WithFields(logrus.Fields{"error": err}).Error("autocomplete failed")
+		return mcp.NewToolResultError(fmt.Sprintf("autocomplete failed: %v", err)), nil
+	}
+	suggestions = selectSuggestions(query, suggestions, limit)
+
+	text := fmt.Sprintf("No suggestions for %q; the instance may have no autocomplete backend configured.", query)
+	if len(suggestions) > 0 {
+		text = "Suggestions:\n- " + strings.Join(suggestions, "\n- ")
+	}
+	return mcp.NewToolResultStructured(
+		map[string]interface{}{"query": query, "suggestions": suggestions},
+		text,
+	), nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSuggestions(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/autocompleter").
+		MatchParam("q", "golang").
+		Reply(200).
+		BodyString(`["golang", ["golang tutorial", "golang generics", "golang vs rust"]]`)
+	gock.New("https://searxng.example.com").
+		Get("/autocompleter").
+		MatchParam("q", "xyzzy").
+		Reply(200).
+		BodyString(`["xyzzy", []]`)
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_suggestions", map[string]interface{}{"query": "golang", "limit": 2.0})
+	require.False(t, result.IsError)
+	output := result.StructuredContent.(map[string]interface{})
+	assert.Equal(t, []string{"golang tutorial", "golang generics"}, output["suggestions"])
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "- golang generics")
+
+	result = callToolResult(t, srv, "searxng_suggestions", map[string]interface{}{"query": "xyzzy"})
+	require.False(t, result.IsError)
+	assert.Empty(t, result.StructuredContent.(map[string]interface{})["suggestions"])
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No suggestions")
+
+	result = callToolResult(t, srv, "searxng_suggestions", map[string]interface{}{"query": " "})
+	assert.True(t, result.IsError)
+}
+
+func TestSelectSuggestions(t *testing.T) {
+	items := []string{"Golang Generics", "generics golang", "golang  generics tutorial", "golang generics", "golang, generics!", "", "golang tutorial generics", "rust generics"}
+	assert.Equal(t, []string{"golang  generics tutorial", "rust generics"}, selectSuggestions("golang generics", items, 5),
+		"the query in another case, order or punctuation and repeated word sets are dropped")
+	assert.Equal(t, []string{"golang  generics tutorial"}, selectSuggestions("golang generics", items, 1))
+	assert.Equal(t, []string{"golang"}, selectSuggestions("golnag", []string{"golang", "Golang"}, 3), "corrections differ from the query")
+	assert.NotNil(t, selectSuggestions("q", nil, 3))
+}
+
+func TestFormatSearchResults_CapsSuggestions(t *testing.T) {
+	resp := &searxng.SearchResponse{
+		Query:       "golang generics",
+		Suggestions: []string{"generics golang", "golang generics tutorial", "golang generics example", "golang generics constraints", "golang generics performance", "golang generics interface", "golang generics type inference"},
+		Corrections: []string{"golang generic", "Golang Generics"},
+	}
+	output := formatSearchResults(resp)
+	assert.Equal(t, []interface{}{"golang generics tutorial", "golang generics example", "golang generics constraints", "golang generics performance", "golang generics interface"}, output["suggestions"])
+	assert.Equal(t, []interface{}{"golang generic"}, output["corrections"])
+
+	resp.Suggestions, resp.Corrections = []string{"Golang generics"}, []string{"golang generics"}
+	output = formatSearchResults(resp)
+	assert.NotContains(t, output, "suggestions", "nothing left to suggest")
+	assert.NotContains(t, output, "corrections")
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMaxRequestTimeout is the default bound of the timeout_seconds of
+// searxng_search and searxng_read
+const DefaultMaxRequestTimeout = 2 * time.Minute
+
+// maxRequestTimeout returns the bound of timeout_seconds (see
+// Options.MaxRequestTimeout)
+func (s *Server) maxRequestTimeout() time.Duration {
+	if s.options.MaxRequestTimeout > 0 {
+		return s.options.MaxRequestTimeout
+	}
+	return DefaultMaxRequestTimeout
+}
+
+// requestTimeout returns the timeout_seconds argument, cut to the server's
+// maximum; 0 when it isn't given
+func (s *Server) requestTimeout(args map[string]interface{}) (time.Duration, error) {
+	seconds, ok := args["timeout_seconds"].(float64)
+	if !ok {
+		return 0, nil
+	}
+	if seconds <= 0 {
+		return 0, fmt.Errorf("timeout_seconds must be positive")
+	}
+	return min(time.Duration(seconds*float64(time.Second)), s.maxRequestTimeout()), nil
+}
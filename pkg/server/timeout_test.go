@@ -0,0 +1,39 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	srv := NewWithOptions(nil, Options{MaxRequestTimeout: time.Minute})
+	for _, tc := range []struct {
+		args map[string]interface{}
+		want time.Duration
+	}{
+		{map[string]interface{}{}, 0},
+		{map[string]interface{}{"timeout_seconds": 1.5}, 1500 * time.Millisecond},
+		{map[string]interface{}{"timeout_seconds": 600.0}, time.Minute},
+	} {
+		timeout, err := srv.requestTimeout(tc.args)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, timeout, tc.args)
+	}
+	_, err := srv.requestTimeout(map[string]interface{}{"timeout_seconds": 0.0})
+	assert.EqualError(t, err, "timeout_seconds must be positive")
+
+	timeout, _ := New(nil).requestTimeout(map[string]interface{}{"timeout_seconds": 1e6})
+	assert.Equal(t, DefaultMaxRequestTimeout, timeout)
+}
+
+func TestReadOptions_Client(t *testing.T) {
+	opts := readOptions{}
+	assert.Same(t, pageClient(nil, nil), opts.client(nil))
+	assert.Equal(t, defaultHTTPTimeout, opts.client(nil).Timeout)
+
+	opts.Timeout = 90 * time.Second
+	assert.Equal(t, 90*time.Second, opts.client(nil).Timeout)
+	assert.Equal(t, defaultHTTPTimeout, pageClient(nil, nil).Timeout, "the shared client is left unchanged")
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+// ToolLogging controls whether tool call arguments and results, which hold
+// the queries, URLs and page contents of the agent, are logged
+type ToolLogging string
+
+const (
+	ToolLoggingNone      ToolLogging = "none"
+	ToolLoggingTruncated ToolLogging = "truncated"
+	ToolLoggingFull      ToolLogging = "full"
+)
+
+// maxLoggedToolText bounds the arguments and result text logged by
+// ToolLoggingTruncated, in bytes
+const maxLoggedToolText = 256
+
+// ParseToolLogging parses a tool logging mode name. An empty string maps
+// to ToolLoggingNone.
+func ParseToolLogging(s string) (ToolLogging, error) {
+	switch ToolLogging(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ToolLoggingNone:
+		return ToolLoggingNone, nil
+	case ToolLoggingTruncated:
+		return ToolLoggingTruncated, nil
+	case ToolLoggingFull:
+		return ToolLoggingFull, nil
+	default:
+		return "", fmt.Errorf("invalid tool logging mode: %s (must be 'none', 'truncated' or 'full')", s)
+	}
+}
+
+// logToolCalls logs the arguments and result of each tool call at info
+// level, whole or truncated depending on Options.LogToolResults
+func (s *Server) logToolCalls(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	mode := s.options.LogToolResults
+	if mode != ToolLoggingTruncated && mode != ToolLoggingFull {
+		return next
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		args, _ := json.Marshal(request.Params.Arguments)
+		fields := logrus.Fields{
+			"tool":      request.Params.Name,
+			"arguments": loggedToolText(string(args), mode),
+			"duration":  time.Since(start),
+		}
+		switch {
+		case err != nil:
+			fields["error"] = err
+		case result != nil:
+			fields["result"] = loggedToolText(toolResultText(result), mode)
+			fields["is_error"] = result.IsError
+		}
+		log.WithFields(fields).Info("tool call")
+		return result, err
+	}
+}
+
+// toolResultText returns the text contents of result
+func toolResultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// loggedToolText cuts text to maxLoggedToolText bytes in truncated mode,
+// saying how much was left out
+func loggedToolText(text string, mode ToolLogging) string {
+	if mode == ToolLoggingFull || len(text) <= maxLoggedToolText {
+		return text
+	}
+	cut := maxLoggedToolText
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s… (%d more bytes)", text[:cut], len(text)-cut)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/internal/log"
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolLogging(t *testing.T) {
+	for input, want := range map[string]ToolLogging{
+		"":          ToolLoggingNone,
+		"none":      ToolLoggingNone,
+		"Truncated": ToolLoggingTruncated,
+		" full ":    ToolLoggingFull,
+	} {
+		got, err := ParseToolLogging(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	_, err := ParseToolLogging("debug")
+	assert.ErrorContains(t, err, "invalid tool logging mode: debug")
+}
+
+func TestLoggedToolText(t *testing.T) {
+	assert.Equal(t, "short", loggedToolText("short", ToolLoggingTruncated))
+
+	long := strings.Repeat("a", maxLoggedToolText-1) + "é" + strings.Repeat("b", 100)
+	assert.Equal(t, long, loggedToolText(long, ToolLoggingFull))
+	assert.Equal(t, strings.Repeat("a", maxLoggedToolText-1)+"… (102 more bytes)", loggedToolText(long, ToolLoggingTruncated),
+		"runes are not split")
+}
+
+func TestLogToolCalls(t *testing.T) {
+	defer gock.OffAll()
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Times(3).
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "secret query", Results: []searxng.APIResult{
+			{URL: "https://example.com/", Title: "Example", Content: strings.Repeat("long snippet ", 100)},
+		}})
+
+	hook := logtest.NewLocal(log.Get())
+	defer hook.Reset()
+	defer log.Get().SetLevel(log.Get().GetLevel())
+	log.Get().SetLevel(logrus.DebugLevel)
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	toolCallEntries := func() []*logrus.Entry {
+		var entries []*logrus.Entry
+		for _, entry := range hook.AllEntries() {
+			if entry.Message == "tool call" {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+	args := map[string]interface{}{"query": "secret query"}
+
+	callToolResult(t, New(client), "searxng_search", args)
+	assert.Empty(t, toolCallEntries(), "nothing is logged by default")
+	require.NotEmpty(t, hook.AllEntries())
+	for _, entry := range hook.AllEntries() {
+		assert.NotContains(t, fmt.Sprint(entry.Data), "secret query", entry.Message)
+	}
+
+	callToolResult(t, NewWithOptions(client, Options{LogToolResults: ToolLoggingTruncated}), "searxng_search", args)
+	entries := toolCallEntries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "searxng_search", entries[0].Data["tool"])
+	assert.Equal(t, `{"query":"secret query"}`, entries[0].Data["arguments"])
+	assert.Contains(t, entries[0].Data["result"], "more bytes)")
+	assert.Equal(t, false, entries[0].Data["is_error"])
+
+	hook.Reset()
+	callToolResult(t, NewWithOptions(client, Options{LogToolResults: ToolLoggingFull}), "searxng_search", args)
+	entries = toolCallEntries()
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Data["result"], strings.Repeat("long snippet ", 99))
+}
+
+func TestRefineSearch_KeepsQueryOutOfLogs(t *testing.T) {
+	defer gock.OffAll()
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "secret query"})
+
+	hook := logtest.NewLocal(log.Get())
+	defer hook.Reset()
+	defer log.Get().SetLevel(log.Get().GetLevel())
+	log.Get().SetLevel(logrus.DebugLevel)
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+
+	result := callToolResult(t, New(client), "searxng_refine_search",
+		map[string]interface{}{"query": "secret query", "feedback": "need recent, site:secret.example"})
+	require.False(t, result.IsError)
+	var refined *logrus.Entry
+	for _, entry := range hook.AllEntries() {
+		assert.NotContains(t, fmt.Sprint(entry.Data), "secret", entry.Message)
+		if entry.Message == "refined search" {
+			refined = entry
+		}
+	}
+	require.NotNil(t, refined)
+	assert.Equal(t, []string{"recent", "site"}, refined.Data["rules"])
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/denysvitali/searxng-mcp/pkg/searxng"
 	"github.com/h2non/gock"
@@ -280,6 +282,225 @@ func TestHandleWebRead(t *testing.T) {
 	assert.Contains(t, textContent.Text, "test page")
 }
 
+func TestHandleWebRead_IncludeHTML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>
+			<script>alert(1)</script>
+			<h1 id="title" onclick="steal()">Welcome</h1>
+			<a href="javascript:void(0)" data-sku="42">Buy</a>
+			<table class="prices"><tr><td>9.99</td></tr></table>
+		</body></html>`))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "searxng_read",
+			Arguments: map[string]interface{}{"url": ts.URL, "include_html": true},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Welcome")
+
+	resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	assert.Equal(t, ts.URL, resource.URI)
+	assert.Equal(t, "text/html", resource.MIMEType)
+	assert.Contains(t, resource.Text, `id="title"`)
+	assert.Contains(t, resource.Text, `data-sku="42"`)
+	assert.Contains(t, resource.Text, `class="prices"`)
+	assert.NotContains(t, resource.Text, "<script")
+	assert.NotContains(t, resource.Text, "onclick")
+	assert.NotContains(t, resource.Text, "javascript:")
+}
+
+func TestURLScheme(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com":        "https",
+		"  JavaScript:alert(1)":      "javascript",
+		"java\tscript:alert(1)":      "javascript",
+		"\x00\x01file:///etc/passwd": "file",
+		"data:text/html;base64,":     "data",
+		"/relative/path:colon":       "",
+		"page?x=a:b":                 "",
+		"#fragment":                  "",
+		"no colon":                   "",
+	}
+	for raw, want := range tests {
+		assert.Equal(t, want, urlScheme(raw), "%q", raw)
+	}
+}
+
+func TestHandleWebRead_DangerousSchemes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>
+			<a href="file:///etc/passwd">Passwords</a>
+			<a href=" JaVa&#09;Script:alert(1)">Click</a>
+			<a href="vbscript:msgbox">Old</a>
+			<img src="data:image/png;base64,AAAA" alt="file: report.pdf">
+			<a href="https://example.com/ok">Safe</a>
+		</body></html>`))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": ts.URL, "include_html": true})
+	require.False(t, result.IsError)
+	markdown := result.Content[0].(mcp.TextContent).Text
+	for _, blocked := range []string{"file:///", "Script:", "vbscript:", "data:image"} {
+		assert.NotContains(t, markdown, blocked)
+	}
+	assert.Contains(t, markdown, "Passwords", "the link text is kept")
+	assert.Contains(t, markdown, "[Safe](https://example.com/ok)")
+
+	html := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents).Text
+	assert.NotContains(t, html, "file:///")
+	assert.NotContains(t, html, "data:image")
+	assert.Contains(t, html, `alt="file: report.pdf"`, "only URL attributes are checked for file: and data:")
+}
+
+func TestHandleWebRead_RedirectToFile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	}))
+	defer ts.Close()
+
+	// A transport that can read files must still never be sent there
+	files := &http.Transport{}
+	files.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	srv := NewWithOptions(nil, Options{Transport: files})
+
+	result := callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": ts.URL})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "redirect to unsupported URL scheme file blocked")
+}
+
+func TestPaginateContent(t *testing.T) {
+	content := "héllo wörld"
+
+	chunk, meta, err := paginateContent(content, 0, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "héllo", chunk)
+	require.NotNil(t, meta.NextOffset)
+	assert.Equal(t, 5, *meta.NextOffset)
+	assert.Equal(t, 11, meta.TotalLength)
+
+	chunk, meta, err = paginateContent(content, 6, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "wörld", chunk)
+	assert.Nil(t, meta.NextOffset)
+
+	chunk, _, err = paginateContent(content, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, content, chunk)
+
+	_, _, err = paginateContent(content, 11, 5)
+	assert.Error(t, err)
+	_, _, err = paginateContent("", 0, 5)
+	assert.NoError(t, err)
+}
+
+func TestHandleWebRead_Pagination(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("0123456789abcdefghij"))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	read := func(args map[string]interface{}) *mcp.CallToolResult {
+		result, err := srv.handleWebRead(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: args},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := read(map[string]interface{}{"url": ts.URL, "max_length": float64(8)})
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, "01234567\n\n[Showing characters 0-8 of 20."))
+	assert.Contains(t, text, "offset=8")
+	chunk := result.StructuredContent.(readMetadata).readChunk
+	require.NotNil(t, chunk)
+	assert.Equal(t, 20, chunk.TotalLength)
+	assert.Equal(t, 8, *chunk.NextOffset)
+
+	result = read(map[string]interface{}{"url": ts.URL, "offset": float64(16), "max_length": float64(8)})
+	require.False(t, result.IsError)
+	assert.Equal(t, "ghij", result.Content[0].(mcp.TextContent).Text)
+	assert.Nil(t, result.StructuredContent.(readMetadata).NextOffset)
+
+	result = read(map[string]interface{}{"url": ts.URL, "offset": float64(50)})
+	assert.True(t, result.IsError)
+
+	// Without pagination arguments the whole page comes back as before
+	result = read(map[string]interface{}{"url": ts.URL})
+	assert.Equal(t, "0123456789abcdefghij", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, readMetadata{ContentType: "text/plain"}, result.StructuredContent)
+}
+
+func TestHandleWebRead_Limits(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/pdf" {
+			w.Header().Set("Content-Type", "application/pdf")
+		}
+		_, _ = w.Write([]byte("0123456789abcdefghij"))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	read := func(options Options, args map[string]interface{}) *mcp.CallToolResult {
+		result, err := NewWithOptions(client, options).handleWebRead(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: args},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := read(Options{ReadMaxBytes: 12}, map[string]interface{}{"url": ts.URL})
+	require.False(t, result.IsError)
+	assert.Equal(t, "0123456789ab\n\n[content truncated: the page exceeds the download size limit]", result.Content[0].(mcp.TextContent).Text)
+	metadata := result.StructuredContent.(readMetadata)
+	assert.True(t, metadata.Truncated)
+	assert.Nil(t, metadata.NextOffset)
+
+	result = read(Options{ReadMaxBytes: 12}, map[string]interface{}{"url": ts.URL + "/pdf"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "PDF exceeds the maximum download size of 12 bytes")
+
+	result = read(Options{ReadMaxLength: 8}, map[string]interface{}{"url": ts.URL, "max_length": float64(100)})
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, "01234567\n\n[content truncated]\n\n[Showing characters 0-8 of 20."))
+	metadata = result.StructuredContent.(readMetadata)
+	assert.True(t, metadata.Truncated)
+	assert.Equal(t, 8, *metadata.NextOffset)
+
+	// Pages within the limits come back whole, as do chunks the caller asked for
+	result = read(Options{ReadMaxLength: 20}, map[string]interface{}{"url": ts.URL})
+	assert.Equal(t, "0123456789abcdefghij", result.Content[0].(mcp.TextContent).Text)
+	assert.Nil(t, result.StructuredContent.(readMetadata).readChunk)
+	result = read(Options{ReadMaxLength: 8}, map[string]interface{}{"url": ts.URL, "max_length": float64(4)})
+	assert.False(t, result.StructuredContent.(readMetadata).Truncated)
+}
+
 func TestHandleWebRead_MissingURL(t *testing.T) {
 	config := searxng.DefaultConfig()
 	client, err := searxng.NewClient(config)
@@ -449,3 +670,173 @@ func TestNewServer(t *testing.T) {
 	assert.NotNil(t, srv)
 	assert.NotNil(t, srv.MCPServer())
 }
+
+func TestHandleWebRead_ReadRateLimit(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer site.Close()
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"q","results":[]}`))
+	}))
+	defer instance.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL, RateLimit: 1})
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{ReadRateLimit: 1, ReadRateBurst: 2})
+	read := func(ctx context.Context) *mcp.CallToolResult {
+		result, err := srv.handleWebRead(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_read", Arguments: map[string]interface{}{"url": site.URL}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.False(t, read(context.Background()).IsError)
+	assert.False(t, read(context.Background()).IsError, "within the burst")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result := read(ctx)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "read rate limit")
+
+	// Reads used up their bucket, not the searches'
+	search, err := srv.handleWebSearch(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{"query": "q"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, search.IsError)
+}
+
+func TestHandleWebSearch_RankBy(t *testing.T) {
+	defer gock.OffAll()
+
+	gock.New("https://searxng.example.com").
+		Get("/search").
+		Reply(200).
+		JSON(searxng.APIResponse{Query: "golang", Results: []searxng.APIResult{
+			{URL: "https://example.com/a", Title: "A", Score: 2, Engines: []string{"google"}},
+			{URL: "https://example.com/b", Title: "B", Score: 1, Engines: []string{"google", "bing"}},
+		}})
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := New(client)
+
+	search := func(rankBy string) *mcp.CallToolResult {
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: map[string]interface{}{"query": "golang", "rank_by": rankBy, "mode": "compact"}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := search("consensus")
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Less(t, strings.Index(text, "**B**"), strings.Index(text, "**A**"))
+
+	result = search("popularity")
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid ranking strategy")
+}
+
+func TestHandleWebSearch_SafeSearch(t *testing.T) {
+	defer gock.OffAll()
+
+	for _, tc := range []struct{ query, param string }{{"caller", "2"}, {"raised", "1"}, {"server", "1"}} {
+		gock.New("https://searxng.example.com").
+			Get("/search").
+			MatchParam("q", tc.query).
+			MatchParam("safesearch", tc.param).
+			Reply(200).
+			JSON(searxng.APIResponse{Query: tc.query})
+	}
+
+	client, err := searxng.NewClient(searxng.DefaultConfig())
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{SafeSearch: searxng.SafeSearchModerate})
+
+	search := func(query, safeSearch string) *mcp.CallToolResult {
+		args := map[string]interface{}{"query": query}
+		if safeSearch != "" {
+			args["safe_search"] = safeSearch
+		}
+		result, err := srv.handleWebSearch(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "searxng_search", Arguments: args},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.False(t, search("caller", "strict").IsError)
+	assert.False(t, search("raised", "off").IsError, "the server level is the least strict one")
+	assert.False(t, search("server", "").IsError)
+	assert.True(t, gock.IsDone())
+
+	result := search("invalid", "extreme")
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid safe search level")
+}
+
+func TestHandleWebSearch_Deadline(t *testing.T) {
+	gock.Off()
+
+	release := make(chan struct{})
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"query":"golang","results":[{"url":"https://go.dev","title":"Go","content":"The Go language"},{"url":"https://ex`))
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer instance.Close()
+	defer close(release)
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: instance.URL, Timeout: time.Minute})
+	require.NoError(t, err)
+	srv := New(client)
+
+	result := callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "golang", "deadline_ms": 100, "mode": "compact"})
+	require.False(t, result.IsError)
+	output := result.StructuredContent.(map[string]interface{})
+	assert.Equal(t, true, output["partial"])
+	assert.Len(t, output["results"], 1)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "https://go.dev")
+	assert.Contains(t, text, "Partial results")
+
+	result = callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "golang", "deadline_ms": 0})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "deadline_ms")
+}
+
+func TestServer_Read(t *testing.T) {
+	gock.Off()
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><h1>Title</h1><p>Body <script>alert(1)</script>text.</p></body></html>`))
+	}))
+	defer site.Close()
+
+	srv := NewWithOptions(nil, Options{RespectRobots: true})
+	page, err := srv.Read(context.Background(), ReadRequest{URL: site.URL + "/doc", IncludeHTML: true})
+	require.NoError(t, err)
+	assert.Contains(t, page.Markdown, "# Title")
+	assert.Contains(t, page.HTML, "<h1>Title</h1>")
+	assert.NotContains(t, page.HTML, "<script>", "the HTML is sanitized")
+
+	page, err = srv.Read(context.Background(), ReadRequest{URL: site.URL + "/doc"})
+	require.NoError(t, err)
+	assert.Empty(t, page.HTML)
+
+	_, err = srv.Read(context.Background(), ReadRequest{URL: site.URL + "/private"})
+	assert.Error(t, err, "robots.txt is honored")
+}
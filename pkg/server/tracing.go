@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans of the tool handlers
+const tracerName = "github.com/denysvitali/searxng-mcp/pkg/server"
+
+// startSpan starts a span of a tool handler with the global TracerProvider
+// (see otel.SetTracerProvider); without one, spans are no-ops. The spans
+// nest under the tools/call span of the tracing middleware, if installed,
+// and the searxng client's spans nest under them.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// spanError records err on the span of ctx
+func spanError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
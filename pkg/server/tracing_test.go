@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denysvitali/searxng-mcp/pkg/searxng"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestToolTracing(t *testing.T) {
+	gock.Off()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"query": "go", "results": [{"url": "https://go.dev/", "title": "Go"}]}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><h1>Traced</h1></body></html>`))
+	}))
+	defer ts.Close()
+
+	client, err := searxng.NewClient(&searxng.Config{BaseURL: ts.URL})
+	require.NoError(t, err)
+	srv := NewWithOptions(client, Options{ReadRateLimit: 10})
+
+	require.False(t, callToolResult(t, srv, "searxng_search", map[string]interface{}{"query": "go"}).IsError)
+	require.False(t, callToolResult(t, srv, "searxng_read", map[string]interface{}{"url": ts.URL + "/page"}).IsError)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "searxng_search")
+	require.Contains(t, spans, "searxng.search")
+	assert.Equal(t, spans["searxng_search"].SpanContext().SpanID(), spans["searxng.search"].Parent().SpanID(),
+		"the client's spans nest under the handler's")
+	assert.Equal(t, spans["searxng.search"].SpanContext().SpanID(), spans["GET"].Parent().SpanID())
+
+	require.Contains(t, spans, "searxng_read")
+	require.Contains(t, spans, "fetch_page")
+	assert.Equal(t, spans["searxng_read"].SpanContext().SpanID(), spans["fetch_page"].Parent().SpanID())
+	assert.Equal(t, spans["fetch_page"].SpanContext().SpanID(), spans["read_rate_limit_wait"].Parent().SpanID())
+}